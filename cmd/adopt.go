@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <session-or-path>...",
+	Short: "Record metadata for files that ended up in the trash directory by other means",
+	Long: `Adopt payloads that are in the trash directory without metadata, e.g. copied
+there by hand or left behind by an interrupted operation, so that list, restore
+and empty can manage them (see 'trash list --orphans').
+
+An argument is a session name (or a unique prefix), a path inside a session, or
+a file lying directly in the trash directory, which is moved into a new session.
+Adopted items have an unknown original location and are trashed "now"; restore
+them with --here.
+
+Examples:
+  trash adopt 20251217_010006
+  trash adopt ~/.local/share/trash/20251217_010006/report.pdf
+  trash adopt --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) > 0) {
+			i18n.Fprintf(os.Stderr, "Error: specify sessions or paths to adopt, or --all\n")
+			os.Exit(1)
+		}
+
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		adopted, failed := 0, 0
+		report := func(session string, items []config.RestoreItem, err error) {
+			for _, item := range items {
+				i18n.Printf("Adopted: %s [%s]\n", item.Name, session)
+			}
+			adopted += len(items)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed++
+			}
+		}
+
+		if all {
+			orphans, err := config.FindOrphans()
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+				os.Exit(1)
+			}
+			seen := make(map[string]bool)
+			for _, orphan := range orphans {
+				if seen[orphan.Session] {
+					continue
+				}
+				seen[orphan.Session] = true
+				if orphan.Reason == config.OrphanInvalidSession {
+					i18n.Fprintf(os.Stderr, "Skipping %s: its metadata is unusable; fix or remove the .restore file first\n", orphan.Session)
+					continue
+				}
+				items, err := config.AdoptSession(orphan.Session)
+				report(orphan.Session, items, err)
+			}
+		}
+
+		for _, arg := range args {
+			session, name, stray, err := adoptTarget(configDir, arg)
+			switch {
+			case err != nil:
+				report(arg, nil, err)
+			case stray:
+				trashDir, item, err := config.AdoptStrayFile(filepath.Join(configDir, name))
+				report(filepath.Base(trashDir), []config.RestoreItem{item}, err)
+			case name != "":
+				items, err := config.AdoptSession(session, name)
+				report(session, items, err)
+			default:
+				items, err := config.AdoptSession(session)
+				report(session, items, err)
+			}
+		}
+
+		if adopted > 0 {
+			bus.EmitChanged(bus.ReasonTrashed)
+			i18n.Printf("Adopted %d item(s); their original location is unknown, restore them with --here\n", adopted)
+		} else if failed == 0 {
+			i18n.Printf("Nothing to adopt\n")
+		}
+		if failed > 0 {
+			os.Exit(exitError)
+		}
+	},
+}
+
+// adoptTarget works out what an adopt argument names: a session, an entry of a
+// session, or a stray file directly in the trash directory
+func adoptTarget(configDir, arg string) (session, name string, stray bool, err error) {
+	if _, statErr := os.Lstat(arg); statErr != nil {
+		session, err := config.ResolveSession(arg)
+		if errors.Is(err, config.ErrNotFound) {
+			return "", "", false, errors.New(i18n.Sprintf("%s is neither a trash session nor a path in the trash", arg))
+		}
+		return session, "", false, err
+	}
+
+	absPath, err := filepath.Abs(arg)
+	if err != nil {
+		return "", "", false, err
+	}
+	store, _ := filepath.EvalSymlinks(configDir)
+	dir, _ := filepath.EvalSymlinks(filepath.Dir(absPath))
+	resolved := filepath.Join(dir, filepath.Base(absPath))
+	if store == "" || !config.IsWithin(resolved, store) {
+		return "", "", false, errors.New(i18n.Sprintf("%s is not in the trash directory %s", arg, configDir))
+	}
+
+	rel, _ := filepath.Rel(store, resolved)
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 3)
+	switch {
+	case len(parts) == 3 && config.IsItemID(parts[1]) && !strings.Contains(parts[2], "/"):
+		return parts[0], parts[1], false, nil // the payload in an item directory
+	case len(parts) == 3:
+		return "", "", false, errors.New(i18n.Sprintf("%s is inside a trashed item; adopt the item %s instead", arg, parts[1]))
+	case len(parts) == 2:
+		return parts[0], parts[1], false, nil
+	}
+
+	if info, err := os.Stat(resolved); err == nil && info.IsDir() {
+		return parts[0], "", false, nil
+	}
+	return "", parts[0], true, nil
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().Bool("all", false, "Adopt every orphan reported by 'list --orphans'")
+}
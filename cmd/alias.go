@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// expandAliases replaces an alias from the [aliases] table of config.toml in
+// the first argument with the words it stands for, following aliases of
+// aliases, and exits on a loop or a malformed alias. Like plugins, aliases
+// never shadow a built-in command or a file of the same name.
+func expandAliases(root *cobra.Command, args []string) []string {
+	aliases, _, err := config.Aliases()
+	if err != nil || len(aliases) == 0 {
+		return args
+	}
+
+	var chain []string
+	for len(args) > 0 && isAliasCandidate(root, args[0]) {
+		expansion, ok := aliases[args[0]]
+		if !ok {
+			break
+		}
+		chain = append(chain, args[0])
+		for _, seen := range chain[:len(chain)-1] {
+			if seen == args[0] {
+				i18n.Fprintf(os.Stderr, "Error: alias loop: %s\n", strings.Join(chain, " -> "))
+				os.Exit(1)
+			}
+		}
+
+		words, err := splitWords(expansion)
+		if err != nil || len(words) == 0 {
+			i18n.Fprintf(os.Stderr, "Error: invalid alias %s = %q in config.toml\n", args[0], expansion)
+			os.Exit(1)
+		}
+		args = append(words, args[1:]...)
+	}
+	return args
+}
+
+// isAliasCandidate reports whether name may stand for an alias: it is not a
+// flag, a built-in command or an existing file
+func isAliasCandidate(root *cobra.Command, name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return false
+	}
+	if found, _, err := root.Find([]string{name}); err == nil && found != root {
+		return false
+	}
+	_, err := os.Lstat(name)
+	return err != nil
+}
+
+// splitWords splits an alias into words like a shell would: at unquoted
+// whitespace, keeping single- and double-quoted text together and taking
+// a backslash outside single quotes to escape the next character
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// aliasFindings reports aliases in config.toml that are never used because a
+// built-in command of root has their name, or that cannot be expanded
+func aliasFindings(root *cobra.Command) []config.Finding {
+	aliases, invalid, err := config.Aliases()
+	if err != nil {
+		return nil
+	}
+	settingsPath, _ := config.GetSettingsPath()
+
+	var findings []config.Finding
+	sort.Strings(invalid)
+	for _, name := range invalid {
+		findings = append(findings, config.Finding{
+			Check:   "config",
+			Problem: fmt.Sprintf("alias %s in %s is not a string", name, settingsPath),
+			Fix:     fmt.Sprintf("write it as %s = \"command --flag\"", name),
+		})
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if found, _, err := root.Find([]string{name}); err == nil && found != root {
+			findings = append(findings, config.Finding{
+				Check:   "config",
+				Problem: fmt.Sprintf("alias %s in %s is never used: '%s' is a built-in command", name, settingsPath, found.CommandPath()),
+				Fix:     fmt.Sprintf("rename the alias in the [%s] table", config.AliasesTableName),
+			})
+			continue
+		}
+		if words, err := splitWords(aliases[name]); err != nil || len(words) == 0 {
+			findings = append(findings, config.Finding{
+				Check:   "config",
+				Problem: fmt.Sprintf("alias %s = %q in %s cannot be expanded", name, aliases[name], settingsPath),
+				Fix:     "close its quotes and give it at least a command",
+			})
+		}
+	}
+	return findings
+}
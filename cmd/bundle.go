@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bundle"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export or import the trash as a portable bundle",
+	Long: `Create a single self-describing archive of the trash (payloads, metadata and
+schema version) and apply it on another machine. Original paths under the old home
+directory are remapped to the new home directory automatically.
+
+Examples:
+  trash bundle create trash.tar.gz
+  trash bundle create old.tar.gz --session 20251217_010006
+  trash bundle apply trash.tar.gz`,
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create [bundle-file]",
+	Short: "Write trash sessions to a bundle file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sessions, _ := cmd.Flags().GetStringSlice("session")
+		for i, session := range sessions {
+			sessions[i] = resolveSessionRef(session)
+		}
+
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(sessions) == 0 {
+			if sessions, err = config.ListTrashSessions(); err != nil {
+				i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if len(sessions) == 0 {
+			i18n.Printf("Trash is empty, nothing to bundle\n")
+			return
+		}
+
+		file, err := os.Create(args[0])
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error creating bundle: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := bundle.Create(file, configDir, sessions); err != nil {
+			file.Close()
+			os.Remove(args[0])
+			i18n.Fprintf(os.Stderr, "Error creating bundle: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := file.Close(); err != nil {
+			i18n.Fprintf(os.Stderr, "Error writing bundle: %v\n", err)
+			os.Exit(1)
+		}
+
+		i18n.Printf("Bundled %d session(s) into %s\n", len(sessions), args[0])
+	},
+}
+
+var bundleApplyCmd = &cobra.Command{
+	Use:   "apply [bundle-file]",
+	Short: "Import a bundle into the trash",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		home, err := os.UserHomeDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error opening bundle: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		result, err := bundle.Apply(file, configDir, home)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error applying bundle: %v\n", err)
+			os.Exit(1)
+		}
+
+		if verbose {
+			i18n.Printf("Bundle from %s created %s\n", result.Manifest.Hostname, result.Manifest.CreatedAt)
+			if result.Manifest.Home != home {
+				i18n.Printf("Remapped %s -> %s\n", result.Manifest.Home, home)
+			}
+			for from, to := range result.Sessions {
+				if from != to {
+					i18n.Printf("Session %s imported as %s\n", from, to)
+				}
+			}
+		}
+
+		i18n.Printf("Imported %d item(s) in %d session(s)\n", result.Items, len(result.Sessions))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleApplyCmd)
+	bundleCreateCmd.Flags().StringSlice("session", nil, "Only bundle these sessions (repeatable)")
+	bundleCreateCmd.RegisterFlagCompletionFunc("session", completeSessions)
+}
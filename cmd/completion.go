@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// completeSessions offers existing session timestamps for flag completion,
+// described by their age and item count
+func completeSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return sessionCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRestoreTimestamp offers the sessions that hold the item being restored,
+// or every session when no item was given yet
+func completeRestoreTimestamp(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeSessions(cmd, args, toComplete)
+	}
+
+	normalize, _ := cmd.Flags().GetBool("normalize")
+	ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+	matcher := config.MatchOptions{Normalize: normalize, IgnoreCase: ignoreCase}
+
+	matchItem := func(item config.RestoreItem) bool {
+		return matcher.Equal(item.Name, args[0])
+	}
+	if useRegex, _ := cmd.Flags().GetBool("regex"); useRegex {
+		re, err := matcher.Regexp(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		matchItem = func(item config.RestoreItem) bool {
+			return matcher.MatchRegexp(re, item.Name)
+		}
+	}
+
+	return sessionCompletions(toComplete, matchItem), cobra.ShellCompDirectiveNoFileComp
+}
+
+// sessionCompletions lists sessions starting with prefix, newest first, that contain
+// at least one item accepted by matchItem (all sessions when matchItem is nil)
+func sessionCompletions(prefix string, matchItem func(config.RestoreItem) bool) []string {
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	var sessions []string
+	for _, entry := range items {
+		if !strings.HasPrefix(entry.Session, prefix) {
+			continue
+		}
+		if matchItem != nil && !matchItem(entry.Item) {
+			continue
+		}
+		if counts[entry.Session] == 0 {
+			sessions = append(sessions, entry.Session)
+		}
+		counts[entry.Session]++
+	}
+
+	now := time.Now()
+	var completions []string
+	for i := len(sessions) - 1; i >= 0; i-- {
+		session := sessions[i]
+		description := i18n.Sprintf("%d item(s)", counts[session])
+		if t, err := config.SessionTime(session); err == nil {
+			description = config.HumanizeAge(t, now) + ", " + description
+		}
+		completions = append(completions, session+"\t"+description)
+	}
+
+	return completions
+}
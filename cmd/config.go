@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change settings in config.toml",
+	Long: `View and change the settings stored in $XDG_CONFIG_HOME/trash/config.toml
+(~/.config/trash/config.toml).
+Values are validated before they are written.
+
+Tables named after a command, e.g. [list] or list.absolute = true, set defaults
+for its flags; [root] is plain trash. Edit those in config.toml itself.
+
+Examples:
+  trash config list
+  trash config get min_free
+  trash config set min_free 10GB
+  trash config set notify false
+  trash config unset min_free`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show every setting with its current value",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		settings := loadSettingsOrExit()
+
+		var records []settingRecord
+		for _, info := range config.SettingsInfo() {
+			value, set, _ := settings.Value(info.Name)
+			records = append(records, settingRecord{
+				Name:        info.Name,
+				Value:       value,
+				Default:     !set,
+				Description: info.Description,
+			})
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, records)
+			return
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		for _, record := range records {
+			value := fmt.Sprintf("%q", record.Value)
+			if record.Default {
+				value += i18n.Sprintf(" (default)")
+			}
+			fmt.Printf("%s = %s\n", record.Name, value)
+			if verbose {
+				fmt.Printf("    %s\n", record.Description)
+			}
+		}
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Print the value of a setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSettingNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		settings := loadSettingsOrExit()
+
+		value, _, err := settings.Value(args[0])
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set [key] [value]",
+	Short:             "Validate and store a setting",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSettingNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.SetSetting(args[0], args[1]); err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset [key]",
+	Short:             "Remove a setting so its default applies",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSettingNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.UnsetSetting(args[0]); err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// settingRecord is the structured (--output) representation of a setting
+type settingRecord struct {
+	Name        string `json:"name" yaml:"name"`
+	Value       string `json:"value" yaml:"value"`
+	Default     bool   `json:"default" yaml:"default"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// loadSettingsOrExit loads config.toml, exiting when it cannot be parsed
+func loadSettingsOrExit() *config.Settings {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return settings
+}
+
+// completeSettingNames offers the supported keys as the first argument
+func completeSettingNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, info := range config.SettingsInfo() {
+		names = append(names, info.Name+"\t"+info.Description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// cronMarker tags the crontab line managed by 'trash cron --install'
+const cronMarker = "# trash retention purge"
+
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Print or install a crontab entry for scheduled cleanup",
+	Long: `Print a crontab entry that runs 'trash prune' on a schedule, applying the
+configured retention policies (expired items and min_free).
+Use --install to add it to the current user's crontab, --vacuum to compact
+the metadata with 'trash vacuum' after each prune and --verify to check a
+batch of items for corruption with 'trash verify --incremental'.
+XDG_DATA_HOME and XDG_CONFIG_HOME are carried into the entry when set, so
+that it purges the same trash directory.
+
+Examples:
+  trash cron
+  trash cron --schedule "30 3 * * *"
+  trash cron --install --vacuum`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		schedule, _ := cmd.Flags().GetString("schedule")
+		install, _ := cmd.Flags().GetBool("install")
+		vacuum, _ := cmd.Flags().GetBool("vacuum")
+		verify, _ := cmd.Flags().GetBool("verify")
+
+		if len(strings.Fields(schedule)) != 5 {
+			i18n.Fprintf(os.Stderr, "Error: schedule must have 5 fields, got %q\n", schedule)
+			os.Exit(1)
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error locating trash executable: %v\n", err)
+			os.Exit(1)
+		}
+
+		trash := cronEnvironment() + cronQuote(executable)
+		command := fmt.Sprintf("%s prune --nice >/dev/null 2>&1", trash)
+		if vacuum {
+			command += fmt.Sprintf("; %s vacuum --nice >/dev/null 2>&1", trash)
+		}
+		if verify {
+			// Problems are printed, so that cron mails them
+			command += fmt.Sprintf("; %s verify --incremental --nice >/dev/null", trash)
+		}
+		entry := fmt.Sprintf("%s %s %s", schedule, command, cronMarker)
+
+		if !install {
+			fmt.Println(entry)
+			return
+		}
+
+		existing, err := readCrontab()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error reading crontab, leaving it unchanged: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Keep every other line as it is, replacing an entry installed before
+		var lines []string
+		if existing != "" {
+			for _, line := range strings.Split(strings.TrimSuffix(existing, "\n"), "\n") {
+				if !strings.HasSuffix(line, cronMarker) {
+					lines = append(lines, line)
+				}
+			}
+		}
+		lines = append(lines, entry)
+
+		crontab := exec.Command("crontab", "-")
+		crontab.Stdin = bytes.NewBufferString(strings.Join(lines, "\n") + "\n")
+		crontab.Stderr = os.Stderr
+		if err := crontab.Run(); err != nil {
+			i18n.Fprintf(os.Stderr, "Error installing crontab: %v\n", err)
+			os.Exit(1)
+		}
+
+		i18n.Printf("Installed crontab entry:\n%s\n", entry)
+	},
+}
+
+// readCrontab returns the current user's crontab, empty when there is none.
+// Any other failure is an error, so that installing never replaces a crontab
+// that could not be read.
+func readCrontab() (string, error) {
+	var stderr bytes.Buffer
+	list := exec.Command("crontab", "-l")
+	list.Stderr = &stderr
+	out, err := list.Output()
+	if err == nil {
+		return string(out), nil
+	}
+	// cronie, vixie cron and busybox all exit 1 with "no crontab for <user>"
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && strings.Contains(strings.ToLower(stderr.String()), "no crontab") {
+		return "", nil
+	}
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return "", fmt.Errorf("%w: %s", err, message)
+	}
+	return "", err
+}
+
+// cronEnvironment returns shell assignments of the XDG base directories set
+// in the environment, to run a command of the entry with. cron starts it with
+// little more than HOME, which would resolve to another trash directory and
+// config.toml than the ones in use when the entry was made.
+func cronEnvironment() string {
+	var assignments string
+	for _, name := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME"} {
+		if dir := os.Getenv(name); filepath.IsAbs(dir) {
+			assignments += name + "=" + cronQuote(dir) + " "
+		}
+	}
+	return assignments
+}
+
+// cronQuote quotes s for the shell cron runs a command with. cron itself
+// turns an unescaped % into a newline, so it is escaped too.
+func cronQuote(s string) string {
+	quoted := "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	return strings.ReplaceAll(quoted, "%", `\%`)
+}
+
+func init() {
+	rootCmd.AddCommand(cronCmd)
+	cronCmd.Flags().String("schedule", "0 * * * *", "Cron schedule for the purge")
+	cronCmd.Flags().Bool("install", false, "Install the entry into the user's crontab")
+	cronCmd.Flags().Bool("vacuum", false, "Also run 'trash vacuum' after the purge")
+	cronCmd.Flags().Bool("verify", false, "Also check a batch of items with 'trash verify --incremental'")
+}
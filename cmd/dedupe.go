@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Replace identical files in the trash with hard links",
+	Long: `Find files in the trash that are byte-identical to a file of another trashed
+item, e.g. the same download or build output trashed again and again, and
+replace them with hard links to the oldest copy, so the data is stored once.
+Files are compared by size, then SHA-256 checksum, then byte by byte.
+
+Only files with the same permissions, owner and modification time are
+linked, so that restoring either puts back what was trashed. Files with hard
+links outside the trash are left alone, so trashed data never changes with
+a live file. Restoring an item with linked files copies them out instead of
+moving them, so that changing them does not change the other items.
+
+'trash list --duplicates' shows whole items trashed more than once. Dedupe
+needs the trash directory on the local disk.
+
+Examples:
+  trash dedupe --dry-run
+  trash dedupe -v`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		links, err := config.Dedupe(dryRun)
+		var reclaimed uint64
+		for _, link := range links {
+			reclaimed += link.Size
+			if verbose && dryRun {
+				i18n.Printf("Would link %s to %s\n", link.Path, link.Target)
+			} else if verbose {
+				i18n.Printf("Linked %s to %s\n", link.Path, link.Target)
+			}
+		}
+
+		switch {
+		case len(links) == 0 && err == nil:
+			i18n.Printf("No duplicate files to link\n")
+		case dryRun:
+			i18n.Printf("Would replace %d duplicate file(s) with hard links, reclaiming %s\n", len(links), config.FormatSize(reclaimed))
+		case len(links) > 0:
+			i18n.Printf("Replaced %d duplicate file(s) with hard links, reclaimed %s\n", len(links), config.FormatSize(reclaimed))
+		}
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+	dedupeCmd.Flags().BoolP("dry-run", "n", false, "Only report what would be linked")
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// commandKey names cmd in config.toml: its path below trash joined with dots,
+// or "root" for trash itself
+func commandKey(cmd *cobra.Command) string {
+	if !cmd.HasParent() {
+		return config.RootCommandName
+	}
+	return strings.Join(strings.Fields(cmd.CommandPath())[1:], ".")
+}
+
+// defaultFlag returns the flag of cmd a config.toml key names; on_conflict
+// and on-conflict both name --on-conflict
+func defaultFlag(cmd *cobra.Command, key string) *pflag.Flag {
+	return cmd.Flag(strings.ReplaceAll(key, "_", "-"))
+}
+
+// applyCommandDefaults sets the flags of cmd that config.toml gives a default
+// for, as if they were typed, unless they are given on the command line
+func applyCommandDefaults(cmd *cobra.Command) {
+	all, err := config.CommandDefaults()
+	if err != nil {
+		return
+	}
+	defaults := all[commandKey(cmd)]
+
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		flag := defaultFlag(cmd, key)
+		if flag == nil {
+			i18n.Fprintf(os.Stderr, "Warning: config.toml sets %s.%s, but '%s' has no such flag\n", commandKey(cmd), key, cmd.CommandPath())
+			continue
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := setDefaultFlag(flag, defaults[key]); err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: invalid default for --%s in config.toml: %v\n", flag.Name, err)
+		}
+	}
+}
+
+// setDefaultFlag sets flag to a value from config.toml and marks it given;
+// arrays set every element of a flag that can be repeated
+func setDefaultFlag(flag *pflag.Flag, value interface{}) error {
+	values, isArray := value.([]interface{})
+	if !isArray {
+		values = []interface{}{value}
+	} else if _, repeatable := flag.Value.(pflag.SliceValue); !repeatable {
+		return fmt.Errorf("--%s takes a single value, not a list", flag.Name)
+	}
+
+	for _, v := range values {
+		if err := flag.Value.Set(defaultValueString(v)); err != nil {
+			return err
+		}
+		flag.Changed = true
+	}
+	return nil
+}
+
+// checkDefaultValue reports whether flag accepts a value from config.toml,
+// leaving it as it was: flags inherited from trash are shared by every command
+func checkDefaultValue(flag *pflag.Flag, value interface{}) error {
+	saved, changed := flag.Value.String(), flag.Changed
+	slice, isSlice := flag.Value.(pflag.SliceValue)
+	var savedSlice []string
+	if isSlice {
+		savedSlice = slice.GetSlice()
+	}
+
+	err := setDefaultFlag(flag, value)
+
+	if isSlice {
+		slice.Replace(savedSlice)
+	} else {
+		flag.Value.Set(saved)
+	}
+	flag.Changed = changed
+	return err
+}
+
+// defaultValueString formats a TOML value the way it would be typed
+func defaultValueString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// commandDefaultFindings reports flag defaults in config.toml for commands
+// below root or flags that do not exist and values their flags do not accept
+func commandDefaultFindings(root *cobra.Command) []config.Finding {
+	all, err := config.CommandDefaults()
+	if err != nil {
+		// A config.toml that does not parse is reported with the settings
+		return nil
+	}
+	settingsPath, _ := config.GetSettingsPath()
+
+	commands := make([]string, 0, len(all))
+	for command := range all {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	var findings []config.Finding
+	for _, command := range commands {
+		cmd := findCommandByKey(root, command)
+		if cmd == nil {
+			findings = append(findings, config.Finding{
+				Check:   "config",
+				Problem: fmt.Sprintf("%s sets flag defaults for [%s], which is not a command", settingsPath, command),
+				Fix:     fmt.Sprintf("rename the table after a command, e.g. [list], or remove it from %s", settingsPath),
+			})
+			continue
+		}
+
+		keys := make([]string, 0, len(all[command]))
+		for key := range all[command] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flag := defaultFlag(cmd, key)
+			if flag == nil {
+				findings = append(findings, config.Finding{
+					Check:   "config",
+					Problem: fmt.Sprintf("%s sets %s.%s, but '%s' has no such flag", settingsPath, command, key, cmd.CommandPath()),
+					Fix:     fmt.Sprintf("run '%s --help' for its flags and correct or remove the key", cmd.CommandPath()),
+				})
+				continue
+			}
+
+			if err := checkDefaultValue(flag, all[command][key]); err != nil {
+				findings = append(findings, config.Finding{
+					Check:   "config",
+					Problem: fmt.Sprintf("%s: invalid default for --%s of '%s': %v", settingsPath, flag.Name, cmd.CommandPath(), err),
+					Fix:     fmt.Sprintf("correct %s.%s in %s", command, key, settingsPath),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// findCommandByKey returns the command below root named key in config.toml, or nil
+func findCommandByKey(root *cobra.Command, key string) *cobra.Command {
+	if key == config.RootCommandName {
+		return root
+	}
+	cmd, rest, err := root.Find(strings.Split(key, "."))
+	if err != nil || cmd == root || len(rest) > 0 {
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// dereferenceEnabled reports whether symlink arguments should be replaced by
+// their targets. Links are trashed themselves by default, and --no-dereference
+// wins over --dereference so the safe behaviour can always be forced.
+func dereferenceEnabled(cmd *cobra.Command) bool {
+	dereference, _ := cmd.Flags().GetBool("dereference")
+	noDereference, _ := cmd.Flags().GetBool("no-dereference")
+	return dereference && !noDereference
+}
+
+// dereferenceArgs replaces every symlink argument with the file it points to,
+// exiting before anything is trashed when a link cannot be resolved
+func dereferenceArgs(paths []string, verbose bool) []string {
+	resolved := make([]string, 0, len(paths))
+	failed := false
+
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			resolved = append(resolved, path)
+			continue
+		}
+
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: cannot dereference %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		if verbose {
+			i18n.Printf("Dereferenced %s -> %s\n", path, target)
+		}
+		resolved = append(resolved, target)
+	}
+
+	if failed {
+		os.Exit(exitError)
+	}
+	return resolved
+}
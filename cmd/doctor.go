@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the trash and its environment for problems",
+	Long: `Check the trash directory and what it depends on, and say how to fix each
+problem found:
+
+  config       config.toml does not parse, or sets unknown keys or invalid values
+  permissions  the store or a session is not writable or belongs to another user
+  free space   the trash filesystem is below min_free or nearly full
+  lock         a lock file cannot be opened, so locking commands fail
+  index        a .restore file lists items whose payload is gone
+  metadata     sessions or payloads the metadata does not describe
+  signature    metadata modified outside trash
+  other trash  trash-cli, gio or a file manager keep deletions in a trash of
+               their own, or a shell alias sends rm to them ('trash import')
+
+Permissions, free space and locks are only checked for a local store.
+The exit status is 1 when problems are found.
+
+With --fix-perms, the permissions the dir_mode setting (0700 by default) does
+not allow are first removed from the trash directory, its sessions and their
+metadata, as needed for stores created before trash kept them private.
+
+Examples:
+  trash doctor
+  trash doctor --fix-perms
+  trash doctor -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if fixPerms, _ := cmd.Flags().GetBool("fix-perms"); fixPerms {
+			fixed, err := config.FixPermissions()
+			if verbose {
+				for _, fix := range fixed {
+					i18n.Fprintf(os.Stderr, "Changed %s from %04o to %04o\n", fix.Path, fix.Mode, fix.Want)
+				}
+			}
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			i18n.Fprintf(os.Stderr, "Fixed the permissions of %d entry(s)\n", len(fixed))
+		}
+
+		findings, err := config.CheckEnvironment()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		findings = append(findings, commandDefaultFindings(cmd.Root())...)
+		findings = append(findings, aliasFindings(cmd.Root())...)
+		trashFindings, err := config.Diagnose()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		findings = append(findings, trashFindings...)
+
+		if format := outputFormat(cmd); format.Structured() {
+			problems := make([]statusProblem, 0, len(findings))
+			for _, finding := range findings {
+				problems = append(problems, statusProblem(finding))
+			}
+			printStructured(format, problems)
+		} else if len(findings) == 0 {
+			i18n.Printf("No problems found\n")
+		} else {
+			for _, finding := range findings {
+				i18n.Printf("[%s] %s\n", finding.Check, finding.Problem)
+				i18n.Printf("    Fix: %s\n", finding.Fix)
+			}
+			i18n.Printf("%d problem(s) found\n", len(findings))
+		}
+
+		if len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("fix-perms", false, "Remove permissions dir_mode does not allow from the trash directory first")
+}
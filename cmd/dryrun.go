@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// printEstimate reports what trashing paths would cost: how many files and
+// bytes would move and whether a cross-device copy would be needed
+func printEstimate(paths []string) {
+	selectDriveStore(paths)
+
+	estimate, err := config.EstimateTrash(paths)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, path := range estimate.Missing {
+		i18n.Fprintf(os.Stderr, "Error: path does not exist: %s\n", path)
+	}
+
+	i18n.Printf("Would trash %d item(s): %d file(s), %d dir(s), %s\n",
+		estimate.Items, estimate.Files, estimate.Dirs, config.FormatSize(estimate.Bytes))
+
+	if estimate.CopyItems == 0 {
+		i18n.Printf("Everything is on the trash filesystem and would be moved without copying\n")
+		return
+	}
+
+	i18n.Printf("Cross-device copy needed for %d item(s) (%s)\n", estimate.CopyItems, config.FormatSize(estimate.CopyBytes))
+	if estimate.CopyBytes > estimate.FreeSpace {
+		i18n.Fprintf(os.Stderr, "Warning: only %s free on the trash filesystem\n", config.FormatSize(estimate.FreeSpace))
+	}
+}
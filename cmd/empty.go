@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var emptyCmd = &cobra.Command{
+	Use:   "empty [item-id...]",
+	Short: "Permanently delete trashed items",
+	Long: `Permanently delete items from the trash.
+Without flags every trash session is deleted after confirmation.
+Use --expired to only purge items whose expiry (set with --expire) has passed.
+Use --regex to only purge items whose name matches a regular expression.
+Use --interactive to review the sessions oldest first, seeing what each holds
+and how big it is, and purge, skip or stop at each.
+Given item IDs as shown by 'trash list', only those items are purged.
+Items kept with --keep-for or 'trash retain' stay until their retention runs
+out unless --include-retained is given.
+
+Purged sessions and items are first moved aside, then deleted in batches, at
+most --purge-rate files per second (or the purge_rate setting) when given.
+An interrupted purge never leaves half-deleted items in the trash; use
+--resume to finish deleting what it left, which emptying everything and
+'trash prune' also do.
+
+Examples:
+  trash empty
+  trash empty --force
+  trash empty --expired
+  trash empty --regex '^core\.\d+$'
+  trash empty --include-retained
+  trash empty --interactive
+  trash empty --force --purge-rate 2000
+  trash empty --resume
+  trash empty k3f9qa 7mz2rd`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		expiredOnly, _ := cmd.Flags().GetBool("expired")
+		force, _ := cmd.Flags().GetBool("force")
+		format := outputFormat(cmd)
+		pattern, _ := cmd.Flags().GetString("regex")
+		includeRetained, _ := cmd.Flags().GetBool("include-retained")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		resume, _ := cmd.Flags().GetBool("resume")
+		now := time.Now()
+
+		if resume {
+			if len(args) > 0 || expiredOnly || pattern != "" || interactive {
+				i18n.Fprintf(os.Stderr, "Error: --resume cannot be combined with item IDs, --expired, --regex or --interactive\n")
+				os.Exit(1)
+			}
+			resumePurge(true)
+			return
+		}
+
+		if len(args) > 0 && (expiredOnly || pattern != "" || interactive) {
+			i18n.Fprintf(os.Stderr, "Error: item IDs cannot be combined with --expired, --regex or --interactive\n")
+			os.Exit(1)
+		}
+
+		if interactive {
+			if expiredOnly || pattern != "" || force {
+				i18n.Fprintf(os.Stderr, "Error: --interactive cannot be combined with --expired, --regex or --force\n")
+				os.Exit(1)
+			}
+			if !isInteractive() {
+				i18n.Fprintf(os.Stderr, "Error: --interactive needs a terminal\n")
+				os.Exit(1)
+			}
+		}
+
+		// Count the retained items that will stay, to say why they did
+		retained := 0
+		if !includeRetained && !expiredOnly && pattern == "" && !interactive && len(args) == 0 {
+			items, _ := config.ListTrashedItems()
+			for _, entry := range items {
+				if entry.Item.IsRetained(now) {
+					retained++
+				}
+			}
+		}
+
+		var purged []config.PurgedItem
+		var err error
+
+		if interactive {
+			absolute, _ := cmd.Flags().GetBool("absolute")
+			purged, err = emptyReview(includeRetained, absolute)
+		} else if len(args) > 0 {
+			purged, err = purgeByID(args, force)
+		} else if expiredOnly {
+			purged, err = config.PurgeExpired(now)
+		} else if pattern != "" {
+			matcher := config.MatchOptions{Normalize: true}
+			re, reErr := matcher.Regexp(pattern)
+			if reErr != nil {
+				i18n.Fprintf(os.Stderr, "Error: invalid regular expression: %v\n", reErr)
+				os.Exit(1)
+			}
+			matchItem := func(entry config.TrashedItem) bool {
+				return matcher.MatchRegexp(re, entry.Item.Name) && (includeRetained || !entry.Item.IsRetained(now))
+			}
+
+			// Count matches first so the prompt says what is at stake
+			items, _ := config.ListTrashedItems()
+			count := 0
+			for _, entry := range items {
+				if matchItem(entry) {
+					count++
+				}
+			}
+			question := i18n.Sprintf("Permanently delete %d item(s) matching '%s'?", count, pattern)
+			if count > 0 && !force && !confirm(question) {
+				i18n.Fprintf(os.Stderr, "Aborted\n")
+				return
+			}
+			purged, err = config.PurgeMatching(matchItem)
+		} else {
+			if !force && !confirm(i18n.Sprintf("Permanently delete everything in the trash?")) {
+				i18n.Fprintf(os.Stderr, "Aborted\n")
+				return
+			}
+			purged, err = config.EmptyTrash(includeRetained)
+			if err == nil {
+				resumePurge(false)
+			}
+		}
+
+		if format.Structured() {
+			printStructured(format, newItemRecords(purged))
+		} else if verbose {
+			for _, p := range purged {
+				i18n.Printf("Purged: %s (from %s)\n", p.Item.Name, formatOriginal(p.Item.OriginalPath))
+			}
+		}
+
+		if len(purged) > 0 {
+			bus.EmitChanged(bus.ReasonPurged)
+		}
+
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error emptying trash: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !format.Structured() {
+			i18n.Printf("Permanently deleted %d item(s)\n", len(purged))
+			if retained > 0 {
+				i18n.Printf("Kept %d retained item(s); use --include-retained to delete them too\n", retained)
+			}
+		}
+	},
+}
+
+// purgeByID permanently deletes the items with the given short IDs, retained
+// or not, after confirmation unless force is set. It exits when an ID matches
+// no item.
+func purgeByID(ids []string, force bool) ([]config.PurgedItem, error) {
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[strings.ToLower(id)] = false
+	}
+	count := 0
+	for _, entry := range items {
+		if _, ok := wanted[entry.Item.ShortID()]; ok {
+			wanted[entry.Item.ShortID()] = true
+			count++
+		}
+	}
+	for _, id := range ids {
+		if !wanted[strings.ToLower(id)] {
+			i18n.Fprintf(os.Stderr, "Error: item '%s' not found in trash\n", id)
+			os.Exit(exitNotFound)
+		}
+	}
+
+	if !force && !confirm(i18n.Sprintf("Permanently delete %d item(s)?", count)) {
+		i18n.Fprintf(os.Stderr, "Aborted\n")
+		os.Exit(0)
+	}
+	return config.PurgeMatching(func(entry config.TrashedItem) bool {
+		_, ok := wanted[entry.Item.ShortID()]
+		return ok
+	})
+}
+
+// resumePurge finishes deleting what interrupted purges left, saying so when
+// report is set or there was something to finish
+func resumePurge(report bool) {
+	resumed, err := config.ResumePurge()
+	if resumed > 0 {
+		i18n.Printf("Finished %d interrupted purge(s)\n", resumed)
+	} else if report && err == nil {
+		i18n.Printf("No interrupted purge to finish\n")
+	}
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// confirm asks a yes/no question on stdin and returns true only for an explicit yes
+// The prompt goes to stderr so it never mixes with structured output
+func confirm(question string) bool {
+	i18n.Fprintf(os.Stderr, "%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	return i18n.IsYes(answer)
+}
+
+func init() {
+	rootCmd.AddCommand(emptyCmd)
+	emptyCmd.Flags().Bool("expired", false, "Only purge items whose expiry has passed")
+	emptyCmd.Flags().BoolP("force", "f", false, "Do not ask for confirmation")
+	emptyCmd.Flags().String("regex", "", "Only purge items whose name matches this regular expression")
+	emptyCmd.Flags().Bool("include-retained", false, "Also purge items kept with --keep-for or retain")
+	emptyCmd.Flags().BoolP("interactive", "i", false, "Review the sessions oldest first and choose which to purge")
+	emptyCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+	emptyCmd.Flags().Bool("resume", false, "Finish deleting what an interrupted purge left")
+}
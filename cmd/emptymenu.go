@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// emptyReview walks the trash sessions oldest first, showing the items and size
+// of each, and purges those the user approves until they stop or none are left.
+// Retained items survive an approved session unless includeRetained is set.
+func emptyReview(includeRetained, absolute bool) ([]config.PurgedItem, error) {
+	sessions, err := config.ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return nil, err
+	}
+	bySession := make(map[string][]config.TrashedItem)
+	for _, entry := range items {
+		bySession[entry.Session] = append(bySession[entry.Session], entry)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	now := time.Now()
+	var purged []config.PurgedItem
+
+	for i, session := range sessions {
+		entries := bySession[session]
+
+		i18n.Fprintf(os.Stderr, "\n[%s] (%d/%d)\n", formatSession(session, absolute), i+1, len(sessions))
+		var total uint64
+		for _, entry := range entries {
+			size := entry.Size()
+			total += size
+			if entry.Item.IsRetained(now) && !includeRetained {
+				i18n.Fprintf(os.Stderr, "  • %s (from %s) %s, retained until %s\n", entry.Item.Name,
+					formatOriginal(entry.Item.OriginalPath), config.FormatSize(size), entry.Item.ExpiresAt)
+			} else {
+				i18n.Fprintf(os.Stderr, "  • %s (from %s) %s\n", entry.Item.Name,
+					formatOriginal(entry.Item.OriginalPath), config.FormatSize(size))
+			}
+		}
+		if len(entries) == 0 {
+			i18n.Fprintf(os.Stderr, "  (no items recorded)\n")
+		}
+		i18n.Fprintf(os.Stderr, "  Total: %d item(s), %s\n", len(entries), config.FormatSize(total))
+
+		answer, ok := ask(reader, i18n.Sprintf("Purge this session? [y]es, [n]o or [q]uit: "))
+		if !ok {
+			break
+		}
+		answer = strings.ToLower(answer)
+		if answer == "q" || answer == "quit" {
+			break
+		}
+		if !i18n.IsYes(answer) {
+			i18n.Fprintf(os.Stderr, "Skipped\n")
+			continue
+		}
+
+		removed, err := config.PurgeSession(session, includeRetained)
+		purged = append(purged, removed...)
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
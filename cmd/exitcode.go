@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// Exit codes, so scripts can tell failures apart without parsing messages
+const (
+	exitError             = 1 // any other failure
+	exitNotFound          = 2 // path or trashed item does not exist
+	exitDestinationExists = 3 // restore target exists and --force was not given
+	exitCrossDevice       = 4 // copy fallback across filesystems failed
+	exitQuotaExceeded     = 5 // filesystem full or quota exhausted
+)
+
+// exitCode maps a library error to the process exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, config.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, config.ErrDestinationExists):
+		return exitDestinationExists
+	case errors.Is(err, config.ErrQuotaExceeded):
+		return exitQuotaExceeded
+	case errors.Is(err, config.ErrCrossDevice):
+		return exitCrossDevice
+	}
+	return exitError
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// trashFilter selects which arguments of a trash operation are actually trashed
+type trashFilter struct {
+	olderThan  time.Time // only paths last modified before this, zero for any
+	largerThan uint64    // minimum size in bytes (directories count their contents), 0 for any
+}
+
+// parseTrashFilter reads --older-than and --larger-than, exiting on an invalid value
+func parseTrashFilter(cmd *cobra.Command) trashFilter {
+	var filter trashFilter
+
+	if value, _ := cmd.Flags().GetString("older-than"); value != "" {
+		// An age such as 90d, or a time such as "last week" or 2025-01-15
+		t, err := config.ParseTime(value, time.Now())
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: invalid --older-than value: %v\n", err)
+			os.Exit(1)
+		}
+		filter.olderThan = t
+	}
+
+	if value, _ := cmd.Flags().GetString("larger-than"); value != "" {
+		size, err := config.ParseSize(value)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: invalid --larger-than value: %v\n", err)
+			os.Exit(1)
+		}
+		filter.largerThan = size
+	}
+
+	return filter
+}
+
+// active reports whether the filter excludes anything
+func (f trashFilter) active() bool {
+	return !f.olderThan.IsZero() || f.largerThan > 0
+}
+
+// apply returns the paths meeting every criterion. Paths that do not exist are
+// kept so the trash operation reports them.
+func (f trashFilter) apply(paths []string, now time.Time, verbose bool) []string {
+	if !f.active() {
+		return paths
+	}
+
+	var kept []string
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			kept = append(kept, path)
+			continue
+		}
+
+		if reason := f.check(path, info, now); reason != "" {
+			if verbose {
+				i18n.Printf("Skipped (%s): %s\n", reason, path)
+			}
+			continue
+		}
+
+		kept = append(kept, path)
+	}
+
+	return kept
+}
+
+// check returns why path fails the filter, or "" when it meets every criterion
+func (f trashFilter) check(path string, info os.FileInfo, now time.Time) string {
+	if !f.olderThan.IsZero() && !info.ModTime().Before(f.olderThan) {
+		return i18n.Sprintf("modified %s", config.HumanizeAge(info.ModTime(), now))
+	}
+
+	if f.largerThan > 0 {
+		size, _ := config.PathSize(path)
+		if size <= f.largerThan {
+			return config.FormatSize(size)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find <dir>...",
+	Short: "Trash files matching criteria below a directory",
+	Long: `Walk directory trees and trash every entry matching the given criteria as
+one session, a recoverable replacement for 'find ... -delete'.
+
+A matching directory is trashed as a whole and not descended into. Paths
+protected by a .trashignore file are skipped.
+
+Examples:
+  trash find . --name '*.log' --older-than 30d --dry-run
+  trash find ~/src --name node_modules --type dir
+  trash find /var/tmp/builds --larger-than 1G`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		names, _ := cmd.Flags().GetStringArray("name")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		kind, _ := cmd.Flags().GetString("type")
+		expire, _ := cmd.Flags().GetString("expire")
+
+		if kind != "" && kind != "file" && kind != "dir" {
+			i18n.Fprintf(os.Stderr, "Error: invalid --type %q: expected file or dir\n", kind)
+			os.Exit(1)
+		}
+
+		expiresAt := ""
+		if expire != "" {
+			expireAfter, err := config.ParseDuration(expire)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: invalid --expire value: %v\n", err)
+				os.Exit(1)
+			}
+			expiresAt = time.Now().Add(expireAfter).Format(time.RFC3339)
+		}
+
+		selector := findSelector{
+			names:  names,
+			match:  config.MatchOptions{Normalize: true, IgnoreCase: ignoreCase},
+			kind:   kind,
+			filter: parseTrashFilter(cmd),
+			now:    time.Now(),
+		}
+		selector.store, _ = config.GetConfigDir()
+
+		var matches []string
+		for _, root := range args {
+			found, err := selector.walk(root)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			matches = append(matches, found...)
+		}
+
+		if noIgnore, _ := cmd.Flags().GetBool("no-ignore"); !noIgnore {
+			respectGitignore, _ := cmd.Flags().GetBool("respect-gitignore")
+			matches = skipProtected(matches, respectGitignore)
+		}
+
+		if len(matches) == 0 {
+			i18n.Printf("No matches found\n")
+			return
+		}
+
+		if dryRun {
+			for _, path := range matches {
+				i18n.Printf("Would trash: %s\n", path)
+			}
+			printEstimate(matches)
+			return
+		}
+
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		trashPaths(matches, trashOptions(cmd, expiresAt), verbose, quiet, printNone)
+	},
+}
+
+// findSelector decides which entries of a walked tree are trashed
+type findSelector struct {
+	names  []string // shell patterns matched against entry names, any of which selects
+	match  config.MatchOptions
+	kind   string // "file", "dir" or "" for any
+	filter trashFilter
+	now    time.Time
+	store  string // the trash directory, never selected or descended into
+}
+
+// walk returns the entries below root that match, without descending into matched directories
+func (s findSelector) walk(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "find", Path: root, Err: fs.ErrInvalid}
+	}
+
+	var matches []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		if absPath, _ := filepath.Abs(path); absPath == s.store {
+			return filepath.SkipDir
+		}
+
+		if !s.selects(path, entry) {
+			return nil
+		}
+
+		matches = append(matches, path)
+		if entry.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return matches, err
+}
+
+// selects reports whether an entry meets the name, type, age and size criteria
+func (s findSelector) selects(path string, entry fs.DirEntry) bool {
+	switch s.kind {
+	case "file":
+		if entry.IsDir() {
+			return false
+		}
+	case "dir":
+		if !entry.IsDir() {
+			return false
+		}
+	}
+
+	if len(s.names) > 0 {
+		matched := false
+		for _, pattern := range s.names {
+			if s.match.Glob(pattern, entry.Name()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if s.filter.active() {
+		info, err := entry.Info()
+		if err != nil || s.filter.check(path, info, s.now) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+	findCmd.Flags().StringArray("name", nil, "Only select entries whose name matches this shell pattern (repeatable)")
+	findCmd.Flags().BoolP("ignore-case", "i", false, "Match names case-insensitively")
+	findCmd.Flags().String("type", "", "Only select entries of this type: file or dir")
+	findCmd.Flags().String("older-than", "", "Only select entries last modified longer ago than this (e.g. 30d) or before this time (e.g. last month, 2025-01-15)")
+	findCmd.Flags().String("larger-than", "", "Only select entries larger than this size (e.g. 100M)")
+	findCmd.Flags().String("expire", "", "Automatically purge the trashed entries after this long (e.g. 7d, 12h)")
+	findCmd.Flags().String("session-window", "", "Append to the current hour's or day's session: none, hour or day (default from config)")
+	findCmd.Flags().Bool("allow-mounts", false, "Trash paths that are or contain mount points")
+	findCmd.Flags().BoolP("dry-run", "n", false, "Only show what would be trashed and how much it would move")
+	findCmd.Flags().Bool("respect-gitignore", false, "Skip paths ignored by git")
+	findCmd.Flags().Bool("no-ignore", false, "Trash paths even when a .trashignore file protects them")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"github.com/artemisfowl/trash/internal/output"
+)
+
+// itemRecord is the structured (--output) representation of a trashed item
+type itemRecord struct {
+	ID           string  `json:"id" yaml:"id"`
+	Session      string  `json:"session" yaml:"session"`
+	Name         string  `json:"name" yaml:"name"`
+	OriginalPath string  `json:"original_path" yaml:"original_path"`
+	TrashedAt    string  `json:"trashed_at" yaml:"trashed_at"`
+	ExpiresAt    string  `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Retained     bool    `json:"retained,omitempty" yaml:"retained,omitempty"`
+	User         string  `json:"user,omitempty" yaml:"user,omitempty"`
+	UID          string  `json:"uid,omitempty" yaml:"uid,omitempty"`
+	Hostname     string  `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Type         string  `json:"type,omitempty" yaml:"type,omitempty"`
+	MIMEType     string  `json:"mime_type,omitempty" yaml:"mime_type,omitempty"`
+	Size         *uint64 `json:"size,omitempty" yaml:"size,omitempty"`
+	Checksum     string  `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	Store        string  `json:"store,omitempty" yaml:"store,omitempty"`
+}
+
+// newItemRecord converts a trashed item into its structured representation
+func newItemRecord(entry config.TrashedItem) itemRecord {
+	return itemRecord{
+		ID:           entry.Item.ShortID(),
+		Session:      entry.Session,
+		Name:         entry.Item.Name,
+		OriginalPath: entry.Item.OriginalPath,
+		TrashedAt:    entry.Item.TrashedAt,
+		ExpiresAt:    entry.Item.ExpiresAt,
+		Retained:     entry.Item.Retained,
+		User:         entry.Item.User,
+		UID:          entry.Item.UID,
+		Hostname:     entry.Item.Hostname,
+		Type:         entry.Item.Type,
+		MIMEType:     entry.Item.MIMEType,
+		Size:         entry.Item.Size,
+		Checksum:     entry.Item.Checksum,
+		Store:        entry.Root.Name,
+	}
+}
+
+// newItemRecords converts trashed items into their structured representation
+func newItemRecords(entries []config.TrashedItem) []itemRecord {
+	records := make([]itemRecord, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, newItemRecord(entry))
+	}
+	return records
+}
+
+// outputFormat returns the validated --output format, exiting on an invalid value
+func outputFormat(cmd *cobra.Command) output.Format {
+	value, _ := cmd.Flags().GetString("output")
+
+	format, err := output.ParseFormat(value)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	return format
+}
+
+// printStructured writes v to stdout in the given structured format, exiting on failure
+func printStructured(format output.Format, v interface{}) {
+	if err := output.Write(os.Stdout, format, v); err != nil {
+		i18n.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadTrashedItems returns the items of the current trash, or of every trash
+// root when --all-roots is set, exiting on error
+func loadTrashedItems(cmd *cobra.Command) []config.TrashedItem {
+	if roots, ok := userRoots(cmd); ok {
+		return config.ListTrashedItemsInRoots(roots)
+	}
+
+	load := config.ListTrashedItems
+	if allRoots, _ := cmd.Flags().GetBool("all-roots"); allRoots {
+		load = config.ListAllTrashedItems
+	}
+
+	items, err := load()
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+		os.Exit(1)
+	}
+	return items
+}
+
+// userRoots returns the trash directories selected by --user or --all-users,
+// exiting when they cannot be read. Other users' trashes need root.
+func userRoots(cmd *cobra.Command) ([]config.Root, bool) {
+	name, _ := cmd.Flags().GetString("user")
+	allUsers, _ := cmd.Flags().GetBool("all-users")
+	if name == "" && !allUsers {
+		return nil, false
+	}
+
+	if allUsers {
+		if os.Geteuid() != 0 {
+			i18n.Fprintf(os.Stderr, "Error: --all-users requires root\n")
+			os.Exit(1)
+		}
+		roots, err := config.AllUserRoots()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return roots, true
+	}
+
+	account, err := config.LookupUser(name)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if account.UID != strconv.Itoa(os.Getuid()) && os.Geteuid() != 0 {
+		i18n.Fprintf(os.Stderr, "Error: --user %s requires root\n", name)
+		os.Exit(1)
+	}
+	return config.UserRoots(account), true
+}
+
+// formatRoot returns " (root)" for items found through --all-roots, or ""
+func formatRoot(root config.Root) string {
+	if root.Name == "" {
+		return ""
+	}
+	return " (" + root.Name + ")"
+}
+
+// resolveSessionRef expands a session name or unique prefix to the full session
+// name, exiting when it is ambiguous. Unknown references are returned unchanged
+// so callers report them as they would any other missing session.
+func resolveSessionRef(ref string) string {
+	session, err := config.ResolveSession(ref)
+	if errors.Is(err, config.ErrNotFound) {
+		return ref
+	}
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return session
+}
+
+// formatTimestamp returns an RFC3339 timestamp followed by its relative age,
+// or the raw timestamp when absolute is set or it cannot be parsed
+func formatTimestamp(timestamp string, absolute bool) string {
+	if absolute {
+		return timestamp
+	}
+
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+
+	return timestamp + " (" + config.HumanizeAge(t, time.Now()) + ")"
+}
+
+// formatSession returns a session name followed by its relative age,
+// or the bare name when absolute is set or it cannot be parsed
+func formatSession(session string, absolute bool) string {
+	if absolute {
+		return session
+	}
+
+	t, err := config.SessionTime(session)
+	if err != nil {
+		return session
+	}
+
+	return session + ", " + config.HumanizeAge(t, time.Now())
+}
+
+// formatOriginal returns the original location of an item, or "unknown" for
+// adopted items that have none
+func formatOriginal(originalPath string) string {
+	if originalPath == "" {
+		return i18n.Sprintf("unknown")
+	}
+	return originalPath
+}
+
+// formatOwner renders the recorded owner of an item as "user (uid 1000) on host"
+func formatOwner(user, uid, hostname string) string {
+	owner := user
+	if owner == "" {
+		owner = i18n.Sprintf("unknown")
+	}
+	if uid != "" {
+		owner += " (uid " + uid + ")"
+	}
+	if hostname != "" {
+		owner = i18n.Sprintf("%s on %s", owner, hostname)
+	}
+	return owner
+}
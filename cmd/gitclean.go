@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/git"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var gitCleanCmd = &cobra.Command{
+	Use:   "git-clean",
+	Short: "Trash untracked files in a git working tree",
+	Long: `Trash the files git does not track below the current directory, a recoverable
+alternative to 'git clean'. By default only untracked files that are not ignored
+are trashed, like 'git clean -d'. Paths protected by a .trashignore file are skipped.
+
+Examples:
+  trash git-clean --dry-run   # show what would be trashed
+  trash git-clean --ignored   # only build output and other ignored files (git clean -dX)
+  trash git-clean --all       # untracked and ignored files (git clean -dx)`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		ignored, _ := cmd.Flags().GetBool("ignored")
+		all, _ := cmd.Flags().GetBool("all")
+
+		if ignored && all {
+			i18n.Fprintf(os.Stderr, "Error: --ignored and --all are mutually exclusive\n")
+			os.Exit(1)
+		}
+
+		selection := git.Untracked
+		if ignored {
+			selection = git.Ignored
+		} else if all {
+			selection = git.All
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		paths, err := git.ListUntracked(cwd, selection)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		paths = withoutTrashStore(paths)
+		if noIgnore, _ := cmd.Flags().GetBool("no-ignore"); !noIgnore {
+			paths = skipProtected(paths, false)
+		}
+
+		if len(paths) == 0 {
+			i18n.Printf("Nothing to clean\n")
+			return
+		}
+
+		if dryRun {
+			for _, path := range paths {
+				i18n.Printf("Would trash: %s\n", relativeTo(cwd, path))
+			}
+			printEstimate(paths)
+			return
+		}
+
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		trashPaths(paths, trashOptions(cmd, ""), verbose, quiet, printNone)
+	},
+}
+
+// withoutTrashStore drops the trash directory itself and anything inside it,
+// which a per-project trash at the repository root would otherwise match
+func withoutTrashStore(paths []string) []string {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return paths
+	}
+
+	var kept []string
+	for _, path := range paths {
+		if path == configDir || config.IsWithin(path, configDir) {
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept
+}
+
+// relativeTo returns path relative to base when possible
+func relativeTo(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func init() {
+	rootCmd.AddCommand(gitCleanCmd)
+	gitCleanCmd.Flags().String("session-window", "", "Append to the current hour's or day's session: none, hour or day (default from config)")
+	gitCleanCmd.Flags().Bool("allow-mounts", false, "Trash paths that are or contain mount points")
+	gitCleanCmd.Flags().BoolP("dry-run", "n", false, "Only show what would be trashed and how much it would move")
+	gitCleanCmd.Flags().BoolP("ignored", "X", false, "Only trash files matched by .gitignore")
+	gitCleanCmd.Flags().BoolP("all", "x", false, "Trash ignored files as well as untracked ones")
+	gitCleanCmd.Flags().Bool("no-ignore", false, "Trash paths even when a .trashignore file protects them")
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show when items were trashed, restored and purged",
+	Long: `Show the log of every item trashed, restored or purged, oldest first, with the
+invocation of trash that did it. Entries of one invocation share its id.
+
+Use --op to only show one kind of operation, --path to only show items from a
+file or directory, and --since, --until and --limit like for 'trash list'.
+
+Examples:
+  trash history
+  trash history --op restore --since yesterday
+  trash history --path ~/projects -v
+  trash history --limit 20 -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		absolute, _ := cmd.Flags().GetBool("absolute")
+		op, _ := cmd.Flags().GetString("op")
+		path, _ := cmd.Flags().GetString("path")
+		invocation, _ := cmd.Flags().GetString("invocation")
+
+		switch op {
+		case "", config.OpTrash, config.OpRestore, config.OpPurge:
+		default:
+			i18n.Fprintf(os.Stderr, "Error: invalid --op value %q: expected trash, restore or purge\n", op)
+			os.Exit(1)
+		}
+		if path != "" {
+			absPath, err := filepath.Abs(path)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			path = absPath
+		}
+
+		entries, err := config.LoadHistory()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		window := parseListWindow(cmd)
+		var records []historyRecord
+		for _, entry := range entries {
+			if op != "" && entry.Op != op {
+				continue
+			}
+			if invocation != "" && entry.Invocation != invocation {
+				continue
+			}
+			if path != "" && !isAtOrBelow(entry.Path, path) && !isAtOrBelow(entry.Dest, path) {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339, entry.Time); err == nil &&
+				((!window.since.IsZero() && t.Before(window.since)) || (!window.until.IsZero() && t.After(window.until))) {
+				continue
+			}
+			records = append(records, historyRecord(entry))
+		}
+		if window.limit > 0 && len(records) > window.limit {
+			records = records[len(records)-window.limit:]
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			if records == nil {
+				records = []historyRecord{}
+			}
+			printStructured(format, records)
+			return
+		}
+
+		if len(records) == 0 {
+			i18n.Printf("No history\n")
+			return
+		}
+
+		for _, record := range records {
+			destination := record.Path
+			if record.Dest != "" {
+				destination = record.Dest
+			}
+			switch record.Op {
+			case config.OpTrash:
+				i18n.Printf("%s  trashed   %s\n", formatTimestamp(record.Time, absolute), formatOriginal(record.Path))
+			case config.OpRestore:
+				i18n.Printf("%s  restored  %s\n", formatTimestamp(record.Time, absolute), formatOriginal(destination))
+			case config.OpPurge:
+				i18n.Printf("%s  purged    %s\n", formatTimestamp(record.Time, absolute), formatOriginal(record.Path))
+			}
+			if verbose {
+				if record.Dest != "" {
+					i18n.Printf("    Original:   %s\n", formatOriginal(record.Path))
+				}
+				i18n.Printf("    Session:    %s/%s\n", record.Session, record.Name)
+				i18n.Printf("    Invocation: %s (%s)\n", record.Invocation, record.Command)
+			}
+		}
+	},
+}
+
+// isAtOrBelow reports whether path is dir or lies inside it
+func isAtOrBelow(path, dir string) bool {
+	return path != "" && (path == dir || config.IsWithin(path, dir))
+}
+
+// historyRecord is the structured (--output) representation of a history entry
+type historyRecord struct {
+	Time       string `json:"time" yaml:"time"`
+	Op         string `json:"op" yaml:"op"`
+	Name       string `json:"name" yaml:"name"`
+	Path       string `json:"path" yaml:"path"`
+	Session    string `json:"session" yaml:"session"`
+	Dest       string `json:"dest,omitempty" yaml:"dest,omitempty"`
+	Invocation string `json:"invocation" yaml:"invocation"`
+	Command    string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().String("op", "", "Only show this operation: trash, restore or purge")
+	historyCmd.Flags().String("path", "", "Only show items trashed from or restored to this file or directory")
+	historyCmd.Flags().String("invocation", "", "Only show what one invocation of trash did, by its id")
+	historyCmd.Flags().String("since", "", "Only show operations since this time: a date, a duration ago (e.g. 7d), today, yesterday or last week")
+	historyCmd.Flags().String("until", "", "Only show operations until this time, given like for --since")
+	historyCmd.Flags().Int("limit", 0, "Only show this many of the most recent operations")
+	historyCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/git"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// skipProtected drops paths protected by a .trashignore file and, when
+// respectGitignore is set, paths ignored by git, reporting each one skipped
+func skipProtected(paths []string, respectGitignore bool) []string {
+	var kept []string
+	for _, path := range paths {
+		match, err := config.MatchTrashIgnore(path)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: failed to read %s: %v\n", config.IgnoreFileName, err)
+		}
+		if match != nil {
+			i18n.Fprintf(os.Stderr, "Skipping %s: protected by %s (%s)\n", path, match.File, match.Pattern)
+			continue
+		}
+
+		if respectGitignore {
+			absPath, _ := filepath.Abs(path)
+			ignored, err := git.IsIgnored(absPath)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			if ignored {
+				i18n.Fprintf(os.Stderr, "Skipping %s: ignored by git\n", path)
+				continue
+			}
+		}
+
+		kept = append(kept, path)
+	}
+	return kept
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [trash-dir...]",
+	Short: "Move items deleted with trash-cli, gio or a file manager into this trash",
+	Long: `Import the items of freedesktop.org trash directories, the trash shared by
+trash-cli, gio and desktop file managers, so that deletions are no longer
+scattered across trashes that know nothing of each other. Each directory's
+items become one session; they keep their original location and deletion
+time, so they can be restored to where they came from.
+
+Without arguments, the home trash ($XDG_DATA_HOME/Trash) and the trash
+directories of the current user at the top of mounted volumes are imported.
+Items on another filesystem than this trash are copied. 'trash doctor' lists
+the directories holding items and shell aliases that still use other tools.
+
+Examples:
+  trash import --dry-run
+  trash import
+  trash import ~/.local/share/Trash`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		dirs := args
+		if len(dirs) == 0 {
+			for _, trash := range config.ForeignTrashes() {
+				dirs = append(dirs, trash.Dir)
+			}
+		}
+
+		imported, failed := 0, 0
+		for _, dir := range dirs {
+			if _, err := os.Stat(filepath.Join(dir, "info")); err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %s is not a trash directory of trash-cli, gio or a file manager\n", dir)
+				failed++
+				continue
+			}
+
+			session, results, err := config.ImportForeignTrash(dir, dryRun)
+			for _, result := range results {
+				switch {
+				case result.Err != nil:
+					i18n.Fprintf(os.Stderr, "Skipping %s: %v\n", result.Name, result.Err)
+					failed++
+				case dryRun:
+					i18n.Printf("Would import: %s (from %s)\n", result.Item.Name, result.Item.OriginalPath)
+					imported++
+				default:
+					i18n.Printf("Imported: %s (from %s) [%s]\n", result.Item.Name, result.Item.OriginalPath, session)
+					imported++
+				}
+			}
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed++
+			}
+		}
+
+		switch {
+		case imported == 0 && failed == 0:
+			i18n.Printf("Nothing to import\n")
+		case dryRun:
+			i18n.Printf("Would import %d item(s)\n", imported)
+		case imported > 0:
+			bus.EmitChanged(bus.ReasonTrashed)
+			i18n.Printf("Imported %d item(s)\n", imported)
+		}
+		if failed > 0 {
+			os.Exit(exitError)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().BoolP("dry-run", "n", false, "Only list what would be imported")
+}
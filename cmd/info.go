@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info [item-name]",
+	Short: "Show detailed information about a trashed item",
+	Long: `Show everything known about a trashed item: its session, original location,
+location inside the trash, type and size. All instances with the given name are shown;
+an item ID as shown by 'trash list' shows just that item.
+
+Examples:
+  trash info test1.txt
+  trash info testdir --output json
+  trash info k3f9qa
+  trash info report.pdf --show       # reveal the newest instance in the file manager`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		itemName := args[0]
+		absolute, _ := cmd.Flags().GetBool("absolute")
+
+		items, err := config.ListTrashedItems()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		var records []infoRecord
+		for _, entry := range items {
+			if entry.Item.Name == itemName {
+				records = append(records, newInfoRecord(entry))
+			}
+		}
+		if len(records) == 0 {
+			for _, entry := range items {
+				if strings.EqualFold(entry.Item.ShortID(), itemName) {
+					records = append(records, newInfoRecord(entry))
+				}
+			}
+		}
+
+		if len(records) == 0 {
+			i18n.Fprintf(os.Stderr, "Error: item '%s' not found in trash\n", itemName)
+			os.Exit(exitNotFound)
+		}
+
+		if show, _ := cmd.Flags().GetBool("show"); show {
+			newest := records[len(records)-1]
+			if err := bus.ShowItems([]string{newest.TrashPath}); err != nil {
+				i18n.Fprintf(os.Stderr, "Error: could not open file manager: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, records)
+			return
+		}
+
+		for i, record := range records {
+			if i > 0 {
+				fmt.Println()
+			}
+			i18n.Printf("%s\n", record.Name)
+			i18n.Printf("  ID:       %s\n", record.ID)
+			i18n.Printf("  Session:  %s\n", formatSession(record.Session, absolute))
+			i18n.Printf("  Original: %s\n", formatOriginal(record.OriginalPath))
+			if record.Compressed {
+				i18n.Printf("  Location: %s (compressed into %s)\n", record.TrashPath, config.ArchiveFileName)
+			} else if record.Snapshot != "" {
+				i18n.Printf("  Location: %s (in the %s snapshot %s)\n", record.TrashPath, record.SnapshotKind, record.Snapshot)
+			} else {
+				i18n.Printf("  Location: %s\n", record.TrashPath)
+			}
+			i18n.Printf("  Trashed:  %s\n", formatTimestamp(record.TrashedAt, absolute))
+			if record.Retained {
+				i18n.Printf("  Expires:  %s (retained until then)\n", record.ExpiresAt)
+			} else if record.ExpiresAt != "" {
+				i18n.Printf("  Expires:  %s\n", record.ExpiresAt)
+			}
+			if record.User != "" || record.Hostname != "" {
+				i18n.Printf("  Owner:    %s\n", formatOwner(record.User, record.UID, record.Hostname))
+			}
+			if record.MIMEType != "" {
+				i18n.Printf("  Type:     %s (%s)\n", record.Type, record.MIMEType)
+			} else {
+				i18n.Printf("  Type:     %s\n", record.Type)
+			}
+			i18n.Printf("  Size:     %s\n", config.FormatSize(record.Size))
+			if record.Links > 1 {
+				i18n.Printf("  Links:    %d when trashed (inode %s on device %s); other links may still exist\n",
+					record.Links, fmt.Sprint(record.Inode), fmt.Sprint(record.Device))
+			}
+		}
+	},
+}
+
+// infoRecord is the structured (--output) representation of a trashed item's details
+type infoRecord struct {
+	ID           string `json:"id" yaml:"id"`
+	Session      string `json:"session" yaml:"session"`
+	Name         string `json:"name" yaml:"name"`
+	OriginalPath string `json:"original_path" yaml:"original_path"`
+	TrashPath    string `json:"trash_path" yaml:"trash_path"`
+	TrashedAt    string `json:"trashed_at" yaml:"trashed_at"`
+	ExpiresAt    string `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Retained     bool   `json:"retained,omitempty" yaml:"retained,omitempty"`
+	User         string `json:"user,omitempty" yaml:"user,omitempty"`
+	UID          string `json:"uid,omitempty" yaml:"uid,omitempty"`
+	Hostname     string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Type         string `json:"type" yaml:"type"`
+	MIMEType     string `json:"mime_type,omitempty" yaml:"mime_type,omitempty"`
+	Size         uint64 `json:"size" yaml:"size"`
+	Device       uint64 `json:"device,omitempty" yaml:"device,omitempty"`
+	Inode        uint64 `json:"inode,omitempty" yaml:"inode,omitempty"`
+	Links        uint64 `json:"links,omitempty" yaml:"links,omitempty"`
+	Compressed   bool   `json:"compressed,omitempty" yaml:"compressed,omitempty"`
+	Snapshot     string `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	SnapshotKind string `json:"snapshot_kind,omitempty" yaml:"snapshot_kind,omitempty"`
+}
+
+// newInfoRecord gathers the details of a trashed item, inspecting its payload
+func newInfoRecord(entry config.TrashedItem) infoRecord {
+	record := infoRecord{
+		ID:           entry.Item.ShortID(),
+		Session:      entry.Session,
+		Name:         entry.Item.Name,
+		OriginalPath: entry.Item.OriginalPath,
+		TrashedAt:    entry.Item.TrashedAt,
+		ExpiresAt:    entry.Item.ExpiresAt,
+		Retained:     entry.Item.Retained,
+		User:         entry.Item.User,
+		UID:          entry.Item.UID,
+		Hostname:     entry.Item.Hostname,
+		Device:       entry.Item.Device,
+		Inode:        entry.Item.Inode,
+		Links:        entry.Item.Links,
+		Type:         "missing",
+	}
+	if entry.Item.Snapshot != nil {
+		record.Snapshot, record.SnapshotKind = entry.Item.Snapshot.Name, entry.Item.Snapshot.Kind
+	}
+
+	trashPath, err := config.ItemPath(entry.Session, entry.Item)
+	if err != nil {
+		return record
+	}
+	record.TrashPath = trashPath
+
+	if _, err := config.StoreFS().Lstat(trashPath); err != nil {
+		// Payloads of compressed sessions are in the session's archive
+		if entry.Archived() {
+			record.Compressed = true
+			record.Type, record.MIMEType = entry.Item.Type, entry.Item.MIMEType
+			record.Size = entry.Size()
+		}
+		return record
+	}
+
+	// Prefer the type recorded at trash time; older items are inspected on disk
+	record.Type, record.MIMEType = entry.Item.Type, entry.Item.MIMEType
+	if record.Type == "" {
+		record.Type, record.MIMEType = config.DetectFileType(trashPath)
+	}
+	record.Size = entry.Size()
+
+	return record
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+	infoCmd.Flags().Bool("show", false, "Reveal the most recent instance in the desktop file manager (Linux, D-Bus)")
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"golang.org/x/term"
+)
+
+// handleTrashedArgs deals with arguments that are already in a trash directory,
+// which would otherwise be nested trash-within-trash. The user is offered to
+// purge each such item or, when it is in another trash directory, to re-file it
+// into this one. The remaining paths are returned, and whether any such item was
+// left as it is for a reason other than the user choosing to skip it.
+func handleTrashedArgs(paths []string, verbose bool) ([]string, bool) {
+	configDir, _ := config.GetConfigDir()
+	interactive := isInteractive()
+
+	var remaining []string
+	failed := false
+	for _, path := range paths {
+		location, ok := config.LocateInTrash(path)
+		if !ok {
+			remaining = append(remaining, path)
+			continue
+		}
+
+		if location.Item == nil || location.Rel != "" {
+			i18n.Fprintf(os.Stderr, "Skipping %s: it is inside trash session %s; restore the item first\n", path, location.Session)
+			failed = true
+			continue
+		}
+
+		i18n.Fprintf(os.Stderr, "%s is already in the trash (session %s, from %s)\n", path, location.Session, formatOriginal(location.Item.OriginalPath))
+		if !interactive {
+			i18n.Fprintf(os.Stderr, "Skipping %s: run interactively to purge or re-file it\n", path)
+			failed = true
+			continue
+		}
+
+		otherStore := !sameDir(location.StoreDir, configDir)
+		switch askTrashedAction(otherStore) {
+		case "p":
+			if err := config.PurgeTrashedItem(location); err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+				continue
+			}
+			i18n.Printf("Purged: %s\n", path)
+		case "r":
+			trashDir, err := config.RefileTrashedItem(location)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+				continue
+			}
+			i18n.Printf("Re-filed: %s\n", path)
+			if verbose {
+				i18n.Printf("Trash directory: %s\n", trashDir)
+			}
+		default:
+			i18n.Fprintf(os.Stderr, "Skipped: %s\n", path)
+		}
+	}
+
+	return remaining, failed
+}
+
+// askTrashedAction asks what to do with an argument already in the trash and
+// returns "p" (purge), "r" (re-file) or "s" (skip)
+func askTrashedAction(canRefile bool) string {
+	if canRefile {
+		i18n.Fprintf(os.Stderr, "[p]urge it, [r]e-file it into this trash, or [s]kip? ")
+	} else {
+		i18n.Fprintf(os.Stderr, "[p]urge it or [s]kip? ")
+	}
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "s"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "p", "purge":
+		return "p"
+	case "r", "re-file", "refile":
+		if canRefile {
+			return "r"
+		}
+	}
+	return "s"
+}
+
+// isInteractive reports whether stdin is a terminal
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// sameDir reports whether a and b name the same directory
+func sameDir(a, b string) bool {
+	resolvedA, errA := filepath.EvalSymlinks(a)
+	resolvedB, errB := filepath.EvalSymlinks(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return resolvedA == resolvedB
+}
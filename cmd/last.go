@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Show what the most recent trash session holds",
+	Long: `Show the items of the most recent trash session with their sizes and the paths
+they were trashed from: what did I just trash?
+
+Use --restore to put all of them back where they came from, or --empty to
+permanently delete the session after confirmation.
+
+Examples:
+  trash last
+  trash last --restore
+  trash last --restore --on-conflict=rename
+  trash last --empty`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		absolute, _ := cmd.Flags().GetBool("absolute")
+		restore, _ := cmd.Flags().GetBool("restore")
+		empty, _ := cmd.Flags().GetBool("empty")
+		force, _ := cmd.Flags().GetBool("force")
+		includeRetained, _ := cmd.Flags().GetBool("include-retained")
+		format := outputFormat(cmd)
+
+		if restore && empty {
+			i18n.Fprintf(os.Stderr, "Error: --restore and --empty cannot be used together\n")
+			os.Exit(1)
+		}
+		onConflictValue, _ := cmd.Flags().GetString("on-conflict")
+		onConflict, err := config.ParseConflict(onConflictValue)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		sessions, err := config.ListTrashSessions()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(sessions) == 0 {
+			i18n.Printf("Trash is empty\n")
+			return
+		}
+		session := sessions[len(sessions)-1]
+		trashDir := filepath.Join(configDir, session)
+
+		var items []config.TrashedItem
+		if metadata, err := config.LoadRestoreMetadata(trashDir); err == nil {
+			for _, item := range metadata.Items {
+				items = append(items, config.TrashedItem{Session: session, Item: item})
+			}
+		}
+
+		switch {
+		case restore:
+			matches := make([]restoreCandidate, 0, len(items))
+			for _, entry := range items {
+				matches = append(matches, restoreCandidate{Timestamp: session, Item: entry.Item, TrashDirPath: trashDir})
+			}
+			noVerify, _ := cmd.Flags().GetBool("no-verify")
+			opts := restoreOptions{OnConflict: onConflict, Verbose: verbose, Quiet: quiet, Messages: os.Stdout,
+				NoVerify: noVerify, Summary: newTransferSummary()}
+			if format.Structured() {
+				opts.Messages = os.Stderr
+			}
+			if len(matches) == 0 {
+				i18n.Printf("Nothing to restore\n")
+				return
+			}
+			restoreAll(matches, opts, format)
+
+		case empty:
+			if !force && !confirm(i18n.Sprintf("Permanently delete the %d item(s) of session %s?", len(items), session)) {
+				i18n.Fprintf(os.Stderr, "Aborted\n")
+				return
+			}
+			purged, err := config.PurgeSession(session, includeRetained)
+			if len(purged) > 0 {
+				bus.EmitChanged(bus.ReasonPurged)
+			}
+			if format.Structured() {
+				printStructured(format, newItemRecords(purged))
+			} else if verbose {
+				for _, p := range purged {
+					i18n.Printf("Purged: %s (from %s)\n", p.Item.Name, formatOriginal(p.Item.OriginalPath))
+				}
+			}
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error emptying trash: %v\n", err)
+				os.Exit(1)
+			}
+			if !format.Structured() {
+				i18n.Printf("Permanently deleted %d item(s)\n", len(purged))
+				if kept := len(items) - len(purged); kept > 0 {
+					i18n.Printf("Kept %d retained item(s); use --include-retained to delete them too\n", kept)
+				}
+			}
+
+		default:
+			if format.Structured() {
+				printStructured(format, newItemRecords(items))
+				return
+			}
+
+			now := time.Now()
+			i18n.Printf("[%s]\n", formatSession(session, absolute))
+			var total uint64
+			for _, entry := range items {
+				size := entry.Size()
+				total += size
+				if entry.Item.IsRetained(now) {
+					i18n.Printf("  • %s (from %s) %s, retained until %s\n", entry.Item.Name,
+						formatOriginal(entry.Item.OriginalPath), config.FormatSize(size), entry.Item.ExpiresAt)
+				} else {
+					i18n.Printf("  • %s (from %s) %s\n", entry.Item.Name,
+						formatOriginal(entry.Item.OriginalPath), config.FormatSize(size))
+				}
+			}
+			if len(items) == 0 {
+				i18n.Printf("  (no items recorded)\n")
+			}
+			i18n.Printf("  Total: %d item(s), %s\n", len(items), config.FormatSize(total))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lastCmd)
+	lastCmd.Flags().Bool("restore", false, "Restore every item of the session to where it was trashed from")
+	lastCmd.Flags().Bool("empty", false, "Permanently delete the session (asks for confirmation)")
+	lastCmd.Flags().BoolP("force", "f", false, "With --empty, do not ask for confirmation")
+	lastCmd.Flags().Bool("include-retained", false, "With --empty, also purge items kept with --keep-for or retain")
+	lastCmd.Flags().String("on-conflict", "fail", "With --restore, what to do when a destination exists: fail, overwrite or rename")
+	lastCmd.Flags().Bool("no-verify", false, "Restore even if the session metadata does not match its signature (see sign_metadata)")
+	lastCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+}
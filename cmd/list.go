@@ -1,101 +1,591 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all trashed files",
-	Long:  `Display all files and directories currently in the trash, organized by when they were trashed.`,
+	Long: `Display all files and directories currently in the trash, organized by when they were trashed.
+
+Use --since and --until to only show what was trashed in a period of time, and
+--limit to only show the most recent items; sessions outside the period are
+not even read.
+
+Examples:
+  trash list --since yesterday --limit 50
+  trash list --since 2025-12-01 --until 2025-12-31
+  trash list --since 2h -o json`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if orphans, _ := cmd.Flags().GetBool("orphans"); orphans {
+			listOrphans(cmd)
+			return
+		}
+		if duplicates, _ := cmd.Flags().GetBool("duplicates"); duplicates {
+			listDuplicates(cmd)
+			return
+		}
+
+		if tmpl, _ := cmd.Flags().GetString("format"); tmpl != "" {
+			listWithTemplate(tmpl, loadListItems(cmd))
+			return
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, newItemRecords(loadListItems(cmd)))
+			return
+		}
+
+		if byDir, _ := cmd.Flags().GetBool("by-dir"); byDir {
+			listByDir(cmd)
+			return
+		}
+
+		// Items from several trash directories are shown with the one they are in
+		allRoots, _ := cmd.Flags().GetBool("all-roots")
+		allUsers, _ := cmd.Flags().GetBool("all-users")
+		if user, _ := cmd.Flags().GetString("user"); allRoots || allUsers || user != "" {
+			listBySession(cmd, loadListItems(cmd))
+			return
+		}
+
 		configDir, err := config.GetConfigDir()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Read all timestamped directories
-		entries, err := os.ReadDir(configDir)
+		entries, err := config.StoreFS().ReadDir(configDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+			i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Filter and sort timestamped directories
+		// Read each session's metadata once, to order the sessions and list them
 		var trashDirs []string
+		loaded := make(map[string]*config.RestoreMetadata)
+		problems := make(map[string]string)
 		for _, entry := range entries {
-			if entry.IsDir() {
-				trashDirs = append(trashDirs, entry.Name())
+			if !entry.IsDir() {
+				continue
+			}
+			trashDirs = append(trashDirs, entry.Name())
+			if metadata, problem := readListedSession(configDir, entry.Name()); problem != "" {
+				problems[entry.Name()] = problem
+			} else {
+				loaded[entry.Name()] = metadata
 			}
 		}
-		sort.Strings(trashDirs) // Chronological order due to YYYYMMDD_HHMMSS format
+		config.SortLoadedSessions(trashDirs, loaded)
 
 		if len(trashDirs) == 0 {
-			fmt.Println("Trash is empty")
+			i18n.Printf("Trash is empty\n")
 			return
 		}
 
 		verbose, _ := cmd.Flags().GetBool("verbose")
+		absolute, _ := cmd.Flags().GetBool("absolute")
+		keep := listFilter(cmd)
+		window := parseListWindow(cmd)
 		totalItems := 0
 
-		// Process each trash directory
-		for _, dirName := range trashDirs {
-			dirPath := filepath.Join(configDir, dirName)
-			restoreFile := filepath.Join(dirPath, ".restore")
-
-			// Check if .restore file exists
-			if _, err := os.Stat(restoreFile); os.IsNotExist(err) {
-				if verbose {
-					fmt.Printf("\n[%s] (no metadata)\n", dirName)
-				}
+		// Take sessions newest first, so that --limit stops as soon as it has enough
+		var listed []listedSession
+		trashDirs = window.sessions(trashDirs)
+		for i := len(trashDirs) - 1; i >= 0 && (window.limit == 0 || totalItems < window.limit); i-- {
+			dirName := trashDirs[i]
+			session := listedSession{name: dirName, problem: problems[dirName]}
+			if session.problem != "" {
+				listed = append(listed, session)
 				continue
 			}
+			metadata := loaded[dirName]
 
-			// Read and parse .restore file
-			data, err := os.ReadFile(restoreFile)
-			if err != nil {
-				if verbose {
-					fmt.Printf("\n[%s] Error reading metadata: %v\n", dirName, err)
+			// Keep the newest items of this session that fit in the limit
+			for j := len(metadata.Items) - 1; j >= 0 && (window.limit == 0 || totalItems < window.limit); j-- {
+				entry := config.TrashedItem{Session: dirName, Item: metadata.Items[j]}
+				if keep(entry) && window.contains(entry) {
+					session.items = append([]config.RestoreItem{entry.Item}, session.items...)
+					totalItems++
 				}
-				continue
 			}
+			listed = append(listed, session)
+		}
 
-			var metadata config.RestoreMetadata
-			if err := json.Unmarshal(data, &metadata); err != nil {
+		// Display the sessions oldest first
+		for i := len(listed) - 1; i >= 0; i-- {
+			session := listed[i]
+			if session.problem != "" {
 				if verbose {
-					fmt.Printf("\n[%s] Error parsing metadata: %v\n", dirName, err)
+					fmt.Print(session.problem)
 				}
 				continue
 			}
 
-			// Display items from this trash session
-			if len(metadata.Items) > 0 {
-				fmt.Printf("\n[%s]\n", dirName)
-				for _, item := range metadata.Items {
-					totalItems++
+			if len(session.items) > 0 {
+				i18n.Printf("\n[%s]\n", formatSession(session.name, absolute))
+				for _, item := range session.items {
 					if verbose {
-						fmt.Printf("  • %s\n", item.Name)
-						fmt.Printf("    Original: %s\n", item.OriginalPath)
-						fmt.Printf("    Trashed:  %s\n", item.TrashedAt)
+						i18n.Printf("  • %s\n", item.Name)
+						i18n.Printf("    ID:       %s\n", item.ShortID())
+						i18n.Printf("    Original: %s\n", formatOriginal(item.OriginalPath))
+						i18n.Printf("    Trashed:  %s\n", formatTimestamp(item.TrashedAt, absolute))
+						if item.Retained {
+							i18n.Printf("    Expires:  %s (retained until then)\n", item.ExpiresAt)
+						} else if item.ExpiresAt != "" {
+							i18n.Printf("    Expires:  %s\n", item.ExpiresAt)
+						}
+						if item.MIMEType != "" {
+							i18n.Printf("    Type:     %s (%s)\n", item.Type, item.MIMEType)
+						} else if item.Type != "" {
+							i18n.Printf("    Type:     %s\n", item.Type)
+						}
+						if item.User != "" || item.Hostname != "" {
+							i18n.Printf("    Owner:    %s\n", formatOwner(item.User, item.UID, item.Hostname))
+						}
 					} else {
-						fmt.Printf("  • %s (from %s)\n", item.Name, item.OriginalPath)
+						i18n.Printf("  • %s  %s (from %s)\n", item.ShortID(), item.Name, formatOriginal(item.OriginalPath))
 					}
 				}
 			}
 		}
 
-		fmt.Printf("\nTotal: %d item(s) in trash\n", totalItems)
+		i18n.Printf("\nTotal: %d item(s) in trash\n", totalItems)
 	},
 }
 
+// listFilter returns a predicate implementing the list filtering flags
+func listFilter(cmd *cobra.Command) func(config.TrashedItem) bool {
+	owner, _ := cmd.Flags().GetString("owner")
+	host, _ := cmd.Flags().GetString("host")
+	kind, _ := cmd.Flags().GetString("type")
+
+	return func(entry config.TrashedItem) bool {
+		if owner != "" && entry.Item.User != owner && entry.Item.UID != owner {
+			return false
+		}
+		if host != "" && entry.Item.Hostname != host {
+			return false
+		}
+		if kind != "" {
+			payloadPath, _ := entry.Path()
+			if !entry.Item.MatchesType(kind, payloadPath) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// loadListItems returns every trashed item accepted by the list filtering and
+// windowing flags, exiting on error
+func loadListItems(cmd *cobra.Command) []config.TrashedItem {
+	items := loadTrashedItems(cmd)
+
+	keep := listFilter(cmd)
+	window := parseListWindow(cmd)
+	var filtered []config.TrashedItem
+	for _, entry := range items {
+		if keep(entry) && window.contains(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return window.newest(filtered)
+}
+
+// listedSession is a session read by list: the items to show, or the problem
+// that kept its metadata from being read
+type listedSession struct {
+	name    string
+	items   []config.RestoreItem
+	problem string
+}
+
+// readListedSession reads the metadata of a session for list, or describes the
+// problem that keeps it from being read
+func readListedSession(configDir, dirName string) (*config.RestoreMetadata, string) {
+	restoreFile := filepath.Join(configDir, dirName, ".restore")
+
+	// Check if .restore file exists
+	if _, err := config.StoreFS().Lstat(restoreFile); errors.Is(err, fs.ErrNotExist) {
+		return nil, i18n.Sprintf("\n[%s] (no metadata)\n", dirName)
+	}
+
+	// Read and parse .restore file
+	data, err := config.ReadFile(config.StoreFS(), restoreFile)
+	if err != nil {
+		return nil, i18n.Sprintf("\n[%s] Error reading metadata: %v\n", dirName, err)
+	}
+
+	metadata, err := config.ParseRestoreMetadata(data)
+	if err != nil {
+		return nil, i18n.Sprintf("\n[%s] Error parsing metadata: %v\n", dirName, err)
+	}
+	if err := metadata.Validate(); err != nil {
+		return nil, i18n.Sprintf("\n[%s] Invalid metadata: %v\n", dirName, err)
+	}
+	return metadata, ""
+}
+
+// listWindow implements --since, --until and --limit
+type listWindow struct {
+	since time.Time // zero when not set
+	until time.Time // zero when not set
+	limit int       // 0 for no limit
+}
+
+// parseListWindow returns the window given by the flags, exiting on an invalid value
+func parseListWindow(cmd *cobra.Command) listWindow {
+	var window listWindow
+	now := time.Now()
+	for _, flag := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &window.since}, {"until", &window.until}} {
+		value, _ := cmd.Flags().GetString(flag.name)
+		if value == "" {
+			continue
+		}
+		t, err := config.ParseTime(value, now)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: invalid --%s value: %v\n", flag.name, err)
+			os.Exit(1)
+		}
+		*flag.t = t
+	}
+
+	window.limit, _ = cmd.Flags().GetInt("limit")
+	if window.limit < 0 {
+		i18n.Fprintf(os.Stderr, "Error: --limit must not be negative\n")
+		os.Exit(1)
+	}
+	return window
+}
+
+// sessions drops the sessions that cannot hold items in the window, judging by
+// the time in their names alone
+func (w listWindow) sessions(sessions []string) []string {
+	if w.since.IsZero() && w.until.IsZero() {
+		return sessions
+	}
+
+	var kept []string
+	for _, session := range sessions {
+		created, err := config.SessionTime(session)
+		if err == nil && ((!w.until.IsZero() && created.After(w.until)) ||
+			(!w.since.IsZero() && created.Add(config.MaxSessionSpan).Before(w.since))) {
+			continue
+		}
+		kept = append(kept, session)
+	}
+	return kept
+}
+
+// contains reports whether an item was trashed within the window
+func (w listWindow) contains(entry config.TrashedItem) bool {
+	if w.since.IsZero() && w.until.IsZero() {
+		return true
+	}
+	t := entry.TrashedTime()
+	return (w.since.IsZero() || !t.Before(w.since)) && (w.until.IsZero() || !t.After(w.until))
+}
+
+// newest keeps the limit most recently trashed items, in their original order
+func (w listWindow) newest(items []config.TrashedItem) []config.TrashedItem {
+	if w.limit == 0 || len(items) <= w.limit {
+		return items
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	// Items trashed in the same second are listed in the order they were trashed
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := items[order[a]].TrashedTime(), items[order[b]].TrashedTime()
+		if ta.Equal(tb) {
+			return order[a] > order[b]
+		}
+		return ta.After(tb)
+	})
+	chosen := make([]bool, len(items))
+	for _, i := range order[:w.limit] {
+		chosen[i] = true
+	}
+
+	var kept []config.TrashedItem
+	for i, entry := range items {
+		if chosen[i] {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// listBySession displays items grouped by root and session, for --all-roots
+func listBySession(cmd *cobra.Command, items []config.TrashedItem) {
+	absolute, _ := cmd.Flags().GetBool("absolute")
+
+	if len(items) == 0 {
+		i18n.Printf("Trash is empty\n")
+		return
+	}
+
+	var current config.TrashedItem
+	for i, entry := range items {
+		if i == 0 || entry.Root != current.Root || entry.Session != current.Session {
+			i18n.Printf("\n[%s]%s\n", formatSession(entry.Session, absolute), formatRoot(entry.Root))
+			current = entry
+		}
+		i18n.Printf("  • %s  %s (from %s)\n", entry.Item.ShortID(), entry.Item.Name, formatOriginal(entry.Item.OriginalPath))
+	}
+
+	i18n.Printf("\nTotal: %d item(s) in trash\n", len(items))
+}
+
+// listByDir displays trashed items grouped by the directory they were trashed from
+func listByDir(cmd *cobra.Command) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	absolute, _ := cmd.Flags().GetBool("absolute")
+
+	items := loadListItems(cmd)
+	if len(items) == 0 {
+		i18n.Printf("Trash is empty\n")
+		return
+	}
+
+	// Group items by original parent directory
+	groups := make(map[string][]config.TrashedItem)
+	var dirs []string
+	for _, entry := range items {
+		dir := formatOriginal(entry.Item.OriginalPath)
+		if entry.Item.OriginalPath != "" {
+			dir = filepath.Dir(entry.Item.OriginalPath)
+		}
+		if _, ok := groups[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], entry)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		i18n.Printf("\n%s\n", dir)
+		for _, entry := range groups[dir] {
+			if verbose {
+				i18n.Printf("  • %s\n", entry.Item.Name)
+				i18n.Printf("    ID:       %s\n", entry.Item.ShortID())
+				i18n.Printf("    Session:  %s\n", entry.Session)
+				if entry.Root.Name != "" {
+					i18n.Printf("    Store:    %s\n", entry.Root.Dir)
+				}
+				i18n.Printf("    Trashed:  %s\n", formatTimestamp(entry.Item.TrashedAt, absolute))
+			} else {
+				i18n.Printf("  • %s  %s [%s]%s\n", entry.Item.ShortID(), entry.Item.Name, formatSession(entry.Session, absolute), formatRoot(entry.Root))
+			}
+		}
+	}
+
+	i18n.Printf("\nTotal: %d item(s) in trash\n", len(items))
+}
+
+// orphanRecord is the structured (--output) representation of an orphan
+type orphanRecord struct {
+	Session string `json:"session" yaml:"session"`
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Reason  string `json:"reason" yaml:"reason"`
+	Bytes   uint64 `json:"bytes" yaml:"bytes"`
+}
+
+// listOrphans displays payloads missing from their session's metadata and
+// sessions without usable metadata, which explains why du and list disagree
+func listOrphans(cmd *cobra.Command) {
+	orphans, err := config.FindOrphans()
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if format := outputFormat(cmd); format.Structured() {
+		records := []orphanRecord{}
+		for _, orphan := range orphans {
+			records = append(records, orphanRecord{Session: orphan.Session, Name: orphan.Name, Reason: orphan.Reason, Bytes: orphan.Bytes})
+		}
+		printStructured(format, records)
+		return
+	}
+
+	if len(orphans) == 0 {
+		i18n.Printf("No orphans: everything in the trash is listed\n")
+		return
+	}
+
+	var total uint64
+	for _, orphan := range orphans {
+		total += orphan.Bytes
+		if orphan.Name == "" {
+			i18n.Printf("[%s] whole session, %s: %s\n", orphan.Session, orphan.Reason, config.FormatSize(orphan.Bytes))
+		} else {
+			i18n.Printf("[%s] %s, %s: %s\n", orphan.Session, orphan.Name, orphan.Reason, config.FormatSize(orphan.Bytes))
+		}
+	}
+
+	i18n.Printf("\nTotal: %d orphan(s), %s not shown by list\n", len(orphans), config.FormatSize(total))
+}
+
+// duplicateRecord is the structured (--output) representation of a group of
+// items with identical payloads
+type duplicateRecord struct {
+	Checksum string       `json:"checksum" yaml:"checksum"`
+	Size     uint64       `json:"size" yaml:"size"`
+	Savings  uint64       `json:"savings" yaml:"savings"`
+	Items    []itemRecord `json:"items" yaml:"items"`
+}
+
+// listDuplicates displays the items whose payloads are identical, grouped,
+// with the space keeping one copy of each would save
+func listDuplicates(cmd *cobra.Command) {
+	absolute, _ := cmd.Flags().GetBool("absolute")
+
+	groups, err := config.FindDuplicates()
+	if err != nil && groups == nil {
+		i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Warning: checksums could not be stored: %v\n", err)
+	}
+
+	if format := outputFormat(cmd); format.Structured() {
+		records := []duplicateRecord{}
+		for _, group := range groups {
+			records = append(records, duplicateRecord{
+				Checksum: group.Checksum,
+				Size:     group.Size,
+				Savings:  group.Savings(),
+				Items:    newItemRecords(group.Items),
+			})
+		}
+		printStructured(format, records)
+		return
+	}
+
+	if len(groups) == 0 {
+		i18n.Printf("No duplicates: every payload in the trash is different\n")
+		return
+	}
+
+	var total uint64
+	for _, group := range groups {
+		total += group.Savings()
+		i18n.Printf("\n%d copies of %s, %s each (%s to save)\n", len(group.Items), group.Items[0].Item.Name,
+			config.FormatSize(group.Size), config.FormatSize(group.Savings()))
+		for _, entry := range group.Items {
+			i18n.Printf("  • %s  %s [%s] (from %s)\n", entry.Item.ShortID(), entry.Item.Name,
+				formatSession(entry.Session, absolute), formatOriginal(entry.Item.OriginalPath))
+		}
+	}
+
+	i18n.Printf("\nTotal: %d group(s) of duplicates, %s could be saved\n", len(groups), config.FormatSize(total))
+}
+
+// templateItem is the data exposed to --format templates
+type templateItem struct {
+	ID           string
+	Session      string
+	Name         string
+	OriginalPath string
+	TrashPath    string
+	SessionDir   string
+	TrashedAt    string
+	ExpiresAt    string
+	Retained     bool
+	User         string
+	UID          string
+	Hostname     string
+	Type         string
+	MIMEType     string
+
+	entry config.TrashedItem
+}
+
+// Size returns the payload size in bytes, as recorded at trash time; it is only
+// measured, when a template uses it, for items trashed by older versions
+func (t templateItem) Size() uint64 {
+	return t.entry.Size()
+}
+
+// listWithTemplate renders every trashed item with a user supplied Go template
+func listWithTemplate(tmpl string, items []config.TrashedItem) {
+	// Allow escaped tabs and newlines as typed in a shell, like docker --format
+	tmpl = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(tmpl)
+
+	t, err := template.New("format").Funcs(template.FuncMap{
+		"humanSize": config.FormatSize,
+	}).Parse(tmpl)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error parsing --format template: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, entry := range items {
+		trashPath, _ := entry.Path()
+		sessionDir, _ := entry.SessionDir()
+		data := templateItem{
+			ID:           entry.Item.ShortID(),
+			Session:      entry.Session,
+			Name:         entry.Item.Name,
+			OriginalPath: entry.Item.OriginalPath,
+			TrashPath:    trashPath,
+			SessionDir:   sessionDir,
+			TrashedAt:    entry.Item.TrashedAt,
+			ExpiresAt:    entry.Item.ExpiresAt,
+			Retained:     entry.Item.Retained,
+			User:         entry.Item.User,
+			UID:          entry.Item.UID,
+			Hostname:     entry.Item.Hostname,
+			Type:         entry.Item.Type,
+			MIMEType:     entry.Item.MIMEType,
+			entry:        entry,
+		}
+
+		if err := t.Execute(os.Stdout, data); err != nil {
+			i18n.Fprintf(os.Stderr, "\nError executing --format template: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println()
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().Bool("by-dir", false, "Group items by their original parent directory")
+	listCmd.Flags().String("format", "", "Format each item with a Go template (e.g. '{{.Name}}\\t{{.Size}}')")
+	listCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+	listCmd.Flags().String("owner", "", "Only show items trashed by this user name or uid")
+	listCmd.Flags().String("host", "", "Only show items trashed on this hostname")
+	listCmd.Flags().Bool("all-roots", false, "List the home trash, the project's local trash and the configured roots together")
+	listCmd.Flags().String("user", "", "List the trash of this user, in their home and on every mounted volume (root only for other users)")
+	listCmd.Flags().Bool("all-users", false, "List the trash of every local user (root only)")
+	listCmd.Flags().Bool("orphans", false, "List payloads missing from their session's metadata and sessions without metadata")
+	listCmd.Flags().Bool("duplicates", false, "List items whose payloads are identical and the space keeping one copy would save")
+	listCmd.Flags().String("type", "", "Only show items of this type: file, dir, symlink, a MIME category (image, text) or MIME type")
+	listCmd.Flags().String("since", "", "Only show items trashed since this time: a date, a duration ago (e.g. 7d), today, yesterday or last week")
+	listCmd.Flags().String("until", "", "Only show items trashed until this time, given like for --since")
+	listCmd.Flags().Int("limit", 0, "Only show this many of the most recently trashed items")
 }
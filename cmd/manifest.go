@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// manifestEntry is one line of a restore manifest
+type manifestEntry struct {
+	Line    int
+	Session string // optional session the item must come from
+	Name    string
+	Target  string // optional destination overriding the original path
+}
+
+// parseRestoreManifest reads a restore manifest. Each non-empty line that does not
+// start with '#' names an item, optionally prefixed by its session ("SESSION/name"),
+// optionally followed by a tab and the path to restore it to.
+func parseRestoreManifest(path string) ([]manifestEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []manifestEntry
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		entry := manifestEntry{Line: lineNo}
+		ref, target, _ := strings.Cut(line, "\t")
+		ref = strings.TrimSpace(ref)
+
+		// Accept backslashes as typed on Windows
+		if session, name, ok := strings.Cut(strings.Replace(ref, `\`, "/", 1), "/"); ok {
+			if _, err := config.ParseSessionTime(session); err == nil {
+				entry.Session, ref = session, name
+			}
+		}
+		entry.Name = ref
+
+		if target = strings.TrimSpace(target); target != "" {
+			absTarget, err := filepath.Abs(target)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid target %q: %w", lineNo, target, err)
+			}
+			entry.Target = absTarget
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// resolveManifest finds the trashed item for every manifest entry (the most recent one
+// when the session is not given). All problems are reported before anything is restored.
+func resolveManifest(configDir string, entries []manifestEntry, matcher config.MatchOptions) ([]restoreCandidate, []error) {
+	var resolved []restoreCandidate
+	var problems []error
+
+	for _, entry := range entries {
+		name := entry.Name
+		matches, err := findRestoreMatches(configDir, entry.Session, func(item config.RestoreItem) bool {
+			return matcher.Equal(item.Name, name)
+		})
+		if err != nil {
+			problems = append(problems, fmt.Errorf("line %d: %w", entry.Line, err))
+			continue
+		}
+		if len(matches) == 0 {
+			problems = append(problems, fmt.Errorf("line %d: item '%s' not found in trash", entry.Line, name))
+			continue
+		}
+
+		match := matches[0]
+		match.Target = entry.Target
+		resolved = append(resolved, match)
+	}
+
+	return resolved, problems
+}
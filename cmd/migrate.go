@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the trash directory to the current storage layout",
+	Long: `Upgrade sessions created by older versions in place: payloads lying directly
+in a session are moved into item directories of their own, and items without a
+recorded size are measured once so that listings no longer need to.
+
+Older sessions keep working without migrating; migrating only makes them behave
+like new ones. The .restore files of the migrated sessions are first saved to a
+migrate-backup-<time>.json file in the trash directory. Sessions whose metadata
+is unusable or fails its signature check are reported and left alone.
+
+With --xdg, a trash directory an older version kept in ~/.config/trash is moved
+to $XDG_DATA_HOME/trash (~/.local/share/trash) instead, leaving config.toml in
+$XDG_CONFIG_HOME/trash. Until then it keeps being used where it is.
+
+Examples:
+  trash migrate --dry-run
+  trash migrate
+  trash migrate --xdg`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if xdg, _ := cmd.Flags().GetBool("xdg"); xdg {
+			migrateToXDG(dryRun)
+			return
+		}
+
+		migrations, backupPath, err := config.MigrateStore(dryRun)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		moved, sized, failed := 0, 0, 0
+		for _, migration := range migrations {
+			if migration.Err != nil {
+				i18n.Fprintf(os.Stderr, "Skipping %s: %v\n", migration.Session, migration.Err)
+				failed++
+				continue
+			}
+			moved += migration.Moved
+			sized += migration.Sized
+			if verbose && dryRun {
+				i18n.Printf("%s: would move %d item(s) into item directories and record %d size(s)\n", migration.Session, migration.Moved, migration.Sized)
+			} else if verbose {
+				i18n.Printf("%s: %d item(s) moved into item directories, %d size(s) recorded\n", migration.Session, migration.Moved, migration.Sized)
+			}
+		}
+
+		switch {
+		case moved == 0 && sized == 0 && failed == 0:
+			i18n.Printf("The trash directory is up to date\n")
+		case dryRun:
+			i18n.Printf("Would move %d item(s) into item directories and record %d size(s)\n", moved, sized)
+		default:
+			i18n.Printf("Moved %d item(s) into item directories and recorded %d size(s)\n", moved, sized)
+			if backupPath != "" {
+				i18n.Printf("Previous metadata saved to %s\n", backupPath)
+			}
+		}
+
+		if failed > 0 {
+			os.Exit(exitError)
+		}
+	},
+}
+
+// migrateToXDG moves a trash directory of an older version to the XDG data directory
+func migrateToXDG(dryRun bool) {
+	from, to, err := config.MigrateToXDG(dryRun)
+	if errors.Is(err, config.ErrNoLegacyStore) {
+		i18n.Printf("The trash directory is up to date\n")
+		return
+	}
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if dryRun {
+		i18n.Printf("Would move %s to %s\n", from, to)
+	} else {
+		i18n.Printf("Moved %s to %s\n", from, to)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().BoolP("dry-run", "n", false, "Only report what would be migrated")
+	migrateCmd.Flags().Bool("xdg", false, "Move a trash directory from ~/.config/trash to $XDG_DATA_HOME/trash (%LOCALAPPDATA%\\trash on Windows)")
+}
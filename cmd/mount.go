@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"github.com/artemisfowl/trash/internal/mount"
+)
+
+var mountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Browse the trash as a read-only filesystem",
+	Long: `Mount the trash as a read-only FUSE filesystem, so trashed files can be
+browsed and copied out with any file manager. Every session is a directory
+holding its items at their original paths:
+
+  <mountpoint>/20251217_010006.../home/me/notes.txt
+
+Items whose original location is unknown are shown by name directly in their
+session. Items of compressed sessions are extracted to a temporary directory
+the first time they are opened.
+
+The filesystem shows the trash as it was when it was mounted; mount it again
+to see later changes. It stays mounted until trash is interrupted (Ctrl+C)
+or terminated, then it is unmounted. Needs FUSE (fuse3 on Linux, macFUSE on
+macOS) and a trash directory on the local disk.
+
+Examples:
+  trash mount ~/trash-view`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mountpoint := args[0]
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		if info, err := os.Stat(mountpoint); err != nil || !info.IsDir() {
+			i18n.Fprintf(os.Stderr, "Error: mount point %s is not a directory\n", mountpoint)
+			os.Exit(1)
+		}
+
+		m, err := mount.New(mountpoint, debug)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: cannot mount the trash: %v\n", err)
+			os.Exit(1)
+		}
+		i18n.Printf("Trash mounted at %s; press Ctrl+C to unmount\n", mountpoint)
+
+		// Unmount on a signal; while programs still use the filesystem that
+		// fails, and the next signal tries again
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			for range signals {
+				if err := m.Unmount(); err != nil {
+					i18n.Fprintf(os.Stderr, "Error: cannot unmount %s: %v\n", mountpoint, err)
+					i18n.Fprintf(os.Stderr, "Close the programs using it and try again\n")
+				}
+			}
+		}()
+
+		m.Wait()
+		i18n.Printf("Unmounted %s\n", mountpoint)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mountCmd)
+	mountCmd.Flags().Bool("debug", false, "Log every FUSE request to standard error")
+}
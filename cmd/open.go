@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open <item>",
+	Short: "Open a trashed item with the default application",
+	Long: `Open a trashed item to check it is the right one, without restoring it.
+
+Files are opened from a read-only temporary copy, so the application cannot
+change what is in the trash; directories are opened in place. Items of
+compressed sessions are extracted to a temporary directory first. The item is given
+like for 'trash path': by name, as session/name or by its original path, and the
+most recently trashed match is opened.
+
+The default application is used (xdg-open, open or start), unless --with or the
+viewer setting names another command, which then runs in the terminal.
+
+Examples:
+  trash open report.pdf
+  trash open 20251217_010006/notes.txt --with less
+  trash config set viewer "code --wait"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		matches := findItems(args[0])
+		entry := matches[len(matches)-1]
+		trashPath, err := entry.Path()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var target, tmpDir string
+		if entry.Archived() {
+			target, tmpDir, err = extractedCopy(entry)
+		} else {
+			target, tmpDir, err = readOnlyCopy(trashPath)
+		}
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: could not copy %s: %v\n", entry.Item.Name, err)
+			os.Exit(exitCode(err))
+		}
+		if verbose {
+			i18n.Printf("Opening %s\n", target)
+		}
+
+		viewer := viewerCommand(cmd)
+		if len(viewer) == 0 {
+			// The application may still be loading the copy, so it is left for
+			// the system to clean up with the rest of the temporary directory
+			if err := openDefault(target); err != nil {
+				removeCopy(target, tmpDir)
+				i18n.Fprintf(os.Stderr, "Error: could not open %s: %v\n", entry.Item.Name, err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		view := exec.Command(viewer[0], append(viewer[1:], target)...)
+		view.Stdin = os.Stdin
+		view.Stdout = os.Stdout
+		view.Stderr = os.Stderr
+		err = view.Run()
+		removeCopy(target, tmpDir)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %s: %v\n", viewer[0], err)
+			os.Exit(1)
+		}
+	},
+}
+
+// readOnlyCopy copies a trashed file into a new temporary directory and makes
+// it read-only. Directories are returned as they are, with no temporary directory.
+func readOnlyCopy(trashPath string) (target, tmpDir string, err error) {
+	info, err := os.Stat(trashPath)
+	if err != nil {
+		return "", "", err
+	}
+	if info.IsDir() {
+		return trashPath, "", nil
+	}
+
+	tmpDir, err = os.MkdirTemp("", "trash-open-")
+	if err != nil {
+		return "", "", err
+	}
+	target = filepath.Join(tmpDir, filepath.Base(trashPath))
+	if err := config.CopyFile(trashPath, target); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+	if err := os.Chmod(target, 0444); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+	return target, tmpDir, nil
+}
+
+// extractedCopy extracts an item of a compressed session into a new temporary
+// directory; files are made read-only like those of readOnlyCopy
+func extractedCopy(entry config.TrashedItem) (target, tmpDir string, err error) {
+	tmpDir, err = os.MkdirTemp("", "trash-open-")
+	if err != nil {
+		return "", "", err
+	}
+	trashDir, err := entry.SessionDir()
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+	target = filepath.Join(tmpDir, entry.Item.Name)
+	if err := config.ExtractArchived(trashDir, entry.Item, target); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+	if info, err := os.Lstat(target); err == nil && info.Mode().IsRegular() {
+		os.Chmod(target, 0444)
+	}
+	return target, tmpDir, nil
+}
+
+// removeCopy removes a copy made by readOnlyCopy; read-only files cannot be
+// removed on Windows, so write permission is restored first
+func removeCopy(target, tmpDir string) {
+	if tmpDir == "" {
+		return
+	}
+	os.Chmod(target, 0600)
+	os.RemoveAll(tmpDir)
+}
+
+// viewerCommand returns the command from --with or the viewer setting, split
+// into words, or nil to use the default application
+func viewerCommand(cmd *cobra.Command) []string {
+	viewer, _ := cmd.Flags().GetString("with")
+	if !cmd.Flags().Changed("with") {
+		if settings, err := config.LoadSettings(); err == nil {
+			viewer = settings.Viewer
+		}
+	}
+	return strings.Fields(viewer)
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().String("with", "", "Open with this command instead of the default application (e.g. less)")
+}
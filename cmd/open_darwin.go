@@ -0,0 +1,10 @@
+//go:build darwin
+
+package cmd
+
+import "os/exec"
+
+// openDefault opens path with the application macOS associates with it
+func openDefault(path string) error {
+	return exec.Command("open", path).Run()
+}
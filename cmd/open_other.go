@@ -0,0 +1,19 @@
+//go:build !darwin && !windows
+
+package cmd
+
+import (
+	"os/exec"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// openDefault opens path with the desktop's default application via xdg-open,
+// or termux-open on Android
+func openDefault(path string) error {
+	opener := "xdg-open"
+	if config.IsTermux() {
+		opener = "termux-open"
+	}
+	return exec.Command(opener, path).Run()
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package cmd
+
+import "os/exec"
+
+// openDefault opens path with the application Windows associates with it
+func openDefault(path string) error {
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", path).Start()
+}
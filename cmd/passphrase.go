@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"golang.org/x/term"
+)
+
+// applyPassphrase makes the signing key come from a passphrase when
+// --passphrase is given, e.g. on headless machines without a keyring
+func applyPassphrase(cmd *cobra.Command) {
+	if usePassphrase, _ := cmd.Flags().GetBool("passphrase"); usePassphrase {
+		config.UsePassphrase(readPassphrase)
+	}
+}
+
+// readPassphrase asks for the passphrase at the terminal without echoing it,
+// or reads the first line of stdin when it is not a terminal
+func readPassphrase() (string, error) {
+	if !isInteractive() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	i18n.Fprintf(os.Stderr, "Passphrase for the signing key: ")
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var pathCmd = &cobra.Command{
+	Use:   "path <item>",
+	Short: "Print where a trashed item is stored inside the trash",
+	Long: `Print the location of a trashed item inside the trash directory, so it can be
+opened in an editor or passed to other tools without restoring it.
+
+The item is given by name, as session/name (the session may be a unique prefix),
+by its ID as shown by 'trash list', or by the path it was trashed from. When several items match, the most recently
+trashed one is printed; use --all to print every match.
+
+Examples:
+  trash path report.pdf
+  trash path 20251217_010006/report.pdf
+  trash path ~/Documents/report.pdf
+  less "$(trash path notes.txt)"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+
+		matches := findItems(args[0])
+		if !all {
+			matches = matches[len(matches)-1:]
+		}
+		for _, entry := range matches {
+			trashPath, err := entry.Path()
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if entry.Archived() {
+				i18n.Fprintf(os.Stderr, "Error: %s is compressed into %s of its session; use 'trash open' or 'trash restore --keep'\n", entry.Item.Name, config.ArchiveFileName)
+				os.Exit(1)
+			}
+			fmt.Println(trashPath)
+		}
+	},
+}
+
+// findItems returns the trashed items a reference names, oldest first, exiting
+// when there are none. A reference is session/name, a name, or an original path.
+func findItems(ref string) []config.TrashedItem {
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	matches := matchItemRef(items, ref)
+	if len(matches) == 0 {
+		i18n.Fprintf(os.Stderr, "Error: item '%s' not found in trash\n", ref)
+		os.Exit(exitNotFound)
+	}
+	return matches
+}
+
+// matchItemRef returns the items named by ref, trying session/name first, then
+// the item name, the short item ID and finally the original path
+func matchItemRef(items []config.TrashedItem, ref string) []config.TrashedItem {
+	var matches []config.TrashedItem
+
+	if sessionRef, name, ok := strings.Cut(filepath.ToSlash(ref), "/"); ok && sessionRef != "" {
+		if session, err := config.ResolveSession(sessionRef); err == nil {
+			for _, entry := range items {
+				if entry.Session == session && entry.Item.Name == name {
+					matches = append(matches, entry)
+				}
+			}
+			if len(matches) > 0 {
+				return matches
+			}
+		}
+	}
+
+	for _, entry := range items {
+		if entry.Item.Name == ref {
+			matches = append(matches, entry)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	for _, entry := range items {
+		if strings.EqualFold(entry.Item.ShortID(), ref) {
+			matches = append(matches, entry)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	absPath, err := filepath.Abs(ref)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range items {
+		if entry.Item.OriginalPath == absPath {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+func init() {
+	rootCmd.AddCommand(pathCmd)
+	pathCmd.Flags().Bool("all", false, "Print every matching item, oldest first")
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// pluginPrefix is prepended to unknown subcommand names to find plugins on PATH
+const pluginPrefix = "trash-"
+
+// reservedNames are handled by cobra itself and never looked up as plugins
+var reservedNames = map[string]bool{
+	"help":             true,
+	"completion":       true,
+	"__complete":       true,
+	"__completeNoDesc": true,
+}
+
+// findPlugin returns the trash-<name> executable for the first argument when it
+// is neither a built-in command nor an existing path, which is trashed instead
+func findPlugin(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	name := args[0]
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) || reservedNames[name] {
+		return "", false
+	}
+	if found, _, err := rootCmd.Find(args); err == nil && found != rootCmd {
+		return "", false
+	}
+	if _, err := os.Lstat(name); err == nil {
+		return "", false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// pluginEnv returns the environment for a plugin: the caller's, plus the trash
+// directory a built-in command would use here and how to call trash back
+func pluginEnv() []string {
+	if cwd, err := os.Getwd(); err == nil {
+		if root, marked := config.FindMarkedProject(cwd); marked {
+			config.UseStoreDir(config.LocalStoreDir(root))
+		}
+	}
+
+	env := os.Environ()
+	if dir, err := config.GetConfigDir(); err == nil {
+		env = append(env, "TRASH_DIR="+dir)
+	}
+	if self, err := os.Executable(); err == nil {
+		if abs, err := filepath.Abs(self); err == nil {
+			self = abs
+		}
+		env = append(env, "TRASH_BIN="+self)
+	}
+	return append(env, "TRASH_VERSION="+Version)
+}
@@ -0,0 +1,35 @@
+//go:build !linux && !darwin && !freebsd
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// runPlugin runs the plugin as a child process and exits with its status
+func runPlugin(path string, args []string) {
+	plugin := exec.Command(path, args...)
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+	plugin.Env = pluginEnv()
+
+	// Ctrl+C reaches the plugin too; let it decide how to stop
+	signal.Ignore(os.Interrupt)
+
+	err := plugin.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: cannot run plugin %s: %v\n", path, err)
+		os.Exit(exitError)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// runPlugin replaces the process with the plugin, so it gets the terminal,
+// signals and exit status directly
+func runPlugin(path string, args []string) {
+	argv := append([]string{path}, args...)
+	err := syscall.Exec(path, argv, pluginEnv())
+	i18n.Fprintf(os.Stderr, "Error: cannot run plugin %s: %v\n", path, err)
+	os.Exit(exitError)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"github.com/artemisfowl/trash/internal/notify"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Apply the configured retention policies",
+	Long: `Purge expired items and, when free space on the trash filesystem is below
+the configured min_free threshold, the oldest trash sessions.
+
+The policies are also applied after every trash operation; 'trash prune'
+is what scheduled cleanup (see 'trash cron') invokes. It also finishes
+purges that were interrupted (see 'trash empty --resume') and compresses
+the payloads of sessions older than the compress_after setting into an
+archive in the session; list and restore work on them as before, restoring
+just takes longer.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if resumed, err := config.ResumePurge(); err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if resumed > 0 {
+			i18n.Printf("Finished %d interrupted purge(s)\n", resumed)
+		}
+		if autoPrune("", verbose) > 0 {
+			bus.EmitChanged(bus.ReasonPurged)
+		}
+		autoCompress(verbose)
+	},
+}
+
+// autoCompress compresses the sessions older than the compress_after setting
+func autoCompress(verbose bool) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return // reported by autoPrune
+	}
+	age, err := settings.CompressAge()
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	if age == 0 {
+		return
+	}
+
+	compressed, err := config.CompressSessions(time.Now().Add(-age))
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Warning: failed to compress old sessions: %v\n", err)
+		return
+	}
+	var count int
+	var before, after uint64
+	for _, session := range compressed {
+		if session.Err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: failed to compress %s: %v\n", session.Session, session.Err)
+			continue
+		}
+		count++
+		before += session.Before
+		after += session.After
+		if verbose {
+			i18n.Printf("Compressed: %s (%s to %s)\n", session.Session, config.FormatSize(session.Before), config.FormatSize(session.After))
+		}
+	}
+	if count > 0 {
+		i18n.Printf("Compressed %d session(s) older than %s, %s to %s\n", count, settings.CompressAfter, config.FormatSize(before), config.FormatSize(after))
+	}
+}
+
+// autoPrune purges expired items, then the oldest sessions when free space on
+// the trash filesystem drops below the configured min_free threshold.
+// It returns the number of expired items and sessions purged.
+func autoPrune(keep string, verbose bool) int {
+	settings, settingsErr := config.LoadSettings()
+	if settingsErr != nil {
+		i18n.Fprintf(os.Stderr, "Warning: %v\n", settingsErr)
+	}
+
+	// Free space before purging, to report what was reclaimed
+	configDir, _ := config.GetConfigDir()
+	freeBefore, _ := config.FreeSpace(configDir)
+
+	expired, err := config.PurgeExpired(time.Now())
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Warning: failed to purge expired items: %v\n", err)
+	}
+	if len(expired) > 0 {
+		i18n.Printf("Purged %d expired item(s)\n", len(expired))
+		if verbose {
+			for _, purged := range expired {
+				i18n.Printf("Purged: %s (from %s)\n", purged.Item.Name, formatOriginal(purged.Item.OriginalPath))
+			}
+		}
+	}
+
+	var purged []string
+	if settingsErr == nil {
+		minFree, err := settings.MinFreeBytes()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			purged, err = config.PruneForFreeSpace(minFree, keep)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Warning: auto-prune failed: %v\n", err)
+			}
+
+			if len(purged) > 0 {
+				i18n.Printf("Free space below %s, purged %d old session(s)\n", config.FormatSize(minFree), len(purged))
+				if verbose {
+					for _, session := range purged {
+						i18n.Printf("Purged: %s\n", session)
+					}
+				}
+			}
+		}
+	}
+
+	// Unattended purges should not go unnoticed
+	if len(expired)+len(purged) > 0 && settings.NotifyEnabled() {
+		var reclaimed uint64
+		if freeAfter, err := config.FreeSpace(configDir); err == nil && freeAfter > freeBefore {
+			reclaimed = freeAfter - freeBefore
+		}
+		notifyPurge(len(expired), len(purged), reclaimed)
+	}
+
+	return len(expired) + len(purged)
+}
+
+// notifyPurge sends a desktop notification summarizing an automatic purge
+func notifyPurge(expired, sessions int, reclaimed uint64) {
+	var lines []string
+	if expired > 0 {
+		lines = append(lines, i18n.Sprintf("Purged %d expired item(s)", expired))
+	}
+	if sessions > 0 {
+		lines = append(lines, i18n.Sprintf("Purged %d old session(s) to keep free space", sessions))
+	}
+	if reclaimed > 0 {
+		lines = append(lines, i18n.Sprintf("Reclaimed %s", config.FormatSize(reclaimed)))
+	}
+
+	notify.Send(i18n.Sprintf("Trash purged"), strings.Join(lines, "\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+}
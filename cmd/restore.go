@@ -1,14 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
 	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"github.com/artemisfowl/trash/internal/output"
 )
 
 var restoreCmd = &cobra.Command{
@@ -16,220 +22,598 @@ var restoreCmd = &cobra.Command{
 	Short: "Restore a trashed file or directory",
 	Long: `Restore a file or directory from trash back to its original location.
 If multiple items with the same name exist, the most recently trashed one will be restored.
+The item may also be given by its ID as shown by 'trash list'.
 Use --all flag to see all matches and choose, or --timestamp to specify which one.
+At a terminal, --all and --interactive offer a numbered menu to restore each match
+to its original location or the current directory, purge it or skip it.
+With --regex the argument is a regular expression and every matching item is restored
+(the most recent instance for each original path). When more than one item matches,
+the matches are listed and restored only after confirmation, or with --yes.
+With --stage items are restored under a staging directory instead, at their original
+path below it (e.g. /home/me/notes.txt to DIR/home/me/notes.txt), so a recovered tree
+can be inspected before its pieces are moved into place. With --timestamp and no item
+name every item of the session is staged; add --keep to leave them in the trash.
 
 Examples:
   trash restore test1.txt
   trash restore testdir
-  trash restore test1.txt --timestamp 20251217_010006`,
-	Args: cobra.ExactArgs(1),
+  trash restore test1.txt --timestamp 20251217_010006
+  trash restore test1.txt --timestamp yesterday
+  trash restore k3f9qa
+  trash restore -i readme.md
+  trash restore --regex '\.go$'
+  trash restore --regex '\.go$' --yes
+  trash restore notes.txt --here
+  trash restore notes.txt -I
+  trash restore notes.txt --keep --here
+  trash restore report.pdf --on-conflict=rename
+  trash restore --manifest restore.txt
+  trash restore --stage /tmp/recovered --timestamp 20251217_010006 --keep
+  trash restore --regex '\.go$' --stage /tmp/recovered
+
+A manifest lists one item per line, optionally as SESSION/name, optionally followed
+by a tab and the path to restore it to. Lines starting with # are ignored.`,
+	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
-		itemName := args[0]
+		manifestPath, _ := cmd.Flags().GetString("manifest")
 		specifiedTimestamp, _ := cmd.Flags().GetString("timestamp")
+		stageDir, _ := cmd.Flags().GetString("stage")
+		wholeSession := len(args) == 0 && manifestPath == "" && stageDir != "" && specifiedTimestamp != ""
+		if (manifestPath == "") == (len(args) == 0) && !wholeSession {
+			i18n.Fprintf(os.Stderr, "Error: specify either an item name or --manifest\n")
+			os.Exit(1)
+		}
 		showAll, _ := cmd.Flags().GetBool("all")
 		verbose, _ := cmd.Flags().GetBool("verbose")
-		force, _ := cmd.Flags().GetBool("force")
+		absolute, _ := cmd.Flags().GetBool("absolute")
+		format := outputFormat(cmd)
+		normalize, _ := cmd.Flags().GetBool("normalize")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		useRegex, _ := cmd.Flags().GetBool("regex")
+		here, _ := cmd.Flags().GetBool("here")
+		matcher := config.MatchOptions{Normalize: normalize, IgnoreCase: ignoreCase}
 
-		configDir, err := config.GetConfigDir()
+		noVerify, _ := cmd.Flags().GetBool("no-verify")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		keep, _ := cmd.Flags().GetBool("keep")
+		noPreflight, _ := cmd.Flags().GetBool("no-preflight")
+		onConflict, err := restoreConflict(cmd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		opts := restoreOptions{OnConflict: onConflict, Verbose: verbose, Quiet: quiet, Keep: keep, Messages: os.Stdout, NoVerify: noVerify,
+			NoPreflight: noPreflight, Summary: newTransferSummary()}
 
-		// Find all instances of the item in trash
-		type MatchedItem struct {
-			Timestamp    string
-			Item         config.RestoreItem
-			TrashDirPath string
+		// Progress messages go to stderr when stdout carries structured output
+		if format.Structured() {
+			opts.Messages = os.Stderr
 		}
+		messages := opts.Messages
 
-		var matches []MatchedItem
+		if here {
+			cwd, err := os.Getwd()
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+				os.Exit(1)
+			}
+			opts.TargetDir = cwd
+		}
+		if stageDir != "" {
+			if here {
+				i18n.Fprintf(os.Stderr, "Error: --stage cannot be combined with --here\n")
+				os.Exit(1)
+			}
+			abs, err := filepath.Abs(stageDir)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.StageDir = abs
+		}
 
-		// Read all timestamped directories
-		entries, err := os.ReadDir(configDir)
+		configDir, err := config.GetConfigDir()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Sort directories (newest first for default behavior)
-		var trashDirs []string
-		for _, entry := range entries {
-			if entry.IsDir() {
-				trashDirs = append(trashDirs, entry.Name())
+		if manifestPath != "" {
+			entries, err := parseRestoreManifest(manifestPath)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error reading manifest: %v\n", err)
+				os.Exit(1)
 			}
-		}
-		sort.Sort(sort.Reverse(sort.StringSlice(trashDirs)))
 
-		// Search for the item
-		for _, dirName := range trashDirs {
-			// If timestamp specified, only check that directory
-			if specifiedTimestamp != "" && dirName != specifiedTimestamp {
-				continue
+			resolved, problems := resolveManifest(configDir, entries, matcher)
+			if len(problems) > 0 {
+				for _, problem := range problems {
+					i18n.Fprintf(os.Stderr, "Error: %s: %v\n", manifestPath, problem)
+				}
+				i18n.Fprintf(os.Stderr, "Nothing was restored\n")
+				os.Exit(1)
 			}
 
-			dirPath := filepath.Join(configDir, dirName)
-			restoreFile := filepath.Join(dirPath, ".restore")
+			restoreAll(resolved, opts, format)
+			return
+		}
 
-			// Check if .restore file exists
-			if _, err := os.Stat(restoreFile); os.IsNotExist(err) {
-				continue
+		if wholeSession {
+			matches, err := findRestoreMatches(configDir, specifiedTimestamp, func(config.RestoreItem) bool { return true })
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+				os.Exit(1)
+			}
+			if len(matches) == 0 {
+				i18n.Fprintf(os.Stderr, "Error: no trash session matches '%s'\n", specifiedTimestamp)
+				os.Exit(exitNotFound)
 			}
+			restoreAll(latestPerOriginalPath(matches), opts, format)
+			return
+		}
+
+		itemName := args[0]
 
-			// Read and parse .restore file
-			data, err := os.ReadFile(restoreFile)
+		// Decide how item names are matched against the argument
+		matchItem := func(item config.RestoreItem) bool {
+			return matcher.Equal(item.Name, itemName)
+		}
+		if useRegex {
+			re, err := matcher.Regexp(itemName)
 			if err != nil {
-				continue
+				i18n.Fprintf(os.Stderr, "Error: invalid regular expression: %v\n", err)
+				os.Exit(1)
 			}
-
-			var metadata config.RestoreMetadata
-			if err := json.Unmarshal(data, &metadata); err != nil {
-				continue
+			matchItem = func(item config.RestoreItem) bool {
+				return matcher.MatchRegexp(re, item.Name)
 			}
+		}
 
-			// Look for matching item
-			for _, item := range metadata.Items {
-				if item.Name == itemName {
-					matches = append(matches, MatchedItem{
-						Timestamp:    dirName,
-						Item:         item,
-						TrashDirPath: dirPath,
-					})
-				}
+		// Find all instances of the item in trash
+		var matches []restoreCandidate
+		if allRoots, _ := cmd.Flags().GetBool("all-roots"); allRoots {
+			matches, err = findRestoreMatchesInRoots(specifiedTimestamp, matchItem)
+		} else {
+			matches, err = findRestoreMatches(configDir, specifiedTimestamp, matchItem)
+		}
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Without an item of that name, the argument may be an item ID
+		if len(matches) == 0 && !useRegex {
+			matchID := func(item config.RestoreItem) bool {
+				return strings.EqualFold(item.ShortID(), itemName)
+			}
+			if allRoots, _ := cmd.Flags().GetBool("all-roots"); allRoots {
+				matches, err = findRestoreMatchesInRoots(specifiedTimestamp, matchID)
+			} else {
+				matches, err = findRestoreMatches(configDir, specifiedTimestamp, matchID)
+			}
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+				os.Exit(1)
 			}
 		}
 
 		if len(matches) == 0 {
-			fmt.Fprintf(os.Stderr, "Error: item '%s' not found in trash\n", itemName)
+			i18n.Fprintf(os.Stderr, "Error: item '%s' not found in trash\n", itemName)
+			os.Exit(exitNotFound)
+		}
+
+		// The menu replaces the hint printed after --all when a user is at the terminal
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		if interactive && !isInteractive() {
+			i18n.Fprintf(os.Stderr, "Error: --interactive needs a terminal\n")
 			os.Exit(1)
 		}
+		menu := interactive || (showAll && isInteractive() && !format.Structured())
+
+		if useRegex && !showAll && !menu {
+			selected := latestPerOriginalPath(matches)
+			if yes, _ := cmd.Flags().GetBool("yes"); len(selected) > 1 && !yes {
+				// List what the expression matched before anything is put back
+				i18n.Fprintf(os.Stderr, "%d items match '%s':\n", len(selected), itemName)
+				for _, match := range selected {
+					i18n.Fprintf(os.Stderr, "  • %s (from %s) [%s]\n", match.Item.Name, formatOriginal(match.Item.OriginalPath), match.Timestamp)
+				}
+				if !isInteractive() {
+					i18n.Fprintf(os.Stderr, "Error: restoring more than one match needs --yes when stdin is not a terminal\n")
+					os.Exit(1)
+				}
+				if !confirm(i18n.Sprintf("Restore %d item(s)?", len(selected))) {
+					i18n.Fprintf(os.Stderr, "Aborted\n")
+					return
+				}
+			}
+			restoreAll(selected, opts, format)
+			return
+		}
 
 		// Handle multiple matches
-		if len(matches) > 1 {
-			if showAll {
-				fmt.Printf("Found %d instances of '%s':\n\n", len(matches), itemName)
+		if len(matches) > 1 || (useRegex && showAll) || menu {
+			if showAll && format.Structured() {
+				var entries []config.TrashedItem
+				for _, match := range matches {
+					entries = append(entries, config.TrashedItem{Session: match.Timestamp, Item: match.Item, Root: match.Root})
+				}
+				printStructured(format, newItemRecords(entries))
+				return
+			}
+
+			if showAll || menu {
+				i18n.Printf("Found %d instances of '%s':\n\n", len(matches), itemName)
 				for i, match := range matches {
-					fmt.Printf("%d. [%s]\n", i+1, match.Timestamp)
-					fmt.Printf("   Original: %s\n", match.Item.OriginalPath)
-					fmt.Printf("   Trashed:  %s\n\n", match.Item.TrashedAt)
+					i18n.Printf("%d. [%s]%s\n", i+1, match.Timestamp, formatRoot(match.Root))
+					if useRegex {
+						i18n.Printf("   Name:     %s\n", match.Item.Name)
+					}
+					i18n.Printf("   Original: %s\n", formatOriginal(match.Item.OriginalPath))
+					i18n.Printf("   Trashed:  %s\n\n", formatTimestamp(match.Item.TrashedAt, absolute))
 				}
-				fmt.Println("Use --timestamp flag to specify which one to restore")
-				fmt.Printf("Example: trash restore %s --timestamp %s\n", itemName, matches[0].Timestamp)
+				if menu {
+					restoreMenu(matches, opts)
+					return
+				}
+				i18n.Printf("Use --timestamp flag to specify which one to restore\n")
+				i18n.Printf("Example: trash restore %s --timestamp %s\n", matches[0].Item.Name, matches[0].Timestamp)
 				return
 			}
 
 			if specifiedTimestamp == "" {
-				fmt.Printf("Found %d instances of '%s'. Restoring the most recent one.\n", len(matches), itemName)
-				fmt.Printf("Use --all to see all matches or --timestamp to specify which one.\n\n")
+				i18n.Fprintf(messages, "Found %d instances of '%s'. Restoring the most recent one.\n", len(matches), itemName)
+				i18n.Fprintf(messages, "Use --all to see all matches or --timestamp to specify which one.\n\n")
 			}
 		}
 
 		// Restore the first match (most recent if not specified)
 		match := matches[0]
-		timestamp := match.Timestamp
-		trashDir := match.TrashDirPath
-		itemToRestore := match.Item
-
-		// Source and destination paths
-		sourcePath := filepath.Join(trashDir, itemName)
-		destPath := itemToRestore.OriginalPath
-
-		// Check if destination already exists
-		if _, err := os.Stat(destPath); err == nil {
-			if !force {
-				fmt.Fprintf(os.Stderr, "Error: destination already exists: %s\n", destPath)
-				fmt.Fprintf(os.Stderr, "Use --force to overwrite\n")
-				os.Exit(1)
+		destPath, err := restoreMatch(match, opts)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			if errors.Is(err, config.ErrDestinationExists) {
+				i18n.Fprintf(os.Stderr, "Use --force to overwrite, or --on-conflict=rename to keep both\n")
 			}
-			if verbose {
-				fmt.Printf("Overwriting existing file/directory: %s\n", destPath)
+			if errors.Is(err, config.ErrTampered) {
+				i18n.Fprintf(os.Stderr, "Check the session's .restore file, or use --no-verify to restore anyway\n")
 			}
-			// Remove existing destination
-			if err := os.RemoveAll(destPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error removing existing destination: %v\n", err)
-				os.Exit(1)
+			if errors.Is(err, config.ErrUnknownOrigin) {
+				i18n.Fprintf(os.Stderr, "Use --here to restore it into the current directory\n")
 			}
+			os.Exit(exitCode(err))
 		}
+		bus.EmitChanged(bus.ReasonRestored)
 
-		// Ensure parent directory exists
-		parentDir := filepath.Dir(destPath)
-		if err := os.MkdirAll(parentDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating parent directory: %v\n", err)
-			os.Exit(1)
+		if format.Structured() {
+			printStructured(format, newRestoreRecord(match, destPath))
+		} else if !quiet {
+			i18n.Printf("Successfully restored: %s\n", destPath)
+		}
+		if !quiet {
+			opts.Summary.print(messages)
 		}
+	},
+}
 
-		// Try to move using rename first
-		err = os.Rename(sourcePath, destPath)
-		if err == nil {
-			if verbose {
-				fmt.Printf("Restored: %s -> %s\n", itemName, destPath)
-			}
-		} else {
-			// Fallback to copy and delete for cross-device
-			sourceInfo, err := os.Stat(sourcePath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error accessing source: %v\n", err)
-				os.Exit(1)
-			}
+// restoreOptions controls how matched items are put back
+type restoreOptions struct {
+	OnConflict  config.Conflict // what to do with existing destinations
+	Verbose     bool            // report each step
+	TargetDir   string          // restore into this directory instead of the original location
+	StageDir    string          // restore at the original path below this directory
+	Messages    io.Writer       // destination for progress messages
+	NoVerify    bool            // skip the metadata signature check
+	Quiet       bool            // only report failures
+	Keep        bool            // restore a copy and leave the item in the trash
+	NoPreflight bool            // skip the upfront checks of a batch restore
+	Summary     *transferSummary
+}
 
-			if sourceInfo.IsDir() {
-				if err := config.CopyDir(sourcePath, destPath); err != nil {
-					fmt.Fprintf(os.Stderr, "Error copying directory: %v\n", err)
-					os.Exit(1)
-				}
-			} else {
-				if err := config.CopyFile(sourcePath, destPath); err != nil {
-					fmt.Fprintf(os.Stderr, "Error copying file: %v\n", err)
-					os.Exit(1)
-				}
-			}
+// destination returns where a matched item will be restored to
+func (o restoreOptions) destination(match restoreCandidate) string {
+	if match.Target != "" {
+		return match.Target
+	}
+	if o.TargetDir != "" {
+		return filepath.Join(o.TargetDir, match.Item.Name)
+	}
+	if o.StageDir != "" {
+		return stagedPath(o.StageDir, match.Item)
+	}
+	return match.Item.OriginalPath
+}
 
-			// Remove from trash after successful copy
-			if err := os.RemoveAll(sourcePath); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to remove from trash: %v\n", err)
-			}
+// stagedPath returns where item is restored to below the staging directory
+// stageDir: its original path, with the volume name of Windows paths turned
+// into a directory (C:\Users to C\Users). Items whose original location is
+// unknown are staged directly below it.
+func stagedPath(stageDir string, item config.RestoreItem) string {
+	if item.OriginalPath == "" {
+		return filepath.Join(stageDir, item.Name)
+	}
+	volume := filepath.VolumeName(item.OriginalPath)
+	rest := item.OriginalPath[len(volume):]
+	volume = strings.Trim(strings.ReplaceAll(volume, ":", ""), `\/`)
+	return filepath.Join(stageDir, volume, rest)
+}
 
-			if verbose {
-				fmt.Printf("Restored (copied): %s -> %s\n", itemName, destPath)
-			}
+// restoreCandidate is a trashed item matched by restore, with the session it lives in
+type restoreCandidate struct {
+	Timestamp    string
+	Item         config.RestoreItem
+	TrashDirPath string
+	Target       string      // explicit destination, e.g. from a manifest
+	Root         config.Root // the trash root it was found in, with --all-roots
+}
+
+// findRestoreMatches returns the items accepted by matchItem, newest session first
+// When timestamp is set only the sessions it refers to are searched (see
+// config.SessionMatcher)
+func findRestoreMatches(configDir, timestamp string, matchItem func(config.RestoreItem) bool) ([]restoreCandidate, error) {
+	// Read all timestamped directories
+	entries, err := config.StoreFS().ReadDir(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read each session's metadata once, to order the sessions and search them
+	var trashDirs []string
+	loaded := make(map[string]*config.RestoreMetadata)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		trashDirs = append(trashDirs, entry.Name())
+		if metadata, err := config.LoadRestoreMetadata(filepath.Join(configDir, entry.Name())); err == nil {
+			loaded[entry.Name()] = metadata
 		}
+	}
 
-		// Update metadata to remove restored item
-		restoreFile := filepath.Join(trashDir, ".restore")
-		data, _ := os.ReadFile(restoreFile)
-		var metadata config.RestoreMetadata
-		json.Unmarshal(data, &metadata)
+	// Sort directories (newest first for default behavior)
+	config.SortLoadedSessions(trashDirs, loaded)
+	for i, j := 0, len(trashDirs)-1; i < j; i, j = i+1, j-1 {
+		trashDirs[i], trashDirs[j] = trashDirs[j], trashDirs[i]
+	}
+
+	inSession := config.SessionMatcher(timestamp, time.Now())
+	var matches []restoreCandidate
+	for _, dirName := range trashDirs {
+		// If timestamp specified, only check the directories it names
+		if timestamp != "" && !inSession(dirName) {
+			continue
+		}
 
-		var updatedItems []config.RestoreItem
+		dirPath := filepath.Join(configDir, dirName)
+
+		metadata, ok := loaded[dirName]
+		if !ok {
+			continue
+		}
+
+		// Look for matching items
 		for _, item := range metadata.Items {
-			if item.Name != itemName {
-				updatedItems = append(updatedItems, item)
+			if matchItem(item) {
+				matches = append(matches, restoreCandidate{
+					Timestamp:    dirName,
+					Item:         item,
+					TrashDirPath: dirPath,
+				})
 			}
 		}
+	}
 
-		if len(updatedItems) == 0 {
-			// No items left, remove the entire trash directory
-			if err := os.RemoveAll(trashDir); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to remove empty trash directory: %v\n", err)
+	return matches, nil
+}
+
+// findRestoreMatchesInRoots searches every trash root, newest session first across roots
+func findRestoreMatchesInRoots(timestamp string, matchItem func(config.RestoreItem) bool) ([]restoreCandidate, error) {
+	roots, err := config.Roots()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []restoreCandidate
+	for _, root := range roots {
+		rootMatches, err := findRestoreMatches(root.Dir, timestamp, matchItem)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", root.Dir, err)
+			continue
+		}
+		for _, match := range rootMatches {
+			match.Root = root
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		ti, _ := config.SessionTime(matches[i].Timestamp)
+		tj, _ := config.SessionTime(matches[j].Timestamp)
+		return ti.After(tj)
+	})
+
+	return matches, nil
+}
+
+// latestPerOriginalPath keeps only the most recent match for each original path
+// matches must be ordered newest first. Adopted items without an original path
+// are all kept.
+func latestPerOriginalPath(matches []restoreCandidate) []restoreCandidate {
+	seen := make(map[string]bool)
+	var latest []restoreCandidate
+	for _, match := range matches {
+		if seen[match.Item.OriginalPath] {
+			continue
+		}
+		if match.Item.OriginalPath != "" {
+			seen[match.Item.OriginalPath] = true
+		}
+		latest = append(latest, match)
+	}
+	return latest
+}
+
+// restoreAll restores every match, reporting each result, and exits non-zero if any failed
+func restoreAll(matches []restoreCandidate, opts restoreOptions, format output.Format) {
+	var records []restoreRecord
+	failed := 0
+	failureCode := exitError
+
+	// Report everything that would fail partway before restoring anything
+	if !opts.NoPreflight {
+		plans := make([]config.RestorePlan, 0, len(matches))
+		for _, match := range matches {
+			plans = append(plans, config.RestorePlan{
+				Source:   match.Item.PayloadPath(match.TrashDirPath),
+				Dest:     opts.destination(match),
+				Force:    opts.OnConflict != config.ConflictFail,
+				Keep:     opts.Keep,
+				Archived: config.IsArchived(match.TrashDirPath, match.Item),
+				Size:     match.Item.PayloadSize(match.TrashDirPath),
+			})
+		}
+		if problems := config.CheckRestore(plans); len(problems) > 0 {
+			for _, problem := range problems {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", problem)
 			}
-			if verbose {
-				fmt.Printf("Removed empty trash directory: %s\n", timestamp)
+			i18n.Fprintf(os.Stderr, "Nothing was restored; fix the problems above or use --no-preflight\n")
+			os.Exit(exitCode(problems[0]))
+		}
+	}
+
+	for _, match := range matches {
+		destPath, err := restoreMatch(match, opts)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error restoring %s: %v\n", match.Item.Name, err)
+			if failed == 0 {
+				failureCode = exitCode(err)
 			}
+			failed++
+			continue
+		}
+		records = append(records, newRestoreRecord(match, destPath))
+		if !format.Structured() && !opts.Verbose && !opts.Quiet {
+			i18n.Printf("Restored: %s\n", destPath)
+		}
+	}
+
+	if len(records) > 0 {
+		bus.EmitChanged(bus.ReasonRestored)
+	}
+
+	if format.Structured() {
+		printStructured(format, records)
+	} else if len(records) > 0 && !opts.Quiet {
+		i18n.Printf("Successfully restored %d item(s)\n", len(records))
+	}
+	if !opts.Quiet {
+		opts.Summary.print(opts.Messages)
+	}
+
+	if failed > 0 {
+		i18n.Fprintf(os.Stderr, "Failed to restore %d item(s)\n", failed)
+		os.Exit(failureCode)
+	}
+}
+
+// restoreMatch moves a trashed item back to its destination and removes it from
+// the session metadata, returning where it was restored to
+func restoreMatch(match restoreCandidate, opts restoreOptions) (string, error) {
+	destPath := opts.destination(match)
+
+	// Metadata edited outside trash could point the restore anywhere
+	if !opts.NoVerify {
+		if err := config.VerifyRestoreMetadata(match.TrashDirPath); err != nil {
+			return "", err
+		}
+	}
+
+	restore := config.RestoreTrashedItem
+	if opts.Keep {
+		restore = config.CopyTrashedItem
+	}
+	result, err := restore(match.TrashDirPath, match.Item, destPath, opts.OnConflict)
+	if err != nil {
+		return "", err
+	}
+	destPath = result.Destination
+
+	for _, warning := range result.Warnings {
+		i18n.Fprintf(os.Stderr, "Warning: %v\n", warning)
+	}
+	if opts.Summary != nil {
+		opts.Summary.add(result.Bytes, result.Copied)
+	}
+
+	if opts.Verbose {
+		if result.Overwrote {
+			i18n.Fprintf(opts.Messages, "Overwrote existing file/directory: %s\n", destPath)
+		}
+		if result.Copied {
+			i18n.Fprintf(opts.Messages, "Restored (copied): %s -> %s\n", match.Item.Name, destPath)
 		} else {
-			// Update .restore file with remaining items
-			metadata.Items = updatedItems
-			if err := config.SaveRestoreMetadata(trashDir, &metadata); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to update metadata: %v\n", err)
-			}
+			i18n.Fprintf(opts.Messages, "Restored: %s -> %s\n", match.Item.Name, destPath)
+		}
+		if result.SessionRemoved {
+			i18n.Fprintf(opts.Messages, "Removed empty trash directory: %s\n", match.Timestamp)
 		}
+	}
 
-		fmt.Printf("Successfully restored: %s\n", destPath)
-	},
+	return destPath, nil
+}
+
+// restoreRecord is the structured (--output) result of a restore
+type restoreRecord struct {
+	Session      string `json:"session" yaml:"session"`
+	Name         string `json:"name" yaml:"name"`
+	OriginalPath string `json:"original_path" yaml:"original_path"`
+	RestoredTo   string `json:"restored_to" yaml:"restored_to"`
+}
+
+// newRestoreRecord describes a completed restore to destPath
+func newRestoreRecord(match restoreCandidate, destPath string) restoreRecord {
+	return restoreRecord{
+		Session:      match.Timestamp,
+		Name:         match.Item.Name,
+		OriginalPath: match.Item.OriginalPath,
+		RestoredTo:   destPath,
+	}
+}
+
+// restoreConflict returns the --on-conflict strategy; --force stands for overwrite
+func restoreConflict(cmd *cobra.Command) (config.Conflict, error) {
+	value, _ := cmd.Flags().GetString("on-conflict")
+	onConflict, err := config.ParseConflict(value)
+	if err != nil {
+		return onConflict, err
+	}
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		if cmd.Flags().Changed("on-conflict") && onConflict != config.ConflictOverwrite {
+			return onConflict, fmt.Errorf("--force cannot be combined with --on-conflict=%s", value)
+		}
+		onConflict = config.ConflictOverwrite
+	}
+	return onConflict, nil
 }
 
 func init() {
 	rootCmd.AddCommand(restoreCmd)
-	restoreCmd.Flags().BoolP("force", "f", false, "Overwrite destination if it exists")
-	restoreCmd.Flags().String("timestamp", "", "Specify which timestamp to restore from")
+	restoreCmd.Flags().BoolP("force", "f", false, "Overwrite destination if it exists (same as --on-conflict=overwrite)")
+	restoreCmd.Flags().String("on-conflict", "fail", "What to do when the destination exists: fail, overwrite or rename (keep both as \"name (restored).ext\")")
+	restoreCmd.Flags().String("timestamp", "", "Specify which session to restore from: a prefix such as 20251217_010006, or when it was trashed (e.g. yesterday, 2h, last week, 2025-01-15)")
+	restoreCmd.RegisterFlagCompletionFunc("timestamp", completeRestoreTimestamp)
+	restoreCmd.Flags().BoolP("interactive", "I", false, "Choose matches from a numbered menu and restore, restore here, purge or skip each")
 	restoreCmd.Flags().Bool("all", false, "Show all matches without restoring")
+	restoreCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+	restoreCmd.Flags().Bool("normalize", true, "Match names regardless of Unicode normalization form (NFC/NFD)")
+	restoreCmd.Flags().BoolP("ignore-case", "i", false, "Match names case-insensitively")
+	restoreCmd.Flags().Bool("regex", false, "Treat the argument as a regular expression and restore every match")
+	restoreCmd.Flags().BoolP("yes", "y", false, "With --regex, restore several matches without asking")
+	restoreCmd.Flags().Bool("here", false, "Restore into the current directory instead of the original location")
+	restoreCmd.Flags().String("stage", "", "Restore below this directory at the original paths, for inspection; with --timestamp and no item name, the whole session")
+	restoreCmd.Flags().Bool("all-roots", false, "Search the home trash, the project's local trash and the configured roots together")
+	restoreCmd.Flags().Bool("keep", false, "Restore a copy and keep the item in the trash")
+	restoreCmd.Flags().Bool("no-preflight", false, "Start a batch restore without first checking destinations, permissions and free space")
+	restoreCmd.Flags().Bool("no-verify", false, "Restore even if the session metadata does not match its signature (see sign_metadata)")
+	restoreCmd.Flags().String("manifest", "", "Restore every item listed in this manifest file")
 }
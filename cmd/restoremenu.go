@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// restoreMenu lets the user pick listed matches by number and, for each, restore
+// it to its original location, restore it into the current directory, purge it
+// or skip it. It returns when the user enters nothing.
+func restoreMenu(matches []restoreCandidate, opts restoreOptions) {
+	// Each choice is reported as it is made; a summary timed over the user's
+	// thinking would be meaningless
+	opts.Summary = nil
+
+	reader := bufio.NewReader(os.Stdin)
+	handled := make([]bool, len(matches))
+	restored, purged := 0, 0
+
+	for {
+		choice, ok := ask(reader, i18n.Sprintf("Choose an item by number (empty to finish): "))
+		if !ok || choice == "" {
+			break
+		}
+
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(matches) {
+			i18n.Fprintf(os.Stderr, "Invalid choice: %s\n", choice)
+			continue
+		}
+		if handled[n-1] {
+			i18n.Fprintf(os.Stderr, "Item %d was already handled\n", n)
+			continue
+		}
+		match := matches[n-1]
+
+		action, _ := ask(reader, i18n.Sprintf("%d. %s: [o]riginal location, [h]ere, [p]urge or [s]kip? ", n, match.Item.Name))
+		switch strings.ToLower(action) {
+		case "o", "original":
+			target := opts
+			target.TargetDir = ""
+			if restoreFromMenu(match, target) {
+				handled[n-1] = true
+				restored++
+			}
+		case "h", "here":
+			cwd, err := os.Getwd()
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+				continue
+			}
+			target := opts
+			target.TargetDir = cwd
+			if restoreFromMenu(match, target) {
+				handled[n-1] = true
+				restored++
+			}
+		case "p", "purge":
+			answer, _ := ask(reader, i18n.Sprintf("%s [y/N]: ", i18n.Sprintf("Permanently delete %s?", match.Item.Name)))
+			if !i18n.IsYes(answer) {
+				continue
+			}
+			item := match.Item
+			location := &config.TrashedLocation{StoreDir: filepath.Dir(match.TrashDirPath), Session: match.Timestamp, Item: &item}
+			if err := config.PurgeTrashedItem(location); err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			i18n.Printf("Purged: %s\n", match.Item.Name)
+			handled[n-1] = true
+			purged++
+		default:
+			i18n.Printf("Skipped: %s\n", match.Item.Name)
+		}
+	}
+
+	if restored > 0 {
+		bus.EmitChanged(bus.ReasonRestored)
+	}
+	if purged > 0 {
+		bus.EmitChanged(bus.ReasonPurged)
+	}
+}
+
+// restoreFromMenu restores one match chosen in the menu, reporting the outcome
+func restoreFromMenu(match restoreCandidate, opts restoreOptions) bool {
+	destPath, err := restoreMatch(match, opts)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error restoring %s: %v\n", match.Item.Name, err)
+		return false
+	}
+	i18n.Printf("Restored: %s\n", destPath)
+	return true
+}
+
+// ask prints prompt to stderr and reads one line of input, reporting false at
+// the end of input
+func ask(reader *bufio.Reader, prompt string) (string, bool) {
+	os.Stderr.WriteString(prompt)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var retainCmd = &cobra.Command{
+	Use:   "retain <item> <duration|none>",
+	Short: "Keep a trashed item for a given time whatever the retention policies say",
+	Long: `Set how long a trashed item is kept, counted from now, overriding the retention
+policies: min_free pruning, 'trash prune' and 'trash empty' leave it alone until
+then, and it is purged once the time has passed, like an item trashed with
+--keep-for. "none" removes the override and any expiry.
+
+The item is given like for 'trash path': by name, as session/name or by its
+original path, and the most recently trashed match is changed.
+
+Examples:
+  trash retain important.db 90d
+  trash retain 20251217_010006/notes.txt 2w
+  trash retain important.db none`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		expiresAt := ""
+		if args[1] != "none" {
+			keepFor, err := config.ParseDuration(args[1])
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			expiresAt = time.Now().Add(keepFor).Format(time.RFC3339)
+		}
+
+		matches := findItems(args[0])
+		entry := matches[len(matches)-1]
+
+		item, err := config.RetainItem(entry.Session, entry.Item, expiresAt)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		entry.Item = item
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, []itemRecord{newItemRecord(entry)})
+			return
+		}
+		if expiresAt == "" {
+			i18n.Printf("%s is no longer retained\n", entry.Item.Name)
+			return
+		}
+		i18n.Printf("Keeping %s until %s\n", entry.Item.Name, expiresAt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(retainCmd)
+}
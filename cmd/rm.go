@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm [file/directory paths...]",
+	Short: "Permanently delete files without moving them to trash",
+	Long: `Permanently delete files and directories, skipping the trash entirely.
+This is the escape hatch for huge temporary data when rm is aliased to trash.
+You are asked for confirmation unless --force is given.
+
+Examples:
+  trash rm big-build-cache/
+  trash rm --force /tmp/scratch.iso
+  trash --permanently old.tar.gz`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		force, _ := cmd.Flags().GetBool("force")
+		permanentlyDelete(args, force, verbose)
+	},
+}
+
+// permanentlyDelete removes paths without moving them to trash, asking first unless force is set
+func permanentlyDelete(paths []string, force, verbose bool) {
+	// Check everything exists and report the total size before asking
+	var total uint64
+	var existing []string
+	failed := 0
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err != nil {
+			i18n.Fprintf(os.Stderr, "Error: path does not exist: %s\n", path)
+			failed++
+			continue
+		}
+		size, _ := config.PathSize(path)
+		total += size
+		existing = append(existing, path)
+	}
+
+	if len(existing) > 0 {
+		question := i18n.Sprintf("Permanently delete %d item(s) (%s)? This cannot be undone.", len(existing), config.FormatSize(total))
+		if !force && !confirm(question) {
+			i18n.Fprintf(os.Stderr, "Aborted\n")
+			os.Exit(1)
+		}
+	}
+
+	deleted := 0
+	for _, path := range existing {
+		if err := os.RemoveAll(path); err != nil {
+			i18n.Fprintf(os.Stderr, "Error deleting %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		deleted++
+		if verbose {
+			i18n.Printf("Deleted: %s\n", path)
+		}
+	}
+
+	if deleted > 0 {
+		i18n.Printf("Permanently deleted %d item(s)\n", deleted)
+	}
+
+	if failed > 0 {
+		i18n.Fprintf(os.Stderr, "Failed to delete %d item(s)\n", failed)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+	rmCmd.Flags().BoolP("force", "f", false, "Do not ask for confirmation")
+}
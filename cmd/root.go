@@ -1,113 +1,440 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
 	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "trash [file/directory paths...]",
 	Short: "Move files or directories to trash",
 	Long: `Trash is a CLI application that moves files and directories to a trash directory.
-Files are moved to ~/.config/trash in timestamped subdirectories.
+Files are moved to $XDG_DATA_HOME/trash (~/.local/share/trash; %LOCALAPPDATA%\trash
+on Windows) in timestamped subdirectories.
 
 When called without arguments, shows a welcome message.
 When called with file/directory paths, moves them to trash.
 
-Use subcommands for additional functionality like version info.`,
+Use subcommands for additional functionality like version info.
+
+Aliases from the [aliases] table of config.toml, e.g. purge = "empty --expired",
+are expanded first. Any trash-<name> executable on PATH can be run as
+"trash <name>", unless a file named <name> exists in the current directory. It
+gets the trash directory in TRASH_DIR, the trash executable in TRASH_BIN and its
+version in TRASH_VERSION.`,
 	Args:                  cobra.ArbitraryArgs,
 	DisableFlagParsing:    false,
 	FParseErrWhitelist:    cobra.FParseErrWhitelist{UnknownFlags: true},
+	PersistentPreRun:      setup,
 	Run: func(cmd *cobra.Command, args []string) {
 		// If no arguments provided, show welcome message
 		if len(args) == 0 {
-			fmt.Println("Welcome to Trash! Use --help to see available commands.")
-			fmt.Println("Usage: trash [file/directory paths...] to move items to trash")
+			i18n.Printf("Welcome to Trash! Use --help to see available commands.\n")
+			i18n.Printf("Usage: trash [file/directory paths...] to move items to trash\n")
 			return
 		}
 
 		// Handle trash operation
 		verbose, _ := cmd.Flags().GetBool("verbose")
-		
-		// Create a timestamped directory for this trash operation
-		trashDir, err := config.CreateTrashTimestampDir()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating trash directory: %v\n", err)
+
+		// Skip the trash entirely when asked to
+		if permanently, _ := cmd.Flags().GetBool("permanently"); permanently {
+			permanentlyDelete(args, false, verbose)
+			return
+		}
+
+		expire, _ := cmd.Flags().GetString("expire")
+		keepFor, _ := cmd.Flags().GetString("keep-for")
+		if expire != "" && keepFor != "" {
+			i18n.Fprintf(os.Stderr, "Error: --expire and --keep-for cannot be used together\n")
 			os.Exit(1)
 		}
 
-		if verbose {
-			fmt.Printf("Created trash directory: %s\n", trashDir)
+		// Work out the expiry before touching anything
+		expiresAt := ""
+		if expire != "" {
+			expireAfter, err := config.ParseDuration(expire)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: invalid --expire value: %v\n", err)
+				os.Exit(1)
+			}
+			expiresAt = time.Now().Add(expireAfter).Format(time.RFC3339)
+		}
+		if keepFor != "" {
+			keepAfter, err := config.ParseDuration(keepFor)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: invalid --keep-for value: %v\n", err)
+				os.Exit(1)
+			}
+			expiresAt = time.Now().Add(keepAfter).Format(time.RFC3339)
+		}
+
+		// With --dereference, symlink arguments stand for the files they point to
+		if dereferenceEnabled(cmd) {
+			args = dereferenceArgs(args, verbose)
 		}
 
-		// Track success and failures
-		successCount := 0
-		failedPaths := []string{}
-		
-		// Prepare restore metadata
-		metadata := &config.RestoreMetadata{
-			Items: []config.RestoreItem{},
+		// Arguments already in a trash directory are purged or re-filed instead of nested
+		args, skipped := handleTrashedArgs(args, verbose)
+		if skipped {
+			// The rest is still trashed, but scripts must see that not everything was
+			defer os.Exit(exitError)
+		}
+		if len(args) == 0 {
+			return
 		}
 
-		// Move each specified path to trash
-		for _, path := range args {
-			// Get absolute path for metadata
-			absPath, err := os.Getwd()
-			if err == nil {
-				absPath, _ = filepath.Abs(path)
-			} else {
-				absPath = path
+		// Only trash the arguments meeting --older-than and --larger-than
+		if filter := parseTrashFilter(cmd); filter.active() {
+			args = filter.apply(args, time.Now(), verbose)
+			if len(args) == 0 {
+				i18n.Printf("Nothing to trash\n")
+				return
 			}
-			
-			baseName, err := config.MoveToTrash(path, trashDir)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				failedPaths = append(failedPaths, path)
-			} else {
-				successCount++
-				if verbose {
-					fmt.Printf("Moved to trash: %s\n", path)
+		}
+
+		// Leave alone whatever the user declared off-limits
+		if noIgnore, _ := cmd.Flags().GetBool("no-ignore"); !noIgnore {
+			respectGitignore, _ := cmd.Flags().GetBool("respect-gitignore")
+			args = skipProtected(args, respectGitignore)
+			if len(args) == 0 {
+				i18n.Printf("Nothing to trash\n")
+				return
+			}
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			for _, path := range args {
+				if _, err := os.Lstat(path); err == nil {
+					i18n.Printf("Would trash: %s\n", path)
 				}
-				
-				// Add to metadata
-				metadata.Items = append(metadata.Items, config.RestoreItem{
-					Name:         baseName,
-					OriginalPath: absPath,
-					TrashedAt:    time.Now().Format(time.RFC3339),
-				})
 			}
+			printEstimate(args)
+			return
+		}
+
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		opts := trashOptions(cmd, expiresAt)
+		opts.Retained = keepFor != ""
+		trashPaths(args, opts, verbose, quiet, trashedPrintMode(cmd))
+	},
+}
+
+// trashOptions combines the expiry with the session window from --session-window
+// or, when the flag is not given, the session_window setting, and likewise
+// --snapshot and the snapshots setting
+func trashOptions(cmd *cobra.Command, expiresAt string) config.TrashOptions {
+	opts := config.TrashOptions{ExpiresAt: expiresAt}
+	opts.AllowMounts, _ = cmd.Flags().GetBool("allow-mounts")
+	opts.NoIgnore, _ = cmd.Flags().GetBool("no-ignore")
+	opts.SkipPreflight, _ = cmd.Flags().GetBool("no-preflight")
+
+	opts.Snapshot, _ = cmd.Flags().GetBool("snapshot")
+	if !cmd.Flags().Changed("snapshot") {
+		if settings, err := config.LoadSettings(); err == nil {
+			opts.Snapshot = settings.Snapshots
 		}
+	}
 
-		// Save restore metadata
-		if len(metadata.Items) > 0 {
-			if err := config.SaveRestoreMetadata(trashDir, metadata); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to save restore metadata: %v\n", err)
+	value, _ := cmd.Flags().GetString("session-window")
+	if !cmd.Flags().Changed("session-window") {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return opts
+		}
+		value = settings.SessionWindow
+	}
+
+	window, err := config.ParseSessionWindow(value)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Window = window
+
+	return opts
+}
+
+// printMode selects the records written on stdout for the trashed items
+type printMode int
+
+const (
+	printNone  printMode = iota
+	printLines           // --print: original and in-trash path, tab-separated, one item per line
+	printNul             // --print0: original and in-trash path, each terminated by a NUL
+)
+
+// trashedPrintMode returns the print mode asked for with --print or --print0
+func trashedPrintMode(cmd *cobra.Command) printMode {
+	print, _ := cmd.Flags().GetBool("print")
+	print0, _ := cmd.Flags().GetBool("print0")
+	switch {
+	case print && print0:
+		i18n.Fprintf(os.Stderr, "Error: --print and --print0 cannot be used together\n")
+		os.Exit(1)
+	case print0:
+		return printNul
+	case print:
+		return printLines
+	}
+	return printNone
+}
+
+// printTrashed writes the original and in-trash path of every trashed item in
+// the session trashDir to stdout, in the given print mode
+func printTrashed(trashDir string, results []config.TrashResult, mode printMode) {
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		trashed := result.Item.PayloadPath(trashDir)
+		switch mode {
+		case printLines:
+			fmt.Printf("%s\t%s\n", result.Item.OriginalPath, trashed)
+		case printNul:
+			fmt.Printf("%s\x00%s\x00", result.Item.OriginalPath, trashed)
+		}
+	}
+}
+
+// trashPaths moves paths into a new trash session, reporting progress and, unless
+// quiet, a summary; it exits with the first failure's code when any path could
+// not be trashed. With a print mode other than printNone, stdout only gets the
+// records of the trashed items.
+func trashPaths(paths []string, opts config.TrashOptions, verbose, quiet bool, mode printMode) {
+	if mode != printNone {
+		quiet = true
+	}
+
+	selectDriveStore(paths)
+	warnSharedStorage(paths)
+
+	// Track success and failures
+	successCount := 0
+	failedPaths := []string{}
+	failureCode := exitError
+	summary := newTransferSummary()
+
+	// Move each specified path to trash
+	trashDir, results, err := config.Trash(paths, opts, func(result config.TrashResult) {
+		if result.Err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", result.Err)
+			if errors.Is(result.Err, config.ErrTrashStore) {
+				i18n.Fprintf(os.Stderr, "Use 'trash empty' to permanently delete what is in the trash\n")
+			}
+			if errors.Is(result.Err, config.ErrMountPoint) {
+				i18n.Fprintf(os.Stderr, "Unmount it first, or use --allow-mounts to trash it anyway\n")
+			}
+			if len(failedPaths) == 0 {
+				failureCode = exitCode(result.Err)
 			}
+			failedPaths = append(failedPaths, result.Path)
+			return
+		}
+		successCount++
+		if result.Item.Snapshot != nil {
+			summary.addSnapshot(result.Bytes)
+		} else {
+			summary.add(result.Bytes, result.Copied)
+		}
+		if verbose && !quiet {
+			i18n.Printf("Moved to trash: %s\n", result.Path)
+		}
+	})
+	// Everything that would fail partway was reported before moving anything
+	var preflight *config.PreflightError
+	if errors.As(err, &preflight) {
+		for _, problem := range preflight.Problems {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", problem)
+		}
+		i18n.Fprintf(os.Stderr, "Nothing was trashed; fix the problems above or use --no-preflight\n")
+		os.Exit(exitCode(preflight.Problems[0]))
+	}
+	if trashDir == "" {
+		i18n.Fprintf(os.Stderr, "Error creating trash directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Warning: failed to save restore metadata: %v\n", err)
+	}
+	if verbose && !quiet {
+		i18n.Printf("Trash directory: %s\n", trashDir)
+	}
+	printTrashed(trashDir, results, mode)
+
+	// Purge expired items and enforce the free-space policy, never touching the session just created
+	autoPrune(filepath.Base(trashDir), verbose)
+
+	// Let desktop trash indicators refresh
+	if successCount > 0 {
+		bus.EmitChanged(bus.ReasonTrashed)
+	}
+
+	// Summary
+	if successCount > 0 && !quiet {
+		i18n.Printf("Successfully moved %d item(s) to trash\n", successCount)
+		summary.print(os.Stdout)
+	}
+	
+	if len(failedPaths) > 0 {
+		i18n.Fprintf(os.Stderr, "Failed to trash %d item(s)\n", len(failedPaths))
+		os.Exit(failureCode)
+	}
+}
+
+
+// setup runs before every command: it selects the trash directory and applies
+// the settings that depend on it
+func setup(cmd *cobra.Command, args []string) {
+	applyCommandDefaults(cmd)
+	selectStore(cmd, args)
+	selectRemoteStore(cmd)
+	applyBandwidthLimit(cmd)
+	applyPurgeRate(cmd)
+	applyCopyRetries(cmd)
+	applyPassphrase(cmd)
+
+	if nice, _ := cmd.Flags().GetBool("nice"); nice {
+		if err := config.LowerPriority(); err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
 		}
+	}
+}
 
-		// Summary
-		if successCount > 0 {
-			fmt.Printf("Successfully moved %d item(s) to trash\n", successCount)
+// applyBandwidthLimit limits cross-device copies to --bwlimit or, when the flag
+// is not given, the bwlimit setting
+func applyBandwidthLimit(cmd *cobra.Command) {
+	value, _ := cmd.Flags().GetString("bwlimit")
+	if !cmd.Flags().Changed("bwlimit") {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return
 		}
-		
-		if len(failedPaths) > 0 {
-			fmt.Fprintf(os.Stderr, "Failed to trash %d item(s)\n", len(failedPaths))
-			os.Exit(1)
+		value = settings.BWLimit
+	}
+	if value == "" {
+		return
+	}
+
+	limit, err := config.ParseSize(value)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "Error: invalid bandwidth limit: %v\n", err)
+		os.Exit(1)
+	}
+	config.SetBandwidthLimit(limit)
+}
+
+// applyPurgeRate throttles purges to --purge-rate or, when the flag is not
+// given, the purge_rate setting
+func applyPurgeRate(cmd *cobra.Command) {
+	rate, _ := cmd.Flags().GetUint64("purge-rate")
+	if !cmd.Flags().Changed("purge-rate") {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return
 		}
-	},
+		rate = settings.PurgeRate
+	}
+	config.SetPurgeRate(rate)
+}
+
+// applyCopyRetries sets how often copies retry after a transient I/O error to
+// --retries or, when the flag is not given, the copy_retries setting
+func applyCopyRetries(cmd *cobra.Command) {
+	retries, _ := cmd.Flags().GetUint64("retries")
+	if !cmd.Flags().Changed("retries") {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return
+		}
+		retries = settings.Retries()
+	}
+	config.SetCopyRetries(retries)
+}
+
+// selectStore switches to a project's local .trash directory when --local is given
+// or a .trashrc marks the project, unless --global is given
+func selectStore(cmd *cobra.Command, args []string) {
+	local, _ := cmd.Flags().GetBool("local")
+	global, _ := cmd.Flags().GetBool("global")
+	if global {
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+
+	root, marked := config.FindMarkedProject(cwd)
+	if !marked {
+		if !local {
+			return
+		}
+		root = config.FindProjectRoot(cwd)
+	}
+
+	config.UseStoreDir(config.LocalStoreDir(root))
+	if err := config.EnsureConfigDir(); err != nil {
+		i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// selectRemoteStore moves the store to the location of the store setting. The
+// config commands keep working without it, so a broken setting can be fixed,
+// and doctor falls back to the local trash directory to report it.
+func selectRemoteStore(cmd *cobra.Command) {
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if parent == configCmd {
+			return
+		}
+	}
+
+	if err := config.UseConfiguredStore(); err != nil {
+		if cmd == doctorCmd {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return
+		}
+		i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// Cross-device copies leave out sockets and similar; say so
+	config.WarnSkipped = func(err error) {
+		i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	config.WarnSnapshot = func(path string, err error) {
+		i18n.Fprintf(os.Stderr, "Warning: cannot snapshot %s, copying it instead: %v\n", path, err)
+	}
+	config.WarnRetrying = func(path string, err error, delay time.Duration) {
+		i18n.Fprintf(os.Stderr, "Warning: copying %s failed: %v; retrying in %s\n", path, err, delay)
+	}
+
 	// Ensure config directory exists before executing any commands
 	if err := config.EnsureConfigDir(); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// Aliases from config.toml are expanded first, so they may name plugins too
+	args := expandAliases(rootCmd, os.Args[1:])
+	rootCmd.SetArgs(args)
+
+	// "trash foo" runs a trash-foo executable from PATH when foo is not a command or a file
+	if plugin, ok := findPlugin(args); ok {
+		runPlugin(plugin, args[1:])
 	}
 	
 	if err := rootCmd.Execute(); err != nil {
@@ -119,4 +446,31 @@ func Execute() {
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "do not print the success message and transfer summary")
+	rootCmd.PersistentFlags().StringP("output", "o", "text", "output format: text, json, yaml or csv")
+	rootCmd.PersistentFlags().Bool("local", false, "use the .trash directory at the project root instead of the global trash")
+	rootCmd.PersistentFlags().Bool("global", false, "use the global trash even inside a project with a .trashrc")
+	rootCmd.PersistentFlags().Bool("nice", false, "run with low CPU and I/O priority so large copies and purges do not slow down the machine")
+	rootCmd.PersistentFlags().String("bwlimit", "", "limit cross-device copies to this many bytes per second (e.g. 20MB)")
+	rootCmd.PersistentFlags().Uint64("purge-rate", 0, "delete at most this many files per second when purging (0 for no limit)")
+	rootCmd.PersistentFlags().Uint64("retries", config.DefaultCopyRetries, "retry a read or write of a copy this many times when it fails with a transient error such as EIO")
+	rootCmd.PersistentFlags().Bool("passphrase", false, "protect the signing key with a passphrase, asked for or read from TRASH_PASSPHRASE, instead of key_source")
+
+	// Trash operation flags
+	rootCmd.Flags().String("expire", "", "Automatically purge trashed items after this long (e.g. 7d, 12h)")
+	rootCmd.Flags().String("keep-for", "", "Keep trashed items this long whatever the retention policies say, then purge them (e.g. 90d)")
+	rootCmd.Flags().Bool("permanently", false, "Delete permanently instead of trashing (asks for confirmation)")
+	rootCmd.Flags().String("older-than", "", "Only trash paths last modified longer ago than this (e.g. 90d) or before this time (e.g. last month, 2025-01-15)")
+	rootCmd.Flags().String("larger-than", "", "Only trash paths larger than this size (e.g. 100M)")
+	rootCmd.Flags().String("session-window", "", "Append to the current hour's or day's session: none, hour or day (default from config)")
+	rootCmd.Flags().Bool("allow-mounts", false, "Trash paths that are or contain mount points")
+	rootCmd.Flags().Bool("snapshot", false, "Keep btrfs subvolumes, and directories and large files on ZFS datasets with trash:snapshots=on, in a snapshot instead of copying them to the trash on another filesystem (default from config)")
+	rootCmd.Flags().Bool("no-preflight", false, "Start trashing without first checking permissions and free space for every path")
+	rootCmd.Flags().BoolP("dry-run", "n", false, "Only show what would be trashed and how much it would move")
+	rootCmd.Flags().BoolP("dereference", "L", false, "Trash the targets of symlink arguments instead of the links")
+	rootCmd.Flags().BoolP("no-dereference", "P", false, "Trash symlink arguments as links, never their targets (default)")
+	rootCmd.Flags().Bool("respect-gitignore", false, "Skip paths ignored by git")
+	rootCmd.Flags().Bool("print", false, "Print the original and in-trash path of each trashed item, tab-separated, one per line")
+	rootCmd.Flags().Bool("print0", false, "Like --print, but end each path with a NUL instead of a tab or newline")
+	rootCmd.Flags().Bool("no-ignore", false, "Trash paths even when a .trashignore file protects them")
 }
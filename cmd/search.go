@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [pattern]",
+	Short: "Search trashed items by name or original path",
+	Long: `Search the trash for items whose name matches a glob pattern,
+or whose name or original path contains the given text.
+
+Examples:
+  trash search report
+  trash search '*.log'
+  trash search projects/website
+  trash search --regex '^core\.\d+$'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pattern := args[0]
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		absolute, _ := cmd.Flags().GetBool("absolute")
+		normalize, _ := cmd.Flags().GetBool("normalize")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		matcher := config.MatchOptions{Normalize: normalize, IgnoreCase: ignoreCase}
+
+		items := loadTrashedItems(cmd)
+
+		matchItem := func(item config.RestoreItem) bool {
+			return matchesSearch(item, pattern, matcher)
+		}
+		if useRegex, _ := cmd.Flags().GetBool("regex"); useRegex {
+			re, err := matcher.Regexp(pattern)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Error: invalid regular expression: %v\n", err)
+				os.Exit(1)
+			}
+			matchItem = func(item config.RestoreItem) bool {
+				return matcher.MatchRegexp(re, item.Name) || matcher.MatchRegexp(re, item.OriginalPath)
+			}
+		}
+
+		var matched []config.TrashedItem
+		for _, entry := range items {
+			if matchItem(entry.Item) {
+				matched = append(matched, entry)
+			}
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, newItemRecords(matched))
+			return
+		}
+
+		for _, entry := range matched {
+			if verbose {
+				i18n.Printf("  • %s\n", entry.Item.Name)
+				i18n.Printf("    Original: %s\n", formatOriginal(entry.Item.OriginalPath))
+				i18n.Printf("    Session:  %s\n", entry.Session)
+				if entry.Root.Name != "" {
+					i18n.Printf("    Store:    %s\n", entry.Root.Dir)
+				}
+				i18n.Printf("    Trashed:  %s\n", formatTimestamp(entry.Item.TrashedAt, absolute))
+			} else {
+				i18n.Printf("  • %s (from %s) [%s]%s\n", entry.Item.Name, formatOriginal(entry.Item.OriginalPath), formatSession(entry.Session, absolute), formatRoot(entry.Root))
+			}
+		}
+
+		if len(matched) == 0 {
+			i18n.Printf("No items matching '%s' found in trash\n", pattern)
+			return
+		}
+
+		i18n.Printf("\nFound %d matching item(s)\n", len(matched))
+	},
+}
+
+// matchesSearch reports whether an item's name matches the glob pattern
+// or its name or original path contains the pattern as text
+func matchesSearch(item config.RestoreItem, pattern string, matcher config.MatchOptions) bool {
+	if matcher.Glob(pattern, item.Name) {
+		return true
+	}
+
+	// Compare paths with forward slashes so "projects/website" also finds Windows paths
+	return matcher.Contains(item.Name, pattern) ||
+		matcher.Contains(filepath.ToSlash(item.OriginalPath), filepath.ToSlash(pattern))
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().Bool("absolute", false, "Show raw timestamps without relative ages")
+	searchCmd.Flags().Bool("all-roots", false, "Search the home trash, the project's local trash and the configured roots together")
+	searchCmd.Flags().Bool("normalize", true, "Match names regardless of Unicode normalization form (NFC/NFD)")
+	searchCmd.Flags().BoolP("ignore-case", "i", false, "Match names case-insensitively")
+	searchCmd.Flags().Bool("regex", false, "Treat the pattern as a regular expression matched against names and original paths")
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"github.com/artemisfowl/trash/internal/server"
+)
+
+// socketName is the default unix socket of the control interface, inside the trash directory
+const socketName = "trash.sock"
+
+// tokenName is the file in the trash directory holding the token clients of
+// a TCP listener must send
+const tokenName = "serve.token"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the gRPC control interface",
+	Long: `Serve the trash over gRPC so programs can list, trash, restore and empty items
+without parsing CLI output. The service is defined in api/trash/v1/trash.proto;
+trash and restore stream one progress message per item.
+
+By default the server listens on a unix socket in the trash directory
+(~/.local/share/trash/trash.sock) that only the current user can access.
+Use --listen to serve on a TCP address instead. Anyone who can reach a TCP
+address could otherwise trash and purge your files, so every call must then
+carry the token in ~/.local/share/trash/serve.token (created on first use,
+readable by the current user only) as "authorization: Bearer <token>"
+metadata. The connection is not encrypted: listen on a loopback address, or
+reach it through an SSH tunnel.
+
+With --dbus the server also claims io.github.artemisfowl.Trash1 on the D-Bus
+session bus, so desktop applications can call its Trash method.
+
+With --verify-every the server also checks trashed items for corruption in
+the background, like 'trash verify --incremental', reporting problems on
+standard error.
+
+Examples:
+  trash serve
+  trash serve --listen 127.0.0.1:7070
+  trash serve --dbus --verify-every 1h`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		address, _ := cmd.Flags().GetString("listen")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		withDBus, _ := cmd.Flags().GetBool("dbus")
+		verifyEvery, _ := cmd.Flags().GetString("verify-every")
+
+		var verifyInterval time.Duration
+		if verifyEvery != "" {
+			interval, err := config.ParseDuration(verifyEvery)
+			if err != nil || interval <= 0 {
+				i18n.Fprintf(os.Stderr, "Error: invalid --verify-every %q\n", verifyEvery)
+				os.Exit(1)
+			}
+			verifyInterval = interval
+		}
+
+		listener, err := serveListener(address)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// The unix socket is the user's own; a TCP port is open to anyone who can reach it
+		var token string
+		if address != "" {
+			var tokenPath string
+			if token, tokenPath, err = serveToken(); err != nil {
+				listener.Close()
+				i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if verbose {
+				i18n.Fprintf(os.Stderr, "Clients must send the token in %s\n", tokenPath)
+			}
+		}
+
+		srv := server.New(token)
+		done := make(chan struct{})
+
+		// Shut down cleanly so the socket file is removed
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			close(done)
+			srv.GracefulStop()
+		}()
+
+		if withDBus {
+			go func() {
+				if err := bus.Serve(done); err != nil {
+					i18n.Fprintf(os.Stderr, "Error: D-Bus: %v\n", err)
+					srv.Stop()
+					os.Exit(1)
+				}
+			}()
+		}
+
+		if verifyInterval > 0 {
+			go verifyPeriodically(verifyInterval, done)
+		}
+
+		if verbose {
+			i18n.Fprintf(os.Stderr, "Listening on %s\n", listener.Addr())
+		}
+
+		if err := srv.Serve(listener); err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// verifyPeriodically checks a batch of trashed items for corruption every
+// interval until done is closed, reporting problems on standard error
+func verifyPeriodically(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		results, err := config.VerifyPayloads(defaultVerifyLimit)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: verify: %v\n", err)
+		}
+		for _, result := range results {
+			switch result.Status {
+			case config.VerifyCorrupt, config.VerifyFailed:
+				i18n.Fprintf(os.Stderr, "Warning: verify: %s [%s] is %s: %v\n", result.Item.Item.Name, result.Item.Session, result.Status, result.Err)
+			case config.VerifyMissing:
+				i18n.Fprintf(os.Stderr, "Warning: verify: %s [%s] is missing\n", result.Item.Item.Name, result.Item.Session)
+			}
+		}
+	}
+}
+
+// serveListener listens on address, or on the default unix socket when address is empty
+func serveListener(address string) (net.Listener, error) {
+	if address != "" {
+		return net.Listen("tcp", address)
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting config directory: %w", err)
+	}
+	socketPath := filepath.Join(configDir, socketName)
+
+	// A socket left behind by a crashed server would make Listen fail
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another server is already listening on %s", socketPath)
+	}
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	// Windows has no permission bits; the socket inherits the store directory's ACL
+	if err := os.Chmod(socketPath, 0600); err != nil && runtime.GOOS != "windows" {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// serveToken returns the token clients of a TCP listener must send and the
+// file holding it, creating one readable by the current user only when there
+// is none yet
+func serveToken() (string, string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", "", fmt.Errorf("getting config directory: %w", err)
+	}
+	path := filepath.Join(configDir, tokenName)
+
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, path, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(random)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return token, path, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("listen", "", "Serve on this TCP address (host:port) instead of the unix socket; clients must send the token in serve.token")
+	serveCmd.Flags().Bool("dbus", false, "Also accept trash requests on the D-Bus session bus")
+	serveCmd.Flags().String("verify-every", "", "Check a batch of trashed items for corruption at this interval (e.g. 1h)")
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// shellInitPosix defines the rm wrapper and helper functions shared by bash and zsh
+const shellInitPosix = `# rm wrapper that moves files to the trash; use 'command rm' to really delete
+rm() {
+    local args=() arg opts=1
+    for arg in "$@"; do
+        if [ "$opts" -eq 1 ] && [ "$arg" = "--" ]; then opts=0; continue; fi
+        if [ "$opts" -eq 1 ] && [[ "$arg" == -* ]]; then continue; fi
+        args+=("$arg")
+    done
+    command trash -- "${args[@]}"
+}
+
+# cd into the session holding the most recently trashed item
+trash-cd() {
+    local latest
+    latest=$(command trash list --format '{{.SessionDir}}' | tail -n 1)
+    if [ -z "$latest" ]; then echo "Trash is empty" >&2; return 1; fi
+    cd "$latest"
+}
+
+# pick a trashed item (with fzf when available) and restore it
+trash-pick() {
+    local line session id
+    if command -v fzf >/dev/null 2>&1; then
+        line=$(command trash list --format '{{.Session}}\t{{.ID}}\t{{.Name}}\t{{.OriginalPath}}' |
+            fzf --tac --delimiter='\t' --with-nth=3.. --prompt='restore> ') || return
+    else
+        local IFS=$'\n'
+        select line in $(command trash list --format '{{.Session}}\t{{.ID}}\t{{.Name}}\t{{.OriginalPath}}'); do break; done
+    fi
+    [ -n "$line" ] || return 1
+    session=${line%%$'\t'*}
+    id=${line#*$'\t'}
+    id=${id%%$'\t'*}
+    command trash restore "$id" --timestamp "$session"
+}
+
+# restore one of the most recently trashed items into the current directory
+trash-restore-widget() {
+    local line session id
+    if command -v fzf >/dev/null 2>&1; then
+        line=$(command trash list --format '{{.Session}}\t{{.ID}}\t{{.Name}}\t{{.OriginalPath}}' | tail -n 50 |
+            fzf --tac --delimiter='\t' --with-nth=3.. --prompt='restore here> ') || return 0
+    else
+        local IFS=$'\n'
+        select line in $(command trash list --format '{{.Session}}\t{{.ID}}\t{{.Name}}\t{{.OriginalPath}}' | tail -n 20); do break; done
+    fi
+    [ -n "$line" ] || return 0
+    session=${line%%$'\t'*}
+    id=${line#*$'\t'}
+    id=${id%%$'\t'*}
+    command trash restore "$id" --timestamp "$session" --here
+}
+`
+
+const shellInitBash = `# trash shell integration for bash
+# Add to ~/.bashrc:  eval "$(trash shell-init bash)"
+
+` + shellInitPosix + `
+# Ctrl-X Ctrl-R: restore a recently trashed item into the current directory
+bind -x '"\C-x\C-r": trash-restore-widget'
+
+# completion
+source <(command trash completion bash)
+`
+
+const shellInitZsh = `# trash shell integration for zsh
+# Add to ~/.zshrc:  eval "$(trash shell-init zsh)"
+
+` + shellInitPosix + `
+# Ctrl-X Ctrl-R: restore a recently trashed item into the current directory
+_trash_restore_widget() {
+    zle -I
+    trash-restore-widget </dev/tty
+    zle reset-prompt
+}
+zle -N _trash_restore_widget
+bindkey '^X^R' _trash_restore_widget
+
+# completion (requires compinit)
+source <(command trash completion zsh)
+`
+
+const shellInitFish = `# trash shell integration for fish
+# Add to ~/.config/fish/config.fish:  trash shell-init fish | source
+
+# rm wrapper that moves files to the trash; use 'command rm' to really delete
+function rm --description 'Move files to the trash'
+    set -l args
+    set -l opts 1
+    for arg in $argv
+        if test $opts -eq 1; and test "$arg" = "--"
+            set opts 0
+            continue
+        end
+        if test $opts -eq 1; and string match -q -- '-*' $arg
+            continue
+        end
+        set -a args $arg
+    end
+    command trash -- $args
+end
+
+# cd into the session holding the most recently trashed item
+function trash-cd --description 'cd into the most recent trash session'
+    set -l latest (command trash list --format '{{.SessionDir}}' | tail -n 1)
+    if test -z "$latest"
+        echo "Trash is empty" >&2
+        return 1
+    end
+    cd $latest
+end
+
+# pick a trashed item (with fzf when available) and restore it
+function trash-pick --description 'Pick a trashed item and restore it'
+    set -l items (command trash list --format '{{.Session}}\t{{.ID}}\t{{.Name}}\t{{.OriginalPath}}')
+    test (count $items) -gt 0; or return 1
+    set -l line
+    if type -q fzf
+        set line (printf '%s\n' $items | fzf --tac --delimiter='\t' --with-nth=3.. --prompt='restore> '); or return
+    else
+        for i in (seq (count $items))
+            printf '%d) %s\n' $i $items[$i]
+        end
+        read -P 'restore #? ' choice; or return
+        set line $items[$choice]
+    end
+    set -l fields (string split \t -- $line)
+    command trash restore $fields[2] --timestamp $fields[1]
+end
+
+# restore one of the most recently trashed items into the current directory
+function trash-restore-widget --description 'Restore a recently trashed item here'
+    set -l items (command trash list --format '{{.Session}}\t{{.ID}}\t{{.Name}}\t{{.OriginalPath}}' | tail -n 50)
+    if test (count $items) -eq 0
+        commandline -f repaint
+        return
+    end
+    set -l line
+    if type -q fzf
+        set line (printf '%s\n' $items | fzf --tac --delimiter='\t' --with-nth=3.. --prompt='restore here> ')
+    else
+        for i in (seq (count $items))
+            printf '%d) %s\n' $i $items[$i]
+        end
+        read -P 'restore #? ' choice; and set line $items[$choice]
+    end
+    if test -n "$line"
+        set -l fields (string split \t -- $line)
+        command trash restore $fields[2] --timestamp $fields[1] --here
+    end
+    commandline -f repaint
+end
+
+# Ctrl-X Ctrl-R: restore a recently trashed item into the current directory
+bind \cx\cr trash-restore-widget
+
+# completion
+command trash completion fish | source
+`
+
+// shellInitSnippets maps supported shells to their integration code
+var shellInitSnippets = map[string]string{
+	"bash": shellInitBash,
+	"zsh":  shellInitZsh,
+	"fish": shellInitFish,
+}
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init [bash|zsh|fish]",
+	Short: "Print shell integration code",
+	Long: `Print a shell snippet that defines an rm wrapper which moves files to the trash,
+loads completion, and adds the trash-cd and trash-pick helpers. Ctrl-X Ctrl-R is
+bound to a widget that restores one of the most recently trashed items into the
+current directory.
+
+Examples:
+  eval "$(trash shell-init bash)"    # in ~/.bashrc
+  eval "$(trash shell-init zsh)"     # in ~/.zshrc
+  trash shell-init fish | source     # in ~/.config/fish/config.fish`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Run: func(cmd *cobra.Command, args []string) {
+		snippet, ok := shellInitSnippets[args[0]]
+		if !ok {
+			i18n.Fprintf(os.Stderr, "Error: unsupported shell '%s' (expected bash, zsh or fish)\n", args[0])
+			os.Exit(1)
+		}
+
+		io.WriteString(os.Stdout, snippet)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show trash usage statistics",
+	Long: `Show the number of sessions and items in the trash, the disk space they use and their age range.
+
+With --prometheus the statistics, including cumulative purge counters, are printed
+in the Prometheus text exposition format, e.g. for the node_exporter textfile collector:
+
+  trash stats --prometheus > /var/lib/node_exporter/trash.prom.$$ &&
+    mv /var/lib/node_exporter/trash.prom.$$ /var/lib/node_exporter/trash.prom`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		usage, err := config.GetUsage(time.Now())
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		if prometheus, _ := cmd.Flags().GetBool("prometheus"); prometheus {
+			purges, err := config.LoadPurgeStats()
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			writePrometheus(os.Stdout, usage, purges, time.Now())
+			return
+		}
+
+		record := statsRecord{
+			Sessions:      usage.Sessions,
+			Items:         usage.Items,
+			TotalBytes:    usage.TotalBytes,
+			ExpiredItems:  usage.ExpiredItems,
+			OldestSession: usage.OldestSession,
+			NewestSession: usage.NewestSession,
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, record)
+			return
+		}
+
+		i18n.Printf("Trash directory: %s\n", configDir)
+		i18n.Printf("Sessions:        %d\n", record.Sessions)
+		i18n.Printf("Items:           %d\n", record.Items)
+		i18n.Printf("Total size:      %s\n", config.FormatSize(record.TotalBytes))
+		if record.ExpiredItems > 0 {
+			i18n.Printf("Expired items:   %d (run 'trash empty --expired')\n", record.ExpiredItems)
+		}
+		if record.Sessions > 0 {
+			i18n.Printf("Oldest session:  %s\n", formatSession(record.OldestSession, false))
+			i18n.Printf("Newest session:  %s\n", formatSession(record.NewestSession, false))
+		}
+	},
+}
+
+// statsRecord is the structured (--output) representation of trash statistics
+type statsRecord struct {
+	Sessions      int    `json:"sessions" yaml:"sessions"`
+	Items         int    `json:"items" yaml:"items"`
+	TotalBytes    uint64 `json:"total_bytes" yaml:"total_bytes"`
+	ExpiredItems  int    `json:"expired_items" yaml:"expired_items"`
+	OldestSession string `json:"oldest_session,omitempty" yaml:"oldest_session,omitempty"`
+	NewestSession string `json:"newest_session,omitempty" yaml:"newest_session,omitempty"`
+}
+
+// writePrometheus writes trash metrics in the Prometheus text exposition format
+func writePrometheus(w io.Writer, usage *config.Usage, purges *config.PurgeStats, now time.Time) {
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+		fmt.Fprintf(w, "%s %v\n", name, value)
+	}
+
+	oldestAge := 0.0
+	if !usage.OldestItem.IsZero() {
+		oldestAge = now.Sub(usage.OldestItem).Seconds()
+	}
+
+	metric("trash_size_bytes", "gauge", "Disk space used by the trash.", usage.TotalBytes)
+	metric("trash_items", "gauge", "Number of items in the trash.", usage.Items)
+	metric("trash_sessions", "gauge", "Number of trash sessions.", usage.Sessions)
+	metric("trash_expired_items", "gauge", "Number of items whose expiry has passed but are not purged yet.", usage.ExpiredItems)
+	metric("trash_oldest_item_age_seconds", "gauge", "Age of the oldest item in the trash.", int64(oldestAge))
+	metric("trash_purged_items_total", "counter", "Items permanently deleted from the trash.", purges.Items)
+	metric("trash_purged_sessions_total", "counter", "Trash sessions removed by purges.", purges.Sessions)
+	metric("trash_purged_bytes_total", "counter", "Bytes permanently deleted from the trash.", purges.Bytes)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Bool("prometheus", false, "Print metrics in the Prometheus text exposition format")
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show an overview of the trash on one screen",
+	Long: `Show where the trash is kept, how much it holds, its oldest item, the retention
+settings and how close the trash is to them, items waiting to be purged, and any
+problems found in the trash directory.
+
+Examples:
+  trash status
+  trash status -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		now := time.Now()
+
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
+			os.Exit(1)
+		}
+		settings, err := config.LoadSettings()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			settings = &config.Settings{}
+		}
+		usage, err := config.GetUsage(now)
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error reading trash directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		record := statusRecord{
+			Store:         configDir,
+			Backend:       "local",
+			Sessions:      usage.Sessions,
+			Items:         usage.Items,
+			TotalBytes:    usage.TotalBytes,
+			ExpiredItems:  usage.ExpiredItems,
+			MinFree:       settings.MinFree,
+			SessionWindow: settings.SessionWindow,
+		}
+		if settings.Store != "" {
+			record.Store = settings.Store
+			if location, err := url.Parse(settings.Store); err == nil {
+				record.Backend = location.Scheme
+			}
+		}
+		if !usage.OldestItem.IsZero() {
+			record.OldestItem = usage.OldestItem.Format(time.RFC3339)
+		}
+
+		items, _ := config.ListTrashedItems()
+		for _, entry := range items {
+			if entry.Item.IsRetained(now) {
+				record.RetainedItems++
+			}
+		}
+
+		// The free space of a remote store is not known
+		if settings.Store == "" {
+			if free, err := config.FreeSpace(configDir); err == nil {
+				record.FreeBytes = &free
+			}
+		}
+
+		record.OtherTrashes = config.ForeignTrashes()
+
+		findings, err := config.Diagnose()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		record.Problems = make([]statusProblem, 0, len(findings))
+		for _, finding := range findings {
+			record.Problems = append(record.Problems, statusProblem(finding))
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, record)
+			return
+		}
+
+		i18n.Printf("Store:          %s (%s)\n", record.Store, record.Backend)
+		i18n.Printf("Contents:       %d item(s) in %d session(s), %s\n", record.Items, record.Sessions, config.FormatSize(record.TotalBytes))
+		if record.OldestItem != "" {
+			i18n.Printf("Oldest item:    %s\n", formatTimestamp(record.OldestItem, false))
+		}
+		if record.ExpiredItems > 0 {
+			i18n.Printf("Expired:        %d item(s) waiting to be purged (run 'trash empty --expired')\n", record.ExpiredItems)
+		}
+		if record.RetainedItems > 0 {
+			i18n.Printf("Retained:       %d item(s) kept past the retention policies\n", record.RetainedItems)
+		}
+
+		switch minFree, err := config.ParseSize(settings.MinFree); {
+		case settings.MinFree == "":
+			i18n.Printf("Min free:       not set\n")
+		case err != nil:
+			i18n.Printf("Min free:       %s (invalid: %v)\n", settings.MinFree, err)
+		case record.FreeBytes == nil:
+			i18n.Printf("Min free:       %s (free space unknown)\n", config.FormatSize(minFree))
+		case *record.FreeBytes < minFree:
+			i18n.Printf("Min free:       %s, only %s free: the next trash operation purges the oldest sessions\n",
+				config.FormatSize(minFree), config.FormatSize(*record.FreeBytes))
+		default:
+			i18n.Printf("Min free:       %s, %s free (%s to spare)\n", config.FormatSize(minFree),
+				config.FormatSize(*record.FreeBytes), config.FormatSize(*record.FreeBytes-minFree))
+		}
+		if record.SessionWindow != "" {
+			i18n.Printf("Session window: %s\n", record.SessionWindow)
+		}
+		for _, other := range record.OtherTrashes {
+			i18n.Printf("Other trash:    %d item(s) in %s (run 'trash import' to move them here)\n", other.Items, other.Dir)
+		}
+
+		if len(findings) == 0 {
+			i18n.Printf("Problems:       none\n")
+			return
+		}
+		i18n.Printf("Problems:       %d\n", len(findings))
+		for _, finding := range findings {
+			i18n.Printf("  • %s\n", finding.Problem)
+			i18n.Printf("    Fix: %s\n", finding.Fix)
+		}
+	},
+}
+
+// statusRecord is the structured (--output) representation of the trash status
+type statusRecord struct {
+	Store         string                `json:"store" yaml:"store"`
+	Backend       string                `json:"backend" yaml:"backend"`
+	Sessions      int                   `json:"sessions" yaml:"sessions"`
+	Items         int                   `json:"items" yaml:"items"`
+	TotalBytes    uint64                `json:"total_bytes" yaml:"total_bytes"`
+	OldestItem    string                `json:"oldest_item,omitempty" yaml:"oldest_item,omitempty"`
+	ExpiredItems  int                   `json:"expired_items" yaml:"expired_items"`
+	RetainedItems int                   `json:"retained_items" yaml:"retained_items"`
+	MinFree       string                `json:"min_free,omitempty" yaml:"min_free,omitempty"`
+	FreeBytes     *uint64               `json:"free_bytes,omitempty" yaml:"free_bytes,omitempty"`
+	SessionWindow string                `json:"session_window,omitempty" yaml:"session_window,omitempty"`
+	OtherTrashes  []config.ForeignTrash `json:"other_trashes,omitempty" yaml:"other_trashes,omitempty"`
+	Problems      []statusProblem       `json:"problems" yaml:"problems"`
+}
+
+// statusProblem is the structured representation of a problem found in the trash
+type statusProblem struct {
+	Check   string `json:"check" yaml:"check"`
+	Problem string `json:"problem" yaml:"problem"`
+	Fix     string `json:"fix" yaml:"fix"`
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"time"
+
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// transferSummary accumulates what a trash or restore operation moved, for
+// the summary printed at its end
+type transferSummary struct {
+	start   time.Time
+	items   int
+	bytes   uint64
+	renamed int
+	copied  int
+	snapped int // left in a snapshot of their filesystem
+}
+
+// newTransferSummary starts timing an operation
+func newTransferSummary() *transferSummary {
+	return &transferSummary{start: time.Now()}
+}
+
+// add records one moved item
+func (s *transferSummary) add(bytes uint64, copied bool) {
+	s.items++
+	s.bytes += bytes
+	if copied {
+		s.copied++
+	} else {
+		s.renamed++
+	}
+}
+
+// addSnapshot records one item left in a snapshot instead of moved
+func (s *transferSummary) addSnapshot(bytes uint64) {
+	s.items++
+	s.bytes += bytes
+	s.snapped++
+}
+
+// print writes the item count, bytes, elapsed time, throughput and how the
+// items were moved; renames are instant, copies across filesystems are not
+func (s *transferSummary) print(w io.Writer) {
+	if s == nil || s.items == 0 {
+		return
+	}
+
+	elapsed := time.Since(s.start)
+	rate := uint64(float64(s.bytes) / max(elapsed.Seconds(), 0.001))
+
+	if s.snapped > 0 {
+		i18n.Fprintf(w, "%d item(s), %s in %s (%s/s): %d renamed, %d copied, %d snapshotted\n",
+			s.items, config.FormatSize(s.bytes), elapsed.Round(time.Millisecond), config.FormatSize(rate), s.renamed, s.copied, s.snapped)
+		return
+	}
+	i18n.Fprintf(w, "%d item(s), %s in %s (%s/s): %d renamed, %d copied\n",
+		s.items, config.FormatSize(s.bytes), elapsed.Round(time.Millisecond), config.FormatSize(rate), s.renamed, s.copied)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Put back what the last trash operation trashed",
+	Long: `Restore every item of the last trash operation to where it was trashed from.
+Run it again to walk back the operations before it, up to the last 100; 'trash
+redo' trashes the paths of an undone operation again.
+
+Items restored or purged since are skipped. Items whose original path is taken
+again are left in the trash and the operation stays on the undo stack, so
+'trash undo' can be run again once the way is clear.
+
+Examples:
+  trash undo
+  trash undo && trash undo
+  trash redo`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		noVerify, _ := cmd.Flags().GetBool("no-verify")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		result, err := config.Undo(!noVerify)
+		if errors.Is(err, config.ErrNothingToUndo) {
+			i18n.Printf("Nothing to undo\n")
+			return
+		}
+		if result == nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			if errors.Is(err, config.ErrTampered) {
+				i18n.Fprintf(os.Stderr, "Check the session's .restore file, or use --no-verify to restore anyway\n")
+			}
+			os.Exit(exitCode(err))
+		}
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: failed to save the undo stack: %v\n", err)
+		}
+
+		if len(result.Restored) > 0 {
+			bus.EmitChanged(bus.ReasonRestored)
+		}
+		if !quiet {
+			for _, path := range result.Restored {
+				i18n.Printf("Restored: %s\n", path)
+			}
+			for _, path := range result.Missing {
+				i18n.Printf("No longer in the trash: %s\n", path)
+			}
+		}
+		for _, failure := range result.Failed {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", failure)
+		}
+		if len(result.Failed) > 0 {
+			if errors.Is(result.Failed[0], config.ErrDestinationExists) {
+				i18n.Fprintf(os.Stderr, "Move the files now in the way and run 'trash undo' again\n")
+			}
+			os.Exit(exitCode(result.Failed[0]))
+		}
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Trash again what the last 'trash undo' put back",
+	Long: `Trash the paths restored by the last 'trash undo' again, into a new session.
+Run it again to redo the operations undone before it. Trashing anything else
+in between forgets what was undone, like in an editor.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		op, err := config.PopRedo()
+		if errors.Is(err, config.ErrNothingToRedo) {
+			i18n.Printf("Nothing to redo\n")
+			return
+		}
+		if op == nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: failed to save the undo stack: %v\n", err)
+		}
+
+		opts := trashOptions(cmd, "")
+		opts.Redo = true
+		opts.NoIgnore = true // they were trashed before, maybe with --no-ignore
+		trashPaths(op.Paths, opts, verbose, quiet, printNone)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+	undoCmd.Flags().Bool("no-verify", false, "Restore even if the session metadata does not match its signature (see sign_metadata)")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var vacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Compact the metadata of the trash and remove leftover files",
+	Long: `Tidy up what the trash keeps besides the trashed items themselves:
+
+  - items listed in the metadata whose payload is gone are dropped, so list
+    and the index check of 'trash doctor' no longer show them
+  - metadata written by older versions is rewritten in the current format
+  - empty session directories are removed
+  - temporary files left by interrupted writes, older than an hour, are removed
+
+The size of the metadata and state files is reported before and after.
+Trashed items are never touched; sessions without usable metadata are left
+for 'trash adopt' and those failing their signature check for you to inspect.
+
+Vacuum is meant to run unattended, e.g. from cron (see 'trash cron --vacuum').
+
+Examples:
+  trash vacuum --dry-run -v
+  trash vacuum`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		report, err := config.Vacuum(dryRun)
+		if err != nil && report == nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if verbose {
+			for _, item := range report.DroppedItems {
+				i18n.Printf("Dropped from the metadata: %s\n", item)
+			}
+			for _, session := range report.EmptySessions {
+				i18n.Printf("Empty session: %s\n", session)
+			}
+			for _, path := range report.TempFiles {
+				i18n.Printf("Temporary file: %s\n", path)
+			}
+		}
+
+		if dryRun {
+			i18n.Printf("Would drop %d missing item(s), rewrite %d session(s), remove %d empty session(s) and %d temporary file(s)\n",
+				len(report.DroppedItems), report.Rewritten, len(report.EmptySessions), len(report.TempFiles))
+		} else {
+			i18n.Printf("Dropped %d missing item(s), rewrote %d session(s), removed %d empty session(s) and %d temporary file(s)\n",
+				len(report.DroppedItems), report.Rewritten, len(report.EmptySessions), len(report.TempFiles))
+			i18n.Printf("Metadata and state: %s before, %s after\n", config.FormatSize(report.Before), config.FormatSize(report.After))
+			if len(report.DroppedItems)+len(report.EmptySessions) > 0 {
+				bus.EmitChanged(bus.ReasonPurged)
+			}
+		}
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vacuumCmd)
+	vacuumCmd.Flags().BoolP("dry-run", "n", false, "Only report what would be done")
+}
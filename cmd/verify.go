@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// defaultVerifyLimit is how many items verify --incremental checks per run
+const defaultVerifyLimit = 100
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check trashed items for silent corruption",
+	Long: `Check the content of trashed items against their SHA-256 checksums, so that
+a file damaged on an aging disk is noticed before it is needed. Items
+without a checksum get one taken now, against which later runs check them.
+
+With --incremental only a bounded number of items is checked per run, those
+never verified first and then the ones verified longest ago, so that runs
+from cron (see 'trash cron --verify') or 'trash serve --verify-every' cycle
+through the whole trash over time without reading all of it at once.
+
+Items of compressed sessions are checked by reading their session's archive,
+whose own checksum detects corruption. Problems are reported on standard
+error and make the exit status non-zero: items that are corrupt, missing or
+cannot be read.
+
+Examples:
+  trash verify
+  trash verify --incremental --limit 500
+  trash verify -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		incremental, _ := cmd.Flags().GetBool("incremental")
+		limit, _ := cmd.Flags().GetInt("limit")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if !incremental {
+			limit = 0
+		}
+
+		results, err := config.VerifyPayloads(limit)
+		if err != nil && results == nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		counts := make(map[string]int)
+		for _, result := range results {
+			counts[result.Status]++
+		}
+		problems := counts[config.VerifyCorrupt] + counts[config.VerifyMissing] + counts[config.VerifyFailed]
+
+		if format := outputFormat(cmd); format.Structured() {
+			records := make([]verifyRecord, 0, len(results))
+			for _, result := range results {
+				records = append(records, newVerifyRecord(result))
+			}
+			printStructured(format, records)
+		} else {
+			for _, result := range results {
+				name, session := result.Item.Item.Name, result.Item.Session
+				switch result.Status {
+				case config.VerifyCorrupt:
+					i18n.Fprintf(os.Stderr, "Corrupt: %s [%s]: %v\n", name, session, result.Err)
+				case config.VerifyMissing:
+					i18n.Fprintf(os.Stderr, "Missing: %s [%s]\n", name, session)
+				case config.VerifyFailed:
+					i18n.Fprintf(os.Stderr, "Unreadable: %s [%s]: %v\n", name, session, result.Err)
+				case config.VerifyRecorded:
+					if verbose {
+						i18n.Printf("Recorded: %s [%s]\n", name, session)
+					}
+				default:
+					if verbose {
+						i18n.Printf("OK: %s [%s]\n", name, session)
+					}
+				}
+			}
+			i18n.Printf("Verified %d item(s): %d intact, %d checksum(s) recorded, %d problem(s)\n",
+				len(results), counts[config.VerifyOK], counts[config.VerifyRecorded], problems)
+		}
+
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if problems > 0 {
+			os.Exit(exitError)
+		}
+	},
+}
+
+// verifyRecord is the structured (--output) representation of a verified item
+type verifyRecord struct {
+	ID      string `json:"id" yaml:"id"`
+	Session string `json:"session" yaml:"session"`
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// newVerifyRecord converts the outcome of verifying an item into its structured representation
+func newVerifyRecord(result config.VerifyResult) verifyRecord {
+	record := verifyRecord{
+		ID:      result.Item.Item.ShortID(),
+		Session: result.Item.Session,
+		Name:    result.Item.Item.Name,
+		Status:  result.Status,
+	}
+	if result.Err != nil {
+		record.Error = result.Err.Error()
+	}
+	return record
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().Bool("incremental", false, "Check only the items verified longest ago")
+	verifyCmd.Flags().Int("limit", defaultVerifyLimit, "How many items --incremental checks")
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
-	"fmt"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+	"github.com/artemisfowl/trash/internal/output"
 )
 
 var (
@@ -12,17 +16,89 @@ var (
 	GitCommit = "unknown"
 )
 
+// storageBackend names where trashed items are kept
+const storageBackend = "local"
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of Trash",
-	Long:  `All software has versions. This is Trash's`,
+	Long: `All software has versions. This is Trash's.
+
+Use --json (or --output json/yaml) for machine-readable build and environment
+details, e.g. to attach to bug reports.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Trash v%s\n", Version)
-		fmt.Printf("Build Date: %s\n", BuildDate)
-		fmt.Printf("Git Commit: %s\n", GitCommit)
+		record := newVersionRecord()
+
+		format := outputFormat(cmd)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			format = output.JSON
+		}
+		if format.Structured() {
+			printStructured(format, record)
+			return
+		}
+
+		i18n.Printf("Trash v%s\n", record.Version)
+		i18n.Printf("Build Date: %s\n", record.BuildDate)
+		i18n.Printf("Git Commit: %s\n", record.GitCommit)
+		i18n.Printf("Go Version: %s\n", record.GoVersion)
+		i18n.Printf("Platform:   %s\n", record.Platform)
+		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+			i18n.Printf("Backend:    %s\n", record.Backend)
+			i18n.Printf("Trash Dir:  %s\n", record.TrashDir)
+			i18n.Printf("Config:     %s\n", record.ConfigFile)
+		}
 	},
 }
 
+// versionRecord is the structured (--output) representation of build information
+type versionRecord struct {
+	Version    string `json:"version" yaml:"version"`
+	BuildDate  string `json:"build_date" yaml:"build_date"`
+	GitCommit  string `json:"git_commit" yaml:"git_commit"`
+	CommitTime string `json:"commit_time,omitempty" yaml:"commit_time,omitempty"`
+	Modified   bool   `json:"modified,omitempty" yaml:"modified,omitempty"`
+	GoVersion  string `json:"go_version" yaml:"go_version"`
+	Platform   string `json:"platform" yaml:"platform"`
+	Backend    string `json:"backend" yaml:"backend"`
+	TrashDir   string `json:"trash_dir" yaml:"trash_dir"`
+	ConfigFile string `json:"config_file" yaml:"config_file"`
+}
+
+// newVersionRecord collects build details, falling back to the VCS information
+// embedded by the Go toolchain when the version variables were not set at link time
+func newVersionRecord() versionRecord {
+	record := versionRecord{
+		Version:   Version,
+		BuildDate: BuildDate,
+		GitCommit: GitCommit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Backend:   storageBackend,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if record.GitCommit == "unknown" {
+					record.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				record.CommitTime = setting.Value
+			case "vcs.modified":
+				record.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	record.TrashDir, _ = config.GetConfigDir()
+	record.ConfigFile, _ = config.GetSettingsPath()
+
+	return record
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().Bool("json", false, "Print version information as JSON")
 }
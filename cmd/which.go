@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/git"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which <path>...",
+	Short: "Show where and how a path would be trashed",
+	Long: `Show, without trashing anything, what trashing a path would do: which trash
+directory it would go to (the home trash, a project's local trash, a trash on
+a Windows drive or a remote store), whether it would be renamed or copied
+across filesystems, what would protect it or refuse it, and the settings that
+apply. Useful to find out why trash behaves unexpectedly for a path.
+
+Examples:
+  trash which report.pdf
+  trash which /mnt/usb/photos -o json
+  trash which --local build/`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		allowMounts, _ := cmd.Flags().GetBool("allow-mounts")
+		settings, err := config.LoadSettings()
+		if err != nil {
+			i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			settings = &config.Settings{}
+		}
+
+		records := make([]whichRecord, 0, len(args))
+		for _, path := range args {
+			records = append(records, probeTrash(path, settings, allowMounts))
+		}
+
+		if format := outputFormat(cmd); format.Structured() {
+			printStructured(format, records)
+			return
+		}
+
+		for i, record := range records {
+			if i > 0 {
+				i18n.Printf("\n")
+			}
+			i18n.Printf("%s\n", record.Path)
+			if !record.Exists {
+				i18n.Printf("  Does not exist\n")
+				continue
+			}
+			i18n.Printf("  Store:      %s (%s)\n", record.Store, record.StoreKind)
+			i18n.Printf("  Method:     %s\n", record.Method)
+			for _, note := range record.Protection {
+				i18n.Printf("  Protection: %s\n", note)
+			}
+			for _, policy := range record.Policies {
+				i18n.Printf("  Policy:     %s\n", policy)
+			}
+		}
+	},
+}
+
+// whichRecord is the structured (--output) representation of what trashing a path would do
+type whichRecord struct {
+	Path       string   `json:"path" yaml:"path"`
+	Exists     bool     `json:"exists" yaml:"exists"`
+	Store      string   `json:"store,omitempty" yaml:"store,omitempty"`
+	StoreKind  string   `json:"store_kind,omitempty" yaml:"store_kind,omitempty"`
+	Method     string   `json:"method,omitempty" yaml:"method,omitempty"`
+	Protection []string `json:"protection,omitempty" yaml:"protection,omitempty"`
+	Policies   []string `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// probeTrash works out what trashing path would do, the way trashPaths would
+// do it, without touching anything
+func probeTrash(path string, settings *config.Settings, allowMounts bool) whichRecord {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	record := whichRecord{Path: absPath}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return record
+	}
+	record.Exists = true
+
+	configDir, _ := config.GetConfigDir()
+	record.Store, record.StoreKind = configDir, storeKind(configDir, settings)
+
+	// Under WSL, a Windows drive may have a trash of its own, see selectDriveStore
+	if drive, ok := config.WindowsDrive(absPath); ok && settings.WSLDriveTrash && settings.Store == "" {
+		if storeDrive, onDrive := config.WindowsDrive(configDir); !onDrive || storeDrive != drive {
+			previous := ""
+			if config.StoreOverridden() {
+				previous = configDir
+			}
+			config.UseStoreDir(config.DriveStoreDir(drive))
+			defer config.UseStoreDir(previous)
+			configDir = config.DriveStoreDir(drive)
+			record.Store, record.StoreKind = configDir, i18n.Sprintf("trash on Windows drive %s", drive)
+		}
+	}
+
+	record.Method = trashMethod(absPath, info, configDir, settings)
+
+	if err := config.CheckTrashable(absPath, allowMounts); err != nil {
+		record.Protection = append(record.Protection, i18n.Sprintf("refused: %v", err))
+	}
+	if match, _ := config.MatchTrashIgnore(absPath); match != nil {
+		record.Protection = append(record.Protection, i18n.Sprintf("protected by %s (%s); trashed only with --no-ignore", match.File, match.Pattern))
+	}
+	if ignored, _ := git.IsIgnored(absPath); ignored {
+		record.Protection = append(record.Protection, i18n.Sprintf("ignored by git; skipped with --respect-gitignore"))
+	}
+
+	record.Policies = trashPolicies(settings, configDir)
+	return record
+}
+
+// storeKind describes the trash directory configDir
+func storeKind(configDir string, settings *config.Settings) string {
+	switch {
+	case settings.Store != "":
+		return i18n.Sprintf("remote store %s", settings.Store)
+	case config.StoreOverridden():
+		return i18n.Sprintf("local trash of the project at %s", filepath.Dir(configDir))
+	}
+	if legacy, err := config.LegacyStoreDir(); err == nil && legacy == configDir {
+		return i18n.Sprintf("home trash, still where an older version kept it; see 'trash migrate --xdg'")
+	}
+	return i18n.Sprintf("home trash")
+}
+
+// trashMethod describes how the payload of absPath would get into configDir
+func trashMethod(absPath string, info os.FileInfo, configDir string, settings *config.Settings) string {
+	var method string
+	if same, err := config.SameDevice(absPath, configDir); settings.Store == "" && err == nil && same {
+		method = i18n.Sprintf("renamed into the trash, nothing is copied")
+	} else {
+		size, _ := config.PathSize(absPath)
+		kind := config.SnapshotKindOf(absPath, info)
+		if settings.Store == "" && settings.Snapshots && kind != "" {
+			return i18n.Sprintf("kept in a %s snapshot of its filesystem (%s), then deleted; nothing is copied", kind, config.FormatSize(size))
+		}
+		if settings.Store != "" {
+			method = i18n.Sprintf("copied to the remote store (%s), then deleted", config.FormatSize(size))
+		} else {
+			method = i18n.Sprintf("copied from another filesystem (%s), then deleted", config.FormatSize(size))
+		}
+		if limit, err := settings.BWLimitBytes(); err == nil && limit > 0 {
+			method += i18n.Sprintf(", at most %s/s", config.FormatSize(limit))
+		}
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		method += i18n.Sprintf("; the link is trashed, not its target (-L trashes the target)")
+	}
+	return method
+}
+
+// trashPolicies lists the settings and defaults that apply to a trash operation
+// into configDir
+func trashPolicies(settings *config.Settings, configDir string) []string {
+	var policies []string
+
+	switch window, _ := settings.Window(); window {
+	case config.WindowHour:
+		policies = append(policies, i18n.Sprintf("joins the session of the current hour (session_window)"))
+	case config.WindowDay:
+		policies = append(policies, i18n.Sprintf("joins today's session (session_window)"))
+	default:
+		policies = append(policies, i18n.Sprintf("gets a session of its own"))
+	}
+	if minFree, err := settings.MinFreeBytes(); err == nil && minFree > 0 {
+		policies = append(policies, i18n.Sprintf("the oldest sessions are purged when less than %s is free (min_free)", config.FormatSize(minFree)))
+	}
+	if settings.SignMetadata {
+		policies = append(policies, i18n.Sprintf("its metadata is signed (sign_metadata)"))
+	}
+	if settings.Store == "" && config.OnNetworkFS(configDir) {
+		if fsType, detected := config.NetworkFilesystem(configDir); detected {
+			policies = append(policies, i18n.Sprintf("the trash directory is on a network filesystem (%s): it is locked with lockfiles and copies are synced before originals are deleted (network_fs)", fsType))
+		} else {
+			policies = append(policies, i18n.Sprintf("the trash directory is treated as on a network filesystem: it is locked with lockfiles and copies are synced before originals are deleted (network_fs)"))
+		}
+	}
+	if dirMode, fileMode, err := settings.Modes(); err == nil {
+		policies = append(policies, i18n.Sprintf("sessions are created %04o, metadata %04o (dir_mode)", dirMode, fileMode))
+	}
+
+	if defaults, err := config.CommandDefaults(); err == nil && len(defaults[config.RootCommandName]) > 0 {
+		var flags []string
+		for key, value := range defaults[config.RootCommandName] {
+			flags = append(flags, "--"+strings.ReplaceAll(key, "_", "-")+"="+defaultValueString(value))
+		}
+		sort.Strings(flags)
+		policies = append(policies, i18n.Sprintf("defaults from [root] in config.toml: %s", strings.Join(flags, " ")))
+	}
+	return policies
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+	whichCmd.Flags().Bool("allow-mounts", false, "Probe as if trashing with --allow-mounts")
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+
+	"github.com/artemisfowl/trash/internal/config"
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// selectDriveStore handles paths on Windows drives under WSL, which can never be
+// renamed into the Linux trash. With wsl_drive_trash set they are trashed into a
+// trash directory on their drive, provided all paths are on that one drive;
+// otherwise a warning explains that they will be copied in full.
+func selectDriveStore(paths []string) {
+	drives := make(map[string]bool)
+	onLinux := false
+	for _, path := range paths {
+		if drive, ok := config.WindowsDrive(path); ok {
+			drives[drive] = true
+		} else {
+			onLinux = true
+		}
+	}
+	if len(drives) == 0 {
+		return
+	}
+
+	var names []string
+	for drive := range drives {
+		names = append(names, drive)
+	}
+	sort.Strings(names)
+	singleDrive := len(names) == 1 && !onLinux
+
+	// Nothing to do when the store already lives on that drive, e.g. a project's local trash
+	configDir, _ := config.GetConfigDir()
+	if drive, ok := config.WindowsDrive(configDir); ok && singleDrive && drive == names[0] {
+		return
+	}
+
+	settings, err := config.LoadSettings()
+	if err == nil && settings.WSLDriveTrash {
+		if singleDrive {
+			config.UseStoreDir(config.DriveStoreDir(names[0]))
+			if err := config.EnsureConfigDir(); err != nil {
+				i18n.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			return
+		}
+		i18n.Fprintf(os.Stderr, "Warning: paths span several drives, using the default trash\n")
+	}
+
+	for _, drive := range names {
+		i18n.Fprintf(os.Stderr, "Warning: %s is a Windows drive; trashing from it copies everything into the Linux trash (set wsl_drive_trash = true to keep a trash on the drive)\n", drive)
+	}
+}
+
+// warnSharedStorage notes paths on Android shared storage, which are copied in
+// full into the Termux home rather than renamed
+func warnSharedStorage(paths []string) {
+	for _, path := range paths {
+		if config.SharedStorage(path) {
+			i18n.Fprintf(os.Stderr, "Note: %s is on shared storage and will be copied into the trash\n", path)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// SchemaVersion is the bundle layout version written by Create
+const SchemaVersion = 1
+
+// manifestName is the first entry of every bundle
+const manifestName = "bundle.json"
+
+// sessionsPrefix holds the session directories inside the archive
+const sessionsPrefix = "sessions/"
+
+// Manifest describes a bundle; it is stored as bundle.json at the start of the archive
+type Manifest struct {
+	SchemaVersion int      `json:"schema_version"`
+	CreatedAt     string   `json:"created_at"`
+	Hostname      string   `json:"hostname,omitempty"`
+	Home          string   `json:"home"`
+	Sessions      []string `json:"sessions"`
+}
+
+// ApplyResult reports what Apply imported
+type ApplyResult struct {
+	Manifest Manifest
+	Sessions map[string]string // bundle session name -> session name in the local trash
+	Items    int
+}
+
+// Create writes a gzip compressed tar bundle of the given sessions of the trash directory
+func Create(w io.Writer, configDir string, sessions []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	hostname, _ := os.Hostname()
+
+	manifest := Manifest{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     time.Now().Format(time.RFC3339),
+		Hostname:      hostname,
+		Home:          home,
+		Sessions:      sessions,
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+	header := &tar.Header{
+		Name:    manifestName,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := addSession(tw, filepath.Join(configDir, session), sessionsPrefix+session); err != nil {
+			return fmt.Errorf("failed to add session %s: %w", session, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addSession adds the session directory trashDir to the archive under the
+// name prefix. Payloads kept in snapshots are added where the payloads in the
+// session are, since the snapshots stay behind.
+func addSession(tw *tar.Writer, trashDir, prefix string) error {
+	metadata, err := config.LoadRestoreMetadata(trashDir)
+	if err != nil {
+		return addTree(tw, trashDir, prefix, nil)
+	}
+
+	skip := make(map[string]bool)
+	for _, item := range metadata.Items {
+		if item.Snapshot != nil {
+			skip[path.Join(item.Entry(), config.SnapshotRefName)] = true
+		}
+	}
+	if err := addTree(tw, trashDir, prefix, skip); err != nil {
+		return err
+	}
+
+	for _, item := range metadata.Items {
+		if item.Snapshot == nil {
+			continue
+		}
+		if err := addTree(tw, item.Snapshot.Path, path.Join(prefix, item.StoragePath), nil); err != nil {
+			return fmt.Errorf("failed to add %s from its snapshot: %w", item.Name, err)
+		}
+	}
+	return nil
+}
+
+// addTree adds the directory tree at root to the archive under the name
+// prefix, leaving out the entries in skip (relative, slash-separated)
+func addTree(tw *tar.Writer, root, prefix string, skip map[string]bool) error {
+	return filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		if skip[filepath.ToSlash(rel)] {
+			return nil
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return &config.CopyError{Op: "archive", Path: file, Err: err}
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// Apply extracts a bundle into the trash directory. Original paths under the bundle's
+// home directory are remapped to home. Sessions that already exist locally are imported
+// under the next free session name.
+func Apply(r io.Reader, configDir, home string) (*ApplyResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a trash bundle: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	// The manifest must come first so the layout is known before extracting
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestName {
+		return nil, errors.New("not a trash bundle: missing bundle.json")
+	}
+	result := &ApplyResult{Sessions: make(map[string]string)}
+	if err := json.NewDecoder(tr).Decode(&result.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if result.Manifest.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("bundle schema version %d is newer than supported version %d", result.Manifest.SchemaVersion, SchemaVersion)
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+
+		session, rel, err := splitEntryName(header.Name)
+		if err != nil {
+			return result, err
+		}
+
+		local, ok := result.Sessions[session]
+		if !ok {
+			if local, err = freeSessionName(configDir, session); err != nil {
+				return result, err
+			}
+			result.Sessions[session] = local
+		}
+
+		root := filepath.Join(configDir, local)
+		if err := extractEntry(tr, header, root, filepath.FromSlash(rel)); err != nil {
+			return result, err
+		}
+	}
+
+	// Rewrite metadata for the new machine
+	for _, local := range result.Sessions {
+		trashDir := filepath.Join(configDir, local)
+		metadata, err := config.LoadRestoreMetadata(trashDir)
+		if err != nil {
+			continue
+		}
+		for i := range metadata.Items {
+			metadata.Items[i].OriginalPath = remapHome(metadata.Items[i].OriginalPath, result.Manifest.Home, home)
+			metadata.Items[i].Snapshot = nil // its payload was bundled in its place
+		}
+		metadata.Sequence = 0 // counted by the store the bundle came from
+		if err := config.SaveRestoreMetadata(trashDir, metadata); err != nil {
+			return result, err
+		}
+		result.Items += len(metadata.Items)
+	}
+
+	return result, nil
+}
+
+// splitEntryName validates an archive entry name and splits it into session and relative path
+func splitEntryName(name string) (session, rel string, err error) {
+	clean := path.Clean(strings.TrimSuffix(name, "/"))
+	// Clean resolves any ".." elements, so escaping entries lose the sessions/ prefix
+	if !strings.HasPrefix(clean, sessionsPrefix) || path.IsAbs(clean) {
+		return "", "", fmt.Errorf("unsafe bundle entry: %s", name)
+	}
+
+	session, rel, _ = strings.Cut(strings.TrimPrefix(clean, sessionsPrefix), "/")
+	if session == "" || session == "." {
+		return "", "", fmt.Errorf("unsafe bundle entry: %s", name)
+	}
+
+	return session, rel, nil
+}
+
+// extractEntry writes a single archive entry to rel below the session
+// directory root. Entries are extracted in the order of the archive, so an
+// earlier symlink entry must not lead a later one out of root.
+func extractEntry(tr *tar.Reader, header *tar.Header, root, rel string) error {
+	target := filepath.Join(root, rel)
+	if err := checkNoSymlinks(root, rel); err != nil {
+		return fmt.Errorf("unsafe bundle entry %s: %w", header.Name, err)
+	}
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, mode|0700)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	case tar.TypeSymlink:
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+		return os.Symlink(header.Linkname, target)
+	}
+
+	return &config.CopyError{Op: "extract", Path: header.Name, Err: config.ErrUnsupportedFileType}
+}
+
+// checkNoSymlinks fails if root or any existing component of rel below it is
+// a symlink, which creating or writing root/rel would follow
+func checkNoSymlinks(root, rel string) error {
+	dir := root
+	components := strings.Split(rel, string(filepath.Separator))
+	for i := -1; i < len(components); i++ {
+		if i >= 0 {
+			dir = filepath.Join(dir, components[i])
+		}
+		info, err := os.Lstat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // nothing to follow below it yet
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", dir)
+		}
+	}
+	return nil
+}
+
+// freeSessionName returns session if it is unused locally, otherwise a new session
+// name for the same time, keeping chronological ordering intact
+func freeSessionName(configDir, session string) (string, error) {
+	t, err := config.ParseSessionTime(session)
+	if err != nil {
+		return "", fmt.Errorf("invalid session name in bundle: %s", session)
+	}
+
+	for name := session; ; name = config.NewSessionName(t) {
+		if _, err := os.Lstat(filepath.Join(configDir, name)); os.IsNotExist(err) {
+			return name, nil
+		}
+	}
+}
+
+// remapHome rewrites a path under oldHome to the same location under newHome
+func remapHome(original, oldHome, newHome string) string {
+	if oldHome == "" || newHome == "" || oldHome == newHome {
+		return original
+	}
+	if original == oldHome {
+		return newHome
+	}
+
+	// Paths recorded on Windows use drive letters, backslashes and compare case-insensitively
+	if isWindowsPath(oldHome) {
+		if len(original) > len(oldHome) && strings.EqualFold(original[:len(oldHome)], oldHome) &&
+			(original[len(oldHome)] == '\\' || original[len(oldHome)] == '/') {
+			rest := strings.ReplaceAll(original[len(oldHome)+1:], `\`, "/")
+			return filepath.Join(newHome, filepath.FromSlash(rest))
+		}
+		return original
+	}
+
+	if rest, ok := strings.CutPrefix(original, oldHome+"/"); ok {
+		return filepath.Join(newHome, filepath.FromSlash(rest))
+	}
+	return original
+}
+
+// isWindowsPath reports whether p is an absolute Windows path such as C:\Users
+// or a UNC path, regardless of the platform this runs on
+func isWindowsPath(p string) bool {
+	if strings.HasPrefix(p, `\\`) {
+		return true
+	}
+	return len(p) >= 3 && p[1] == ':' && (p[2] == '\\' || p[2] == '/') &&
+		(p[0] >= 'A' && p[0] <= 'Z' || p[0] >= 'a' && p[0] <= 'z')
+}
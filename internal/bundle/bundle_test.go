@@ -0,0 +1,136 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// entry is an archive entry of a bundle built by a test
+type entry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+// craftBundle returns a bundle holding a manifest and entries, in order
+func craftBundle(t *testing.T, entries []entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.Marshal(Manifest{SchemaVersion: SchemaVersion, Home: "/home/someone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries = append([]entry{{name: manifestName, typeflag: tar.TypeReg, body: string(manifest)}}, entries...)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0644, Size: int64(len(e.body))}
+		if e.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestApplyRejectsEntriesThroughSymlinks(t *testing.T) {
+	const session = "sessions/20250101_120000.000000000-abcd"
+
+	tests := []struct {
+		name    string
+		entries func(victim string) []entry
+	}{
+		{
+			name: "file below a symlinked directory",
+			entries: func(victim string) []entry {
+				return []entry{
+					{name: session + "/0011aabb/", typeflag: tar.TypeDir},
+					{name: session + "/0011aabb/link", typeflag: tar.TypeSymlink, linkname: victim},
+					{name: session + "/0011aabb/link/target.txt", typeflag: tar.TypeReg, body: "overwritten"},
+				}
+			},
+		},
+		{
+			name: "file written over a symlink",
+			entries: func(victim string) []entry {
+				return []entry{
+					{name: session + "/0011aabb/link", typeflag: tar.TypeSymlink, linkname: filepath.Join(victim, "target.txt")},
+					{name: session + "/0011aabb/link", typeflag: tar.TypeReg, body: "overwritten"},
+				}
+			},
+		},
+		{
+			name: "directory below a symlinked directory",
+			entries: func(victim string) []entry {
+				return []entry{
+					{name: session + "/link", typeflag: tar.TypeSymlink, linkname: victim},
+					{name: session + "/link/created/", typeflag: tar.TypeDir},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			victim := t.TempDir()
+			target := filepath.Join(victim, "target.txt")
+			if err := os.WriteFile(target, []byte("original"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			data := craftBundle(t, tt.entries(victim))
+			if _, err := Apply(bytes.NewReader(data), t.TempDir(), "/home/me"); err == nil {
+				t.Fatal("Apply succeeded, want an unsafe entry error")
+			}
+
+			if content, err := os.ReadFile(target); err != nil || string(content) != "original" {
+				t.Errorf("%s = %q, %v; want it untouched", target, content, err)
+			}
+			if _, err := os.Lstat(filepath.Join(victim, "created")); err == nil {
+				t.Errorf("a directory was created in %s", victim)
+			}
+		})
+	}
+}
+
+func TestApplyExtractsSymlinkPayloads(t *testing.T) {
+	const session = "sessions/20250101_120000.000000000-abcd"
+	data := craftBundle(t, []entry{
+		{name: session + "/0011aabb/", typeflag: tar.TypeDir},
+		{name: session + "/0011aabb/dir/", typeflag: tar.TypeDir},
+		{name: session + "/0011aabb/dir/file.txt", typeflag: tar.TypeReg, body: "content"},
+		{name: session + "/0011aabb/dir/link", typeflag: tar.TypeSymlink, linkname: "/etc"},
+	})
+
+	configDir := t.TempDir()
+	result, err := Apply(bytes.NewReader(data), configDir, "/home/me")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(configDir, result.Sessions["20250101_120000.000000000-abcd"], "0011aabb", "dir")
+	if content, err := os.ReadFile(filepath.Join(dir, "file.txt")); err != nil || string(content) != "content" {
+		t.Errorf("file.txt = %q, %v; want %q", content, err, "content")
+	}
+	if link, err := os.Readlink(filepath.Join(dir, "link")); err != nil || link != "/etc" {
+		t.Errorf("link points at %q, %v; want /etc", link, err)
+	}
+}
@@ -0,0 +1,39 @@
+// Package bus integrates the trash with the D-Bus session bus on Linux desktops
+package bus
+
+// ServiceName is the well-known name owned by `trash serve --dbus`; it is also
+// the interface of the exported object and of the Changed signal
+const ServiceName = "io.github.artemisfowl.Trash1"
+
+// ObjectPath is where the trash object is exported and signals are emitted from
+const ObjectPath = "/io/github/artemisfowl/Trash1"
+
+// Reasons passed with the Changed signal
+const (
+	ReasonTrashed  = "trashed"
+	ReasonRestored = "restored"
+	ReasonPurged   = "purged"
+)
+
+// introspection describes the exported object for D-Bus tooling such as busctl
+const introspection = `<node>
+  <interface name="` + ServiceName + `">
+    <method name="Trash">
+      <arg name="paths" type="as" direction="in"/>
+      <arg name="session" type="s" direction="out"/>
+      <arg name="failed" type="as" direction="out"/>
+    </method>
+    <method name="Count">
+      <arg name="items" type="u" direction="out"/>
+    </method>
+    <signal name="Changed">
+      <arg name="reason" type="s"/>
+      <arg name="items" type="u"/>
+    </signal>
+  </interface>
+  <interface name="org.freedesktop.DBus.Introspectable">
+    <method name="Introspect">
+      <arg name="data" type="s" direction="out"/>
+    </method>
+  </interface>
+</node>`
@@ -0,0 +1,124 @@
+//go:build linux
+
+package bus
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// fileManagerName is the freedesktop file manager interface that desktop
+// shells use to reveal files
+const fileManagerName = "org.freedesktop.FileManager1"
+
+// Available reports whether a session bus can be reached
+func Available() bool {
+	return os.Getenv("DBUS_SESSION_BUS_ADDRESS") != ""
+}
+
+// EmitChanged broadcasts the Changed signal so trash indicators can refresh.
+// It does nothing when no session bus is available.
+func EmitChanged(reason string) error {
+	if !Available() {
+		return nil
+	}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("connecting to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Emit(ObjectPath, ServiceName+".Changed", reason, itemCount())
+}
+
+// ShowItems asks the desktop file manager to reveal paths, e.g. an item inside the trash
+func ShowItems(paths []string) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("connecting to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	uris := make([]string, len(paths))
+	for i, path := range paths {
+		uris[i] = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+
+	obj := conn.Object(fileManagerName, "/org/freedesktop/FileManager1")
+	return obj.Call(fileManagerName+".ShowItems", 0, uris, "").Err
+}
+
+// service is the object exported on the session bus
+type service struct {
+	conn *dbus.Conn
+}
+
+// Trash moves paths into a new trash session on behalf of a desktop
+// application, with the checks of the command line
+func (s *service) Trash(paths []string) (string, []string, *dbus.Error) {
+	failed := []string{}
+	trashDir, results, err := config.Trash(paths, config.TrashOptions{}, nil)
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result.Path)
+		}
+	}
+	if err != nil {
+		return "", failed, dbus.MakeFailedError(err)
+	}
+
+	if len(failed) < len(paths) {
+		s.conn.Emit(ObjectPath, ServiceName+".Changed", ReasonTrashed, itemCount())
+	}
+	return filepath.Base(trashDir), failed, nil
+}
+
+// Count returns the number of items in the trash
+func (s *service) Count() (uint32, *dbus.Error) {
+	return itemCount(), nil
+}
+
+// Serve claims ServiceName on the session bus and answers requests until done is closed
+func Serve(done <-chan struct{}) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("connecting to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	svc := &service{conn: conn}
+	if err := conn.Export(svc, ObjectPath, ServiceName); err != nil {
+		return err
+	}
+	if err := conn.Export(introspect.Introspectable(introspection), ObjectPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	reply, err := conn.RequestName(ServiceName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("%s is already owned on the session bus", ServiceName)
+	}
+
+	<-done
+	return nil
+}
+
+// itemCount returns the number of trashed items, 0 if the trash cannot be read
+func itemCount() uint32 {
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return 0
+	}
+	return uint32(len(items))
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package bus
+
+import "errors"
+
+// errUnsupported is returned where D-Bus is not available
+var errUnsupported = errors.New("D-Bus integration is only supported on Linux")
+
+// Available reports whether a session bus can be reached
+func Available() bool {
+	return false
+}
+
+// EmitChanged does nothing without D-Bus
+func EmitChanged(reason string) error {
+	return nil
+}
+
+// ShowItems is not supported without D-Bus
+func ShowItems(paths []string) error {
+	return errUnsupported
+}
+
+// Serve is not supported without D-Bus
+func Serve(done <-chan struct{}) error {
+	return errUnsupported
+}
@@ -0,0 +1,26 @@
+//go:build !linux && !darwin && !freebsd
+
+package config
+
+import "os"
+
+// checkWritable is not supported on this platform; problems surface when
+// the move is made
+func checkWritable(dir string) error {
+	return nil
+}
+
+// checkRemovable is not supported on this platform
+func checkRemovable(path string) error {
+	return nil
+}
+
+// ownedByOther is not supported on this platform
+func ownedByOther(info os.FileInfo) bool {
+	return false
+}
+
+// sameOwner is not supported on this platform
+func sameOwner(a, b os.FileInfo) bool {
+	return true
+}
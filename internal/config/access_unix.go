@@ -0,0 +1,59 @@
+//go:build linux || darwin || freebsd
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkWritable reports why no entries could be created in or removed from dir
+func checkWritable(dir string) error {
+	if err := unix.Access(dir, unix.W_OK|unix.X_OK); err != nil {
+		return &os.PathError{Op: "write to", Path: dir, Err: err}
+	}
+	return nil
+}
+
+// checkRemovable reports why path could not be moved out of its directory
+func checkRemovable(path string) error {
+	dir := filepath.Dir(path)
+	if err := checkWritable(dir); err != nil {
+		return err
+	}
+
+	// In sticky directories such as /tmp only the owners of an entry or the
+	// directory may remove it
+	dirInfo, err := os.Stat(dir)
+	if err != nil || dirInfo.Mode()&os.ModeSticky == 0 {
+		return nil
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+	uid := uint32(os.Geteuid())
+	dirStat, ok1 := dirInfo.Sys().(*syscall.Stat_t)
+	stat, ok2 := info.Sys().(*syscall.Stat_t)
+	if !ok1 || !ok2 || uid == 0 || stat.Uid == uid || dirStat.Uid == uid {
+		return nil
+	}
+	return &os.PathError{Op: "remove", Path: path, Err: syscall.EPERM}
+}
+
+// ownedByOther reports whether info belongs to a user other than the one
+// running trash, as happens after running it once with sudo
+func ownedByOther(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Uid != uint32(os.Geteuid())
+}
+
+// sameOwner reports whether a and b belong to the same user and group
+func sameOwner(a, b os.FileInfo) bool {
+	statA, ok1 := a.Sys().(*syscall.Stat_t)
+	statB, ok2 := b.Sys().(*syscall.Stat_t)
+	return ok1 && ok2 && statA.Uid == statB.Uid && statA.Gid == statB.Gid
+}
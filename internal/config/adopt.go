@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// AdoptSession records metadata for the entries of a session that its metadata
+// does not list, or for all of them when the session has no .restore file, so
+// that list, restore and empty can manage them. With names, only those entries
+// are adopted. Their original location is unknown and their trash time is now.
+// Sessions with unusable metadata are refused rather than overwritten.
+func AdoptSession(session string, names ...string) ([]RestoreItem, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	trashDir := filepath.Join(configDir, session)
+
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	metadata := &RestoreMetadata{Items: []RestoreItem{}}
+	if _, err := storeFS.Lstat(filepath.Join(trashDir, ".restore")); err == nil {
+		if metadata, err = LoadRestoreMetadata(trashDir); err != nil {
+			return nil, err
+		}
+	}
+
+	listed := make(map[string]bool, len(metadata.Items))
+	for _, item := range metadata.Items {
+		listed[item.Entry()] = true
+	}
+
+	entries, err := storeFS.ReadDir(trashDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash session %s: %w", session, err)
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var adopted []RestoreItem
+	for _, entry := range entries {
+		if listed[entry.Name()] || isSessionFile(entry.Name()) {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[entry.Name()] {
+			continue
+		}
+		adopted = append(adopted, adoptedEntry(trashDir, entry))
+	}
+	if len(adopted) == 0 {
+		return nil, nil
+	}
+
+	metadata.Items = append(metadata.Items, adopted...)
+	return adopted, SaveRestoreMetadata(trashDir, metadata)
+}
+
+// AdoptStrayFile moves a file lying directly in the trash directory, outside
+// any session, into a new session and records metadata for it
+func AdoptStrayFile(path string) (string, RestoreItem, error) {
+	trashDir, err := CreateTrashTimestampDir()
+	if err != nil {
+		return "", RestoreItem{}, err
+	}
+
+	id, err := newItemDir(trashDir)
+	if err != nil {
+		storeFS.Remove(trashDir)
+		return "", RestoreItem{}, err
+	}
+	destPath := filepath.Join(trashDir, id, filepath.Base(path))
+	if err := storeFS.Rename(path, destPath); err != nil {
+		storeFS.RemoveAll(trashDir)
+		return "", RestoreItem{}, fmt.Errorf("failed to move %s into a session: %w", path, err)
+	}
+
+	item := adoptedItem(destPath)
+	item.StoragePath = id + "/" + item.Name
+	if err := SaveRestoreMetadata(trashDir, &RestoreMetadata{Items: []RestoreItem{item}}); err != nil {
+		return trashDir, item, err
+	}
+	return trashDir, item, nil
+}
+
+// adoptedEntry describes an unlisted entry of a session directory: an item
+// directory holding a single payload, or a payload lying in the session itself
+func adoptedEntry(trashDir string, entry fs.DirEntry) RestoreItem {
+	entryPath := filepath.Join(trashDir, entry.Name())
+	if entry.IsDir() && IsItemID(entry.Name()) {
+		if children, err := storeFS.ReadDir(entryPath); err == nil && len(children) == 1 {
+			item := adoptedItem(filepath.Join(entryPath, children[0].Name()))
+			item.StoragePath = entry.Name() + "/" + item.Name
+			return item
+		}
+	}
+	return adoptedItem(entryPath)
+}
+
+// adoptedItem describes a payload found in the trash: its original location is
+// unknown, so it can only be restored somewhere else
+func adoptedItem(payloadPath string) RestoreItem {
+	kind, mimeType := DetectFileType(payloadPath)
+	size, _ := treeSize(storeFS, payloadPath)
+	return RestoreItem{
+		Name:      filepath.Base(payloadPath),
+		TrashedAt: time.Now().Format(time.RFC3339),
+		Type:      kind,
+		MIMEType:  mimeType,
+		Size:      &size,
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// limitChunk caps a single read under a bandwidth limit so that the pauses
+// between reads stay short and the rate stays smooth
+const limitChunk = 32 * 1024
+
+// tokenBucket paces I/O to a number of bytes per second, allowing bursts of up
+// to a quarter second's worth
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// copyLimit throttles every copy made by the cross-device fallback, nil for no limit
+var copyLimit *tokenBucket
+
+// SetBandwidthLimit limits the cross-device copies of trash and restore to
+// bytesPerSecond, like rsync's --bwlimit. 0 removes the limit.
+func SetBandwidthLimit(bytesPerSecond uint64) {
+	if bytesPerSecond == 0 {
+		copyLimit = nil
+		return
+	}
+
+	rate := float64(bytesPerSecond)
+	copyLimit = &tokenBucket{rate: rate, burst: max(rate/4, limitChunk), last: time.Now()}
+	copyLimit.tokens = copyLimit.burst
+}
+
+// take consumes n bytes, sleeping until the bucket has refilled enough
+func (b *tokenBucket) take(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	// Going into debt and sleeping it off keeps the average at the rate
+	b.tokens -= float64(n)
+	if b.tokens < 0 {
+		time.Sleep(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+}
+
+// limitedReader reads through a token bucket
+type limitedReader struct {
+	r      io.Reader
+	bucket *tokenBucket
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > limitChunk {
+		p = p[:limitChunk]
+	}
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.bucket.take(n)
+	}
+	return n, err
+}
+
+// limitReader applies the bandwidth limit to r. Without a limit r is returned
+// unchanged so that copies keep using copy_file_range and similar fast paths.
+func limitReader(r io.Reader) io.Reader {
+	if copyLimit == nil {
+		return r
+	}
+	return &limitedReader{r: r, bucket: copyLimit}
+}
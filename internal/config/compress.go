@@ -0,0 +1,330 @@
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArchiveFileName is the compressed tar archive in which CompressSessions packs
+// the payloads of a session. Their metadata stays as it is, so list and the
+// other commands reading it see no difference; restoring extracts them.
+const ArchiveFileName = "payloads.tar.gz"
+
+// CompressedSession reports the compression of one session
+type CompressedSession struct {
+	Session string
+	Before  uint64 // the size of its payloads
+	After   uint64 // the size of the archive
+	Err     error
+}
+
+// CompressSessions packs the payloads of every session whose items were all
+// trashed before cutoff into an archive in the session directory. Sessions
+// already compressed, from older versions (see 'trash migrate') or with
+// metadata that is unusable or fails its signature check are left as they are.
+func CompressSessions(cutoff time.Time) ([]CompressedSession, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed []CompressedSession
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+		if _, err := storeFS.Lstat(filepath.Join(trashDir, ArchiveFileName)); err == nil {
+			continue
+		}
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err != nil || VerifyRestoreMetadata(trashDir) != nil || len(metadata.Items) == 0 {
+			continue
+		}
+		if !trashedBefore(session, metadata, cutoff) {
+			continue
+		}
+
+		result := CompressedSession{Session: session}
+		for _, item := range metadata.Items {
+			if item.StoragePath == "" {
+				result.Err = fmt.Errorf("session %s was created by an older version; run 'trash migrate' first", session)
+				break
+			}
+		}
+		if result.Err == nil {
+			result.Before, result.After, result.Err = compressSession(trashDir, metadata)
+		}
+		compressed = append(compressed, result)
+	}
+	return compressed, nil
+}
+
+// trashedBefore reports whether every item of a session was trashed before cutoff
+func trashedBefore(session string, metadata *RestoreMetadata, cutoff time.Time) bool {
+	for _, item := range metadata.Items {
+		if !(TrashedItem{Session: session, Item: item}).TrashedTime().Before(cutoff) {
+			return false
+		}
+	}
+	return true
+}
+
+// compressSession packs the payloads of a session into its archive, then
+// removes them, returning their size and the size of the archive. The archive
+// is written under a temporary name, so an interrupted run leaves the payloads
+// in place.
+func compressSession(trashDir string, metadata *RestoreMetadata) (before, after uint64, err error) {
+	archivePath := filepath.Join(trashDir, ArchiveFileName)
+	tmpPath := archivePath + ".tmp"
+
+	_, fileMode := storeModes()
+	file, err := storeFS.Create(tmpPath, fileMode)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	measured := false
+	for i, item := range metadata.Items {
+		if item.Snapshot != nil {
+			continue // kept in its snapshot
+		}
+		payload := filepath.Join(trashDir, item.Storage())
+		if _, err := storeFS.Lstat(payload); errors.Is(err, fs.ErrNotExist) {
+			continue // nothing to keep; doctor reports it
+		}
+		size, err := archiveTree(tw, payload, item.StoragePath)
+		if err != nil {
+			file.Close()
+			storeFS.Remove(tmpPath)
+			return 0, 0, fmt.Errorf("failed to archive %s: %w", item.Name, err)
+		}
+		if item.Size == nil {
+			metadata.Items[i].Size = &size
+			measured = true
+		}
+		before += size
+	}
+
+	err = tw.Close()
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = storeFS.Rename(tmpPath, archivePath)
+	}
+	if err != nil {
+		storeFS.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to write %s: %w", archivePath, err)
+	}
+	if info, err := storeFS.Lstat(archivePath); err == nil {
+		after = uint64(info.Size())
+	}
+
+	// Sizes can no longer be measured once the payloads are packed
+	if measured {
+		if err := SaveRestoreMetadata(trashDir, metadata); err != nil {
+			return before, after, err
+		}
+	}
+	for _, item := range metadata.Items {
+		if item.Snapshot != nil {
+			continue
+		}
+		if err := storeFS.RemoveAll(filepath.Join(trashDir, item.Entry())); err != nil {
+			return before, after, fmt.Errorf("failed to remove %s after archiving it: %w", item.Name, err)
+		}
+	}
+	return before, after, nil
+}
+
+// archiveTree adds the payload tree at root to tw under name, returning the
+// bytes of its files
+func archiveTree(tw *tar.Writer, root, name string) (uint64, error) {
+	info, err := storeFS.Lstat(root)
+	if err != nil {
+		return 0, err
+	}
+
+	link := ""
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if link, err = storeFS.Readlink(root); err != nil {
+			return 0, err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return 0, &CopyError{Op: "archive", Path: root, Err: err}
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case info.IsDir():
+		entries, err := storeFS.ReadDir(root)
+		if err != nil {
+			return 0, err
+		}
+		var size uint64
+		for _, entry := range entries {
+			n, err := archiveTree(tw, filepath.Join(root, entry.Name()), path.Join(name, entry.Name()))
+			if err != nil {
+				return 0, err
+			}
+			size += n
+		}
+		return size, nil
+	case info.Mode().IsRegular():
+		file, err := storeFS.Open(root)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		n, err := io.Copy(tw, file)
+		return uint64(n), err
+	}
+	return 0, nil
+}
+
+// IsArchived reports whether the payload of item is packed in the archive of
+// its session trashDir rather than lying in the session
+func IsArchived(trashDir string, item RestoreItem) bool {
+	if item.Snapshot != nil {
+		return false
+	}
+	if _, err := storeFS.Lstat(filepath.Join(trashDir, item.Storage())); err == nil {
+		return false
+	}
+	_, err := storeFS.Lstat(filepath.Join(trashDir, ArchiveFileName))
+	return err == nil
+}
+
+// Archived reports whether the item's payload is packed in its session's archive
+func (e TrashedItem) Archived() bool {
+	trashDir, err := e.SessionDir()
+	return err == nil && IsArchived(trashDir, e.Item)
+}
+
+// ExtractArchived writes the payload of item from the archive of its session
+// trashDir to destPath, which must not exist, with the permissions and
+// modification times it was trashed with
+func ExtractArchived(trashDir string, item RestoreItem, destPath string) error {
+	file, err := storeFS.Open(filepath.Join(trashDir, ArchiveFileName))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read the archive of %s: %w", filepath.Base(trashDir), err)
+	}
+	tr := tar.NewReader(gz)
+
+	prefix := item.StoragePath
+	found := false
+	var dirs []*tar.Header
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			os.RemoveAll(destPath)
+			return fmt.Errorf("failed to read the archive of %s: %w", filepath.Base(trashDir), err)
+		}
+
+		name := strings.TrimSuffix(header.Name, "/")
+		if name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, prefix), "/")
+		if rel != "" && !filepath.IsLocal(rel) {
+			continue
+		}
+		target := filepath.Join(destPath, filepath.FromSlash(rel))
+		found = true
+
+		if err := extractArchiveEntry(tr, header, target); err != nil {
+			os.RemoveAll(destPath)
+			return &CopyError{Op: "extract", Path: target, Err: err}
+		}
+		if header.Typeflag == tar.TypeDir {
+			dirs = append(dirs, header)
+		}
+	}
+	if !found {
+		return withKind(ErrNotFound, fmt.Errorf("%s is missing from the archive of session %s", item.Name, filepath.Base(trashDir)))
+	}
+
+	// Directories are created writable and their times change while their
+	// entries are created, so their modes and times are set last, deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSuffix(dirs[i].Name, "/"), prefix), "/")
+		dir := filepath.Join(destPath, filepath.FromSlash(rel))
+		if err := os.Chmod(dir, os.FileMode(dirs[i].Mode).Perm()); err != nil {
+			return &CopyError{Op: "set mode", Path: dir, Err: err}
+		}
+		os.Chtimes(dir, dirs[i].ModTime, dirs[i].ModTime)
+	}
+	return nil
+}
+
+// extractArchiveEntry creates target from one archive entry
+func extractArchiveEntry(tr *tar.Reader, header *tar.Header, target string) error {
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.Mkdir(target, mode|0700)
+	case tar.TypeReg:
+		file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, tr); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(target, header.ModTime, header.ModTime)
+	case tar.TypeSymlink:
+		return os.Symlink(header.Linkname, target)
+	case tar.TypeFifo, tar.TypeChar, tar.TypeBlock:
+		// Like a copy across filesystems, a node that cannot be recreated here
+		// (a device node without root) is skipped rather than failing the restore
+		if err := extractSpecial(header, target); err != nil {
+			warnSkipped(&CopyError{Op: "skipped special file", Path: target, Err: err})
+		}
+		return nil
+	}
+	return ErrUnsupportedFileType
+}
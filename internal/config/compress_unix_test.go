@@ -0,0 +1,55 @@
+//go:build linux || darwin || freebsd
+
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractArchivedSpecialFilesAndModes(t *testing.T) {
+	trashDir := t.TempDir()
+	file, err := os.Create(filepath.Join(trashDir, ArchiveFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for _, header := range []*tar.Header{
+		{Name: "0011aabb/tree/", Typeflag: tar.TypeDir, Mode: 0555},
+		{Name: "0011aabb/tree/pipe", Typeflag: tar.TypeFifo, Mode: 0640},
+		{Name: "0011aabb/tree/sub/", Typeflag: tar.TypeDir, Mode: 0750},
+		{Name: "0011aabb/tree/sub/file.txt", Typeflag: tar.TypeReg, Mode: 0644},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, closer := range []interface{ Close() error }{tw, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest := filepath.Join(t.TempDir(), "tree")
+	t.Cleanup(func() { os.Chmod(dest, 0700) })
+	item := RestoreItem{Name: "tree", StoragePath: "0011aabb/tree"}
+	if err := ExtractArchived(trashDir, item, dest); err != nil {
+		t.Fatalf("ExtractArchived() = %v", err)
+	}
+
+	if info, err := os.Lstat(filepath.Join(dest, "pipe")); err != nil || info.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("pipe = %v, %v; want a FIFO", info, err)
+	}
+	for rel, want := range map[string]os.FileMode{".": 0555, "sub": 0750} {
+		info, err := os.Stat(filepath.Join(dest, rel))
+		if err != nil {
+			t.Errorf("%s: %v", rel, err)
+		} else if info.Mode().Perm() != want {
+			t.Errorf("mode of %s = %v, want %v", rel, info.Mode().Perm(), want)
+		}
+	}
+}
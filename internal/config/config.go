@@ -1,34 +1,300 @@
 package config
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/artemisfowl/trash/internal/i18n"
 )
 
 // RestoreItem represents metadata for a single trashed item
 type RestoreItem struct {
+	ID           string `json:"id,omitempty"` // see ShortID
 	Name         string `json:"name"`
+	StoragePath  string `json:"storage_path,omitempty"`
 	OriginalPath string `json:"original_path"`
 	TrashedAt    string `json:"trashed_at"`
+	ExpiresAt    string `json:"expires_at,omitempty"`
+	User         string `json:"user,omitempty"`
+	UID          string `json:"uid,omitempty"`
+	Hostname     string `json:"hostname,omitempty"`
+	Type         string `json:"type,omitempty"`
+	MIMEType     string `json:"mime_type,omitempty"`
+
+	// Retained makes ExpiresAt a retention override set with --keep-for or
+	// retain: pruning and emptying the trash leave the item alone until it expires
+	Retained bool `json:"retained,omitempty"`
+
+	// Size is the payload size in bytes recorded when it was trashed, so that
+	// listings and purges need not walk it; nil for items from older versions
+	Size *uint64 `json:"size,omitempty"`
+
+	// Device and Inode identify the original file, and Links is its number of
+	// hard links when it was trashed; more than one means other links to the
+	// same data remained outside the trash
+	Device uint64 `json:"device,omitempty"`
+	Inode  uint64 `json:"inode,omitempty"`
+	Links  uint64 `json:"links,omitempty"`
+
+	// Checksum is "sha256:" and the hex digest of the payload's content (see
+	// payloadChecksum), stored once it has been computed to find duplicates
+	// or by verify
+	Checksum string `json:"checksum,omitempty"`
+
+	// VerifiedAt is when verify last checked the payload against Checksum (RFC 3339)
+	VerifiedAt string `json:"verified_at,omitempty"`
+
+	// Linked means dedupe made files of the payload hard links shared with
+	// other payloads, so restoring it copies them rather than moving them out
+	Linked bool `json:"linked,omitempty"`
+
+	// Snapshot locates the payload when it was left in a snapshot of the
+	// filesystem it was trashed from rather than moved into the session
+	Snapshot *SnapshotRef `json:"snapshot,omitempty"`
+}
+
+// Storage returns the location of the item's payload relative to its session
+// directory: <item-id>/<name>, or just the name for items trashed before
+// payloads got a directory of their own
+func (item RestoreItem) Storage() string {
+	if item.StoragePath == "" {
+		return item.Name
+	}
+	return filepath.FromSlash(item.StoragePath)
+}
+
+// Entry returns the entry of the session directory that holds the item: its
+// item directory, or the payload itself for items without one
+func (item RestoreItem) Entry() string {
+	if id, _, ok := strings.Cut(item.StoragePath, "/"); ok {
+		return id
+	}
+	return item.Name
+}
+
+// ShortID returns the short ID addressing the item unambiguously where its
+// name may not: six base32 characters of a hash of where and when it was
+// trashed. Items from older versions, which have none recorded, get the same
+// ID every time.
+func (item RestoreItem) ShortID() string {
+	if item.ID != "" {
+		return item.ID
+	}
+	sum := sha256.Sum256([]byte(item.StoragePath + "\x00" + item.Name + "\x00" + item.OriginalPath + "\x00" + item.TrashedAt))
+	return strings.ToLower(base32.StdEncoding.EncodeToString(sum[:]))[:6]
+}
+
+// IsExpired reports whether the item carries an expiry that has passed
+func (item RestoreItem) IsExpired(now time.Time) bool {
+	if item.ExpiresAt == "" {
+		return false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt)
+	if err != nil {
+		return false
+	}
+
+	return !now.Before(expiresAt)
+}
+
+// IsRetained reports whether a retention override still keeps the item
+func (item RestoreItem) IsRetained(now time.Time) bool {
+	return item.Retained && !item.IsExpired(now)
+}
+
+// Validate rejects an item that could make restore or purge act outside the
+// trash session or restore somewhere unexpected. .restore files are plain JSON
+// that anything can edit, so the name must be a single path element, the
+// storage path must be <item-id>/<name>, the original path must be absolute
+// and clean, or empty when it is unknown (see AdoptSession), and a snapshot
+// must be one trash takes (see SnapshotRef.Validate).
+func (item RestoreItem) Validate() error {
+	id, name, _ := strings.Cut(item.StoragePath, "/")
+	if item.Snapshot != nil {
+		if err := item.Snapshot.Validate(); err != nil {
+			return fmt.Errorf("invalid snapshot for %s: %w", item.Name, err)
+		}
+	}
+	switch {
+	case item.Name == "", item.Name == ".", item.Name == "..",
+		filepath.Base(item.Name) != item.Name, strings.ContainsRune(item.Name, 0):
+		return fmt.Errorf("invalid item name %q", item.Name)
+	case item.StoragePath != "" && (!IsItemID(id) || name != item.Name):
+		return fmt.Errorf("invalid storage path %q for %s", item.StoragePath, item.Name)
+	case item.OriginalPath == "":
+		return nil
+	case !filepath.IsAbs(item.OriginalPath), filepath.Clean(item.OriginalPath) != item.OriginalPath,
+		strings.ContainsRune(item.OriginalPath, 0):
+		return fmt.Errorf("invalid original path %q for %s: not a clean absolute path", item.OriginalPath, item.Name)
+	}
+	return nil
 }
 
 // RestoreMetadata represents the .restore file structure
 type RestoreMetadata struct {
-	Items []RestoreItem `json:"items"`
+	Version int           `json:"version,omitempty"` // see MetadataVersion
+	Items   []RestoreItem `json:"items"`
+
+	// CreatedAt is when the session was created, RFC 3339 in UTC. Session names
+	// carry the local time without a zone, which daylight saving changes and
+	// travel can reorder; this is what sessions are ordered by (see SortSessions).
+	CreatedAt string `json:"created_at,omitempty"`
+
+	// Sequence is the value of the store's operation counter when the session
+	// was created. It orders the sessions of this store even when the clock
+	// jumps; sessions without one are older or come from elsewhere.
+	Sequence uint64 `json:"sequence,omitempty"`
+}
+
+// Validate checks every item, see RestoreItem.Validate
+func (m *RestoreMetadata) Validate() error {
+	for _, item := range m.Items {
+		if err := item.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SessionTimeFormat is the layout of the timestamp that starts every trash directory name.
+// Sessions created by older versions consist of this timestamp alone.
+const SessionTimeFormat = "20060102_150405"
+
+// sessionNameFormat adds nanoseconds to SessionTimeFormat; a random suffix follows
+// so that concurrent invocations never share a session. Names still sort chronologically.
+const sessionNameFormat = SessionTimeFormat + ".000000000"
+
+// NewSessionName returns a unique session name for a session created at t,
+// e.g. 20251217_010006.123456789-3f2a
+func NewSessionName(t time.Time) string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return t.Format(sessionNameFormat) + "-" + hex.EncodeToString(suffix)
+}
+
+// ParseSessionTime returns the creation time encoded in a trash session name
+func ParseSessionTime(session string) (time.Time, error) {
+	timestamp, _, _ := strings.Cut(session, "-")
+	t, err := time.ParseInLocation(SessionTimeFormat, timestamp, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid session name %q", session)
+	}
+	return t, nil
+}
+
+// sessionPrefix matches the beginnings of session names
+var sessionPrefix = regexp.MustCompile(`^\d{1,8}(_\d{0,6}(\.\d{0,9}(-[0-9a-f]*)?)?)?$`)
+
+// SessionMatcher returns a function reporting whether a session is one ref
+// refers to. ref is the beginning of session names, such as 20251217_010006,
+// or a time understood by ParseTimeRange, which refers to the sessions created
+// in that period: "yesterday" or "2025-01-15" to the sessions of that day,
+// "2h" or "last week" to those created since then.
+func SessionMatcher(ref string, now time.Time) func(session string) bool {
+	prefix := func(session string) bool { return strings.HasPrefix(session, ref) }
+	if sessionPrefix.MatchString(ref) {
+		return prefix
+	}
+	start, end, err := ParseTimeRange(ref, now)
+	if err != nil {
+		return prefix
+	}
+	return func(session string) bool {
+		t, err := SessionTime(session)
+		if err != nil {
+			return prefix(session)
+		}
+		return !t.Before(start) && t.Before(end)
+	}
+}
+
+// ResolveSession returns the session named ref, or the only session whose name
+// starts with ref, so that e.g. the timestamp shown to the second is enough
+func ResolveSession(ref string) (string, error) {
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, session := range sessions {
+		if session == ref {
+			return session, nil
+		}
+		if strings.HasPrefix(session, ref) {
+			matches = append(matches, session)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", withKind(ErrNotFound, fmt.Errorf("no trash session %s", ref))
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("session %s is ambiguous, it matches %d sessions", ref, len(matches))
+}
+
+// TrashedItem pairs a trashed item with the session it belongs to
+type TrashedItem struct {
+	Session string
+	Item    RestoreItem
+	Root    Root // the trash directory the item was found in, set by ListAllTrashedItems
+}
+
+// TrashedTime returns when the item was trashed, or when its session was
+// created if the metadata does not say
+func (e TrashedItem) TrashedTime() time.Time {
+	if t, err := time.Parse(time.RFC3339, e.Item.TrashedAt); err == nil {
+		return t
+	}
+	t, _ := SessionTime(e.Session)
+	return t
+}
+
+// MaxSessionSpan is the longest time between the creation of a session and
+// the last item trashed into it, set by the day session window
+const MaxSessionSpan = 24 * time.Hour
+
+// Path returns the location of the item's payload inside the trash
+func (e TrashedItem) Path() (string, error) {
+	if e.Root.Dir != "" {
+		return e.Item.PayloadPath(filepath.Join(e.Root.Dir, e.Session)), nil
+	}
+	return ItemPath(e.Session, e.Item)
+}
+
+// SessionDir returns the directory of the item's session inside the trash
+func (e TrashedItem) SessionDir() (string, error) {
+	if e.Root.Dir != "" {
+		return filepath.Join(e.Root.Dir, e.Session), nil
+	}
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, e.Session), nil
 }
 
 // GetConfigDir returns the path to the trash config directory
 func GetConfigDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	if storeDir != "" {
+		return storeDir, nil
 	}
-	
-	configDir := filepath.Join(homeDir, ".config", "trash")
-	return configDir, nil
+
+	return homeStoreDir()
 }
 
 // EnsureConfigDir ensures the trash config directory exists
@@ -40,12 +306,18 @@ func EnsureConfigDir() error {
 	}
 	
 	// Check if directory exists
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		// Create directory with appropriate permissions (0755)
-		if err := os.MkdirAll(configDir, 0755); err != nil {
+	if _, err := storeFS.Lstat(configDir); errors.Is(err, fs.ErrNotExist) {
+		// Create directory with the permissions of dir_mode (0700 by default)
+		dirMode, fileMode := storeModes()
+		if err := mkdirStore(configDir, dirMode); err != nil {
 			return fmt.Errorf("failed to create config directory: %w", err)
 		}
-		fmt.Printf("Created config directory: %s\n", configDir)
+		// Keep a project's local trash out of version control
+		if storeDir != "" {
+			WriteFile(storeFS, filepath.Join(configDir, ".gitignore"), []byte("*\n"), fileMode)
+		}
+		// On stderr, so the first run does not corrupt --print or structured output
+		i18n.Fprintf(os.Stderr, "Created config directory: %s\n", configDir)
 	}
 	
 	return nil
@@ -59,73 +331,142 @@ func CreateTrashTimestampDir() (string, error) {
 		return "", err
 	}
 	
-	// Create timestamp in format YYYYMMDD_HHMMSS
-	timestamp := time.Now().Format("20060102_150405")
-	trashDir := filepath.Join(configDir, timestamp)
-	
-	// Create the timestamped directory
-	if err := os.MkdirAll(trashDir, 0755); err != nil {
+	dirMode, _ := storeModes()
+	if err := mkdirStore(configDir, dirMode); err != nil {
 		return "", fmt.Errorf("failed to create trash directory: %w", err)
 	}
-	
-	return trashDir, nil
+
+	// Mkdir fails if the name is taken, so a session is never shared
+	for {
+		trashDir := filepath.Join(configDir, NewSessionName(time.Now()))
+		err := storeFS.Mkdir(trashDir, dirMode)
+		if err == nil {
+			return trashDir, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+}
+
+// itemIDLength is the number of hex digits in an item ID
+const itemIDLength = 8
+
+// IsItemID reports whether name has the form of the item directories that hold
+// payloads inside a session
+func IsItemID(name string) bool {
+	if len(name) != itemIDLength {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil && strings.ToLower(name) == name
 }
 
-// MoveToTrash moves a file or directory to the specified trash directory
-// Returns the basename of the moved item for metadata tracking
+// newItemDir creates a directory with a new item ID in a session and returns the ID
+func newItemDir(trashDir string) (string, error) {
+	dirMode, _ := storeModes()
+	for {
+		random := make([]byte, itemIDLength/2)
+		if _, err := rand.Read(random); err != nil {
+			return "", fmt.Errorf("failed to create item directory: %w", err)
+		}
+		id := hex.EncodeToString(random)
+
+		// Mkdir fails if the ID is taken, so items never share a directory
+		err := storeFS.Mkdir(filepath.Join(trashDir, id), dirMode)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create item directory: %w", err)
+		}
+	}
+}
+
+// MoveToTrash moves a file or directory into an item directory of its own in
+// the specified trash directory. Returns the storage path of the moved item,
+// <item-id>/<basename>, for metadata tracking.
 func MoveToTrash(sourcePath, trashDir string) (string, error) {
+	storagePath, _, err := moveToTrash(sourcePath, trashDir)
+	return storagePath, err
+}
+
+// moveToTrash is MoveToTrash, also reporting whether the cross-device copy
+// fallback was used instead of a rename
+func moveToTrash(sourcePath, trashDir string) (storagePath string, copied bool, err error) {
 	// Get absolute path
 	absPath, err := filepath.Abs(sourcePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path: %w", err)
+		return "", false, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 	
-	// Check if source exists
-	sourceInfo, err := os.Stat(absPath)
+	// Check if source exists; a symlink is trashed itself, never its target
+	sourceInfo, err := os.Lstat(absPath)
 	if os.IsNotExist(err) {
-		return "", fmt.Errorf("path does not exist: %s", absPath)
+		return "", false, withKind(ErrNotFound, fmt.Errorf("path does not exist: %s", absPath))
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to stat source: %w", err)
+		return "", false, fmt.Errorf("failed to stat source: %w", err)
 	}
 	
-	// Get the base name of the file/directory
+	// Every item gets a directory of its own, so equal basenames never collide
+	id, err := newItemDir(trashDir)
+	if err != nil {
+		return "", false, err
+	}
+	itemDir := filepath.Join(trashDir, id)
 	baseName := filepath.Base(absPath)
-	destPath := filepath.Join(trashDir, baseName)
+	destPath := filepath.Join(itemDir, baseName)
+	storagePath = id + "/" + baseName
 	
 	// Try to move the file/directory using rename first (fast)
-	err = os.Rename(absPath, destPath)
-	if err == nil {
-		return baseName, nil // Success!
+	if isLocal(storeFS) {
+		if err := renamePath(absPath, destPath); err == nil {
+			return storagePath, false, nil // Success!
+		}
+	}
+	
+	// If rename failed due to cross-device link, or the store is not on the
+	// local disk, copy and delete instead
+	if err := transfer(LocalFS{}, absPath, storeFS, destPath); err != nil {
+		storeFS.RemoveAll(itemDir)
+		return "", false, copyFailed(fmt.Errorf("failed to copy %s to trash: %w", absPath, err))
 	}
 	
-	// If rename failed due to cross-device link, copy and delete instead
+	// Remove the original after a successful copy
 	if sourceInfo.IsDir() {
-		// For directories, use recursive copy
-		if err := CopyDir(absPath, destPath); err != nil {
-			return "", fmt.Errorf("failed to copy directory %s to trash: %w", absPath, err)
-		}
-		// Remove original directory after successful copy
-		if err := os.RemoveAll(absPath); err != nil {
-			return "", fmt.Errorf("failed to remove original directory %s: %w", absPath, err)
-		}
+		err = os.RemoveAll(absPath)
 	} else {
-		// For files, use simple copy
-		if err := CopyFile(absPath, destPath); err != nil {
-			return "", fmt.Errorf("failed to copy file %s to trash: %w", absPath, err)
-		}
-		// Remove original file after successful copy
-		if err := os.Remove(absPath); err != nil {
-			return "", fmt.Errorf("failed to remove original file %s: %w", absPath, err)
-		}
+		err = os.Remove(absPath)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to remove original %s: %w", absPath, err)
 	}
 	
-	return baseName, nil
+	return storagePath, true, nil
 }
 
 // SaveRestoreMetadata saves the restore metadata to a .restore file in the trash directory
 func SaveRestoreMetadata(trashDir string, metadata *RestoreMetadata) error {
 	restoreFilePath := filepath.Join(trashDir, ".restore")
+	metadata.Version = MetadataVersion
+
+	// The name of a session being created still holds the exact local time it
+	// was created at; older sessions get the best guess when first saved again
+	if metadata.CreatedAt == "" {
+		if created, err := ParseSessionTime(filepath.Base(trashDir)); err == nil {
+			metadata.CreatedAt = created.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	// A session saved for the first time is the newest one
+	if metadata.Sequence == 0 {
+		if _, err := storeFS.Lstat(restoreFilePath); errors.Is(err, fs.ErrNotExist) {
+			if metadata.Sequence, err = nextSequence(); err != nil {
+				return err
+			}
+		}
+	}
 	
 	// Marshal metadata to JSON with indentation
 	jsonData, err := json.MarshalIndent(metadata, "", "  ")
@@ -134,11 +475,136 @@ func SaveRestoreMetadata(trashDir string, metadata *RestoreMetadata) error {
 	}
 	
 	// Write to .restore file
-	if err := os.WriteFile(restoreFilePath, jsonData, 0644); err != nil {
+	_, fileMode := storeModes()
+	if err := WriteFile(storeFS, restoreFilePath, jsonData, fileMode); err != nil {
 		return fmt.Errorf("failed to write .restore file: %w", err)
 	}
 	
-	return nil
+	return signRestoreMetadata(trashDir, jsonData)
+}
+
+// LoadRestoreMetadata reads and parses the .restore file in the trash directory
+func LoadRestoreMetadata(trashDir string) (*RestoreMetadata, error) {
+	restoreFilePath := filepath.Join(trashDir, ".restore")
+
+	data, err := ReadFile(storeFS, restoreFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .restore file: %w", err)
+	}
+
+	metadata, err := ParseRestoreMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse .restore file: %w", err)
+	}
+	if err := metadata.Validate(); err != nil {
+		return nil, fmt.Errorf("refusing to use .restore file in %s: %w", trashDir, err)
+	}
+
+	return metadata, nil
+}
+
+// ListTrashSessions returns the names of all timestamped trash directories, oldest first
+func ListTrashSessions() ([]string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return listSessionsIn(configDir)
+}
+
+// listSessionsIn returns the names of the session directories in configDir, oldest first
+func listSessionsIn(configDir string) ([]string, error) {
+	entries, err := storeFS.ReadDir(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	var sessions []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != PurgingDirName {
+			sessions = append(sessions, entry.Name())
+		}
+	}
+	SortSessions(configDir, sessions)
+
+	return sessions, nil
+}
+
+// ItemPath returns the location of a trashed item's payload inside the trash
+func ItemPath(session string, item RestoreItem) (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return item.PayloadPath(filepath.Join(configDir, session)), nil
+}
+
+// ListTrashedItems returns every item recorded in the trash, oldest session first
+// Sessions without readable metadata are skipped
+func ListTrashedItems() ([]TrashedItem, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return listTrashedItemsIn(configDir)
+}
+
+// listTrashedItemsIn returns every item recorded in the trash directory configDir
+func listTrashedItemsIn(configDir string) ([]TrashedItem, error) {
+	entries, err := storeFS.ReadDir(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	// Read every session's metadata once, for ordering and listing
+	var sessions []string
+	loaded := make(map[string]*RestoreMetadata)
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == PurgingDirName {
+			continue
+		}
+		sessions = append(sessions, entry.Name())
+		if metadata, err := LoadRestoreMetadata(filepath.Join(configDir, entry.Name())); err == nil {
+			loaded[entry.Name()] = metadata
+		}
+	}
+	SortLoadedSessions(sessions, loaded)
+
+	var items []TrashedItem
+	for _, session := range sessions {
+		metadata, ok := loaded[session]
+		if !ok {
+			continue
+		}
+		for _, item := range metadata.Items {
+			items = append(items, TrashedItem{Session: session, Item: item})
+		}
+	}
+
+	return items, nil
+}
+
+// WarnSkipped, when set, is called for every entry a recursive copy leaves out
+// because its type cannot be reproduced, such as a socket
+var WarnSkipped func(err error)
+
+// warnSkipped reports an entry left out of a copy to WarnSkipped
+func warnSkipped(err error) {
+	if WarnSkipped != nil {
+		WarnSkipped(err)
+	}
+}
+
+// CopySymlink creates dst as a symlink with the same target as the symlink src
+func CopySymlink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
 }
 
 // CopyFile copies a single file from src to dst
@@ -153,57 +619,20 @@ func CopyFile(src, dst string) error {
 	if err != nil {
 		return err
 	}
-	defer destFile.Close()
 	
 	// Copy the contents
-	if _, err := destFile.ReadFrom(sourceFile); err != nil {
-		return err
-	}
-	
-	// Copy permissions
-	sourceInfo, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-	return os.Chmod(dst, sourceInfo.Mode())
-}
-
-// CopyDir recursively copies a directory from src to dst
-func CopyDir(src, dst string) error {
-	// Get source directory info
-	sourceInfo, err := os.Stat(src)
-	if err != nil {
+	if err := copyContents(destFile, sourceFile, src); err != nil {
+		destFile.Close()
 		return err
 	}
-	
-	// Create destination directory
-	if err := os.MkdirAll(dst, sourceInfo.Mode()); err != nil {
+	if err := closeCopy(destFile); err != nil {
 		return err
 	}
 	
-	// Read directory contents
-	entries, err := os.ReadDir(src)
+	// Copy permissions
+	sourceInfo, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
-	
-	// Copy each entry
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-		
-		if entry.IsDir() {
-			// Recursively copy subdirectory
-			if err := CopyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			// Copy file
-			if err := CopyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
-	}
-	
-	return nil
+	return preserveMode(dst, sourceInfo.Mode())
 }
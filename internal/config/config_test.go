@@ -0,0 +1,111 @@
+package config
+
+import "testing"
+
+func TestRestoreItemValidate(t *testing.T) {
+	const (
+		label     = "20250101_120000.000000000-abcd-0011aabb"
+		btrfsName = "/data/.trash-snapshots/" + label
+		zfsName   = "tank/data@trash-" + label
+		zfsDir    = "/data/.zfs/snapshot/trash-" + label
+	)
+
+	tests := []struct {
+		name  string
+		item  RestoreItem
+		valid bool
+	}{
+		{"plain item", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/notes.txt", OriginalPath: "/home/me/notes.txt"}, true},
+		{"legacy item without storage path", RestoreItem{Name: "notes.txt", OriginalPath: "/home/me/notes.txt"}, true},
+		{"adopted item without original path", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/notes.txt"}, true},
+
+		{"empty name", RestoreItem{Name: "", StoragePath: "0011aabb/"}, false},
+		{"dot name", RestoreItem{Name: ".", OriginalPath: "/home/me/."}, false},
+		{"dot-dot name", RestoreItem{Name: "..", StoragePath: "0011aabb/.."}, false},
+		{"name with a separator", RestoreItem{Name: "a/b", StoragePath: "0011aabb/a/b"}, false},
+		{"name escaping the session", RestoreItem{Name: "../../etc", StoragePath: "0011aabb/../../etc"}, false},
+		{"name with a NUL", RestoreItem{Name: "a\x00b", StoragePath: "0011aabb/a\x00b"}, false},
+
+		{"storage path of another name", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/other.txt"}, false},
+		{"storage path without an item ID", RestoreItem{Name: "notes.txt", StoragePath: "notes.txt"}, false},
+		{"storage path with an upper case item ID", RestoreItem{Name: "notes.txt", StoragePath: "0011AABB/notes.txt"}, false},
+		{"storage path with a short item ID", RestoreItem{Name: "notes.txt", StoragePath: "0011aab/notes.txt"}, false},
+		{"storage path escaping the session", RestoreItem{Name: "notes.txt", StoragePath: "../notes.txt"}, false},
+		{"storage path nested deeper", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/x/notes.txt"}, false},
+
+		{"relative original path", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/notes.txt", OriginalPath: "notes.txt"}, false},
+		{"unclean original path", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/notes.txt", OriginalPath: "/home/me/../root/notes.txt"}, false},
+		{"original path with a trailing slash", RestoreItem{Name: "notes", StoragePath: "0011aabb/notes", OriginalPath: "/home/me/notes/"}, false},
+		{"original path with a NUL", RestoreItem{Name: "notes.txt", StoragePath: "0011aabb/notes.txt", OriginalPath: "/home/me\x00/notes.txt"}, false},
+
+		{"btrfs snapshot", RestoreItem{Name: "data", StoragePath: "0011aabb/data", OriginalPath: "/data/vm",
+			Snapshot: &SnapshotRef{Kind: SnapshotBtrfs, Name: btrfsName, Path: btrfsName}}, true},
+		{"btrfs snapshot with a dot-dot name", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotBtrfs, Name: "/x/.trash-snapshots/..", Path: "/x/.trash-snapshots/.."}}, false},
+		{"btrfs snapshot outside a snapshot directory", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotBtrfs, Name: "/home/me/" + label, Path: "/home/me/" + label}}, false},
+		{"btrfs snapshot not named after an item", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotBtrfs, Name: "/data/.trash-snapshots/home", Path: "/data/.trash-snapshots/home"}}, false},
+		{"btrfs payload elsewhere", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotBtrfs, Name: btrfsName, Path: "/home/me/.ssh"}}, false},
+		{"btrfs payload inside the snapshot", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotBtrfs, Name: btrfsName, Path: btrfsName + "/sub"}}, false},
+
+		{"ZFS snapshot", RestoreItem{Name: "data", StoragePath: "0011aabb/data", OriginalPath: "/data/vm",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: zfsName, Path: zfsDir + "/vm"}}, true},
+		{"ZFS snapshot of the whole dataset", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: zfsName, Path: zfsDir}}, true},
+		{"ZFS payload elsewhere", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: zfsName, Path: "/etc/passwd"}}, false},
+		{"ZFS payload in another snapshot", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: zfsName, Path: zfsDir + "x/vm"}}, false},
+		{"ZFS payload escaping the snapshot", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: zfsName, Path: zfsDir + "/../../../../etc"}}, false},
+		{"ZFS snapshot not taken by trash", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: "tank/data@daily", Path: "/data/.zfs/snapshot/daily/vm"}}, false},
+		{"ZFS snapshot without a dataset", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: SnapshotZFS, Name: "@trash-" + label, Path: zfsDir}}, false},
+		{"unknown snapshot kind", RestoreItem{Name: "data", StoragePath: "0011aabb/data",
+			Snapshot: &SnapshotRef{Kind: "lvm", Name: btrfsName, Path: btrfsName}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.item.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Validate() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestRestoreMetadataValidate(t *testing.T) {
+	good := RestoreItem{Name: "a.txt", StoragePath: "0011aabb/a.txt", OriginalPath: "/home/me/a.txt"}
+	bad := RestoreItem{Name: "b.txt", StoragePath: "../b.txt", OriginalPath: "/home/me/b.txt"}
+
+	tests := []struct {
+		name  string
+		items []RestoreItem
+		valid bool
+	}{
+		{"no items", nil, true},
+		{"valid items", []RestoreItem{good, good}, true},
+		{"one invalid item", []RestoreItem{good, bad}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &RestoreMetadata{Items: tt.items}
+			err := metadata.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Validate() = nil, want an error")
+			}
+		})
+	}
+}
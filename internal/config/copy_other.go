@@ -0,0 +1,72 @@
+//go:build !linux && !darwin && !freebsd
+
+package config
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CopyDir recursively copies a directory from src to dst, re-applying its
+// modification time once the children are copied
+func CopyDir(src, dst string) error {
+	// Get source directory info
+	sourceInfo, err := os.Stat(src)
+	if err != nil {
+		return &CopyError{Op: "stat", Path: src, Err: err}
+	}
+
+	// Create destination directory
+	if err := os.MkdirAll(dst, sourceInfo.Mode()); err != nil {
+		return &CopyError{Op: "create directory", Path: dst, Err: err}
+	}
+
+	// Read directory contents
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return &CopyError{Op: "read directory", Path: src, Err: err}
+	}
+
+	// Copy each entry
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+
+		if entry.Type()&(os.ModeNamedPipe|os.ModeSocket|os.ModeDevice) != 0 {
+			// Special files cannot be reproduced here, and opening a FIFO would block
+			warnSkipped(&CopyError{Op: "skipped special file", Path: srcPath, Err: ErrUnsupportedFileType})
+			continue
+		}
+
+		if entry.IsDir() {
+			// Recursively copy subdirectory
+			if err := CopyDir(srcPath, dstPath); err != nil {
+				return err
+			}
+		} else {
+			// Copy file
+			if err := CopyFile(srcPath, dstPath); err != nil {
+				return &CopyError{Op: "copy", Path: srcPath, Err: err}
+			}
+		}
+	}
+
+	if err := os.Chtimes(dst, time.Now(), sourceInfo.ModTime()); err != nil {
+		return &CopyError{Op: "set times", Path: dst, Err: err}
+	}
+
+	return nil
+}
+
+// CopySpecial recreates a FIFO, socket or device node; this platform cannot
+func CopySpecial(src, dst string) error {
+	return &CopyError{Op: "copy", Path: src, Err: ErrUnsupportedFileType}
+}
+
+// extractSpecial recreates the FIFO or device node of an archive entry; this
+// platform cannot
+func extractSpecial(header *tar.Header, target string) error {
+	return ErrUnsupportedFileType
+}
@@ -0,0 +1,279 @@
+//go:build linux || darwin || freebsd
+
+package config
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// CopyDir recursively copies a directory from src to dst
+// The tree is walked with directory file descriptors (openat/mkdirat), so the
+// depth of the tree and the length of the full paths inside it are not limited
+// by PATH_MAX. Failures are reported as *CopyError naming the exact entry.
+// Directory modification times are re-applied once their children are copied.
+func CopyDir(src, dst string) error {
+	sourceInfo, err := os.Stat(src)
+	if err != nil {
+		return &CopyError{Op: "stat", Path: src, Err: err}
+	}
+
+	// Create destination directory
+	if err := os.MkdirAll(dst, sourceInfo.Mode().Perm()); err != nil {
+		return &CopyError{Op: "create directory", Path: dst, Err: err}
+	}
+
+	srcDir, err := openDirAt(unix.AT_FDCWD, src, src)
+	if err != nil {
+		return err
+	}
+	dstDir, err := openDirAt(unix.AT_FDCWD, dst, dst)
+	if err != nil {
+		srcDir.Close()
+		return err
+	}
+	dirs := &dirPair{src: srcDir, dst: dstDir}
+	defer dirs.close()
+
+	if err := copyDirAt(dirs, src, dst, 0); err != nil {
+		return err
+	}
+
+	if err := setMtimeAt(unix.AT_FDCWD, dst, sourceInfo.ModTime()); err != nil {
+		return &CopyError{Op: "set times", Path: dst, Err: err}
+	}
+	return nil
+}
+
+// setMtimeAt sets the modification time of name relative to dirfd; the access
+// time becomes the current time
+func setMtimeAt(dirfd int, name string, mtime time.Time) error {
+	times := []unix.Timespec{
+		unix.NsecToTimespec(time.Now().UnixNano()),
+		unix.NsecToTimespec(mtime.UnixNano()),
+	}
+	return unix.UtimesNanoAt(dirfd, name, times, unix.AT_SYMLINK_NOFOLLOW)
+}
+
+// openDirAt opens the directory name relative to dirfd; path is only used for error reporting
+func openDirAt(dirfd int, name, path string) (*os.File, error) {
+	fd, err := unix.Openat(dirfd, name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &CopyError{Op: "open directory", Path: path, Err: err}
+	}
+
+	return os.NewFile(uintptr(fd), path), nil
+}
+
+// maxOpenDepth is how many directory levels of a copy keep their descriptors
+// open while their subdirectories are copied. Deeper ones close them and
+// reopen them through ".." afterwards, so that the depth of a tree is not
+// limited by the number of files a process may have open either.
+const maxOpenDepth = 32
+
+// dirPair holds the descriptors of a source directory and its copy
+type dirPair struct {
+	src, dst *os.File
+}
+
+func (d *dirPair) close() {
+	d.src.Close()
+	d.dst.Close()
+}
+
+// reopenParents replaces d with the parent directories of child
+func (d *dirPair) reopenParents(child *dirPair) error {
+	src, err := openDirAt(int(child.src.Fd()), "..", d.src.Name())
+	if err != nil {
+		return err
+	}
+	dst, err := openDirAt(int(child.dst.Fd()), "..", d.dst.Name())
+	if err != nil {
+		src.Close()
+		return err
+	}
+	d.src, d.dst = src, dst
+	return nil
+}
+
+// copyDirAt copies the contents of the directories dirs, depth levels below
+// where the copy started, using fd-relative operations. srcPath and dstPath
+// are only used for error reporting.
+func copyDirAt(dirs *dirPair, srcPath, dstPath string, depth int) error {
+	entries, err := dirs.src.ReadDir(-1)
+	if err != nil {
+		return &CopyError{Op: "read directory", Path: srcPath, Err: err}
+	}
+
+	for _, entry := range entries {
+		// Reopened after copying a subdirectory deeper than maxOpenDepth
+		srcFd, dstFd := int(dirs.src.Fd()), int(dirs.dst.Fd())
+		name := entry.Name()
+		srcEntry := filepath.Join(srcPath, name)
+		dstEntry := filepath.Join(dstPath, name)
+
+		var stat unix.Stat_t
+		if err := unix.Fstatat(srcFd, name, &stat, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+			return &CopyError{Op: "stat", Path: srcEntry, Err: err}
+		}
+		mode := uint32(stat.Mode)
+		perm := mode & 07777
+
+		switch mode & unix.S_IFMT {
+		case unix.S_IFDIR:
+			if err := unix.Mkdirat(dstFd, name, perm); err != nil && err != unix.EEXIST {
+				return &CopyError{Op: "create directory", Path: dstEntry, Err: err}
+			}
+
+			childSrc, err := openDirAt(srcFd, name, srcEntry)
+			if err != nil {
+				return err
+			}
+			childDst, err := openDirAt(dstFd, name, dstEntry)
+			if err != nil {
+				childSrc.Close()
+				return err
+			}
+			child := &dirPair{src: childSrc, dst: childDst}
+
+			release := depth >= maxOpenDepth
+			if release {
+				dirs.close()
+			}
+			err = copyDirAt(child, srcEntry, dstEntry, depth+1)
+			if release {
+				if reopenErr := dirs.reopenParents(child); err == nil {
+					err = reopenErr
+				}
+			}
+			child.close()
+			if err != nil {
+				return err
+			}
+			srcFd, dstFd = int(dirs.src.Fd()), int(dirs.dst.Fd())
+
+			// Creating the children updated the directory's mtime
+			mtime := time.Unix(stat.Mtim.Unix())
+			if err := setMtimeAt(dstFd, name, mtime); err != nil {
+				return &CopyError{Op: "set times", Path: dstEntry, Err: err}
+			}
+
+		case unix.S_IFREG:
+			if err := copyFileAt(srcFd, dstFd, name, perm, srcEntry, dstEntry); err != nil {
+				return err
+			}
+
+		case unix.S_IFLNK:
+			target, err := readlinkAt(srcFd, name)
+			if err != nil {
+				return &CopyError{Op: "read symlink", Path: srcEntry, Err: err}
+			}
+			if err := unix.Symlinkat(target, dstFd, name); err != nil {
+				return &CopyError{Op: "create symlink", Path: dstEntry, Err: err}
+			}
+
+		case unix.S_IFIFO, unix.S_IFCHR, unix.S_IFBLK:
+			// Opening a FIFO would block until a writer appears, so the node is
+			// recreated instead; device nodes need root, other users skip them
+			if err := mknodAt(dstFd, name, dstEntry, mode, uint64(stat.Rdev)); err != nil {
+				warnSkipped(&CopyError{Op: "skipped special file", Path: srcEntry, Err: err})
+			}
+
+		case unix.S_IFSOCK:
+			// A socket only lives as long as the process listening on it
+			warnSkipped(&CopyError{Op: "skipped socket", Path: srcEntry, Err: ErrUnsupportedFileType})
+
+		default:
+			return &CopyError{Op: "copy", Path: srcEntry, Err: ErrUnsupportedFileType}
+		}
+	}
+
+	return nil
+}
+
+// copyFileAt copies the regular file name from srcFd into dstFd with the given permissions
+func copyFileAt(srcFd, dstFd int, name string, perm uint32, srcPath, dstPath string) error {
+	in, err := unix.Openat(srcFd, name, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return &CopyError{Op: "open", Path: srcPath, Err: err}
+	}
+	sourceFile := os.NewFile(uintptr(in), srcPath)
+	defer sourceFile.Close()
+
+	out, err := unix.Openat(dstFd, name, unix.O_WRONLY|unix.O_CREAT|unix.O_TRUNC|unix.O_CLOEXEC, 0600)
+	if err != nil {
+		return &CopyError{Op: "create", Path: dstPath, Err: err}
+	}
+	destFile := os.NewFile(uintptr(out), dstPath)
+
+	if err := copyContents(destFile, sourceFile, srcPath); err != nil {
+		destFile.Close()
+		return &CopyError{Op: "copy", Path: srcPath, Err: err}
+	}
+
+	// Copy permissions
+	if err := unix.Fchmod(out, perm); err != nil && !SharedStorage(dstPath) {
+		destFile.Close()
+		return &CopyError{Op: "chmod", Path: dstPath, Err: err}
+	}
+
+	if err := closeCopy(destFile); err != nil {
+		return &CopyError{Op: "write", Path: dstPath, Err: err}
+	}
+	return nil
+}
+
+// CopySpecial recreates the FIFO or device node src as dst. Sockets and, for
+// users other than root, device nodes cannot be reproduced.
+func CopySpecial(src, dst string) error {
+	var stat unix.Stat_t
+	if err := unix.Lstat(src, &stat); err != nil {
+		return &CopyError{Op: "stat", Path: src, Err: err}
+	}
+
+	mode := uint32(stat.Mode)
+	switch mode & unix.S_IFMT {
+	case unix.S_IFIFO, unix.S_IFCHR, unix.S_IFBLK:
+		if err := mknodAt(unix.AT_FDCWD, dst, dst, mode, uint64(stat.Rdev)); err != nil {
+			return &CopyError{Op: "create special file", Path: dst, Err: err}
+		}
+		return nil
+	}
+
+	return &CopyError{Op: "copy", Path: src, Err: ErrUnsupportedFileType}
+}
+
+// extractSpecial recreates the FIFO or device node of an archive entry as target
+func extractSpecial(header *tar.Header, target string) error {
+	mode := uint32(header.Mode) & 07777
+	switch header.Typeflag {
+	case tar.TypeFifo:
+		mode |= unix.S_IFIFO
+	case tar.TypeChar:
+		mode |= unix.S_IFCHR
+	case tar.TypeBlock:
+		mode |= unix.S_IFBLK
+	default:
+		return ErrUnsupportedFileType
+	}
+	dev := unix.Mkdev(uint32(header.Devmajor), uint32(header.Devminor))
+	return mknodAt(unix.AT_FDCWD, target, target, mode, uint64(dev))
+}
+
+// readlinkAt returns the target of the symlink name relative to dirfd
+func readlinkAt(dirfd int, name string) (string, error) {
+	for size := 256; ; size *= 2 {
+		buf := make([]byte, size)
+		n, err := unix.Readlinkat(dirfd, name, buf)
+		if err != nil {
+			return "", err
+		}
+		if n < size {
+			return string(buf[:n]), nil
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DedupeLink is a file of a payload that dedupe replaced, or would replace,
+// with a hard link to an identical file of another payload
+type DedupeLink struct {
+	Path   string // the duplicate
+	Target string // the file it is linked to
+	Size   uint64 // the bytes freed, 0 until the last link to the data is replaced
+}
+
+// dedupeFile is a regular file inside a payload, with the item it belongs to
+type dedupeFile struct {
+	path    string
+	info    os.FileInfo
+	device  uint64
+	inode   uint64
+	links   uint64
+	private bool // no hard link to it lies outside the trash directory
+	session string
+	entry   string // the item's entry in its session, see RestoreItem.Entry
+}
+
+// Dedupe replaces files of payloads in the trash directory that are
+// byte-identical to a file of another payload, or of the same one, with hard
+// links to it, oldest file first, and marks the items involved as linked.
+// Files are compared by size, then checksum, then byte by byte; only files
+// with the same permissions, owner and modification time are linked, since
+// links share them.
+// Sessions whose metadata is unusable or fails its signature check are left
+// alone. With dryRun nothing is changed.
+func Dedupe(dryRun bool) ([]DedupeLink, error) {
+	if !isLocal(storeFS) {
+		return nil, fmt.Errorf("dedupe needs the trash directory on the local disk, hard links cannot be made in a remote store")
+	}
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Without inode numbers, files already linked cannot be told apart
+	if info, err := os.Stat(configDir); err == nil {
+		if _, _, _, ok := FileID(info); !ok {
+			return nil, fmt.Errorf("dedupe is not supported on this platform")
+		}
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only files of a size shared with another file can have a duplicate
+	var files []dedupeFile
+	bySize := make(map[int64][]int)
+	metadatas := make(map[string]*RestoreMetadata)
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err != nil || VerifyRestoreMetadata(trashDir) != nil {
+			continue
+		}
+		metadatas[session] = metadata
+
+		for _, item := range metadata.Items {
+			if item.Snapshot != nil {
+				continue // outside the trash directory, in a snapshot
+			}
+			payload := filepath.Join(trashDir, item.Storage())
+			filepath.WalkDir(payload, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || !d.Type().IsRegular() {
+					return nil
+				}
+				info, err := d.Info()
+				if err != nil || info.Size() == 0 {
+					return nil
+				}
+				device, inode, links, _ := FileID(info)
+				bySize[info.Size()] = append(bySize[info.Size()], len(files))
+				files = append(files, dedupeFile{path: path, info: info, device: device, inode: inode,
+					links: links, session: session, entry: item.Entry()})
+				return nil
+			})
+		}
+	}
+
+	// Files hard linked to something outside the trash, like those trashed
+	// with links left in place, must not share their data with other payloads
+	inodeLinks := make(map[[2]uint64]uint64)
+	for _, file := range files {
+		inodeLinks[[2]uint64{file.device, file.inode}]++
+	}
+	for i := range files {
+		files[i].private = files[i].links == inodeLinks[[2]uint64{files[i].device, files[i].inode}]
+	}
+
+	var links []DedupeLink
+	linked := make(map[string]map[string]bool) // session, then item entry
+	markLinked := func(file dedupeFile) {
+		if linked[file.session] == nil {
+			linked[file.session] = make(map[string]bool)
+		}
+		linked[file.session][file.entry] = true
+	}
+
+	// Biggest files first, as they free the most
+	sizes := make([]int64, 0, len(bySize))
+	for size, candidates := range bySize {
+		if len(candidates) > 1 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+
+	for _, size := range sizes {
+		candidates := bySize[size]
+		byChecksum := make(map[string][]int)
+		var order []string
+		for _, i := range candidates {
+			checksum, err := fileChecksum(files[i].path)
+			if err != nil {
+				continue
+			}
+			if byChecksum[checksum] == nil {
+				order = append(order, checksum)
+			}
+			byChecksum[checksum] = append(byChecksum[checksum], i)
+		}
+
+		for _, checksum := range order {
+			group := byChecksum[checksum]
+			// Files are found oldest session first; each is linked to the
+			// first earlier file it can share an inode with
+			var targets []int
+			for _, i := range group {
+				file := files[i]
+				if !file.private {
+					continue
+				}
+				target := -1
+				for _, t := range targets {
+					if canLink(files[t], file) {
+						target = t
+						break
+					}
+				}
+				if target < 0 {
+					targets = append(targets, i)
+					continue
+				}
+				if files[target].inode == file.inode {
+					continue // already one file
+				}
+				if same, err := sameContent(files[target].path, file.path); err != nil || !same {
+					continue
+				}
+
+				// The data is freed with the last link to it
+				link := DedupeLink{Path: file.path, Target: files[target].path}
+				key := [2]uint64{file.device, file.inode}
+				if inodeLinks[key]--; inodeLinks[key] == 0 {
+					link.Size = uint64(file.info.Size())
+				}
+				if !dryRun {
+					if err := replaceWithLink(files[target].path, file.path); err != nil {
+						return links, err
+					}
+				}
+				links = append(links, link)
+				markLinked(files[target])
+				markLinked(file)
+				files[i].inode = files[target].inode
+			}
+		}
+	}
+
+	if dryRun {
+		return links, nil
+	}
+	for session, entries := range linked {
+		metadata := metadatas[session]
+		changed := false
+		for i := range metadata.Items {
+			if entries[metadata.Items[i].Entry()] && !metadata.Items[i].Linked {
+				metadata.Items[i].Linked = true
+				changed = true
+			}
+		}
+		if changed {
+			if err := SaveRestoreMetadata(filepath.Join(configDir, session), metadata); err != nil {
+				return links, err
+			}
+		}
+	}
+	return links, nil
+}
+
+// canLink reports whether file can become a hard link to target without
+// changing what restoring it puts back: same filesystem, permissions, owner
+// and modification time
+func canLink(target, file dedupeFile) bool {
+	return target.device == file.device && target.info.Mode() == file.info.Mode() &&
+		sameOwner(target.info, file.info) && target.info.ModTime().Equal(file.info.ModTime())
+}
+
+// replaceWithLink replaces path with a hard link to target, atomically
+func replaceWithLink(target, path string) error {
+	tmpPath := path + ".dedupe"
+	os.Remove(tmpPath)
+	if err := os.Link(target, tmpPath); err != nil {
+		return fmt.Errorf("failed to link %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to link %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileChecksum returns the hex SHA-256 of the content of a file
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sameContent reports whether two files hold the same bytes, so that a
+// checksum collision never links different files
+func sameContent(a, b string) (bool, error) {
+	fileA, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fileA.Close()
+	fileB, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fileB.Close()
+
+	bufA, bufB := make([]byte, 64*1024), make([]byte, 64*1024)
+	for {
+		nA, errA := io.ReadFull(fileA, bufA)
+		nB, errB := io.ReadFull(fileB, bufB)
+		if nA != nB || !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+		doneA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		doneB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if doneA || doneB {
+			return doneA && doneB, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
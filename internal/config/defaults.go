@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// RootCommandName is the table of config.toml holding the flag defaults of
+// trash itself, as opposed to one of its subcommands
+const RootCommandName = "root"
+
+// AliasesTableName is the table of config.toml defining command aliases
+const AliasesTableName = "aliases"
+
+// CommandDefaults returns the flag defaults config.toml sets per command: a
+// table per command (e.g. [list] or list.sort = "size"), nested for
+// subcommands ([bundle.create]), whose keys are flag names. The result is
+// keyed by the command's path below trash joined with dots, or "root", then
+// by the key as written.
+func CommandDefaults() (map[string]map[string]interface{}, error) {
+	raw, err := loadRawSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]map[string]interface{})
+	for name, value := range raw {
+		// Settings are plain values at the top; tables belong to commands
+		if table, ok := value.(map[string]interface{}); ok && name != AliasesTableName {
+			collectCommandDefaults(defaults, name, table)
+		}
+	}
+	return defaults, nil
+}
+
+// Aliases returns the command aliases of the [aliases] table of config.toml,
+// e.g. purge = "empty --expired", mapping each name to what it stands for.
+// Values that are not strings are returned in invalid so they can be reported.
+func Aliases() (aliases map[string]string, invalid []string, err error) {
+	raw, err := loadRawSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	table, _ := raw[AliasesTableName].(map[string]interface{})
+	aliases = make(map[string]string, len(table))
+	for name, value := range table {
+		if expansion, ok := value.(string); ok {
+			aliases[name] = expansion
+		} else {
+			invalid = append(invalid, name)
+		}
+	}
+	return aliases, invalid, nil
+}
+
+// loadRawSettings decodes config.toml without a schema; a missing file is empty
+func loadRawSettings() (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return raw, nil
+	}
+
+	if _, err := toml.DecodeFile(settingsPath, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", settingsPath, err)
+	}
+	return raw, nil
+}
+
+// collectCommandDefaults adds the flag values of the table of command to
+// defaults, descending into the tables of its subcommands
+func collectCommandDefaults(defaults map[string]map[string]interface{}, command string, table map[string]interface{}) {
+	for key, value := range table {
+		if sub, ok := value.(map[string]interface{}); ok {
+			collectCommandDefaults(defaults, command+"."+key, sub)
+			continue
+		}
+		if defaults[command] == nil {
+			defaults[command] = make(map[string]interface{})
+		}
+		defaults[command][key] = value
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !linux && !darwin && !freebsd
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SameDevice reports whether path is on the same volume as dir, i.e. whether
+// it can be renamed into dir without copying
+func SameDevice(path, dir string) (bool, error) {
+	if _, err := os.Lstat(path); err != nil {
+		return false, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(filepath.VolumeName(absPath), filepath.VolumeName(absDir)), nil
+}
+
+// FileID is not supported on this platform
+func FileID(info os.FileInfo) (device, inode, links uint64, ok bool) {
+	return 0, 0, 0, false
+}
@@ -0,0 +1,39 @@
+//go:build linux || darwin || freebsd
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// SameDevice reports whether path (not following symlinks) is on the same
+// filesystem as dir, i.e. whether it can be renamed into dir without copying
+func SameDevice(path, dir string) (bool, error) {
+	pathInfo, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return false, err
+	}
+
+	pathStat, ok1 := pathInfo.Sys().(*syscall.Stat_t)
+	dirStat, ok2 := dirInfo.Sys().(*syscall.Stat_t)
+	if !ok1 || !ok2 {
+		return false, fmt.Errorf("failed to read device of %s", path)
+	}
+
+	return pathStat.Dev == dirStat.Dev, nil
+}
+
+// FileID returns the device and inode of info and its number of hard links
+func FileID(info os.FileInfo) (device, inode, links uint64, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return uint64(stat.Dev), uint64(stat.Ino), uint64(stat.Nlink), true
+}
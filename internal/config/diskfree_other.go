@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package config
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// FreeSpace is not supported on this platform
+func FreeSpace(path string) (uint64, error) {
+	return 0, fmt.Errorf("free space detection is not supported on %s", runtime.GOOS)
+}
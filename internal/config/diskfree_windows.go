@@ -0,0 +1,24 @@
+//go:build windows
+
+package config
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// FreeSpace returns the number of bytes available to the current user on the volume containing path
+func FreeSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &available, nil, nil); err != nil {
+		return 0, fmt.Errorf("failed to query free space of %s: %w", path, err)
+	}
+
+	return available, nil
+}
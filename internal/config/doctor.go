@@ -0,0 +1,347 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// lowFreeSpace is the free space below which CheckEnvironment warns even
+// without a min_free policy
+const lowFreeSpace = 100 << 20
+
+// Finding is a problem in the trash directory found by Diagnose
+type Finding struct {
+	Check   string // what was checked, e.g. "metadata"
+	Problem string // what is wrong
+	Fix     string // what to do about it
+}
+
+// Diagnose checks the trash directory for problems that trash cannot fix on
+// its own: sessions whose metadata is missing, unreadable or modified outside
+// trash, payloads their metadata does not list, listed items whose payload
+// is gone and purges that were interrupted
+func Diagnose() ([]Finding, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, err := FindOrphans()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	var payloads int
+	var payloadBytes uint64
+	for _, orphan := range orphans {
+		switch orphan.Reason {
+		case OrphanSession:
+			findings = append(findings, Finding{
+				Check:   "metadata",
+				Problem: fmt.Sprintf("session %s has no .restore file", orphan.Session),
+				Fix:     fmt.Sprintf("run 'trash adopt %s' to list its contents again", orphan.Session),
+			})
+		case OrphanInvalidSession:
+			findings = append(findings, Finding{
+				Check:   "metadata",
+				Problem: fmt.Sprintf("the .restore file of session %s cannot be used", orphan.Session),
+				Fix:     fmt.Sprintf("repair %s, or move it away and run 'trash adopt %s'", filepath.Join(configDir, orphan.Session, ".restore"), orphan.Session),
+			})
+		case OrphanPayload:
+			payloads++
+			payloadBytes += orphan.Bytes
+		}
+	}
+	if payloads > 0 {
+		findings = append(findings, Finding{
+			Check:   "metadata",
+			Problem: fmt.Sprintf("%d payload(s) (%s) are not listed in their session's metadata", payloads, FormatSize(payloadBytes)),
+			Fix:     "run 'trash list --orphans' to see them and 'trash adopt' to list them again",
+		})
+	}
+
+	if pending, size, err := PendingPurge(); err == nil && pending > 0 {
+		findings = append(findings, Finding{
+			Check:   "purge",
+			Problem: fmt.Sprintf("%d session(s) or item(s) (%s) of interrupted purges are not deleted yet", pending, FormatSize(size)),
+			Fix:     "run 'trash empty --resume'",
+		})
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return findings, err
+	}
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+		if metadata, err := LoadRestoreMetadata(trashDir); err == nil {
+			for _, item := range metadata.Items {
+				_, err := storeFS.Lstat(item.PayloadPath(trashDir))
+				if errors.Is(err, fs.ErrNotExist) && !IsArchived(trashDir, item) {
+					findings = append(findings, Finding{
+						Check:   "index",
+						Problem: fmt.Sprintf("%s (%s) in session %s is listed but its payload is gone", item.Name, item.ShortID(), session),
+						Fix:     fmt.Sprintf("run 'trash vacuum' or 'trash empty %s' to drop it from the listing", item.ShortID()),
+					})
+				}
+			}
+		}
+		if status, err := CheckRestoreSignature(trashDir); err == nil && status == SignatureInvalid {
+			findings = append(findings, Finding{
+				Check:   "signature",
+				Problem: fmt.Sprintf("the metadata of session %s was modified outside trash", session),
+				Fix:     fmt.Sprintf("check %s; restore with --no-verify once it is trusted", filepath.Join(trashDir, ".restore")),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckEnvironment checks what the trash directory depends on: the syntax and
+// values of config.toml, the ownership and permissions of the store, the free
+// space on its filesystem and the health of its lock files
+func CheckEnvironment() ([]Finding, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	findings := append(checkSettings(), checkLocation(configDir)...)
+	findings = append(findings, checkForeignTrash()...)
+	settings, _ := LoadSettings()
+
+	// Only a local store has permissions, free space and lock files to check
+	if !isLocal(storeFS) {
+		return findings, nil
+	}
+	findings = append(findings, checkPermissions(configDir)...)
+	findings = append(findings, checkFreeSpace(configDir, settings)...)
+	findings = append(findings, checkLocks(configDir)...)
+	return findings, nil
+}
+
+// checkSettings reports config.toml files that do not parse, keys trash does
+// not know and values it cannot use
+func checkSettings() []Finding {
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return nil
+	}
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	settings := &Settings{}
+	meta, err := toml.DecodeFile(settingsPath, settings)
+	if err != nil {
+		return []Finding{{
+			Check:   "config",
+			Problem: fmt.Sprintf("%s cannot be parsed: %v", settingsPath, err),
+			Fix:     fmt.Sprintf("correct the syntax of %s; until then every setting has its default", settingsPath),
+		}}
+	}
+
+	var findings []Finding
+	for _, key := range meta.Undecoded() {
+		// Tables hold the flag defaults of commands, which only cmd can check
+		if len(key) > 1 || meta.Type(key[0]) == "Hash" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Check:   "config",
+			Problem: fmt.Sprintf("%s sets unknown key %q", settingsPath, key.String()),
+			Fix:     fmt.Sprintf("remove it from %s; 'trash config list' shows the known keys", settingsPath),
+		})
+	}
+	for _, info := range settingsInfo {
+		value, set, err := settings.Value(info.Name)
+		if err != nil || !set {
+			continue
+		}
+		if _, err := parseSetting(info.Name, value); err != nil {
+			findings = append(findings, Finding{
+				Check:   "config",
+				Problem: fmt.Sprintf("%s: %v", settingsPath, err),
+				Fix:     fmt.Sprintf("run 'trash config set %s <value>' with a valid value, or 'trash config unset %s'", info.Name, info.Name),
+			})
+		}
+	}
+	return findings
+}
+
+// checkLocation reports a trash directory of an older version in
+// ~/.config/trash, in use or left behind next to the current one
+func checkLocation(configDir string) []Finding {
+	legacy, err := LegacyStoreDir()
+	if err != nil || storeDir != "" || !isLegacyStore(legacy) {
+		return nil
+	}
+	target, err := xdgStoreDir()
+	if err != nil || target == legacy {
+		return nil
+	}
+
+	if configDir == legacy {
+		return []Finding{{
+			Check:   "location",
+			Problem: fmt.Sprintf("the trash directory is still in %s, where older versions kept it", legacy),
+			Fix:     fmt.Sprintf("run 'trash migrate --xdg' to move it to %s", target),
+		}}
+	}
+	return []Finding{{
+		Check:   "location",
+		Problem: fmt.Sprintf("a trash directory of an older version is left in %s next to %s", legacy, configDir),
+		Fix:     fmt.Sprintf("move its sessions into %s, then remove everything but %s from %s", configDir, SettingsFileName, legacy),
+	}}
+}
+
+// checkForeignTrash reports deletions other trash tools keep beside this trash
+// and shell aliases that make rm use them, so that deleted files end up in
+// trashes that know nothing of each other
+func checkForeignTrash() []Finding {
+	var findings []Finding
+	for _, trash := range ForeignTrashes() {
+		findings = append(findings, Finding{
+			Check:   "other trash",
+			Problem: fmt.Sprintf("%s holds %d item(s) deleted with trash-cli, gio or a file manager", trash.Dir, trash.Items),
+			Fix:     fmt.Sprintf("run 'trash import %s' to move them into this trash", trash.Dir),
+		})
+	}
+	for _, alias := range FindForeignAliases() {
+		findings = append(findings, Finding{
+			Check:   "other trash",
+			Problem: fmt.Sprintf("%s:%d: %q sends deletions to %s", alias.File, alias.Line, alias.Text, alias.Tool),
+			Fix:     "remove it and use 'trash shell-init' for an rm that trashes instead",
+		})
+	}
+	return findings
+}
+
+// checkPermissions reports parts of the store trash cannot write to or that
+// belong to another user
+func checkPermissions(configDir string) []Finding {
+	var findings []Finding
+	if err := checkWritable(configDir); err != nil {
+		findings = append(findings, Finding{
+			Check:   "permissions",
+			Problem: fmt.Sprintf("the trash directory is not writable: %v", err),
+			Fix:     fmt.Sprintf("run 'chmod u+rwx %s'", configDir),
+		})
+	}
+
+	var foreign []string
+	if info, err := os.Lstat(configDir); err == nil && ownedByOther(info) {
+		foreign = append(foreign, configDir)
+	}
+	sessions, _ := ListTrashSessions()
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+		if info, err := os.Lstat(trashDir); err == nil && ownedByOther(info) {
+			foreign = append(foreign, trashDir)
+			continue
+		}
+		if err := checkWritable(trashDir); err != nil {
+			findings = append(findings, Finding{
+				Check:   "permissions",
+				Problem: fmt.Sprintf("session %s is not writable, so it cannot be restored from or purged", session),
+				Fix:     fmt.Sprintf("run 'chmod u+rwx %s'", trashDir),
+			})
+		}
+	}
+	if loose, err := LoosePermissions(); err == nil && len(loose) > 0 {
+		findings = append(findings, Finding{
+			Check:   "permissions",
+			Problem: fmt.Sprintf("%d part(s) of the trash are open to other users beyond dir_mode, e.g. %s (%04o)", len(loose), loose[0].Path, loose[0].Mode),
+			Fix:     "run 'trash doctor --fix-perms'",
+		})
+	}
+	if len(foreign) > 0 {
+		sort.Strings(foreign)
+		findings = append(findings, Finding{
+			Check:   "permissions",
+			Problem: fmt.Sprintf("%d part(s) of the trash belong to another user, e.g. %s", len(foreign), foreign[0]),
+			Fix:     fmt.Sprintf("run 'sudo chown -R %d %s' and avoid running trash with sudo", os.Geteuid(), configDir),
+		})
+	}
+	return findings
+}
+
+// checkFreeSpace reports a filesystem below the min_free policy or nearly full
+func checkFreeSpace(configDir string, settings *Settings) []Finding {
+	free, err := FreeSpace(configDir)
+	if err != nil {
+		return nil
+	}
+
+	minFree, err := settings.MinFreeBytes()
+	if err == nil && minFree > 0 && free < minFree {
+		return []Finding{{
+			Check:   "free space",
+			Problem: fmt.Sprintf("only %s free on the trash filesystem, below min_free of %s", FormatSize(free), FormatSize(minFree)),
+			Fix:     "run 'trash empty --interactive' or 'trash prune' to free space now; the next trash operation purges the oldest sessions",
+		}}
+	}
+	if free < lowFreeSpace {
+		return []Finding{{
+			Check:   "free space",
+			Problem: fmt.Sprintf("only %s free on the trash filesystem", FormatSize(free)),
+			Fix:     "run 'trash empty --interactive' to free space, and set min_free to purge old sessions automatically",
+		}}
+	}
+	return nil
+}
+
+// checkLocks reports lock files that are not regular files or cannot be
+// opened for locking, which makes every locking command fail, lockfiles left
+// by commands that died, and a trash directory on a network filesystem that
+// is locked with flock anyway
+func checkLocks(configDir string) []Finding {
+	var findings []Finding
+	if fsType, network := NetworkFilesystem(configDir); network && !OnNetworkFS(configDir) {
+		findings = append(findings, Finding{
+			Check:   "lock",
+			Problem: fmt.Sprintf("the trash directory is on a network filesystem (%s), but network_fs = \"never\" locks it with flock, which may not exclude other clients", fsType),
+			Fix:     "run 'trash config unset network_fs' to lock it with lockfiles",
+		})
+	}
+
+	for _, name := range []string{LockFileName, SequenceFileName + ".lock"} {
+		lockPath := filepath.Join(configDir, name)
+		if heldPath := lockPath + HeldLockSuffix; HeldLockStale(heldPath) {
+			findings = append(findings, Finding{
+				Check:   "lock",
+				Problem: fmt.Sprintf("lockfile %s was left by a trash command that stopped", heldPath),
+				Fix:     fmt.Sprintf("nothing: the next command that needs the lock removes it; or remove %s yourself", heldPath),
+			})
+		}
+		info, err := os.Lstat(lockPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil && !info.Mode().IsRegular() {
+			err = fmt.Errorf("not a regular file (mode %v)", info.Mode())
+		}
+		if err == nil {
+			var file *os.File
+			if file, err = os.OpenFile(lockPath, os.O_RDWR, 0); err == nil {
+				file.Close()
+			}
+		}
+		if err != nil {
+			findings = append(findings, Finding{
+				Check:   "lock",
+				Problem: fmt.Sprintf("lock file %s is unusable: %v", lockPath, err),
+				Fix:     fmt.Sprintf("remove %s while no trash command runs; it is created again on demand", lockPath),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// checksumPrefix names the hash of RestoreItem.Checksum
+const checksumPrefix = "sha256:"
+
+// DuplicateGroup is a set of trashed items whose payloads are identical
+type DuplicateGroup struct {
+	Checksum string
+	Size     uint64 // of each payload
+	Items    []TrashedItem
+}
+
+// Savings returns the bytes that keeping a single copy of the payload would free
+func (g DuplicateGroup) Savings() uint64 {
+	return g.Size * uint64(len(g.Items)-1)
+}
+
+// FindDuplicates groups the items of the trash directory whose payloads are
+// identical, biggest savings first. Only items of equal size are compared; the
+// checksums this takes are stored in their sessions' metadata, so that they
+// are computed once. Empty payloads and payloads that cannot be read are left
+// out. The error of storing the checksums is returned with the groups.
+func FindDuplicates() ([]DuplicateGroup, error) {
+	items, err := ListTrashedItems()
+	if err != nil {
+		return nil, err
+	}
+
+	bySize := make(map[uint64][]int)
+	sizes := make([]uint64, len(items))
+	for i, entry := range items {
+		sizes[i] = entry.Size()
+		if sizes[i] > 0 {
+			bySize[sizes[i]] = append(bySize[sizes[i]], i)
+		}
+	}
+
+	computed := make(map[string]map[string]string) // session, then item entry
+	byChecksum := make(map[string][]int)
+	for _, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		for _, i := range candidates {
+			entry := &items[i]
+			if entry.Item.Checksum == "" {
+				payload, err := entry.Path()
+				if err != nil {
+					continue
+				}
+				if entry.Item.Checksum, err = payloadChecksum(storeFS, payload); err != nil {
+					continue
+				}
+				if computed[entry.Session] == nil {
+					computed[entry.Session] = make(map[string]string)
+				}
+				computed[entry.Session][entry.Item.Entry()] = entry.Item.Checksum
+			}
+			byChecksum[entry.Item.Checksum] = append(byChecksum[entry.Item.Checksum], i)
+		}
+	}
+
+	storeErr := storeChecksums(computed)
+
+	var groups []DuplicateGroup
+	for checksum, members := range byChecksum {
+		if len(members) < 2 {
+			continue
+		}
+		group := DuplicateGroup{Checksum: checksum, Size: sizes[members[0]]}
+		for _, i := range members {
+			group.Items = append(group.Items, items[i])
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Savings() != groups[j].Savings() {
+			return groups[i].Savings() > groups[j].Savings()
+		}
+		return groups[i].Checksum < groups[j].Checksum
+	})
+	return groups, storeErr
+}
+
+// storeChecksums records computed checksums, by session and item entry, in the
+// metadata of their sessions
+func storeChecksums(computed map[string]map[string]string) error {
+	updates := make(map[string]map[string]func(*RestoreItem))
+	for session, checksums := range computed {
+		updates[session] = make(map[string]func(*RestoreItem))
+		for entry, checksum := range checksums {
+			checksum := checksum
+			updates[session][entry] = func(item *RestoreItem) { item.Checksum = checksum }
+		}
+	}
+	return updateItems(updates)
+}
+
+// updateItems applies updates, by session and item entry, to the items of the
+// metadata of their sessions and saves it. Items restored or purged meanwhile
+// are left out, and sessions whose metadata fails its signature check are left
+// alone rather than signed again.
+func updateItems(updates map[string]map[string]func(*RestoreItem)) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for session, items := range updates {
+		trashDir := filepath.Join(configDir, session)
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err != nil {
+			continue
+		}
+		if err := VerifyRestoreMetadata(trashDir); err != nil {
+			continue
+		}
+		for i := range metadata.Items {
+			if update, ok := items[metadata.Items[i].Entry()]; ok {
+				update(&metadata.Items[i])
+			}
+		}
+		if err := SaveRestoreMetadata(trashDir, metadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// payloadChecksum returns "sha256:" and the hex digest of a payload: of the
+// content of a file, the target of a symlink, or for a directory the names,
+// types and contents of everything in it, so equal trees have equal checksums
+func payloadChecksum(fsys FS, path string) (string, error) {
+	h := sha256.New()
+	if err := hashTree(fsys, path, h); err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	return checksumPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashTree writes what identifies the content of path to h
+func hashTree(fsys FS, path string, h hash.Hash) error {
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := fsys.Readlink(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "l%d:%s", len(target), target)
+	case info.IsDir():
+		entries, err := fsys.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		// By name, so the digest does not depend on the order of the directory
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+		fmt.Fprintf(h, "d%d:", len(entries))
+		for _, entry := range entries {
+			fmt.Fprintf(h, "%d:%s", len(entry.Name()), entry.Name())
+			if err := hashTree(fsys, filepath.Join(path, entry.Name()), h); err != nil {
+				return err
+			}
+		}
+	case info.Mode().IsRegular():
+		file, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		fmt.Fprintf(h, "f%d:", info.Size())
+		if _, err := io.Copy(h, file); err != nil {
+			return err
+		}
+	default:
+		// Devices, fifos and sockets have no content; their type tells them apart
+		fmt.Fprintf(h, "o%s:", info.Mode().Type())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artemisfowl/trash/internal/i18n"
+)
+
+// durationUnits maps day-scale suffixes not understood by time.ParseDuration
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// ParseDuration parses durations such as "7d", "2w", "12h" or "90m"
+// Day, week and year suffixes are supported in addition to Go duration syntax
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	unit := s[len(s)-1:]
+	if multiplier, ok := durationUnits[unit]; ok {
+		n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n * float64(multiplier)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return d, nil
+}
+
+// timeLayouts are the absolute times accepted by ParseTime, in local time
+// unless they carry a zone, with the period each one names
+var timeLayouts = []struct {
+	layout string
+	span   time.Duration
+}{
+	{time.RFC3339, time.Second},
+	{"2006-01-02T15:04:05", time.Second},
+	{"2006-01-02 15:04:05", time.Second},
+	{"2006-01-02T15:04", time.Minute},
+	{"2006-01-02 15:04", time.Minute},
+	{"2006-01-02", 24 * time.Hour},
+	{SessionTimeFormat, time.Second},
+}
+
+// calendarUnits are the units of "last week" and "3 months ago"; months and
+// years are calendar ones
+var calendarUnits = map[string]func(t time.Time, n int) time.Time{
+	"minute": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Minute) },
+	"hour":   func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Hour) },
+	"day":    func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -n) },
+	"week":   func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -7*n) },
+	"month":  func(t time.Time, n int) time.Time { return t.AddDate(0, -n, 0) },
+	"year":   func(t time.Time, n int) time.Time { return t.AddDate(-n, 0, 0) },
+}
+
+// parseAgo parses "last <unit>", "<n> <unit>(s) ago", "a <unit> ago" and
+// "<duration> ago" (e.g. "2h ago")
+func parseAgo(s string, now time.Time) (time.Time, bool) {
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 2 && fields[0] == "last":
+		if back, ok := calendarUnits[fields[1]]; ok {
+			return back(now, 1), true
+		}
+	case len(fields) == 2 && fields[1] == "ago":
+		if d, err := ParseDuration(fields[0]); err == nil {
+			return now.Add(-d), true
+		}
+	case len(fields) == 3 && fields[2] == "ago":
+		n, err := strconv.Atoi(fields[0])
+		if fields[0] == "a" || fields[0] == "an" {
+			n, err = 1, nil
+		}
+		back, ok := calendarUnits[strings.TrimSuffix(fields[1], "s")]
+		if err == nil && n >= 0 && ok {
+			return back(now, n), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseTime parses a point in time relative to now: "now", "today",
+// "yesterday", a duration meaning that long ago (e.g. "7d", "12h"), "last
+// week", "3 days ago", or a date and time such as "2025-12-17",
+// "2025-12-17 14:30" or RFC 3339. Periods stand for their start.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	start, _, err := ParseTimeRange(value, now)
+	return start, err
+}
+
+// ParseTimeRange parses a period of time given like for ParseTime and returns
+// its start and end: the whole day for "today", "yesterday" or a date, the
+// minute or second a date and time names, and from a relative time such as
+// "7d" or "last week" until now.
+func ParseTimeRange(value string, now time.Time) (time.Time, time.Time, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch s {
+	case "now":
+		return now, now, nil
+	case "today":
+		return midnight, midnight.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), midnight, nil
+	}
+
+	if d, err := ParseDuration(s); err == nil {
+		return now.Add(-d), now, nil
+	}
+	if t, ok := parseAgo(s, now); ok {
+		return t, now, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout.layout, strings.TrimSpace(value), now.Location()); err == nil {
+			if layout.span == 24*time.Hour {
+				return t, t.AddDate(0, 0, 1), nil
+			}
+			return t, t.Add(layout.span), nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid time %q: expected a date such as 2025-12-17, a duration such as 7d, today, yesterday or last week", value)
+}
+
+// HumanizeAge describes how long ago t was relative to now (e.g. "3 hours ago")
+func HumanizeAge(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		return i18n.Sprintf("in the future")
+	}
+
+	// Whole messages rather than units so each can be translated
+	steps := []struct {
+		one  string
+		many string
+		size time.Duration
+	}{
+		{"1 year ago", "%d years ago", 365 * 24 * time.Hour},
+		{"1 month ago", "%d months ago", 30 * 24 * time.Hour},
+		{"1 week ago", "%d weeks ago", 7 * 24 * time.Hour},
+		{"1 day ago", "%d days ago", 24 * time.Hour},
+		{"1 hour ago", "%d hours ago", time.Hour},
+		{"1 minute ago", "%d minutes ago", time.Minute},
+	}
+
+	for _, step := range steps {
+		if n := int(d / step.size); n >= 1 {
+			if n == 1 {
+				return i18n.Sprintf(step.one)
+			}
+			return i18n.Sprintf(step.many, n)
+		}
+	}
+
+	return i18n.Sprintf("just now")
+}
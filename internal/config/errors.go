@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// ErrUnsupportedFileType is returned when a copy meets a file type it cannot reproduce
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// ErrDestinationExists is returned when a restore would overwrite an existing path
+var ErrDestinationExists = errors.New("destination already exists")
+
+// ErrNotFound is returned when a path to trash or a trashed item does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrCrossDevice is returned when a move between filesystems fell back to copying and the copy failed
+var ErrCrossDevice = errors.New("cross-device move failed")
+
+// ErrQuotaExceeded is returned when the target filesystem is full or the disk quota is exhausted
+var ErrQuotaExceeded = errors.New("disk full or quota exceeded")
+
+// ErrTrashStore is returned when a path to trash is the trash directory, lies inside it or contains it
+var ErrTrashStore = errors.New("path overlaps the trash directory")
+
+// ErrMountPoint is returned when a path to trash is a mount point or contains one
+var ErrMountPoint = errors.New("path is or contains a mount point")
+
+// ErrProtected is returned when a path to trash is protected by a .trashignore file
+var ErrProtected = errors.New("path is protected by a .trashignore file")
+
+// ErrTampered is returned when session metadata does not match its signature
+var ErrTampered = errors.New("metadata signature mismatch")
+
+// ErrUnknownOrigin is returned when restoring an adopted item, whose original location is unknown, to it
+var ErrUnknownOrigin = errors.New("original location unknown")
+
+// ErrNoLegacyStore is returned when there is no trash directory of an older version to migrate
+var ErrNoLegacyStore = errors.New("no trash directory in ~/.config/trash to migrate")
+
+// kindError tags an error with a sentinel for errors.Is without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind tags err so that errors.Is(err, kind) reports true
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+// copyFailed tags an error from the cross-device copy fallback
+func copyFailed(err error) error {
+	if errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT) {
+		err = withKind(ErrQuotaExceeded, err)
+	}
+	return withKind(ErrCrossDevice, err)
+}
+
+// PreflightError reports the problems CheckTrash found before anything was
+// trashed; Trash moves nothing when it returns one
+type PreflightError struct {
+	Problems []error
+}
+
+func (e *PreflightError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more problems)", e.Problems[0], len(e.Problems)-1)
+}
+
+func (e *PreflightError) Unwrap() []error {
+	return e.Problems
+}
+
+// CopyError reports the exact entry a recursive copy failed on
+type CopyError struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *CopyError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
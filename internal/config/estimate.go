@@ -0,0 +1,74 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// TrashEstimate summarizes what trashing a set of paths would move
+type TrashEstimate struct {
+	Items int    // paths that exist
+	Files int    // non-directory entries, including those inside directories
+	Dirs  int    // directories, including the paths themselves
+	Bytes uint64 // total size, counted as PathSize does
+
+	// CopyItems and CopyBytes cover the items on another filesystem than the
+	// trash, which are copied and then deleted instead of renamed
+	CopyItems int
+	CopyBytes uint64
+
+	// FreeSpace is the space available on the trash filesystem, the largest
+	// uint64 for a remote store
+	FreeSpace uint64
+
+	Missing []string // paths that do not exist
+}
+
+// EstimateTrash walks paths and reports how much trashing them would move and
+// how much of it would need a cross-device copy
+func EstimateTrash(paths []string) (*TrashEstimate, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &TrashEstimate{}
+	estimate.FreeSpace, _ = FreeSpace(configDir)
+	remote := !isLocal(storeFS)
+	if remote {
+		estimate.FreeSpace = math.MaxUint64
+	}
+
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err != nil {
+			estimate.Missing = append(estimate.Missing, path)
+			continue
+		}
+		estimate.Items++
+
+		var size uint64
+		filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil // Unreadable entries are left out of the estimate
+			}
+			if info.IsDir() {
+				estimate.Dirs++
+				return nil
+			}
+			estimate.Files++
+			if info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0 {
+				size += uint64(info.Size())
+			}
+			return nil
+		})
+		estimate.Bytes += size
+
+		if same, err := SameDevice(path, configDir); remote || (err == nil && !same) {
+			estimate.CopyItems++
+			estimate.CopyBytes += size
+		}
+	}
+
+	return estimate, nil
+}
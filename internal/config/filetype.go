@@ -0,0 +1,85 @@
+package config
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File types recorded in RestoreItem.Type
+const (
+	TypeFile      = "file"
+	TypeDirectory = "directory"
+	TypeSymlink   = "symlink"
+	TypeOther     = "other"
+)
+
+// DetectFileType returns the kind of the file at path (without following symlinks)
+// and, for regular files, its MIME type sniffed from content or guessed from the extension
+func DetectFileType(path string) (kind, mimeType string) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", ""
+	}
+
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return TypeDirectory, ""
+	case mode&os.ModeSymlink != 0:
+		return TypeSymlink, ""
+	case !mode.IsRegular():
+		return TypeOther, ""
+	}
+
+	return TypeFile, sniffMIMEType(path)
+}
+
+// sniffMIMEType inspects the first bytes of a file, falling back to its extension
+func sniffMIMEType(path string) string {
+	sniffed := ""
+	if f, err := os.Open(path); err == nil {
+		buf := make([]byte, 512)
+		n, _ := f.Read(buf)
+		f.Close()
+		if n > 0 {
+			sniffed = http.DetectContentType(buf[:n])
+		}
+	}
+
+	// Generic answers are improved upon by the extension when it is known
+	if sniffed == "" || sniffed == "application/octet-stream" || strings.HasPrefix(sniffed, "text/plain") {
+		if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+			return byExt
+		}
+	}
+
+	return sniffed
+}
+
+// MatchesType reports whether the item is of the requested kind. Accepted kinds are
+// "file", "dir"/"directory", "symlink"/"link", a MIME category such as "image" or "text",
+// or a full MIME type such as "image/png". Items trashed before types were recorded are
+// inspected on disk at payloadPath.
+func (item RestoreItem) MatchesType(kind, payloadPath string) bool {
+	itemKind, mimeType := item.Type, item.MIMEType
+	if itemKind == "" {
+		itemKind, mimeType = DetectFileType(payloadPath)
+	}
+
+	switch kind = strings.ToLower(kind); kind {
+	case "file", TypeOther:
+		return itemKind == kind
+	case "dir", TypeDirectory:
+		return itemKind == TypeDirectory
+	case "link", TypeSymlink:
+		return itemKind == TypeSymlink
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	if strings.Contains(kind, "/") {
+		return mediaType == kind
+	}
+	return strings.SplitN(mediaType, "/", 2)[0] == kind
+}
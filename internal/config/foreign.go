@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trashInfoDateLayout is the layout of DeletionDate in .trashinfo files, in local time
+const trashInfoDateLayout = "2006-01-02T15:04:05"
+
+// ForeignTrash is a trash directory of the freedesktop.org trash specification,
+// shared by trash-cli, gio and file managers, holding items of the current user
+type ForeignTrash struct {
+	Dir   string `json:"dir" yaml:"dir"`
+	Items int    `json:"items" yaml:"items"`
+}
+
+// ForeignTrashes returns the freedesktop.org trash directories that hold items:
+// deletions other tools made beside this trash
+func ForeignTrashes() []ForeignTrash {
+	var trashes []ForeignTrash
+	seen := make(map[string]bool)
+	for _, dir := range freedesktopTrashDirs() {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		infos, _ := filepath.Glob(filepath.Join(dir, "info", "*.trashinfo"))
+		if len(infos) > 0 {
+			trashes = append(trashes, ForeignTrash{Dir: dir, Items: len(infos)})
+		}
+	}
+	return trashes
+}
+
+// ForeignAlias is a line of a shell startup file handing deletions to another
+// trash tool
+type ForeignAlias struct {
+	File string
+	Line int
+	Text string
+	Tool string
+}
+
+// foreignCommands are the commands of other trash tools, by the tool they belong to
+var foreignCommands = []struct{ command, tool string }{
+	{"trash-put", "trash-cli"},
+	{"gio trash", "gio"},
+	{"gvfs-trash", "gvfs"},
+	{"kioclient move", "KDE"},
+	{"kioclient5 move", "KDE"},
+	{"rmtrash", "rmtrash"},
+}
+
+// FindForeignAliases scans the shell startup files of the current user for
+// aliases that call another trash tool, which scatters deletions across trashes
+func FindForeignAliases() []ForeignAlias {
+	var aliases []ForeignAlias
+	for _, path := range shellStartupFiles() {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(text, "alias ") && !strings.HasPrefix(text, "abbr ") {
+				continue
+			}
+			for _, foreign := range foreignCommands {
+				if strings.Contains(text, foreign.command) {
+					aliases = append(aliases, ForeignAlias{File: path, Line: line, Text: text, Tool: foreign.tool})
+					break
+				}
+			}
+		}
+		file.Close()
+	}
+	return aliases
+}
+
+// ForeignImport is the outcome of importing one item of a foreign trash
+type ForeignImport struct {
+	Name string // its name in the files directory of the foreign trash
+	Item RestoreItem
+	Err  error
+}
+
+// ImportForeignTrash moves the items of the freedesktop.org trash directory dir
+// into a new session, keeping where they came from and when they were deleted,
+// and removes their .trashinfo files, so that list, restore and empty manage
+// them. Items on another filesystem are copied. With dryRun, nothing is moved
+// and the session is empty. The returned error only reports a failure to set
+// up or save the session; per-item failures are in the results.
+func ImportForeignTrash(dir string, dryRun bool) (string, []ForeignImport, error) {
+	infos, err := filepath.Glob(filepath.Join(dir, "info", "*.trashinfo"))
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Strings(infos)
+	if len(infos) == 0 {
+		return "", nil, nil
+	}
+
+	var results []ForeignImport
+	for _, infoPath := range infos {
+		name := strings.TrimSuffix(filepath.Base(infoPath), ".trashinfo")
+		item, err := readTrashInfo(dir, infoPath)
+		if err == nil {
+			if _, statErr := os.Lstat(filepath.Join(dir, "files", name)); statErr != nil {
+				err = fmt.Errorf("%s has no payload in %s", name, filepath.Join(dir, "files"))
+			}
+		}
+		results = append(results, ForeignImport{Name: name, Item: item, Err: err})
+	}
+	if dryRun {
+		return "", results, nil
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return "", results, err
+	}
+	defer unlock()
+
+	trashDir, err := CreateTrashTimestampDir()
+	if err != nil {
+		return "", results, err
+	}
+
+	owner := CurrentOwner()
+	metadata := &RestoreMetadata{Items: []RestoreItem{}}
+	for i := range results {
+		result := &results[i]
+		if result.Err != nil {
+			continue
+		}
+		payload := filepath.Join(dir, "files", result.Name)
+		kind, mimeType := DetectFileType(payload)
+
+		storagePath, err := MoveToTrash(payload, trashDir)
+		if err != nil {
+			result.Err = err
+			continue
+		}
+		// Names in the files directory are made unique; the original name is the one to restore
+		if name := result.Item.Name; name != result.Name {
+			renamed := filepath.Join(filepath.Dir(storagePath), name)
+			if err := storeFS.Rename(filepath.Join(trashDir, storagePath), filepath.Join(trashDir, renamed)); err == nil {
+				storagePath = filepath.ToSlash(renamed)
+			}
+		}
+		os.Remove(filepath.Join(dir, "info", result.Name+".trashinfo"))
+
+		size, _ := treeSize(storeFS, filepath.Join(trashDir, storagePath))
+		result.Item.Name = filepath.Base(storagePath)
+		result.Item.StoragePath = storagePath
+		result.Item.User, result.Item.UID, result.Item.Hostname = owner.User, owner.UID, owner.Hostname
+		result.Item.Type, result.Item.MIMEType = kind, mimeType
+		result.Item.Size = &size
+		result.Item.ID = result.Item.ShortID()
+		metadata.Items = append(metadata.Items, result.Item)
+	}
+
+	if len(metadata.Items) == 0 {
+		storeFS.RemoveAll(trashDir)
+		return "", results, nil
+	}
+	return filepath.Base(trashDir), results, SaveRestoreMetadata(trashDir, metadata)
+}
+
+// readTrashInfo reads the original location and deletion time of an item from
+// its .trashinfo file. Paths of a trash at the top of a volume may be relative
+// to that volume.
+func readTrashInfo(dir, infoPath string) (RestoreItem, error) {
+	file, err := os.Open(infoPath)
+	if err != nil {
+		return RestoreItem{}, err
+	}
+	defer file.Close()
+
+	var item RestoreItem
+	inSection := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[Trash Info]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inSection || !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			path, err := url.PathUnescape(value)
+			if err != nil {
+				return item, fmt.Errorf("invalid Path in %s: %w", infoPath, err)
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(volumeTop(dir), path)
+			}
+			item.OriginalPath = filepath.Clean(path)
+		case "DeletionDate":
+			if deleted, err := time.ParseInLocation(trashInfoDateLayout, value, time.Local); err == nil {
+				item.TrashedAt = deleted.Format(time.RFC3339)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return item, err
+	}
+
+	if item.OriginalPath == "" {
+		return item, fmt.Errorf("%s does not name the original location", infoPath)
+	}
+	if item.TrashedAt == "" {
+		item.TrashedAt = time.Now().Format(time.RFC3339)
+	}
+	item.Name = filepath.Base(item.OriginalPath)
+	return item, nil
+}
+
+// volumeTop returns the top directory of the volume a trash at $topdir/.Trash/$uid
+// or $topdir/.Trash-$uid belongs to
+func volumeTop(dir string) string {
+	parent := filepath.Dir(dir)
+	if filepath.Base(parent) == ".Trash" {
+		return filepath.Dir(parent)
+	}
+	return parent
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FS is the filesystem holding the trash store. Every operation on sessions,
+// payloads and .restore files goes through it, so that the store can live
+// somewhere other than the local disk. Paths are in the form returned by
+// GetConfigDir, and errors for missing files must match fs.ErrNotExist.
+type FS interface {
+	Open(name string) (io.ReadCloser, error)
+	// Create truncates or creates a regular file with the permission bits perm
+	Create(name string, perm fs.FileMode) (io.WriteCloser, error)
+	// Lstat describes name without following a final symlink
+	Lstat(name string) (fs.FileInfo, error)
+	// ReadDir lists a directory sorted by name
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Mkdir(name string, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Rename(oldname, newname string) error
+	Remove(name string) error
+	RemoveAll(name string) error
+	Symlink(target, name string) error
+	Readlink(name string) (string, error)
+	Chmod(name string, mode fs.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+}
+
+// LocalFS is the FS of the local disk, used unless UseStoreFS selects another
+type LocalFS struct{}
+
+func (LocalFS) Open(name string) (io.ReadCloser, error) { return os.Open(name) }
+
+func (LocalFS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+func (LocalFS) Lstat(name string) (fs.FileInfo, error)       { return os.Lstat(name) }
+func (LocalFS) ReadDir(name string) ([]fs.DirEntry, error)   { return os.ReadDir(name) }
+func (LocalFS) Mkdir(name string, perm fs.FileMode) error    { return os.Mkdir(name, perm) }
+func (LocalFS) MkdirAll(name string, perm fs.FileMode) error { return os.MkdirAll(name, perm) }
+func (LocalFS) Rename(oldname, newname string) error         { return os.Rename(oldname, newname) }
+func (LocalFS) Remove(name string) error                     { return os.Remove(name) }
+func (LocalFS) RemoveAll(name string) error                  { return os.RemoveAll(name) }
+func (LocalFS) Symlink(target, name string) error            { return os.Symlink(target, name) }
+func (LocalFS) Readlink(name string) (string, error)         { return os.Readlink(name) }
+func (LocalFS) Chmod(name string, mode fs.FileMode) error    { return os.Chmod(name, mode) }
+
+func (LocalFS) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// storeFS is the filesystem of the trash store
+var storeFS FS = LocalFS{}
+
+// UseStoreFS makes the trash store live on fsys instead of the local disk.
+// Command code is unaffected: it keeps using GetConfigDir and the functions
+// of this package.
+func UseStoreFS(fsys FS) {
+	storeFS = fsys
+}
+
+// StoreFS returns the filesystem of the trash store
+func StoreFS() FS {
+	return storeFS
+}
+
+// isLocal reports whether fsys is the local disk, where payloads can be
+// renamed in and out of the store instead of copied
+func isLocal(fsys FS) bool {
+	_, ok := fsys.(LocalFS)
+	return ok
+}
+
+// ReadFile reads the whole file name from fsys
+func ReadFile(fsys FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteFile writes data to the file name on fsys, creating it with perm
+func WriteFile(fsys FS, name string, data []byte, perm fs.FileMode) error {
+	f, err := fsys.Create(name, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// treeSize returns the total size in bytes of a file or directory tree on fsys,
+// counting symlinks by their own size like PathSize
+func treeSize(fsys FS, path string) (uint64, error) {
+	if isLocal(fsys) {
+		return PathSize(path)
+	}
+
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		if info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0 {
+			return uint64(info.Size()), nil
+		}
+		return 0, nil
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+	var total uint64
+	for _, entry := range entries {
+		size, err := treeSize(fsys, filepath.Join(path, entry.Name()))
+		if err != nil {
+			return total, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// transfer copies the file, directory, symlink or special file src on srcFS to
+// dst on dstFS. Between two local paths the platform's CopyDir and CopyFile are
+// used; otherwise the tree is copied through the FS interface, leaving out
+// special files.
+func transfer(srcFS FS, src string, dstFS FS, dst string) error {
+	info, err := srcFS.Lstat(src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return withKind(ErrNotFound, fmt.Errorf("accessing source: %w", err))
+	}
+	if err != nil {
+		return fmt.Errorf("accessing source: %w", err)
+	}
+
+	if isLocal(srcFS) && isLocal(dstFS) {
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			return CopySymlink(src, dst)
+		case info.Mode()&(os.ModeNamedPipe|os.ModeSocket|os.ModeDevice) != 0:
+			return CopySpecial(src, dst)
+		case info.IsDir():
+			return CopyDir(src, dst)
+		default:
+			return CopyFile(src, dst)
+		}
+	}
+
+	if info.Mode()&(os.ModeNamedPipe|os.ModeSocket|os.ModeDevice) != 0 {
+		return &CopyError{Op: "copy", Path: src, Err: ErrUnsupportedFileType}
+	}
+	return copyTree(srcFS, src, info, dstFS, dst)
+}
+
+// copyTree copies src, described by info, from srcFS to dstFS entry by entry
+func copyTree(srcFS FS, src string, info fs.FileInfo, dstFS FS, dst string) error {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := srcFS.Readlink(src)
+		if err != nil {
+			return &CopyError{Op: "read link", Path: src, Err: err}
+		}
+		if err := dstFS.Symlink(target, dst); err != nil {
+			return &CopyError{Op: "create symlink", Path: dst, Err: err}
+		}
+		return nil
+
+	case info.IsDir():
+		if err := dstFS.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return &CopyError{Op: "create directory", Path: dst, Err: err}
+		}
+		entries, err := srcFS.ReadDir(src)
+		if err != nil {
+			return &CopyError{Op: "read directory", Path: src, Err: err}
+		}
+		for _, entry := range entries {
+			srcPath := filepath.Join(src, entry.Name())
+			if entry.Type()&(os.ModeNamedPipe|os.ModeSocket|os.ModeDevice) != 0 {
+				warnSkipped(&CopyError{Op: "skipped special file", Path: srcPath, Err: ErrUnsupportedFileType})
+				continue
+			}
+			childInfo, err := entry.Info()
+			if err != nil {
+				return &CopyError{Op: "stat", Path: srcPath, Err: err}
+			}
+			if err := copyTree(srcFS, srcPath, childInfo, dstFS, filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		// Children are in place, so the directory keeps its modification time
+		if err := dstFS.Chtimes(dst, time.Now(), info.ModTime()); err != nil {
+			return &CopyError{Op: "set times", Path: dst, Err: err}
+		}
+		return nil
+
+	default:
+		if err := copyFileBetween(srcFS, src, dstFS, dst, info.Mode().Perm()); err != nil {
+			return &CopyError{Op: "copy", Path: src, Err: err}
+		}
+		return nil
+	}
+}
+
+// copyFileBetween copies the contents of a regular file from srcFS to dstFS
+// and gives the copy the permission bits perm
+func copyFileBetween(srcFS FS, src string, dstFS FS, dst string, perm fs.FileMode) error {
+	sourceFile, err := srcFS.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := dstFS.Create(dst, perm)
+	if err != nil {
+		return err
+	}
+	if err := copyContents(destFile, sourceFile, src); err != nil {
+		destFile.Close()
+		return err
+	}
+	if err := destFile.Close(); err != nil {
+		return err
+	}
+
+	// Create is subject to the umask on local disks
+	if isLocal(dstFS) {
+		return preserveMode(dst, perm)
+	}
+	return nil
+}
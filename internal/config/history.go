@@ -0,0 +1,150 @@
+package config
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryFileName is the log of every item trashed, restored or purged, kept
+// in the trash directory with one JSON entry per line
+const HistoryFileName = "history.jsonl"
+
+// Operations recorded in the history
+const (
+	OpTrash   = "trash"
+	OpRestore = "restore"
+	OpPurge   = "purge"
+)
+
+// HistoryEntry records what happened to one item
+type HistoryEntry struct {
+	Time    string `json:"time"` // RFC 3339
+	Op      string `json:"op"`   // OpTrash, OpRestore or OpPurge
+	Name    string `json:"name"`
+	Path    string `json:"path"` // the original path
+	Session string `json:"session"`
+
+	// Dest is where a restore put the item, when that is not Path
+	Dest string `json:"dest,omitempty"`
+
+	// Invocation identifies the trash process that did it and is shared by
+	// everything that process did; Command is its command line
+	Invocation string `json:"invocation"`
+	Command    string `json:"command,omitempty"`
+}
+
+// invocationID identifies this process in the history
+var invocationID = func() string {
+	id := make([]byte, 4)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}()
+
+// commandLine returns the command line of this process, quoting arguments
+// that would otherwise be ambiguous
+func commandLine() string {
+	args := make([]string, len(os.Args))
+	for i, arg := range os.Args {
+		if i == 0 {
+			arg = filepath.Base(arg)
+		}
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\") {
+			arg = strconv.Quote(arg)
+		}
+		args[i] = arg
+	}
+	return strings.Join(args, " ")
+}
+
+// historyEntry describes an operation on an item of a session, done now
+func historyEntry(op, session string, item RestoreItem) HistoryEntry {
+	return HistoryEntry{
+		Time:       time.Now().Format(time.RFC3339),
+		Op:         op,
+		Name:       item.Name,
+		Path:       item.OriginalPath,
+		Session:    session,
+		Invocation: invocationID,
+		Command:    commandLine(),
+	}
+}
+
+// recordPurged adds purged items to the history
+func recordPurged(items []PurgedItem) {
+	entries := make([]HistoryEntry, 0, len(items))
+	for _, entry := range items {
+		entries = append(entries, historyEntry(OpPurge, entry.Session, entry.Item))
+	}
+	recordHistory(entries...)
+}
+
+// recordHistory appends entries to the history. Like the purge counters it is
+// best effort and never fails an operation.
+func recordHistory(entries ...HistoryEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	// A single append keeps the lines of concurrent invocations whole
+	f, err := os.OpenFile(filepath.Join(configDir, HistoryFileName), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	f.Write(data)
+	f.Close()
+}
+
+// LoadHistory reads the history, oldest first. A missing file yields no
+// entries and lines that cannot be parsed are skipped.
+func LoadHistory() ([]HistoryEntry, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(configDir, HistoryFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return entries, nil
+}
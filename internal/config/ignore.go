@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the file declaring paths that must never be trashed.
+// It uses .gitignore syntax and applies to the directory it is in and below.
+const IgnoreFileName = ".trashignore"
+
+// ignoreRule is one pattern line of a .trashignore file
+type ignoreRule struct {
+	base     string // directory containing the .trashignore file
+	line     string // the line as written, for reporting
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// IgnoreMatch describes the .trashignore rule that protects a path
+type IgnoreMatch struct {
+	File    string
+	Pattern string
+}
+
+// checkNotProtected refuses a path protected by a .trashignore file. A file
+// that cannot be read protects nothing, as for MatchTrashIgnore's callers.
+func checkNotProtected(path string) error {
+	match, _ := MatchTrashIgnore(path)
+	if match == nil {
+		return nil
+	}
+	return withKind(ErrProtected, fmt.Errorf("refusing to trash %s: protected by %s (%s)", path, match.File, match.Pattern))
+}
+
+// MatchTrashIgnore reports whether path is protected by a .trashignore file in
+// its directory or any parent directory. Later rules override earlier ones, and
+// rules in deeper directories override those of their parents.
+func MatchTrashIgnore(path string) (*IgnoreMatch, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(absPath)
+	isDir := err == nil && info.IsDir()
+
+	var rules []ignoreRule
+	for _, dir := range ancestors(filepath.Dir(absPath)) {
+		fileRules, err := loadIgnoreFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	var match *IgnoreMatch
+	for _, rule := range rules {
+		rel, err := filepath.Rel(rule.base, absPath)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if !rule.matches(filepath.ToSlash(rel), isDir) {
+			continue
+		}
+		if rule.negate {
+			match = nil
+			continue
+		}
+		match = &IgnoreMatch{File: filepath.Join(rule.base, IgnoreFileName), Pattern: rule.line}
+	}
+
+	return match, nil
+}
+
+// ancestors returns dir and its parents, outermost first
+func ancestors(dir string) []string {
+	var dirs []string
+	for {
+		dirs = append([]string{dir}, dirs...)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dirs
+		}
+		dir = parent
+	}
+}
+
+// loadIgnoreFile parses the .trashignore file in dir, if there is one
+func loadIgnoreFile(dir string) ([]ignoreRule, error) {
+	file, err := os.Open(filepath.Join(dir, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: dir, line: line}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// A slash anywhere but the end anchors the pattern to the file's directory
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// matches reports whether the slash-separated path rel (relative to the rule's
+// directory) or one of its parent directories matches the rule
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	segments := strings.Split(rel, "/")
+	patternSegments := strings.Split(r.pattern, "/")
+
+	for i := 1; i <= len(segments); i++ {
+		// Every prefix but the full path is a directory
+		last := i == len(segments)
+		if r.dirOnly && last && !isDir {
+			continue
+		}
+
+		if r.anchored {
+			if matchSegments(patternSegments, segments[:i]) {
+				return true
+			}
+		} else if ok, _ := filepath.Match(r.pattern, segments[i-1]); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// matches any number of segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,41 @@
+//go:build darwin
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// Exit codes of security(1)
+const (
+	securityNotFound      = 44
+	securityAlreadyExists = 45
+)
+
+// keyringGet looks a secret up in the login keychain, returning errNoKey when there is none
+func keyringGet(service, account string) (string, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound {
+		return "", errNoKey
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// keyringSet stores a new secret in the login keychain
+func keyringSet(service, account, secret string) error {
+	cmd := exec.Command("security", "add-generic-password", "-s", service, "-a", account, "-w", secret)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityAlreadyExists {
+		return fs.ErrExist
+	}
+	return err
+}
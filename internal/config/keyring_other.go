@@ -0,0 +1,34 @@
+//go:build !darwin && !windows
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// keyringGet looks a secret up in the Secret Service (GNOME Keyring, KWallet)
+// with secret-tool, returning errNoKey when there is none
+func keyringGet(service, account string) (string, error) {
+	out, err := runKeyTool(nil, "secret-tool", "lookup", "service", service, "account", account)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) || (err == nil && strings.TrimSpace(string(out)) == "") {
+		return "", errNoKey
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// keyringSet stores a new secret in the Secret Service with secret-tool
+func keyringSet(service, account, secret string) error {
+	if _, err := keyringGet(service, account); err == nil {
+		return fs.ErrExist
+	}
+	_, err := runKeyTool([]byte(secret), "secret-tool", "store", "--label=trash signing key",
+		"service", service, "account", account)
+	return err
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Where the signing key is kept, see the key_source setting
+const (
+	KeySourceFile       = "file"       // signing.key, readable only by its owner
+	KeySourceKeyring    = "keyring"    // the OS keyring
+	KeySourceAge        = "age"        // signing.key.age, encrypted to an age identity
+	KeySourceGPG        = "gpg"        // signing.key.gpg, encrypted to the default GPG key
+	KeySourcePassphrase = "passphrase" // signing.key.enc, encrypted with a passphrase
+)
+
+// PassphraseEnv holds the passphrase of the passphrase key source on headless machines
+const PassphraseEnv = "TRASH_PASSPHRASE"
+
+// errNoKey is returned by a keyStore that holds no key yet
+var errNoKey = errors.New("no signing key")
+
+// keyStore keeps the signing key somewhere
+type keyStore interface {
+	// load returns the key, or errNoKey when none is stored
+	load() ([]byte, error)
+	// save stores a new key; fs.ErrExist means another invocation stored one first
+	save(key []byte) error
+	// String names the store in messages
+	String() string
+}
+
+// PassphrasePrompt, when set, asks for the passphrase of the passphrase key
+// source. It is called at most once, the first time the key is needed.
+var PassphrasePrompt func() (string, error)
+
+// forcePassphrase makes the passphrase key source apply whatever key_source says
+var forcePassphrase bool
+
+// UsePassphrase makes the signing key come from the passphrase key source,
+// asking prompt for the passphrase unless TRASH_PASSPHRASE is set
+func UsePassphrase(prompt func() (string, error)) {
+	forcePassphrase = true
+	PassphrasePrompt = prompt
+}
+
+// passphrase returns the passphrase from TRASH_PASSPHRASE or PassphrasePrompt
+func passphrase() (string, error) {
+	if value := os.Getenv(PassphraseEnv); value != "" {
+		return value, nil
+	}
+	if PassphrasePrompt == nil {
+		return "", fmt.Errorf("the signing key is protected by a passphrase: use --passphrase or set %s", PassphraseEnv)
+	}
+	value, err := PassphrasePrompt()
+	if err != nil {
+		return "", err
+	}
+	PassphrasePrompt = func() (string, error) { return value, nil }
+	return value, nil
+}
+
+// signingKeyStore returns the store selected by the key_source setting
+func signingKeyStore() (keyStore, error) {
+	dir, err := homeStoreDir()
+	if err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(dir, SigningKeyFileName)
+
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	source := settings.KeySource
+	if forcePassphrase {
+		source = KeySourcePassphrase
+	}
+
+	switch source {
+	case "", KeySourceFile:
+		return fileKeyStore{path: keyPath}, nil
+	case KeySourceKeyring:
+		return keyringStore{}, nil
+	case KeySourceAge:
+		if settings.AgeIdentity == "" {
+			return nil, fmt.Errorf("key_source age needs the age_identity setting")
+		}
+		return ageKeyStore{path: keyPath + ".age", identity: settings.AgeIdentity}, nil
+	case KeySourceGPG:
+		return gpgKeyStore{path: keyPath + ".gpg"}, nil
+	case KeySourcePassphrase:
+		return passphraseKeyStore{path: keyPath + ".enc"}, nil
+	}
+	return nil, fmt.Errorf("invalid key_source %q", source)
+}
+
+// signingKey reads the signing key, generating it when create is set and there
+// is none yet. A key left in signing.key by the file source is moved into
+// another source the first time that source is used, so that signatures stay valid.
+func signingKey(create bool) ([]byte, error) {
+	store, err := signingKeyStore()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		key, err := store.load()
+		if err == nil {
+			return key, nil
+		}
+		if !errors.Is(err, errNoKey) {
+			return nil, fmt.Errorf("failed to read signing key from %s: %w", store, err)
+		}
+
+		plain, isFile := store.(fileKeyStore)
+		if !isFile {
+			dir, err := homeStoreDir()
+			if err != nil {
+				return nil, err
+			}
+			plain = fileKeyStore{path: filepath.Join(dir, SigningKeyFileName)}
+			if key, err := plain.load(); err == nil {
+				if err := store.save(key); err != nil {
+					return nil, fmt.Errorf("failed to move signing key to %s: %w", store, err)
+				}
+				os.Remove(plain.path)
+				return key, nil
+			}
+		}
+
+		if !create {
+			return nil, fmt.Errorf("there is no signing key in %s", store)
+		}
+
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		err = store.save(key)
+		if errors.Is(err, fs.ErrExist) {
+			continue // another invocation won the race; use its key
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to store signing key in %s: %w", store, err)
+		}
+		return key, nil
+	}
+}
+
+// decodeKey parses a hex-encoded key
+func decodeKey(data []byte) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) == 0 {
+		return nil, errors.New("invalid signing key")
+	}
+	return key, nil
+}
+
+// createExclusive writes data to a new file with mode 0600, failing with
+// fs.ErrExist when it exists
+func createExclusive(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// fileKeyStore keeps the key in plain hex in a file
+type fileKeyStore struct {
+	path string
+}
+
+func (s fileKeyStore) String() string { return s.path }
+
+func (s fileKeyStore) load() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, errNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey(data)
+}
+
+func (s fileKeyStore) save(key []byte) error {
+	return createExclusive(s.path, []byte(hex.EncodeToString(key)+"\n"))
+}
+
+// keyringStore keeps the key in the OS keyring
+type keyringStore struct{}
+
+// Service and account the key is filed under in the OS keyring
+const (
+	keyringService = "trash"
+	keyringAccount = "signing-key"
+)
+
+func (keyringStore) String() string { return "the OS keyring" }
+
+func (keyringStore) load() ([]byte, error) {
+	secret, err := keyringGet(keyringService, keyringAccount)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey([]byte(secret))
+}
+
+func (keyringStore) save(key []byte) error {
+	return keyringSet(keyringService, keyringAccount, hex.EncodeToString(key))
+}
+
+// runKeyTool runs an external key tool with stdin, returning its output and
+// its error message on failure
+func runKeyTool(stdin []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s: %s", name, message)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// ageKeyStore keeps the key in a file encrypted to an age identity
+type ageKeyStore struct {
+	path     string
+	identity string
+}
+
+func (s ageKeyStore) String() string { return s.path }
+
+func (s ageKeyStore) load() ([]byte, error) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil, errNoKey
+	}
+	plain, err := runKeyTool(nil, "age", "--decrypt", "--identity", s.identity, s.path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey(plain)
+}
+
+func (s ageKeyStore) save(key []byte) error {
+	encrypted, err := runKeyTool([]byte(hex.EncodeToString(key)+"\n"), "age", "--encrypt", "--identity", s.identity)
+	if err != nil {
+		return err
+	}
+	return createExclusive(s.path, encrypted)
+}
+
+// gpgKeyStore keeps the key in a file encrypted to the user's default GPG key
+type gpgKeyStore struct {
+	path string
+}
+
+func (s gpgKeyStore) String() string { return s.path }
+
+func (s gpgKeyStore) load() ([]byte, error) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil, errNoKey
+	}
+	plain, err := runKeyTool(nil, "gpg", "--quiet", "--batch", "--decrypt", s.path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey(plain)
+}
+
+func (s gpgKeyStore) save(key []byte) error {
+	encrypted, err := runKeyTool([]byte(hex.EncodeToString(key)+"\n"), "gpg", "--quiet", "--batch", "--encrypt", "--default-recipient-self")
+	if err != nil {
+		return err
+	}
+	return createExclusive(s.path, encrypted)
+}
+
+// passphraseKeyStore keeps the key in a file encrypted with AES-GCM under a
+// key derived from a passphrase: salt, nonce, then the sealed key
+type passphraseKeyStore struct {
+	path string
+}
+
+// Parameters of the passphrase key derivation
+const (
+	passphraseSaltSize   = 16
+	passphraseIterations = 600000
+)
+
+func (s passphraseKeyStore) String() string { return s.path }
+
+func (s passphraseKeyStore) load() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, errNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < passphraseSaltSize {
+		return nil, errors.New("invalid encrypted signing key")
+	}
+	salt, data := data[:passphraseSaltSize], data[passphraseSaltSize:]
+	gcm, err := passphraseCipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted signing key")
+	}
+	key, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("wrong passphrase")
+	}
+	return key, nil
+}
+
+func (s passphraseKeyStore) save(key []byte) error {
+	salt := make([]byte, passphraseSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := passphraseCipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := append(append(salt, nonce...), gcm.Seal(nil, nonce, key, nil)...)
+	return createExclusive(s.path, data)
+}
+
+// passphraseCipher returns the AES-GCM cipher keyed by the passphrase and salt
+func passphraseCipher(salt []byte) (cipher.AEAD, error) {
+	value, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(value), salt, passphraseIterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key of keyLen bytes with PBKDF2-HMAC-SHA256 (RFC 8018)
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var derived []byte
+	for block := uint32(1); len(derived) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		derived = append(derived, t...)
+	}
+	return derived[:keyLen]
+}
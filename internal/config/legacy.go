@@ -0,0 +1,50 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// MetadataVersion is the .restore format written by this version of trash.
+// Files without a version were written by older versions: their items hold at
+// least a name, original path and trash time, with the payload directly in the
+// session directory. Every field added since is optional, so those files are
+// read as they are and only rewritten in the current format when the session
+// changes.
+const MetadataVersion = 2
+
+// utf8BOM is prepended to .restore files by some editors
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ParseRestoreMetadata decodes a .restore file of any version, filling in what
+// older versions left out, and refuses files from newer versions, whose fields
+// would be lost when the session is next saved
+func ParseRestoreMetadata(data []byte) (*RestoreMetadata, error) {
+	var metadata RestoreMetadata
+	if err := json.Unmarshal(bytes.TrimPrefix(data, utf8BOM), &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Version > MetadataVersion {
+		return nil, fmt.Errorf("written by a newer version of trash (metadata version %d, this version reads up to %d)",
+			metadata.Version, MetadataVersion)
+	}
+
+	if metadata.Items == nil {
+		metadata.Items = []RestoreItem{}
+	}
+	for i := range metadata.Items {
+		upgradeLegacyItem(&metadata.Items[i])
+	}
+	return &metadata, nil
+}
+
+// upgradeLegacyItem adapts an item written by an older version. Those recorded
+// the path as given when the working directory could not be determined; such a
+// relative path cannot be restored to, so it is treated as unknown.
+func upgradeLegacyItem(item *RestoreItem) {
+	if item.OriginalPath != "" && !filepath.IsAbs(item.OriginalPath) {
+		item.OriginalPath = ""
+	}
+}
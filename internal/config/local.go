@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LocalTrashDirName is the per-project trash directory created at the project root
+const LocalTrashDirName = ".trash"
+
+// LocalMarkerName marks a project root whose trash operations always use the local trash
+const LocalMarkerName = ".trashrc"
+
+// storeDir overrides the global trash directory when set
+var storeDir string
+
+// UseStoreDir makes every operation use dir instead of the global trash directory
+func UseStoreDir(dir string) {
+	storeDir = dir
+}
+
+// StoreOverridden reports whether a directory other than the home trash, such
+// as a project's local trash, was selected with UseStoreDir
+func StoreOverridden() bool {
+	return storeDir != ""
+}
+
+// FindMarkedProject walks up from start looking for a directory containing a
+// .trashrc file and returns it
+func FindMarkedProject(start string) (string, bool) {
+	return findUp(start, LocalMarkerName)
+}
+
+// FindProjectRoot returns the nearest directory above start containing a .trashrc
+// file or a .git entry, or start itself when there is none
+func FindProjectRoot(start string) string {
+	if root, ok := findUp(start, LocalMarkerName); ok {
+		return root
+	}
+	if root, ok := findUp(start, ".git"); ok {
+		return root
+	}
+	return start
+}
+
+// LocalStoreDir returns the local trash directory of a project root
+func LocalStoreDir(root string) string {
+	return filepath.Join(root, LocalTrashDirName)
+}
+
+// findUp returns the first directory from start upwards that contains name
+func findUp(start, name string) (string, bool) {
+	dir := start
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
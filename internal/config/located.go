@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TrashedLocation identifies a path that lies inside a trash directory
+type TrashedLocation struct {
+	StoreDir string       // the trash directory
+	Session  string       // the session containing the path
+	Item     *RestoreItem // the trashed item containing the path, nil without metadata
+	Rel      string       // the path below the item's payload, "" for the payload itself
+}
+
+// TrashDir returns the session directory of the location
+func (l *TrashedLocation) TrashDir() string {
+	return filepath.Join(l.StoreDir, l.Session)
+}
+
+// LocateInTrash reports whether path is a trashed item, or lies inside one, in
+// the current trash directory or any trash root
+func LocateInTrash(path string) (*TrashedLocation, bool) {
+	stores := []string{}
+	if configDir, err := GetConfigDir(); err == nil {
+		stores = append(stores, configDir)
+	}
+	if roots, err := Roots(); err == nil {
+		for _, root := range roots {
+			stores = append(stores, root.Dir)
+		}
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, false
+	}
+	resolved := resolvePath(absPath)
+
+	for _, store := range stores {
+		storeDir := resolvePath(store)
+		if !IsWithin(resolved, storeDir) {
+			continue
+		}
+
+		rel, _ := filepath.Rel(storeDir, resolved)
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 3)
+		if len(parts) < 2 {
+			return nil, false // a session directory itself, not an item
+		}
+
+		location := &TrashedLocation{StoreDir: store, Session: parts[0]}
+		if len(parts) == 3 {
+			location.Rel = filepath.FromSlash(parts[2])
+		}
+		if metadata, err := LoadRestoreMetadata(location.TrashDir()); err == nil {
+			for i := range metadata.Items {
+				if metadata.Items[i].Entry() == parts[1] {
+					location.Item = &metadata.Items[i]
+				}
+			}
+		}
+
+		// Below an item directory, the path is relative to the payload in it
+		if location.Item != nil && location.Item.StoragePath != "" {
+			below, _ := filepath.Rel(location.Item.Storage(), filepath.Join(parts[1], location.Rel))
+			if below == "." || below == ".." {
+				below = ""
+			}
+			location.Rel = below
+		}
+		return location, true
+	}
+
+	return nil, false
+}
+
+// PurgeTrashedItem permanently deletes a trashed item and drops it from its session
+func PurgeTrashedItem(location *TrashedLocation) error {
+	if location.Item == nil {
+		return fmt.Errorf("no metadata for this item in session %s", location.Session)
+	}
+
+	payload := filepath.Join(location.TrashDir(), location.Item.Entry())
+	size := location.Item.PayloadSize(location.TrashDir())
+	if err := purgeTree(location.StoreDir, payload); err != nil {
+		return fmt.Errorf("failed to purge %s: %w", location.Item.Name, err)
+	}
+
+	removed, err := dropFromSession(location.TrashDir(), *location.Item)
+	counted := PurgeStats{Items: 1, Bytes: size}
+	if removed {
+		counted.Sessions = 1
+	}
+	recordPurge(counted)
+	recordPurged([]TrashedItem{{Session: location.Session, Item: *location.Item}})
+
+	return err
+}
+
+// RefileTrashedItem moves a trashed item from another trash directory into a new
+// session of the current one, keeping its original path and trash time
+func RefileTrashedItem(location *TrashedLocation) (string, error) {
+	if location.Item == nil {
+		return "", fmt.Errorf("no metadata for this item in session %s", location.Session)
+	}
+
+	trashDir, err := CreateTrashTimestampDir()
+	if err != nil {
+		return "", err
+	}
+
+	item := *location.Item
+	var storagePath string
+	if item.Snapshot != nil {
+		storagePath, err = moveSnapshotRef(location.TrashDir(), item, trashDir)
+	} else {
+		storagePath, err = MoveToTrash(filepath.Join(location.TrashDir(), item.Storage()), trashDir)
+	}
+	if err != nil {
+		storeFS.RemoveAll(trashDir)
+		return "", err
+	}
+	os.Remove(filepath.Join(location.TrashDir(), item.Entry())) // the emptied item directory, if any
+
+	refiled := item
+	refiled.StoragePath = storagePath
+	if err := SaveRestoreMetadata(trashDir, &RestoreMetadata{Items: []RestoreItem{refiled}}); err != nil {
+		return trashDir, err
+	}
+
+	_, err = dropFromSession(location.TrashDir(), item)
+	return trashDir, err
+}
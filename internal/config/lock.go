@@ -0,0 +1,176 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeldLockSuffix is appended to the name of a lock file for the lockfile that
+// is the lock on network filesystems: it exists while the lock is held
+const HeldLockSuffix = ".held"
+
+// staleLockAge is how long a lockfile may go without its holder refreshing
+// it before it is considered abandoned. It is generous, because the clocks
+// of NFS clients and the server that sets the times may disagree.
+const staleLockAge = 5 * time.Minute
+
+// LockStore takes an exclusive lock on the trash directory, waiting for other
+// invocations to release it. Call the returned function to release it.
+func LockStore() (func(), error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return lockFile(filepath.Join(configDir, LockFileName), "trash directory")
+}
+
+// lockFile takes an exclusive lock on the file at path, creating it, and
+// waits for other holders to release it; what names the locked thing in
+// errors. On network filesystems, where flock may not exclude other clients,
+// the lock is a lockfile next to it instead (see exclusiveLock).
+func lockFile(path, what string) (func(), error) {
+	if OnNetworkFS(filepath.Dir(path)) {
+		return exclusiveLock(path+HeldLockSuffix, what)
+	}
+	return flockFile(path, what)
+}
+
+// exclusiveLock takes a lock by creating the lockfile path with O_EXCL, which
+// is atomic on NFS too, and waits while another holder has it. The lockfile
+// names its holder and is touched while the lock is held, so that one left
+// by a process that died is recognized as stale (see HeldLockStale) and
+// taken over.
+func exclusiveLock(path, what string) (func(), error) {
+	owner, err := lockOwner()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", what, err)
+	}
+
+	delay := 10 * time.Millisecond
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, err = file.WriteString(owner)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to lock %s: %w", what, err)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", what, err)
+		}
+
+		if breakStaleLock(path) {
+			continue
+		}
+		time.Sleep(delay)
+		delay = min(2*delay, time.Second)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(staleLockAge / 5)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				os.Chtimes(path, now, now)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		// Unless it was taken over as stale meanwhile
+		if data, err := os.ReadFile(path); err == nil && string(data) == owner {
+			os.Remove(path)
+		}
+	}, nil
+}
+
+// lockOwner returns the content of a lockfile taken by this process: its
+// host, process ID and a token that tells its lockfiles apart from any other
+func lockOwner() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %d %s\n", hostname, os.Getpid(), hex.EncodeToString(token)), nil
+}
+
+// HeldLockStale reports whether the lockfile path was abandoned: its holder
+// ran on this host and is gone, or it was not refreshed for longer than a
+// holder ever leaves it
+func HeldLockStale(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	data, _ := os.ReadFile(path)
+	return lockStale(data, info.ModTime())
+}
+
+// lockStale reports whether a lockfile with this content and modification
+// time was abandoned (see HeldLockStale)
+func lockStale(data []byte, modTime time.Time) bool {
+	if time.Since(modTime) > staleLockAge {
+		return true
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return false // being written, or unknown to this version
+	}
+	hostname, _ := os.Hostname()
+	pid, err := strconv.Atoi(fields[1])
+	return err == nil && fields[0] == hostname && pid != os.Getpid() && !processAlive(pid)
+}
+
+// breakStaleLock removes the lockfile path if it is stale, and reports whether
+// it did. It is moved aside first and only removed if it is still the stale
+// one, so that a waiter that broke it a moment earlier does not lose the
+// lock it has taken since.
+func breakStaleLock(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return errors.Is(err, fs.ErrNotExist) // released meanwhile; try again now
+	}
+	data, _ := os.ReadFile(path)
+	if !lockStale(data, info.ModTime()) {
+		return false
+	}
+
+	token := make([]byte, 4)
+	rand.Read(token)
+	aside := path + ".stale-" + hex.EncodeToString(token)
+	if err := os.Rename(path, aside); err != nil {
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	movedInfo, err := os.Lstat(aside)
+	movedData, _ := os.ReadFile(aside)
+	if err == nil && (!movedInfo.ModTime().Equal(info.ModTime()) || string(movedData) != string(data)) {
+		// Another waiter's fresh lock; put it back unless the name is taken again
+		os.Link(aside, path)
+	}
+	os.Remove(aside)
+	return true
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package config
+
+// flockFile locks the file at path. Without flock the lock is a lockfile
+// created exclusively, as on network filesystems.
+func flockFile(path, what string) (func(), error) {
+	return exclusiveLock(path+HeldLockSuffix, what)
+}
+
+// processAlive cannot tell whether a process runs here; stale lockfiles are
+// only recognized by their age
+func processAlive(pid int) bool {
+	return true
+}
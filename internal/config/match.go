@@ -0,0 +1,64 @@
+package config
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MatchOptions controls how user supplied names are compared with trashed item names
+// Comparisons never alter the names stored in metadata or on disk
+type MatchOptions struct {
+	// Normalize compares the Unicode NFC forms of both sides, so names trashed
+	// on macOS (NFD) match names typed on Linux (NFC) and vice versa
+	Normalize bool
+
+	// IgnoreCase compares names without regard to letter case
+	IgnoreCase bool
+}
+
+// canonical returns s in the form used for comparison
+func (o MatchOptions) canonical(s string) string {
+	if o.Normalize {
+		s = norm.NFC.String(s)
+	}
+	if o.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// Equal reports whether name matches want exactly under the options
+func (o MatchOptions) Equal(name, want string) bool {
+	return o.canonical(name) == o.canonical(want)
+}
+
+// Contains reports whether s contains substr under the options
+func (o MatchOptions) Contains(s, substr string) bool {
+	return strings.Contains(o.canonical(s), o.canonical(substr))
+}
+
+// Glob reports whether name matches the shell pattern under the options
+func (o MatchOptions) Glob(pattern, name string) bool {
+	ok, _ := filepath.Match(o.canonical(pattern), o.canonical(name))
+	return ok
+}
+
+// Regexp compiles a regular expression that matches names under the options
+// Use the result with MatchRegexp so names are canonicalized the same way
+func (o MatchOptions) Regexp(pattern string) (*regexp.Regexp, error) {
+	if o.Normalize {
+		pattern = norm.NFC.String(pattern)
+	}
+	if o.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// MatchRegexp reports whether name matches a pattern compiled with Regexp
+func (o MatchOptions) MatchRegexp(re *regexp.Regexp, name string) bool {
+	return re.MatchString(o.canonical(name))
+}
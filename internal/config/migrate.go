@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// SessionMigration describes what MigrateStore changes, or would change, in a session
+type SessionMigration struct {
+	Session string
+	Moved   int   // items moved from the session directory into item directories
+	Sized   int   // items given a recorded size
+	Err     error // why the session was skipped or only partly migrated
+}
+
+// migrationBackupPrefix starts the names of the metadata backups written by MigrateStore
+const migrationBackupPrefix = "migrate-backup-"
+
+// MigrateStore upgrades every session of the trash directory to the current
+// layout: payloads lying directly in a session are moved into item directories
+// and items without a recorded size are measured. Before anything changes, the
+// .restore files of the affected sessions are saved to one backup file in the
+// trash directory, whose path is returned. With dryRun nothing is changed.
+// Sessions with unusable or tampered metadata are reported and left alone.
+func MigrateStore(dryRun bool) ([]SessionMigration, string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, "", err
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var migrations []SessionMigration
+	backup := make(map[string]json.RawMessage)
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+		migration := SessionMigration{Session: session}
+
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err == nil {
+			err = VerifyRestoreMetadata(trashDir)
+		}
+		if err != nil {
+			migration.Err = err
+			migrations = append(migrations, migration)
+			continue
+		}
+
+		for _, item := range metadata.Items {
+			if item.StoragePath == "" {
+				migration.Moved++
+			}
+			if item.Size == nil {
+				migration.Sized++
+			}
+		}
+		if migration.Moved == 0 && migration.Sized == 0 {
+			continue
+		}
+		migrations = append(migrations, migration)
+
+		if data, err := ReadFile(storeFS, filepath.Join(trashDir, ".restore")); err == nil {
+			backup[session] = data
+		}
+	}
+
+	if dryRun || len(backup) == 0 {
+		return migrations, "", nil
+	}
+
+	backupPath, err := writeMigrationBackup(configDir, backup)
+	if err != nil {
+		return migrations, "", err
+	}
+
+	for i := range migrations {
+		if migrations[i].Err == nil {
+			migrations[i].Moved, migrations[i].Sized, migrations[i].Err = migrateSession(filepath.Join(configDir, migrations[i].Session))
+		}
+	}
+	return migrations, backupPath, nil
+}
+
+// writeMigrationBackup saves the .restore contents of sessions to a new file
+// in the trash directory
+func writeMigrationBackup(configDir string, backup map[string]json.RawMessage) (string, error) {
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata backup: %w", err)
+	}
+
+	backupPath := filepath.Join(configDir, migrationBackupPrefix+time.Now().Format(SessionTimeFormat)+".json")
+	if err := WriteFile(storeFS, backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write metadata backup: %w", err)
+	}
+	return backupPath, nil
+}
+
+// migrateSession moves the payloads of a session into item directories and
+// records missing sizes, saving the metadata even when a move fails midway so
+// that it always describes where the payloads are
+func migrateSession(trashDir string) (moved, sized int, err error) {
+	metadata, err := LoadRestoreMetadata(trashDir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range metadata.Items {
+		item := &metadata.Items[i]
+		payload := filepath.Join(trashDir, item.Storage())
+		if _, statErr := storeFS.Lstat(payload); statErr != nil {
+			continue // a missing payload has nothing to measure or move
+		}
+		if item.Size == nil {
+			size, _ := treeSize(storeFS, payload)
+			item.Size = &size
+			sized++
+		}
+		if item.StoragePath != "" {
+			continue
+		}
+
+		id, mkErr := newItemDir(trashDir)
+		if mkErr != nil {
+			err = mkErr
+			break
+		}
+		if renameErr := storeFS.Rename(payload, filepath.Join(trashDir, id, item.Name)); renameErr != nil {
+			storeFS.Remove(filepath.Join(trashDir, id))
+			err = fmt.Errorf("failed to move %s into an item directory: %w", item.Name, renameErr)
+			break
+		}
+		item.StoragePath = id + "/" + item.Name
+		moved++
+	}
+
+	if saveErr := SaveRestoreMetadata(trashDir, metadata); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return moved, sized, err
+}
@@ -0,0 +1,11 @@
+//go:build darwin
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// mknodAt creates the special file name relative to dirfd; path is the same
+// file as a full path, used because macOS has no mknodat
+func mknodAt(dirfd int, name, path string, mode uint32, dev uint64) error {
+	return unix.Mknod(path, mode, int(dev))
+}
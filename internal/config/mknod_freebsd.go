@@ -0,0 +1,11 @@
+//go:build freebsd
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// mknodAt creates the special file name relative to dirfd; path is the same
+// file as a full path
+func mknodAt(dirfd int, name, path string, mode uint32, dev uint64) error {
+	return unix.Mknodat(dirfd, name, mode, dev)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// CheckMounts refuses a path that is a mount point or contains one. Renaming
+// across a mount boundary fails, and the copy-and-delete fallback would then
+// delete whatever is mounted there.
+func CheckMounts(path string) error {
+	resolved := resolvePath(path)
+
+	// A different device than the parent directory means a mount point on any platform
+	if parent := filepath.Dir(resolved); parent != resolved {
+		if same, err := SameDevice(resolved, parent); err == nil && !same {
+			return withKind(ErrMountPoint, fmt.Errorf("refusing to trash %s: it is a mount point", path))
+		}
+	}
+
+	points, err := mountPoints()
+	if err != nil {
+		return fmt.Errorf("failed to read mount points: %w", err)
+	}
+	for _, point := range points {
+		switch {
+		case point == resolved:
+			// Bind mounts from the same filesystem keep the device
+			return withKind(ErrMountPoint, fmt.Errorf("refusing to trash %s: it is a mount point", path))
+		case IsWithin(point, resolved):
+			return withKind(ErrMountPoint, fmt.Errorf("refusing to trash %s: it contains the mount point %s", path, point))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package config
+
+// mountPoints is not available on this platform; mounts are detected by
+// comparing devices instead (see CheckMounts)
+func mountPoints() ([]string, error) {
+	return nil, nil
+}
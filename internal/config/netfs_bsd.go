@@ -0,0 +1,26 @@
+//go:build darwin || freebsd
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// networkTypes are the filesystems whose locks and renames are unreliable
+// the way those of NFS are
+var networkTypes = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+	"cifs":   true,
+}
+
+// NetworkFilesystem reports whether path is on a network filesystem, and
+// its type
+func NetworkFilesystem(path string) (string, bool) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return "", false
+	}
+	name := unix.ByteSliceToString(stat.Fstypename[:])
+	return name, networkTypes[name]
+}
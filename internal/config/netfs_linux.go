@@ -0,0 +1,30 @@
+//go:build linux
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// networkMagics names the filesystems, by statfs type, whose locks and
+// renames are unreliable the way those of NFS are
+var networkMagics = map[uint32]string{
+	unix.NFS_SUPER_MAGIC:   "nfs",
+	unix.SMB_SUPER_MAGIC:   "smb",
+	unix.SMB2_SUPER_MAGIC:  "smb2",
+	unix.CIFS_SUPER_MAGIC:  "cifs",
+	unix.CEPH_SUPER_MAGIC:  "ceph",
+	unix.AFS_SUPER_MAGIC:   "afs",
+	unix.CODA_SUPER_MAGIC:  "coda",
+	unix.V9FS_MAGIC:        "9p",
+	unix.OCFS2_SUPER_MAGIC: "ocfs2",
+}
+
+// NetworkFilesystem reports whether path is on a network filesystem, and
+// its type
+func NetworkFilesystem(path string) (string, bool) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return "", false
+	}
+	name, ok := networkMagics[uint32(stat.Type)]
+	return name, ok
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package config
+
+// NetworkFilesystem cannot tell network filesystems apart here; set
+// network_fs = "always" for a trash directory on one
+func NetworkFilesystem(path string) (string, bool) {
+	return "", false
+}
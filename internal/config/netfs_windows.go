@@ -0,0 +1,32 @@
+//go:build windows
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// NetworkFilesystem reports whether path is on a network share, and its
+// type: a UNC path or a mapped network drive
+func NetworkFilesystem(path string) (string, bool) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	volume := filepath.VolumeName(absPath)
+	if strings.HasPrefix(volume, `\\`) {
+		return "smb", true
+	}
+
+	root, err := windows.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return "", false
+	}
+	if windows.GetDriveType(root) == windows.DRIVE_REMOTE {
+		return "smb", true
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// networkCache holds whether directories are treated as on a network
+// filesystem, so statfs and config.toml are not consulted for every file copied
+var networkCache struct {
+	sync.Mutex
+	settingsPath string
+	dirs         map[string]bool
+}
+
+// OnNetworkFS reports whether dir is treated as on a network filesystem such
+// as NFS, where flock may not work across clients and errors of writes may
+// only surface when a file is synced or closed: as detected, or as network_fs
+// says. An invalid network_fs falls back to detecting it; doctor reports it.
+func OnNetworkFS(dir string) bool {
+	settingsPath, _ := GetSettingsPath()
+
+	networkCache.Lock()
+	defer networkCache.Unlock()
+	if networkCache.dirs == nil || networkCache.settingsPath != settingsPath {
+		networkCache.dirs = make(map[string]bool)
+		networkCache.settingsPath = settingsPath
+	}
+	if network, ok := networkCache.dirs[dir]; ok {
+		return network
+	}
+
+	settings, _ := LoadSettings()
+	policy, _ := settings.Network()
+	network := policy == NetworkAlways
+	if policy == NetworkAuto {
+		_, network = NetworkFilesystem(dir)
+	}
+	networkCache.dirs[dir] = network
+	return network
+}
+
+// networkStore reports whether the trash directory is on a network
+// filesystem (see OnNetworkFS); remote stores are not
+func networkStore() bool {
+	if !isLocal(storeFS) {
+		return false
+	}
+	configDir, err := GetConfigDir()
+	return err == nil && OnNetworkFS(configDir)
+}
+
+// closeCopy closes a file written by a copy. On a network filesystem it is
+// synced first, so that a failed write is reported before the original is
+// removed: NFS reports them, e.g. a full quota on the server, only when the
+// file is synced or closed.
+func closeCopy(file *os.File) error {
+	if !networkStore() {
+		return file.Close()
+	}
+	err := file.Sync()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// renamePath renames src to dst on the local disk. On a network filesystem a
+// rename whose reply was lost is sent again and fails because src is already
+// gone; when src is gone and dst exists the rename counts as done.
+func renamePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) || !networkStore() {
+		return err
+	}
+	if _, srcErr := os.Lstat(src); !errors.Is(srcErr, fs.ErrNotExist) {
+		return err
+	}
+	if _, dstErr := os.Lstat(dst); dstErr != nil {
+		return err
+	}
+	return nil
+}
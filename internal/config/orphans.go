@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+)
+
+// Orphan reasons
+const (
+	// OrphanPayload is an entry in a session directory that its metadata does not list
+	OrphanPayload = "not in metadata"
+	// OrphanSession is a session directory without a .restore file
+	OrphanSession = "no metadata"
+	// OrphanInvalidSession is a session whose .restore file cannot be read or is rejected
+	OrphanInvalidSession = "invalid metadata"
+)
+
+// Orphan is something in the trash directory that list and restore cannot see
+type Orphan struct {
+	Session string
+	Name    string // the orphaned entry, "" when the whole session is orphaned
+	Reason  string
+	Bytes   uint64
+}
+
+// isSessionFile reports whether name is a file trash itself keeps in a session directory
+func isSessionFile(name string) bool {
+	return name == ".restore" || name == SignatureFileName || name == ArchiveFileName
+}
+
+// FindOrphans returns the payloads present in session directories but absent
+// from their metadata, and the sessions whose metadata is missing or unusable;
+// they take up space that list does not account for
+func FindOrphans() ([]Orphan, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []Orphan
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err != nil {
+			reason := OrphanInvalidSession
+			if _, statErr := storeFS.Lstat(filepath.Join(trashDir, ".restore")); errors.Is(statErr, fs.ErrNotExist) {
+				reason = OrphanSession
+			}
+			size, _ := treeSize(storeFS, trashDir)
+			orphans = append(orphans, Orphan{Session: session, Reason: reason, Bytes: size})
+			continue
+		}
+
+		listed := make(map[string]bool, len(metadata.Items))
+		for _, item := range metadata.Items {
+			listed[item.Entry()] = true
+		}
+
+		entries, err := storeFS.ReadDir(trashDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if listed[entry.Name()] || isSessionFile(entry.Name()) {
+				continue
+			}
+			size, _ := treeSize(storeFS, filepath.Join(trashDir, entry.Name()))
+			orphans = append(orphans, Orphan{Session: session, Name: entry.Name(), Reason: OrphanPayload, Bytes: size})
+		}
+	}
+
+	return orphans, nil
+}
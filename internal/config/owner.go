@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// Owner identifies who trashed an item and on which machine
+type Owner struct {
+	User     string
+	UID      string
+	Hostname string
+}
+
+// CurrentOwner returns the user and host running this process
+// Lookups are best effort; fields that cannot be determined are left empty
+func CurrentOwner() Owner {
+	var owner Owner
+
+	if u, err := user.Current(); err == nil {
+		owner.User = u.Username
+		owner.UID = u.Uid
+	} else if uid := os.Getuid(); uid >= 0 {
+		owner.UID = strconv.Itoa(uid)
+	}
+
+	owner.Hostname, _ = os.Hostname()
+
+	return owner
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// DefaultDirMode is the permissions of the trash directory and its sessions
+// unless dir_mode says otherwise. Trashed files may hold private data, so by
+// default nobody but their owner can look inside.
+const DefaultDirMode fs.FileMode = 0700
+
+// ParseDirMode parses an octal dir_mode such as "0700" or "750". The owner
+// must keep full access, or trash could not use its own directory.
+func ParseDirMode(value string) (fs.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("expected octal permissions such as 0700, got %q", value)
+	}
+	if mode&0700 != 0700 {
+		return 0, fmt.Errorf("%s would lock the owner out: the owner needs rwx (07xx)", value)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// Modes returns the permissions of store directories and of metadata files
+func (s *Settings) Modes() (dirMode, fileMode fs.FileMode, err error) {
+	dirMode = DefaultDirMode
+	if s.DirMode != "" {
+		if dirMode, err = ParseDirMode(s.DirMode); err != nil {
+			return DefaultDirMode, metadataMode(DefaultDirMode), fmt.Errorf("invalid dir_mode: %w", err)
+		}
+	}
+	return dirMode, metadataMode(dirMode), nil
+}
+
+// metadataMode is the mode of metadata files in directories of mode dirMode
+func metadataMode(dirMode fs.FileMode) fs.FileMode {
+	return dirMode &^ 0111
+}
+
+// modeCache holds the modes of the trash directory they were read for, so
+// config.toml is not read again for every item trashed
+var modeCache struct {
+	sync.Mutex
+	settingsPath string
+	dir, file    fs.FileMode
+}
+
+// storeModes returns the permissions new store directories and metadata files
+// are created with. An invalid dir_mode falls back to the default; doctor
+// reports it.
+func storeModes() (dirMode, fileMode fs.FileMode) {
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return DefaultDirMode, metadataMode(DefaultDirMode)
+	}
+
+	modeCache.Lock()
+	defer modeCache.Unlock()
+	if modeCache.settingsPath != settingsPath {
+		settings, _ := LoadSettings()
+		modeCache.dir, modeCache.file, _ = settings.Modes()
+		modeCache.settingsPath = settingsPath
+	}
+	return modeCache.dir, modeCache.file
+}
+
+// mkdirStore creates the trash directory configDir with mode dirMode. Missing
+// parents such as ~/.config are shared with other programs and get the usual
+// permissions instead.
+func mkdirStore(configDir string, dirMode fs.FileMode) error {
+	if err := storeFS.MkdirAll(filepath.Dir(configDir), 0755); err != nil {
+		return err
+	}
+	if err := storeFS.Mkdir(configDir, dirMode); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	return nil
+}
+
+// PermissionFix is an entry of the trash directory whose permissions allow
+// more than dir_mode does
+type PermissionFix struct {
+	Path string
+	Mode fs.FileMode // current permissions
+	Want fs.FileMode // permissions with the excess removed
+}
+
+// LoosePermissions returns the entries of the local trash directory that other
+// users can access beyond what dir_mode allows: the directory itself, its
+// sessions and item directories, and the metadata and state files. Payloads
+// keep their own permissions, which restore puts back.
+func LoosePermissions() ([]PermissionFix, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	dirMode, fileMode := storeModes()
+
+	var fixes []PermissionFix
+	check := func(path string, info fs.FileInfo) {
+		allowed := fileMode
+		if info.IsDir() {
+			allowed = dirMode
+		} else if !info.Mode().IsRegular() {
+			return
+		}
+		if mode := info.Mode().Perm(); mode&^allowed != 0 {
+			fixes = append(fixes, PermissionFix{Path: path, Mode: mode, Want: mode & allowed})
+		}
+	}
+
+	info, err := os.Lstat(configDir)
+	if err != nil {
+		return nil, err
+	}
+	check(configDir, info)
+
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(configDir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		check(path, info)
+		if !entry.IsDir() {
+			continue
+		}
+
+		// Inside sessions only item directories and metadata are the store's
+		sessionEntries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, sessionEntry := range sessionEntries {
+			if !(sessionEntry.IsDir() && IsItemID(sessionEntry.Name())) && !isSessionFile(sessionEntry.Name()) {
+				continue
+			}
+			if info, err := sessionEntry.Info(); err == nil {
+				check(filepath.Join(path, sessionEntry.Name()), info)
+			}
+		}
+	}
+	return fixes, nil
+}
+
+// FixPermissions removes the permissions of the entries found by
+// LoosePermissions that dir_mode does not allow, and returns what it changed
+func FixPermissions() ([]PermissionFix, error) {
+	fixes, err := LoosePermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	var fixed []PermissionFix
+	for _, fix := range fixes {
+		if err := os.Chmod(fix.Path, fix.Want); err != nil {
+			return fixed, fmt.Errorf("failed to change permissions: %w", err)
+		}
+		fixed = append(fixed, fix)
+	}
+	return fixed, nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RestorePlan describes one item of a batch restore for CheckRestore
+type RestorePlan struct {
+	Source string // the payload inside the trash
+	Dest   string // where it will be restored, empty when unknown
+	Force  bool   // an existing destination will be overwritten
+	Keep   bool   // a copy is restored and the payload stays in the trash
+
+	// Archived payloads are extracted from the archive of a compressed
+	// session, so Size bytes are written to the destination
+	Archived bool
+	Size     uint64
+}
+
+// spaceNeed accumulates the bytes to be copied onto one filesystem
+type spaceNeed struct {
+	dir   string // an existing directory on the filesystem
+	bytes uint64
+}
+
+// addSpaceNeed adds bytes to the filesystem of dir, merging with the entry of
+// a directory on the same filesystem
+func addSpaceNeed(needs []spaceNeed, dir string, bytes uint64) []spaceNeed {
+	for i := range needs {
+		if same, err := SameDevice(dir, needs[i].dir); err == nil && same {
+			needs[i].bytes += bytes
+			return needs
+		}
+	}
+	return append(needs, spaceNeed{dir: dir, bytes: bytes})
+}
+
+// checkSpace returns an ErrQuotaExceeded error for each filesystem with less
+// free space than needed. Filesystems whose free space is unknown are skipped.
+func checkSpace(needs []spaceNeed) []error {
+	var problems []error
+	for _, need := range needs {
+		free, err := FreeSpace(need.dir)
+		if err != nil || need.bytes <= free {
+			continue
+		}
+		problems = append(problems, withKind(ErrQuotaExceeded, fmt.Errorf("copying needs %s on the filesystem of %s, but only %s is free",
+			FormatSize(need.bytes), need.dir, FormatSize(free))))
+	}
+	return problems
+}
+
+// existingAncestor returns path or its nearest parent that exists
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// CheckTrash reports everything that would make trashing paths fail partway:
+// paths that cannot be moved out of their directory, a trash directory that
+// cannot be written and too little space for the cross-device copies. Missing
+// paths are left for the trash operation to report. With snapshot, paths that
+// will be left in a snapshot need no space (see TrashOptions.Snapshot).
+func CheckTrash(paths []string, snapshot bool) []error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return []error{err}
+	}
+
+	// A remote store reports its problems when the copy is made
+	if !isLocal(storeFS) {
+		return nil
+	}
+
+	var problems []error
+	if err := checkWritable(configDir); err != nil {
+		problems = append(problems, err)
+	}
+
+	var needs []spaceNeed
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue
+		}
+		if err := checkRemovable(path); err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		if snapshot && snapshottable(path, info) {
+			continue
+		}
+		if same, err := SameDevice(path, configDir); err == nil && !same {
+			size, _ := PathSize(path)
+			needs = addSpaceNeed(needs, configDir, size)
+		}
+	}
+
+	return append(problems, checkSpace(needs)...)
+}
+
+// CheckRestore reports everything that would make restoring plans fail partway:
+// unknown or existing destinations, directories that cannot be written and too
+// little space where payloads have to be copied
+func CheckRestore(plans []RestorePlan) []error {
+	var problems []error
+	var needs []spaceNeed
+
+	for _, plan := range plans {
+		name := filepath.Base(plan.Source)
+		if plan.Dest == "" {
+			problems = append(problems, withKind(ErrUnknownOrigin, fmt.Errorf("the original location of %s is unknown", name)))
+			continue
+		}
+		if _, err := os.Lstat(plan.Dest); err == nil && !plan.Force {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrDestinationExists, plan.Dest))
+			continue
+		}
+
+		ancestor := existingAncestor(filepath.Dir(plan.Dest))
+		if err := checkWritable(ancestor); err != nil {
+			problems = append(problems, err)
+			continue
+		}
+
+		if plan.Archived {
+			needs = addSpaceNeed(needs, ancestor, plan.Size)
+			continue
+		}
+
+		// Payloads in a store off the local disk are always copied out
+		if !isLocal(storeFS) {
+			if _, err := storeFS.Lstat(plan.Source); err != nil {
+				problems = append(problems, withKind(ErrNotFound, fmt.Errorf("%s is missing from the trash", name)))
+				continue
+			}
+			size, _ := treeSize(storeFS, plan.Source)
+			needs = addSpaceNeed(needs, ancestor, size)
+			continue
+		}
+
+		if !plan.Keep {
+			if err := checkRemovable(plan.Source); err != nil {
+				problems = append(problems, err)
+				continue
+			}
+		}
+
+		same, err := SameDevice(plan.Source, ancestor)
+		if errors.Is(err, os.ErrNotExist) {
+			problems = append(problems, withKind(ErrNotFound, fmt.Errorf("%s is missing from the trash", name)))
+			continue
+		}
+		if plan.Keep || (err == nil && !same) {
+			size, _ := PathSize(plan.Source)
+			needs = addSpaceNeed(needs, ancestor, size)
+		}
+	}
+
+	return append(problems, checkSpace(needs)...)
+}
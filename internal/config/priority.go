@@ -0,0 +1,4 @@
+package config
+
+// niceLevel is the CPU niceness used by LowerPriority on Unix systems
+const niceLevel = 10
@@ -0,0 +1,19 @@
+//go:build darwin || freebsd
+
+package config
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// LowerPriority makes the process nice 10. There is no portable I/O priority
+// here, but the scheduler favours other processes' I/O as well.
+func LowerPriority() error {
+	// EACCES: the process is already nicer and only root may raise it
+	if err := unix.Setpriority(unix.PRIO_PROCESS, 0, niceLevel); err != nil && err != unix.EACCES {
+		return fmt.Errorf("failed to lower CPU priority: %w", err)
+	}
+	return nil
+}
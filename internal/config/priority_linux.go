@@ -0,0 +1,47 @@
+//go:build linux
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// ioprio_set arguments: the lowest level of the best-effort class. The idle
+// class could starve a trash operation indefinitely on a busy disk.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassBE    = 2
+	ioprioLowest     = 7
+	ioprioClassShift = 13
+)
+
+// LowerPriority makes the process nice 10 with the lowest best-effort I/O
+// priority, like 'nice -n 10 ionice -c2 -n7'. Linux applies both per thread,
+// so every existing thread is changed; threads started later inherit them.
+func LowerPriority() error {
+	tids, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fmt.Errorf("failed to lower priority: %w", err)
+	}
+
+	ioprio := uintptr(ioprioClassBE<<ioprioClassShift | ioprioLowest)
+	for _, entry := range tids {
+		tid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// EACCES: the thread is already nicer and only root may raise it
+		if err := unix.Setpriority(unix.PRIO_PROCESS, tid, niceLevel); err != nil && err != unix.EACCES {
+			return fmt.Errorf("failed to lower CPU priority: %w", err)
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprio); errno != 0 {
+			return fmt.Errorf("failed to lower I/O priority: %w", errno)
+		}
+	}
+
+	return nil
+}
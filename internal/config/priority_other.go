@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package config
+
+// LowerPriority is not supported on this platform
+func LowerPriority() error {
+	return nil
+}
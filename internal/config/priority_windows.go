@@ -0,0 +1,18 @@
+//go:build windows
+
+package config
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// LowerPriority puts the process in background mode, which lowers both its CPU
+// and its I/O priority
+func LowerPriority() error {
+	if err := windows.SetPriorityClass(windows.CurrentProcess(), windows.PROCESS_MODE_BACKGROUND_BEGIN); err != nil {
+		return fmt.Errorf("failed to lower priority: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// PurgedItem describes a trashed item that was permanently deleted
+type PurgedItem = TrashedItem
+
+// PruneForFreeSpace purges the oldest trash sessions until the trash filesystem
+// has at least minFree bytes available. The session named keep is never purged,
+// and of a session holding retained items only the other items are.
+// Returns the names of the sessions purged entirely.
+func PruneForFreeSpace(minFree uint64, keep string) ([]string, error) {
+	// The free space of a remote store is not known
+	if minFree == 0 || !isLocal(storeFS) {
+		return nil, nil
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []string
+	var counted PurgeStats
+	defer func() { recordPurge(counted) }()
+
+	now := time.Now()
+	for _, session := range sessions {
+		free, err := FreeSpace(configDir)
+		if err != nil {
+			return purged, err
+		}
+		if free >= minFree {
+			break
+		}
+		if session == keep {
+			continue
+		}
+
+		trashDir := filepath.Join(configDir, session)
+		metadata, _ := LoadRestoreMetadata(trashDir)
+
+		// Items kept by a retention override survive, and so does their session
+		if metadata != nil && metadata.hasRetained(now) {
+			if _, err := purgeItems(session, trashDir, metadata, func(entry TrashedItem) bool {
+				return !entry.Item.IsRetained(now)
+			}, &counted); err != nil {
+				return purged, err
+			}
+			continue
+		}
+
+		size := sessionSize(trashDir)
+		if err := purgeTree(configDir, trashDir); err != nil {
+			return purged, fmt.Errorf("failed to purge session %s: %w", session, err)
+		}
+		purged = append(purged, session)
+
+		counted.Sessions++
+		counted.Bytes += size
+		if metadata != nil {
+			counted.Items += uint64(len(metadata.Items))
+			recordPurged(metadata.trashedItems(session))
+		}
+	}
+
+	return purged, nil
+}
+
+// PurgeExpired permanently deletes every item whose expiry has passed.
+// Sessions left without items are removed entirely.
+func PurgeExpired(now time.Time) ([]PurgedItem, error) {
+	return PurgeMatching(func(entry TrashedItem) bool {
+		return entry.Item.IsExpired(now)
+	})
+}
+
+// PurgeMatching permanently deletes every item for which match returns true.
+// Sessions left without items are removed entirely.
+func PurgeMatching(match func(TrashedItem) bool) ([]PurgedItem, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []PurgedItem
+	var counted PurgeStats
+	defer func() { recordPurge(counted) }()
+
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err != nil {
+			continue // Sessions without readable metadata are left alone
+		}
+
+		items, err := purgeItems(session, trashDir, metadata, match, &counted)
+		purged = append(purged, items...)
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeItems permanently deletes the items of a session for which match returns
+// true, removing the session when none are left and adding them to counted
+func purgeItems(session, trashDir string, metadata *RestoreMetadata, match func(TrashedItem) bool, counted *PurgeStats) ([]PurgedItem, error) {
+	var purged []PurgedItem
+	defer func() { recordPurged(purged) }()
+
+	var remaining []RestoreItem
+	for _, item := range metadata.Items {
+		if !match(TrashedItem{Session: session, Item: item}) {
+			remaining = append(remaining, item)
+			continue
+		}
+
+		payload := filepath.Join(trashDir, item.Entry())
+		size := item.PayloadSize(trashDir)
+		if err := purgeTree(filepath.Dir(trashDir), payload); err != nil {
+			return purged, fmt.Errorf("failed to purge %s: %w", item.Name, err)
+		}
+		purged = append(purged, PurgedItem{Session: session, Item: item})
+		counted.Items++
+		counted.Bytes += size
+	}
+
+	if len(remaining) == len(metadata.Items) {
+		return purged, nil
+	}
+
+	if len(remaining) == 0 {
+		if err := purgeTree(filepath.Dir(trashDir), trashDir); err != nil {
+			return purged, fmt.Errorf("failed to remove empty session %s: %w", session, err)
+		}
+		counted.Sessions++
+		return purged, nil
+	}
+
+	metadata.Items = remaining
+	return purged, SaveRestoreMetadata(trashDir, metadata)
+}
+
+// hasRetained reports whether a retention override keeps any item of the session
+func (m *RestoreMetadata) hasRetained(now time.Time) bool {
+	for _, item := range m.Items {
+		if item.IsRetained(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// trashedItems pairs the items of the session with its name
+func (m *RestoreMetadata) trashedItems(session string) []TrashedItem {
+	items := make([]TrashedItem, 0, len(m.Items))
+	for _, item := range m.Items {
+		items = append(items, TrashedItem{Session: session, Item: item})
+	}
+	return items
+}
+
+// EmptyTrash permanently deletes every trash session. Unless includeRetained
+// is set, items kept by a retention override stay, and so do their sessions.
+func EmptyTrash(includeRetained bool) ([]PurgedItem, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []PurgedItem
+	var counted PurgeStats
+	defer func() { recordPurge(counted) }()
+
+	now := time.Now()
+	for _, session := range sessions {
+		items, err := purgeSession(configDir, session, includeRetained, now, &counted)
+		purged = append(purged, items...)
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// PurgeSession permanently deletes one trash session like EmptyTrash does
+func PurgeSession(session string, includeRetained bool) ([]PurgedItem, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var counted PurgeStats
+	defer func() { recordPurge(counted) }()
+
+	return purgeSession(configDir, session, includeRetained, time.Now(), &counted)
+}
+
+// purgeSession permanently deletes a session, or only its items that are not
+// retained at now unless includeRetained is set, adding them to counted
+func purgeSession(configDir, session string, includeRetained bool, now time.Time, counted *PurgeStats) ([]PurgedItem, error) {
+	trashDir := filepath.Join(configDir, session)
+	metadata, _ := LoadRestoreMetadata(trashDir)
+
+	if metadata != nil && !includeRetained && metadata.hasRetained(now) {
+		return purgeItems(session, trashDir, metadata, func(entry TrashedItem) bool {
+			return !entry.Item.IsRetained(now)
+		}, counted)
+	}
+
+	var items []PurgedItem
+	if metadata != nil {
+		items = metadata.trashedItems(session)
+	}
+	size := sessionSize(trashDir)
+
+	if err := purgeTree(configDir, trashDir); err != nil {
+		return nil, fmt.Errorf("failed to purge session %s: %w", session, err)
+	}
+	recordPurged(items)
+
+	counted.Items += uint64(len(items))
+	counted.Sessions++
+	counted.Bytes += size
+	return items, nil
+}
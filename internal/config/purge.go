@@ -0,0 +1,263 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PurgingDirName is the directory of a trash directory holding what is being
+// purged. Sessions and items are moved there before they are deleted, so that
+// an interrupted purge never leaves half-deleted items listed; what it still
+// holds is deleted by 'trash empty --resume' or the next prune.
+const PurgingDirName = ".purging"
+
+// purgeBatch is how many entries are read from a directory and deleted at a
+// time, so that directories with millions of entries are never read whole
+const purgeBatch = 1000
+
+// purgeLimit throttles the deletions of purges, nil for no limit
+var purgeLimit *tokenBucket
+
+// SetPurgeRate limits purges on the local disk to deleting entriesPerSecond
+// files and directories, so that emptying a huge trash does not saturate the
+// disk. 0 removes the limit.
+func SetPurgeRate(entriesPerSecond uint64) {
+	if entriesPerSecond == 0 {
+		purgeLimit = nil
+		return
+	}
+
+	rate := float64(entriesPerSecond)
+	purgeLimit = &tokenBucket{rate: rate, burst: rate, tokens: rate, last: time.Now()}
+}
+
+// purger deletes trees in batches under the purge rate
+type purger struct {
+	batch   int
+	pending int // entries deleted since the rate was last applied
+}
+
+// newPurger returns a purger whose batches keep the pauses of the purge rate short
+func newPurger() *purger {
+	p := &purger{batch: purgeBatch}
+	if purgeLimit != nil {
+		p.batch = max(1, min(purgeBatch, int(purgeLimit.rate/4)))
+	}
+	return p
+}
+
+// pace counts one deleted entry, waiting out the purge rate after every batch
+func (p *purger) pace() {
+	p.pending++
+	if p.pending >= p.batch {
+		p.flush()
+	}
+}
+
+// flush applies the purge rate to the entries deleted since the last batch
+func (p *purger) flush() {
+	if purgeLimit != nil && p.pending > 0 {
+		purgeLimit.take(p.pending)
+	}
+	p.pending = 0
+}
+
+// removeTree deletes path and everything below it, depth first. A path that
+// does not exist is not an error, so that a deletion can be resumed. refDepth
+// is how many levels below path the item directories' snapshot refs are: 2
+// for a session, 1 for an item directory and negative for anything else.
+func (p *purger) removeTree(path string, refDepth int) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		for {
+			names, err := readNames(path, p.batch)
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				break
+			}
+			for _, name := range names {
+				if err := p.removeTree(filepath.Join(path, name), refDepth-1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// The snapshot an item was kept in goes with the file pointing at it, which
+	// is only ever directly in the item directory; a file of the same name in
+	// a trashed tree is user data
+	if refDepth == 0 && !info.IsDir() && info.Name() == SnapshotRefName {
+		if err := destroyReferenced(path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	p.pace()
+	return nil
+}
+
+// readNames returns up to n names of the entries of a directory
+func readNames(dir string, n int) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(n)
+	if err == io.EOF {
+		return nil, nil
+	}
+	return names, err
+}
+
+// purgeTree permanently deletes path, a session of the trash directory
+// storeDir or an entry of one. On the local disk it is first moved into the
+// purging directory, then deleted in batches under the purge rate.
+func purgeTree(storeDir, path string) error {
+	if !isLocal(storeFS) {
+		return storeFS.RemoveAll(path)
+	}
+
+	p := newPurger()
+	defer p.flush()
+
+	rel, err := filepath.Rel(storeDir, path)
+	if err != nil {
+		return p.removeTree(path, -1)
+	}
+	refDepth := snapshotRefDepth(strings.Split(filepath.ToSlash(rel), "/"))
+	purgingDir := filepath.Join(storeDir, PurgingDirName)
+	dirMode, _ := storeModes()
+	if err := os.MkdirAll(purgingDir, dirMode); err != nil {
+		return p.removeTree(path, refDepth)
+	}
+
+	pending := filepath.Join(purgingDir, strings.ReplaceAll(rel, string(filepath.Separator), "-"))
+	if err := os.Rename(path, pending); err != nil {
+		// E.g. what an interrupted purge left under the same name; delete in place
+		return p.removeTree(path, refDepth)
+	}
+	if err := p.removeTree(pending, refDepth); err != nil {
+		return err
+	}
+	os.Remove(purgingDir) // unless other purges are under way
+	return nil
+}
+
+// snapshotRefDepth returns the refDepth of removeTree for the path of the
+// trash directory made of parts: a session, or an item directory of one
+func snapshotRefDepth(parts []string) int {
+	if !isSessionName(parts[0]) {
+		return -1
+	}
+	switch {
+	case len(parts) == 1:
+		return 2
+	case len(parts) == 2 && IsItemID(parts[1]):
+		return 1
+	}
+	return -1
+}
+
+// isSessionName reports whether name is a session name as NewSessionName
+// makes them, or one from a version that made them without a suffix
+func isSessionName(name string) bool {
+	timestamp, suffix, _ := strings.Cut(name, "-")
+	if _, err := ParseSessionTime(timestamp); err != nil {
+		return false
+	}
+	if suffix == "" {
+		return !strings.Contains(name, "-")
+	}
+	_, err := hex.DecodeString(suffix)
+	return err == nil && len(suffix) == 4 && strings.ToLower(suffix) == suffix
+}
+
+// pendingRefDepth returns the refDepth of removeTree for an entry of the
+// purging directory, named after the path it was moved from with its
+// separators replaced by "-"
+func pendingRefDepth(name string) int {
+	if i := strings.LastIndexByte(name, '-'); i >= 0 && IsItemID(name[i+1:]) {
+		if depth := snapshotRefDepth([]string{name[:i], name[i+1:]}); depth > 0 {
+			return depth
+		}
+	}
+	return snapshotRefDepth([]string{name})
+}
+
+// PendingPurge returns how many sessions and items interrupted purges left to
+// delete in the trash directory, and their size
+func PendingPurge() (int, uint64, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(configDir, PurgingDirName))
+	if os.IsNotExist(err) || !isLocal(storeFS) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var size uint64
+	for _, entry := range entries {
+		n, _ := PathSize(filepath.Join(configDir, PurgingDirName, entry.Name()))
+		size += n
+	}
+	return len(entries), size, nil
+}
+
+// ResumePurge finishes deleting what interrupted purges left in the trash
+// directory, under the purge rate, and returns how many sessions and items
+// it deleted
+func ResumePurge() (int, error) {
+	if !isLocal(storeFS) {
+		return 0, nil
+	}
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return 0, err
+	}
+
+	purgingDir := filepath.Join(configDir, PurgingDirName)
+	entries, err := os.ReadDir(purgingDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", purgingDir, err)
+	}
+
+	p := newPurger()
+	defer p.flush()
+
+	resumed := 0
+	for _, entry := range entries {
+		if err := p.removeTree(filepath.Join(purgingDir, entry.Name()), pendingRefDepth(entry.Name())); err != nil {
+			return resumed, fmt.Errorf("failed to purge %s: %w", entry.Name(), err)
+		}
+		resumed++
+	}
+	os.Remove(purgingDir)
+	return resumed, nil
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRefDepth(t *testing.T) {
+	const session = "20250101_120000.000000000-abcd"
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{session, 2},
+		{session + "/0011aabb", 1},
+		{session + "/0011aabb/data", -1},
+		{session + "/0011aabb/data/.snapshot-ref", -1},
+		{session + "/notes.txt", -1},
+		{"20250101_120000", 2},
+		{"20250101_120000-notes", -1},
+		{"notes", -1},
+	}
+	for _, tt := range tests {
+		if got := snapshotRefDepth(strings.Split(tt.path, "/")); got != tt.want {
+			t.Errorf("snapshotRefDepth(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+		pending := strings.ReplaceAll(tt.path, "/", "-")
+		if got := pendingRefDepth(pending); got != tt.want {
+			t.Errorf("pendingRefDepth(%q) = %d, want %d", pending, got, tt.want)
+		}
+	}
+}
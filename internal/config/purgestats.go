@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PurgeStatsFileName holds cumulative purge counters inside the trash directory
+const PurgeStatsFileName = "purge-stats.json"
+
+// PurgeStats counts everything permanently deleted from the trash since the counters were created
+type PurgeStats struct {
+	Items    uint64 `json:"items"`
+	Sessions uint64 `json:"sessions"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// LoadPurgeStats reads the purge counters; a missing file yields zero counters
+func LoadPurgeStats() (*PurgeStats, error) {
+	stats := &PurgeStats{}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return stats, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, PurgeStatsFileName))
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to read purge stats: %w", err)
+	}
+
+	if err := json.Unmarshal(data, stats); err != nil {
+		return stats, fmt.Errorf("failed to parse purge stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// recordPurge adds to the purge counters. Counting is best effort and never
+// fails a purge.
+func recordPurge(delta PurgeStats) {
+	if delta.Items == 0 && delta.Sessions == 0 {
+		return
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return
+	}
+
+	stats, _ := LoadPurgeStats()
+	stats.Items += delta.Items
+	stats.Sessions += delta.Sessions
+	stats.Bytes += delta.Bytes
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return
+	}
+
+	// Write then rename so a concurrent reader never sees a partial file
+	statsPath := filepath.Join(configDir, PurgeStatsFileName)
+	tmpPath := statsPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return
+	}
+	os.Rename(tmpPath, statsPath)
+}
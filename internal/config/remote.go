@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// UseConfiguredStore moves the trash store to the remote location named by the
+// store setting, if any. Settings, the lock and the purge statistics stay in
+// the local trash directory, which keeps addressing the store.
+func UseConfiguredStore() error {
+	settings, err := LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings.Store == "" {
+		return nil
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	fsys, err := openRemoteStore(configDir, settings)
+	if err != nil {
+		return err
+	}
+	UseStoreFS(fsys)
+	return nil
+}
+
+// openRemoteStore returns the FS of the store setting, serving the paths below configDir
+func openRemoteStore(configDir string, settings *Settings) (FS, error) {
+	location, err := url.Parse(settings.Store)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store %q: %w", settings.Store, err)
+	}
+
+	switch location.Scheme {
+	case "s3":
+		cfg, err := s3ConfigFor(location, settings)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3FS(configDir, cfg), nil
+	case "sftp", "ssh":
+		cfg, err := sshConfigFor(location)
+		if err != nil {
+			return nil, err
+		}
+		return NewSSHFS(configDir, cfg), nil
+	}
+	return nil, fmt.Errorf("invalid store %q: unsupported scheme %q", settings.Store, location.Scheme)
+}
+
+// s3ConfigFor builds the S3 configuration of an s3://bucket/prefix store. The
+// credentials come from the standard AWS environment variables.
+func s3ConfigFor(location *url.URL, settings *Settings) (S3Config, error) {
+	cfg := S3Config{
+		Bucket:       location.Host,
+		Prefix:       strings.Trim(location.Path, "/"),
+		Region:       firstNonEmpty(settings.S3Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1"),
+		Endpoint:     firstNonEmpty(settings.S3Endpoint, os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL")),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if cfg.Bucket == "" {
+		return cfg, fmt.Errorf("invalid store %q: no bucket", location)
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return cfg, fmt.Errorf("store %s needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", location)
+	}
+	return cfg, nil
+}
+
+// sshConfigFor builds the SSH configuration of an sftp://[user@]host[:port]/dir
+// store; a directory starting with ~/ is relative to the remote home directory
+func sshConfigFor(location *url.URL) (SSHConfig, error) {
+	cfg := SSHConfig{
+		Host: location.Hostname(),
+		Port: location.Port(),
+		Dir:  location.Path,
+	}
+	if cfg.Host == "" {
+		return cfg, fmt.Errorf("invalid store %q: no host", location)
+	}
+	if location.User != nil {
+		cfg.Host = location.User.Username() + "@" + cfg.Host
+	}
+	if cfg.Dir == "/~" || strings.HasPrefix(cfg.Dir, "/~/") {
+		cfg.Dir = strings.TrimPrefix(strings.TrimPrefix(cfg.Dir, "/~"), "/")
+	}
+	return cfg, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Conflict says what restoring onto an existing path does
+type Conflict int
+
+const (
+	ConflictFail      Conflict = iota // return ErrDestinationExists
+	ConflictOverwrite                 // replace what is there
+	ConflictRename                    // restore next to it, see RestoredName
+)
+
+// ParseConflict parses an --on-conflict value: fail, overwrite or rename
+func ParseConflict(value string) (Conflict, error) {
+	switch value {
+	case "", "fail":
+		return ConflictFail, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	case "rename":
+		return ConflictRename, nil
+	}
+	return ConflictFail, fmt.Errorf("invalid conflict strategy %q: expected fail, overwrite or rename", value)
+}
+
+// RestoreResult describes how a trashed item was restored
+type RestoreResult struct {
+	Destination    string
+	Overwrote      bool    // an existing destination was replaced
+	Copied         bool    // the cross-device copy fallback was used
+	Bytes          uint64  // size of what was restored
+	SessionRemoved bool    // the session had no items left and was removed
+	Warnings       []error // non-fatal problems, e.g. cleanup failures
+}
+
+// RestoreTrashedItem moves item out of the session directory trashDir to destPath and
+// removes it from the session metadata. onConflict decides what happens when the
+// destination exists; result.Destination is where the item ended up.
+func RestoreTrashedItem(trashDir string, item RestoreItem, destPath string, onConflict Conflict) (*RestoreResult, error) {
+	return restoreTrashedItem(trashDir, item, destPath, onConflict, false)
+}
+
+// CopyTrashedItem restores a copy of item to destPath like RestoreTrashedItem,
+// but leaves the trashed item and its metadata in place
+func CopyTrashedItem(trashDir string, item RestoreItem, destPath string, onConflict Conflict) (*RestoreResult, error) {
+	return restoreTrashedItem(trashDir, item, destPath, onConflict, true)
+}
+
+// restoreTrashedItem implements RestoreTrashedItem and, with keep, CopyTrashedItem
+func restoreTrashedItem(trashDir string, item RestoreItem, destPath string, onConflict Conflict, keep bool) (*RestoreResult, error) {
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+	if destPath == "" {
+		return nil, withKind(ErrUnknownOrigin, fmt.Errorf("the original location of %s is unknown", item.Name))
+	}
+
+	if item.Snapshot != nil {
+		if err := checkSnapshot(item.Snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	sourcePath := item.PayloadPath(trashDir)
+	archived := IsArchived(trashDir, item)
+
+	// Keep both: restore under the first free "(restored)" name next to the original
+	if onConflict == ConflictRename {
+		info, err := storeFS.Lstat(sourcePath)
+		isDir := err == nil && info.IsDir() || archived && item.Type == TypeDirectory
+		for n := 1; ; n++ {
+			if _, err := os.Lstat(destPath); err != nil {
+				break
+			}
+			destPath = filepath.Join(filepath.Dir(destPath), RestoredName(item.Name, isDir, n))
+		}
+	}
+
+	result := &RestoreResult{Destination: destPath}
+	result.Bytes = item.PayloadSize(trashDir)
+
+	// Check if destination already exists
+	if _, err := os.Lstat(destPath); err == nil {
+		if onConflict != ConflictOverwrite {
+			return nil, fmt.Errorf("%w: %s", ErrDestinationExists, destPath)
+		}
+		// Remove existing destination
+		if err := os.RemoveAll(destPath); err != nil {
+			return nil, fmt.Errorf("removing existing destination: %w", err)
+		}
+		result.Overwrote = true
+	}
+
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return nil, fmt.Errorf("creating parent directory: %w", err)
+	}
+
+	// The trashed item stays as it is; only a copy is put back
+	if keep {
+		var err error
+		if archived {
+			err = ExtractArchived(trashDir, item, destPath)
+		} else {
+			err = copyPayload(sourcePath, destPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Copied = true
+		recordRestore(trashDir, item, destPath)
+		return result, nil
+	}
+
+	// Try to move using rename first, falling back to copy and delete for cross-device
+	// or when the store is not on the local disk. Files dedupe linked to other
+	// payloads are copied, so that changing them does not change those. Payloads
+	// of compressed sessions are extracted from the session's archive.
+	switch {
+	case archived:
+		if err := ExtractArchived(trashDir, item, destPath); err != nil {
+			return nil, err
+		}
+		result.Copied = true
+	case item.Linked || !isLocal(storeFS) || renamePath(sourcePath, destPath) != nil:
+		if err := copyPayload(sourcePath, destPath); err != nil {
+			return nil, err
+		}
+		result.Copied = true
+	}
+
+	// Remove what is left: the copied payload, or the empty item directory,
+	// and the snapshot the payload was kept in. A snapshot that cannot be
+	// deleted keeps its item directory, so that purging it tries again.
+	if err := dropSnapshot(trashDir, item); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Errorf("failed to delete snapshot %s: %w", item.Snapshot.Name, err))
+	} else if err := storeFS.RemoveAll(filepath.Join(trashDir, item.Entry())); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Errorf("failed to remove from trash: %w", err))
+	}
+
+	// Update metadata to remove restored item
+	removed, err := dropFromSession(trashDir, item)
+	if err != nil {
+		result.Warnings = append(result.Warnings, err)
+	}
+	result.SessionRemoved = removed
+	recordRestore(trashDir, item, destPath)
+
+	return result, nil
+}
+
+// recordRestore adds the restore of an item of the session trashDir to destPath to the history
+func recordRestore(trashDir string, item RestoreItem, destPath string) {
+	entry := historyEntry(OpRestore, filepath.Base(trashDir), item)
+	if destPath != item.OriginalPath {
+		entry.Dest = destPath
+	}
+	recordHistory(entry)
+}
+
+// RestoredName returns the name given to the n-th restore of name next to an
+// existing path, like macOS does when keeping both: "report (restored).pdf",
+// then "report (restored 2).pdf" and so on. The extension of a file, including
+// a .tar before a compression suffix, stays at the end; directories keep their
+// name whole.
+func RestoredName(name string, isDir bool, n int) string {
+	stem, ext := name, ""
+	if !isDir {
+		ext = filepath.Ext(name)
+		if ext == name {
+			ext = "" // a dotfile such as .bashrc has no extension
+		}
+		stem = strings.TrimSuffix(name, ext)
+		if tar := filepath.Ext(stem); tar == ".tar" && tar != stem {
+			stem, ext = strings.TrimSuffix(stem, tar), tar+ext
+		}
+	}
+
+	suffix := " (restored)"
+	if n > 1 {
+		suffix = " (restored " + strconv.Itoa(n) + ")"
+	}
+	return stem + suffix + ext
+}
+
+// copyPayload copies a trashed payload of any type from sourcePath in the store
+// to destPath on the local disk. A copy that fails is removed again, so that
+// restoring can be retried.
+func copyPayload(sourcePath, destPath string) error {
+	if err := transfer(storeFS, sourcePath, LocalFS{}, destPath); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		os.RemoveAll(destPath)
+		return copyFailed(fmt.Errorf("copying %s: %w", filepath.Base(sourcePath), err))
+	}
+	return nil
+}
+
+// dropFromSession removes item from a session's metadata and removes the
+// session when no items are left, reporting whether it did
+func dropFromSession(trashDir string, item RestoreItem) (bool, error) {
+	metadata, err := LoadRestoreMetadata(trashDir)
+	if err != nil {
+		return false, fmt.Errorf("failed to update metadata: %w", err)
+	}
+
+	var updatedItems []RestoreItem
+	for _, other := range metadata.Items {
+		if other.Storage() != item.Storage() {
+			updatedItems = append(updatedItems, other)
+		}
+	}
+
+	if len(updatedItems) == 0 {
+		// No items left, remove the entire trash directory
+		if err := storeFS.RemoveAll(trashDir); err != nil {
+			return false, fmt.Errorf("failed to remove empty trash directory: %w", err)
+		}
+		return true, nil
+	}
+
+	// Update .restore file with remaining items
+	metadata.Items = updatedItems
+	if err := SaveRestoreMetadata(trashDir, metadata); err != nil {
+		return false, fmt.Errorf("failed to update metadata: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// RetainItem sets a retention override on a trashed item: it is kept until
+// expiresAt, an RFC3339 time, whatever the retention policies say, and purged
+// once it has passed. An empty expiresAt removes the override and the expiry,
+// leaving the item to the retention policies again.
+func RetainItem(session string, item RestoreItem, expiresAt string) (RestoreItem, error) {
+	if expiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, expiresAt); err != nil {
+			return item, fmt.Errorf("invalid expiry %q: %w", expiresAt, err)
+		}
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return item, err
+	}
+	defer unlock()
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return item, err
+	}
+	trashDir := filepath.Join(configDir, session)
+
+	metadata, err := LoadRestoreMetadata(trashDir)
+	if err != nil {
+		return item, err
+	}
+
+	for i, other := range metadata.Items {
+		if other.Storage() != item.Storage() {
+			continue
+		}
+		other.ExpiresAt = expiresAt
+		other.Retained = expiresAt != ""
+		metadata.Items[i] = other
+		if err := SaveRestoreMetadata(trashDir, metadata); err != nil {
+			return item, err
+		}
+		return other, nil
+	}
+
+	return item, fmt.Errorf("%w: %s in session %s", ErrNotFound, item.Name, session)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+)
+
+// ErrTransientIO is returned when a copy kept failing with errors that may
+// go away, such as EIO on a flaky USB or network disk, after every retry
+var ErrTransientIO = errors.New("transient I/O error")
+
+// DefaultCopyRetries is how often a copy retries a failed read or write when
+// copy_retries is not set
+const DefaultCopyRetries = 3
+
+// retryChunk is the size of the pieces a copy continues in after an error,
+// so that one failure costs one chunk rather than the whole file
+const retryChunk = 1024 * 1024
+
+// The first wait before a retry, doubled for every further one up to maxRetryDelay
+const (
+	retryDelay    = 500 * time.Millisecond
+	maxRetryDelay = 10 * time.Second
+)
+
+// copyRetries is how often a read or write of a copy that failed with a
+// transient error is tried again
+var copyRetries uint64 = DefaultCopyRetries
+
+// SetCopyRetries makes copies retry a read or write that fails with a
+// transient error up to n times, waiting longer each time. 0 fails at once.
+func SetCopyRetries(n uint64) {
+	copyRetries = n
+}
+
+// WarnRetrying, when set, is called before a copy retries after err
+var WarnRetrying func(path string, err error, delay time.Duration)
+
+// IsTransient reports whether err may go away when the same read or write is
+// tried again: an I/O error of the device, a timeout or a dropped network
+// connection. Anything else, such as a full disk, missing permission or a
+// file that is gone, is permanent and not retried.
+func IsTransient(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && transientErrno(errno)
+}
+
+// copyContents copies src to dst, named path in warnings. It copies in one go,
+// keeping copy_file_range and similar fast paths; when that fails with a
+// transient error and both files can be read and written at an offset, it
+// continues where it stopped in chunks, retrying each with backoff.
+func copyContents(dst io.Writer, src io.Reader, path string) error {
+	written, err := io.Copy(dst, limitReader(src))
+	if err == nil {
+		return nil
+	}
+	dstAt, okDst := dst.(io.WriterAt)
+	srcAt, okSrc := src.(io.ReaderAt)
+	if !okDst || !okSrc {
+		return err
+	}
+	return resumeCopy(dstAt, srcAt, written, err, path)
+}
+
+// resumeCopy continues a copy that failed with err after offset bytes. The
+// retries start over whenever a retry got further than the last one.
+func resumeCopy(dst io.WriterAt, src io.ReaderAt, offset int64, err error, path string) error {
+	buf := make([]byte, retryChunk)
+	delay := retryDelay
+	var retries uint64
+	for {
+		if !IsTransient(err) {
+			return err
+		}
+		if retries >= copyRetries {
+			if retries == 0 {
+				return withKind(ErrTransientIO, err)
+			}
+			return withKind(ErrTransientIO, fmt.Errorf("%w (still failing after %d retries)", err, retries))
+		}
+		if WarnRetrying != nil {
+			WarnRetrying(path, err, delay)
+		}
+		time.Sleep(delay)
+
+		var copied int64
+		copied, err = copyChunks(dst, src, offset, buf)
+		if err == nil {
+			return nil
+		}
+		if copied > 0 {
+			offset += copied
+			retries, delay = 0, retryDelay
+		} else {
+			retries++
+			delay = min(2*delay, maxRetryDelay)
+		}
+	}
+}
+
+// copyChunks copies src to dst from offset to the end of src, returning how
+// many bytes it copied
+func copyChunks(dst io.WriterAt, src io.ReaderAt, offset int64, buf []byte) (int64, error) {
+	var copied int64
+	for {
+		n, err := src.ReadAt(buf, offset+copied)
+		if n > 0 {
+			if copyLimit != nil {
+				copyLimit.take(n)
+			}
+			w, werr := dst.WriteAt(buf[:n], offset+copied)
+			copied += int64(w)
+			if werr != nil {
+				return copied, werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return copied, nil
+		}
+		if err != nil {
+			return copied, err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Root is one trash directory taking part in aggregated listing and restore
+type Root struct {
+	Name string // "home", "local", the directory of a configured root or, for UserRoots, the user
+	Dir  string
+}
+
+// Roots returns the trash directories to aggregate: the home store, the local
+// trash of the project containing the working directory, the directories
+// listed in the roots setting and, under WSL, the trash directories on the
+// Windows drives. Roots that do not exist are left out.
+func Roots() ([]Root, error) {
+	home, err := homeStoreDir()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []Root{{Name: "home", Dir: home}}
+	if cwd, err := os.Getwd(); err == nil {
+		candidates = append(candidates, Root{Name: "local", Dir: LocalStoreDir(FindProjectRoot(cwd))})
+	}
+	if settings, err := LoadSettings(); err == nil {
+		for _, dir := range settings.Roots {
+			candidates = append(candidates, Root{Name: dir, Dir: dir})
+		}
+	}
+	if IsWSL() {
+		drives, _ := filepath.Glob(filepath.Join(wslMountRoot, "?"))
+		for _, drive := range drives {
+			candidates = append(candidates, Root{Name: "drive " + filepath.Base(drive), Dir: DriveStoreDir(drive)})
+		}
+	}
+
+	return existingRoots(candidates), nil
+}
+
+// existingRoots returns the candidates whose directory exists, each directory once
+func existingRoots(candidates []Root) []Root {
+	seen := make(map[string]bool)
+	var roots []Root
+	for _, root := range candidates {
+		dir, err := filepath.Abs(root.Dir)
+		if err != nil || seen[dir] {
+			continue
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		seen[dir] = true
+		root.Dir = dir
+		roots = append(roots, root)
+	}
+
+	return roots
+}
+
+// ListAllTrashedItems returns the items of every root, each tagged with its root.
+// Roots that cannot be read are skipped.
+func ListAllTrashedItems() ([]TrashedItem, error) {
+	roots, err := Roots()
+	if err != nil {
+		return nil, err
+	}
+
+	return ListTrashedItemsInRoots(roots), nil
+}
+
+// ListTrashedItemsInRoots returns the items of the given roots, each tagged with
+// its root. Roots that cannot be read are skipped.
+func ListTrashedItemsInRoots(roots []Root) []TrashedItem {
+	var items []TrashedItem
+	for _, root := range roots {
+		rootItems, err := listTrashedItemsIn(root.Dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range rootItems {
+			entry.Root = root
+			items = append(items, entry)
+		}
+	}
+
+	return items
+}
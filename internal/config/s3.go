@@ -0,0 +1,656 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config describes the bucket an S3FS keeps the trash store in
+type S3Config struct {
+	Bucket       string
+	Prefix       string // key prefix of the store inside the bucket, without slashes around it
+	Region       string
+	Endpoint     string // base URL of an S3-compatible service; empty for AWS
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// S3 object metadata recording what the key itself cannot
+const (
+	s3MetaMode    = "X-Amz-Meta-Mode"    // fs.FileMode as a decimal number
+	s3MetaMtime   = "X-Amz-Meta-Mtime"   // modification time, RFC 3339
+	s3MetaSymlink = "X-Amz-Meta-Symlink" // target of a symlink
+)
+
+// S3FS keeps the trash store in an S3-compatible bucket. Paths below root, the
+// local trash directory, map to keys below the prefix; directories are key
+// prefixes, with an empty "dir/" marker object carrying their mode and time.
+// S3 has no rename, so Rename copies objects on the server and deletes the
+// originals. New files are staged in a local temporary file and uploaded when
+// closed, which limits a single file to the 5 GB of one PUT.
+type S3FS struct {
+	root   string
+	config S3Config
+	client *http.Client
+}
+
+// NewS3FS returns an S3FS serving the paths below root from the bucket in cfg
+func NewS3FS(root string, cfg S3Config) *S3FS {
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	return &S3FS{root: filepath.Clean(root), config: cfg, client: &http.Client{}}
+}
+
+// key returns the object key of name, without a trailing slash
+func (s *S3FS) key(name string) (string, error) {
+	rel, err := filepath.Rel(s.root, filepath.Clean(name))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the trash store %s", name, s.root)
+	}
+	if rel == "." {
+		return s.config.Prefix, nil
+	}
+	return path.Join(s.config.Prefix, filepath.ToSlash(rel)), nil
+}
+
+// dirPrefix returns the prefix of the keys inside the directory key
+func dirPrefix(key string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "/"
+}
+
+// objectURL returns the URL of key with SigV4 path encoding
+func (s *S3FS) objectURL(key string) string {
+	if s.config.Endpoint != "" {
+		// S3-compatible services generally expect path-style addressing
+		return strings.TrimSuffix(s.config.Endpoint, "/") + "/" + s.config.Bucket + "/" + s3Escape(key, false)
+	}
+	return "https://" + s.config.Bucket + ".s3." + s.config.Region + ".amazonaws.com/" + s3Escape(key, false)
+}
+
+// s3Error is an error response from the service
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request for key and returns the response of a successful
+// one. A 404 is returned as fs.ErrNotExist for name.
+func (s *S3FS) do(op, name, method, key string, query url.Values, header http.Header, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	target := s.objectURL(key)
+	if len(query) > 0 {
+		target += "?" + canonicalQuery(query)
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	for field, values := range header {
+		req.Header[field] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	signS3Request(req, s.config, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	var serviceErr s3Error
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &serviceErr) != nil || serviceErr.Code == "" {
+		serviceErr.Code = resp.Status
+	}
+	err = fmt.Errorf("S3 %s", serviceErr.Code)
+	if serviceErr.Message != "" {
+		err = fmt.Errorf("S3 %s: %s", serviceErr.Code, serviceErr.Message)
+	}
+	return nil, &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// head returns the metadata of the object key
+func (s *S3FS) head(op, name, key string) (http.Header, error) {
+	resp, err := s.do(op, name, http.MethodHead, key, nil, nil, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.ContentLength >= 0 {
+		resp.Header.Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+	return resp.Header, nil
+}
+
+// put uploads size bytes from body as the object key with the given metadata
+func (s *S3FS) put(op, name, key string, header http.Header, body io.Reader, size int64, payloadHash string) error {
+	if body == nil {
+		body = strings.NewReader("")
+	}
+	resp, err := s.do(op, name, http.MethodPut, key, nil, header, body, size, payloadHash)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// copyObject copies the object from to to on the server, replacing its
+// metadata with header unless header is nil
+func (s *S3FS) copyObject(op, name, from, to string, header http.Header) error {
+	copyHeader := http.Header{}
+	copyHeader.Set("X-Amz-Copy-Source", "/"+s.config.Bucket+"/"+s3Escape(from, false))
+	if header != nil {
+		copyHeader.Set("X-Amz-Metadata-Directive", "REPLACE")
+		for field, values := range header {
+			copyHeader[field] = values
+		}
+	}
+	return s.put(op, name, to, copyHeader, nil, 0, emptyPayloadHash)
+}
+
+// deleteObject deletes the object key; deleting a missing key succeeds
+func (s *S3FS) deleteObject(op, name, key string) error {
+	resp, err := s.do(op, name, http.MethodDelete, key, nil, nil, nil, 0, emptyPayloadHash)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// s3Listing is one page of a ListObjectsV2 response
+type s3Listing struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list calls visit for every page of objects whose keys start with prefix.
+// With delimiter "/", deeper keys are grouped into common prefixes. A positive
+// limit stops after the first page of at most that many keys.
+func (s *S3FS) list(op, name, prefix, delimiter string, limit int, visit func(*s3Listing)) error {
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("prefix", prefix)
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if limit > 0 {
+		query.Set("max-keys", strconv.Itoa(limit))
+	}
+
+	for {
+		resp, err := s.do(op, name, http.MethodGet, "", query, nil, nil, 0, emptyPayloadHash)
+		if err != nil {
+			return err
+		}
+		var page s3Listing
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return &fs.PathError{Op: op, Path: name, Err: fmt.Errorf("invalid S3 listing: %w", err)}
+		}
+
+		visit(&page)
+		if limit > 0 || !page.IsTruncated {
+			return nil
+		}
+		query.Set("continuation-token", page.NextContinuationToken)
+	}
+}
+
+// keysUnder returns every key inside the directory key, at any depth
+func (s *S3FS) keysUnder(op, name, key string) ([]string, error) {
+	var keys []string
+	err := s.list(op, name, dirPrefix(key), "", 0, func(page *s3Listing) {
+		for _, object := range page.Contents {
+			keys = append(keys, object.Key)
+		}
+	})
+	return keys, err
+}
+
+// s3FileInfo describes an object or a directory prefix
+type s3FileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi *s3FileInfo) Name() string       { return fi.name }
+func (fi *s3FileInfo) Size() int64        { return fi.size }
+func (fi *s3FileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *s3FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *s3FileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *s3FileInfo) Sys() interface{}   { return nil }
+
+// infoFromHeader describes an object from its HEAD response
+func infoFromHeader(name string, header http.Header, defaultMode fs.FileMode) *s3FileInfo {
+	info := &s3FileInfo{name: filepath.Base(name), mode: defaultMode}
+	info.size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if mode, err := strconv.ParseUint(header.Get(s3MetaMode), 10, 32); err == nil {
+		info.mode = fs.FileMode(mode)
+	}
+	if modTime, err := time.Parse(time.RFC3339Nano, header.Get(s3MetaMtime)); err == nil {
+		info.modTime = modTime
+	} else {
+		info.modTime, _ = http.ParseTime(header.Get("Last-Modified"))
+	}
+	if info.mode&fs.ModeSymlink != 0 {
+		info.size = int64(len(header.Get(s3MetaSymlink)))
+	}
+	return info
+}
+
+// Lstat describes an object, or a directory when only its marker or keys
+// inside it exist
+func (s *S3FS) Lstat(name string) (fs.FileInfo, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: err}
+	}
+	if key == s.config.Prefix {
+		return &s3FileInfo{name: filepath.Base(name), mode: fs.ModeDir | 0755}, nil
+	}
+
+	if header, err := s.head("lstat", name, key); err == nil {
+		return infoFromHeader(name, header, 0644), nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if header, err := s.head("lstat", name, dirPrefix(key)); err == nil {
+		info := infoFromHeader(name, header, fs.ModeDir|0755)
+		info.size = 0
+		return info, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	found := false
+	err = s.list("lstat", name, dirPrefix(key), "", 1, func(page *s3Listing) {
+		found = len(page.Contents) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: fs.ErrNotExist}
+	}
+	return &s3FileInfo{name: filepath.Base(name), mode: fs.ModeDir | 0755}, nil
+}
+
+// s3DirEntry is an entry of a listing; the mode of objects, which tells
+// symlinks apart, is only fetched by Info
+type s3DirEntry struct {
+	fsys *S3FS
+	path string
+	info *s3FileInfo
+}
+
+func (e *s3DirEntry) Name() string { return e.info.name }
+func (e *s3DirEntry) IsDir() bool  { return e.info.IsDir() }
+func (e *s3DirEntry) Type() fs.FileMode {
+	return e.info.mode.Type()
+}
+
+func (e *s3DirEntry) Info() (fs.FileInfo, error) {
+	if e.info.IsDir() {
+		return e.info, nil
+	}
+	return e.fsys.Lstat(e.path)
+}
+
+// ReadDir lists the objects and directory prefixes directly inside name
+func (s *S3FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	prefix := dirPrefix(key)
+
+	var entries []fs.DirEntry
+	marker := false
+	err = s.list("readdir", name, prefix, "/", 0, func(page *s3Listing) {
+		for _, common := range page.CommonPrefixes {
+			base := strings.TrimSuffix(strings.TrimPrefix(common.Prefix, prefix), "/")
+			entries = append(entries, &s3DirEntry{fsys: s, path: filepath.Join(name, base),
+				info: &s3FileInfo{name: base, mode: fs.ModeDir | 0755}})
+		}
+		for _, object := range page.Contents {
+			if object.Key == prefix {
+				marker = true
+				continue
+			}
+			base := strings.TrimPrefix(object.Key, prefix)
+			entries = append(entries, &s3DirEntry{fsys: s, path: filepath.Join(name, base),
+				info: &s3FileInfo{name: base, size: object.Size, mode: 0644, modTime: object.LastModified}})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 && !marker && key != s.config.Prefix {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Open returns the contents of an object
+func (s *S3FS) Open(name string) (io.ReadCloser, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	resp, err := s.do("open", name, http.MethodGet, key, nil, nil, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// s3Upload stages a new object in a local temporary file and uploads it on Close
+type s3Upload struct {
+	fsys    *S3FS
+	name    string
+	key     string
+	perm    fs.FileMode
+	staging *os.File
+}
+
+func (u *s3Upload) Write(p []byte) (int, error) {
+	return u.staging.Write(p)
+}
+
+func (u *s3Upload) Close() error {
+	defer os.Remove(u.staging.Name())
+	defer u.staging.Close()
+
+	hash := sha256.New()
+	if _, err := u.staging.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	size, err := io.Copy(hash, u.staging)
+	if err != nil {
+		return err
+	}
+	if _, err := u.staging.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set(s3MetaMode, strconv.FormatUint(uint64(u.perm), 10))
+	return u.fsys.put("write", u.name, u.key, header, u.staging, size, hex.EncodeToString(hash.Sum(nil)))
+}
+
+// Create stages a new object; it is uploaded with the permission bits perm
+// when the returned writer is closed
+func (s *S3FS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: err}
+	}
+	staging, err := os.CreateTemp("", "trash-s3-")
+	if err != nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: err}
+	}
+	return &s3Upload{fsys: s, name: name, key: key, perm: perm.Perm(), staging: staging}, nil
+}
+
+// Mkdir creates the marker of a directory, failing when name exists
+func (s *S3FS) Mkdir(name string, perm fs.FileMode) error {
+	if _, err := s.Lstat(name); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return s.putMarker("mkdir", name, perm, time.Time{})
+}
+
+// MkdirAll creates the marker of a directory unless it exists. Parents need
+// no marker, since a key implies the prefixes above it.
+func (s *S3FS) MkdirAll(name string, perm fs.FileMode) error {
+	info, err := s.Lstat(name)
+	if err == nil {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return s.putMarker("mkdir", name, perm, time.Time{})
+}
+
+// putMarker writes the marker object of the directory name
+func (s *S3FS) putMarker(op, name string, perm fs.FileMode, modTime time.Time) error {
+	key, err := s.key(name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if key == s.config.Prefix {
+		return nil
+	}
+	header := http.Header{}
+	header.Set(s3MetaMode, strconv.FormatUint(uint64(fs.ModeDir|perm.Perm()), 10))
+	if !modTime.IsZero() {
+		header.Set(s3MetaMtime, modTime.UTC().Format(time.RFC3339Nano))
+	}
+	return s.put(op, name, dirPrefix(key), header, nil, 0, emptyPayloadHash)
+}
+
+// Rename moves an object, or every object inside a directory, by copying on
+// the server and deleting the originals
+func (s *S3FS) Rename(oldname, newname string) error {
+	from, err := s.key(oldname)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: err}
+	}
+	to, err := s.key(newname)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: newname, Err: err}
+	}
+
+	if _, err := s.head("rename", oldname, from); err == nil {
+		if err := s.copyObject("rename", oldname, from, to, nil); err != nil {
+			return err
+		}
+		return s.deleteObject("rename", oldname, from)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	keys, err := s.keysUnder("rename", oldname, from)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrNotExist}
+	}
+	for _, key := range keys {
+		if err := s.copyObject("rename", oldname, key, dirPrefix(to)+strings.TrimPrefix(key, dirPrefix(from)), nil); err != nil {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if err := s.deleteObject("rename", oldname, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes an object or an empty directory
+func (s *S3FS) Remove(name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+
+	if _, err := s.head("remove", name, key); err == nil {
+		return s.deleteObject("remove", name, key)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	keys, err := s.keysUnder("remove", name, key)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(keys) == 0:
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	case len(keys) > 1 || keys[0] != dirPrefix(key):
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	return s.deleteObject("remove", name, keys[0])
+}
+
+// RemoveAll deletes an object or a directory with everything inside it
+func (s *S3FS) RemoveAll(name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return &fs.PathError{Op: "removeall", Path: name, Err: err}
+	}
+	if key != s.config.Prefix {
+		if err := s.deleteObject("removeall", name, key); err != nil {
+			return err
+		}
+	}
+
+	keys, err := s.keysUnder("removeall", name, key)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.deleteObject("removeall", name, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Symlink stores a symlink as an empty object recording its target
+func (s *S3FS) Symlink(target, name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return &fs.PathError{Op: "symlink", Path: name, Err: err}
+	}
+	header := http.Header{}
+	header.Set(s3MetaMode, strconv.FormatUint(uint64(fs.ModeSymlink|0777), 10))
+	header.Set(s3MetaSymlink, url.PathEscape(target))
+	return s.put("symlink", name, key, header, nil, 0, emptyPayloadHash)
+}
+
+// Readlink returns the target recorded by Symlink
+func (s *S3FS) Readlink(name string) (string, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: err}
+	}
+	header, err := s.head("readlink", name, key)
+	if err != nil {
+		return "", err
+	}
+	target, err := url.PathUnescape(header.Get(s3MetaSymlink))
+	if err != nil || header.Get(s3MetaSymlink) == "" {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: errors.New("not a symlink")}
+	}
+	return target, nil
+}
+
+// Chmod replaces the recorded permission bits
+func (s *S3FS) Chmod(name string, mode fs.FileMode) error {
+	return s.updateMeta("chmod", name, func(info *s3FileInfo, header http.Header) {
+		header.Set(s3MetaMode, strconv.FormatUint(uint64(info.mode.Type()|mode.Perm()), 10))
+	})
+}
+
+// Chtimes records mtime as the modification time; S3 keeps no access time
+func (s *S3FS) Chtimes(name string, atime, mtime time.Time) error {
+	return s.updateMeta("chtimes", name, func(info *s3FileInfo, header http.Header) {
+		header.Set(s3MetaMtime, mtime.UTC().Format(time.RFC3339Nano))
+	})
+}
+
+// updateMeta rewrites the metadata of an object or directory marker by
+// copying it onto itself, since S3 cannot change metadata in place
+func (s *S3FS) updateMeta(op, name string, update func(*s3FileInfo, http.Header)) error {
+	key, err := s.key(name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if key == s.config.Prefix {
+		return nil
+	}
+
+	stat, err := s.Lstat(name)
+	if err != nil {
+		return err
+	}
+	info := stat.(*s3FileInfo)
+
+	header := http.Header{}
+	header.Set(s3MetaMode, strconv.FormatUint(uint64(info.mode), 10))
+	if !info.modTime.IsZero() {
+		header.Set(s3MetaMtime, info.modTime.UTC().Format(time.RFC3339Nano))
+	}
+
+	if info.IsDir() {
+		update(info, header)
+		if _, err := s.head(op, name, dirPrefix(key)); errors.Is(err, fs.ErrNotExist) {
+			return s.put(op, name, dirPrefix(key), header, nil, 0, emptyPayloadHash)
+		}
+		return s.copyObject(op, name, dirPrefix(key), dirPrefix(key), header)
+	}
+
+	if info.mode&fs.ModeSymlink != 0 {
+		target, err := s.Readlink(name)
+		if err != nil {
+			return err
+		}
+		header.Set(s3MetaSymlink, url.PathEscape(target))
+	}
+	update(info, header)
+	return s.copyObject(op, name, key, key, header)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SequenceFileName holds the last operation sequence number handed out in the
+// trash directory. It lives in the store next to the sessions it numbers, so a
+// remote store keeps counting from wherever it is used.
+const SequenceFileName = "sequence"
+
+// nextSequence increments the sequence counter of the trash directory and
+// returns the new value. Unlike timestamps it never goes backwards when the
+// clock does, so it orders sessions created by this store reliably.
+func nextSequence() (uint64, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return 0, err
+	}
+	sequencePath := filepath.Join(configDir, SequenceFileName)
+
+	// A lock of its own: callers may already hold the store lock
+	unlock, err := lockFile(sequencePath+".lock", "sequence counter")
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var n uint64
+	data, err := ReadFile(storeFS, sequencePath)
+	switch {
+	case err == nil:
+		n, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid sequence counter in %s: %w", sequencePath, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		// A lost counter would restart at 1 and sort new sessions before the
+		// existing ones, so it carries on from the highest number they hold
+		if n, err = highestSequence(configDir); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("failed to read sequence counter: %w", err)
+	}
+	n++
+
+	// Write then rename so a crash never leaves a truncated counter
+	tmpPath := sequencePath + ".tmp"
+	if err := WriteFile(storeFS, tmpPath, []byte(strconv.FormatUint(n, 10)+"\n"), 0600); err != nil {
+		return 0, fmt.Errorf("failed to write sequence counter: %w", err)
+	}
+	if err := storeFS.Rename(tmpPath, sequencePath); err != nil {
+		return 0, fmt.Errorf("failed to write sequence counter: %w", err)
+	}
+	return n, nil
+}
+
+// highestSequence returns the highest sequence number recorded in the metadata
+// of the sessions in configDir, 0 when there is none
+func highestSequence(configDir string) (uint64, error) {
+	entries, err := storeFS.ReadDir(configDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	var highest uint64
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == PurgingDirName {
+			continue
+		}
+		if metadata, err := LoadRestoreMetadata(filepath.Join(configDir, entry.Name())); err == nil && metadata.Sequence > highest {
+			highest = metadata.Sequence
+		}
+	}
+	return highest, nil
+}
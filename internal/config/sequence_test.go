@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNextSequenceSeedsFromSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	configDir := t.TempDir()
+	UseStoreDir(configDir)
+	defer UseStoreDir("")
+
+	for session, sequence := range map[string]uint64{
+		"20250101_120000.000000000-abcd": 6,
+		"20250102_120000.000000000-abcd": 41,
+	} {
+		trashDir := filepath.Join(configDir, session)
+		if err := os.Mkdir(trashDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveRestoreMetadata(trashDir, &RestoreMetadata{Sequence: sequence}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []uint64{42, 43} {
+		if n, err := nextSequence(); err != nil || n != want {
+			t.Fatalf("nextSequence() = %d, %v; want %d", n, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(configDir, SequenceFileName)); err != nil {
+		t.Errorf("counter not kept in the store: %v", err)
+	}
+}
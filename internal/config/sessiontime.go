@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sessionTimes caches the creation times SortSessions read from session
+// metadata, by session name, so SessionTime does not read it again
+var sessionTimes sync.Map
+
+// SessionTime returns when a session was created, in local time: the UTC time
+// recorded in its metadata when the session has been listed, otherwise the
+// local time its name starts with
+func SessionTime(session string) (time.Time, error) {
+	if t, ok := sessionTimes.Load(session); ok {
+		return t.(time.Time), nil
+	}
+	return ParseSessionTime(session)
+}
+
+// sessionOrder is what sessions are ordered by
+type sessionOrder struct {
+	sequence uint64    // 0 when the session has none
+	created  time.Time // zero when unknown
+}
+
+// before reports whether a session ordered by o comes before one ordered by
+// other: sessions without a sequence number first, by time, then the others
+// by sequence number. Sessions whose time is unknown come last among those
+// without a sequence number.
+func (o sessionOrder) before(other sessionOrder) (bool, bool) {
+	switch {
+	case (o.sequence == 0) != (other.sequence == 0):
+		return o.sequence == 0, true
+	case o.sequence != other.sequence:
+		return o.sequence < other.sequence, true
+	case o.created.IsZero() != other.created.IsZero():
+		return !o.created.IsZero(), true
+	case !o.created.Equal(other.created):
+		return o.created.Before(other.created), true
+	}
+	return false, false
+}
+
+// readSessionOrder returns the sequence number and creation time of a session
+// from its metadata, nil when it could not be read, falling back to the time
+// its name starts with
+func readSessionOrder(session string, metadata *RestoreMetadata) sessionOrder {
+	var order sessionOrder
+	if metadata != nil {
+		order.sequence = metadata.Sequence
+		if t, err := time.Parse(time.RFC3339Nano, metadata.CreatedAt); err == nil {
+			order.created = t.Local()
+			sessionTimes.Store(session, order.created)
+		}
+	}
+	if order.created.IsZero() {
+		order.created, _ = ParseSessionTime(session)
+	}
+	return order
+}
+
+// SortSessions orders the sessions of the trash directory dir oldest first.
+// The sequence numbers in their metadata order the sessions created by this
+// store whatever the clock did; sessions from older versions or other stores
+// come before them, ordered by the creation time in their metadata or their
+// name. Remaining ties are broken by name.
+func SortSessions(dir string, sessions []string) {
+	metadata := make(map[string]*RestoreMetadata, len(sessions))
+	for _, session := range sessions {
+		metadata[session], _ = LoadRestoreMetadata(filepath.Join(dir, session))
+	}
+	SortLoadedSessions(sessions, metadata)
+}
+
+// SortLoadedSessions orders sessions like SortSessions, by the metadata the
+// caller has already read for them, so that a listing reads it only once. A
+// session without metadata in the map is ordered by its name.
+func SortLoadedSessions(sessions []string, metadata map[string]*RestoreMetadata) {
+	orders := make(map[string]sessionOrder, len(sessions))
+	for _, session := range sessions {
+		orders[session] = readSessionOrder(session, metadata[session])
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if before, ok := orders[sessions[i]].before(orders[sessions[j]]); ok {
+			return before
+		}
+		return sessions[i] < sessions[j]
+	})
+}
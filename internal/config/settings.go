@@ -0,0 +1,495 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SettingsFileName is the name of the user configuration file inside the trash directory
+const SettingsFileName = "config.toml"
+
+// Settings represents the user configuration loaded from config.toml
+type Settings struct {
+	// MinFree is the minimum free space to keep on the trash filesystem (e.g. "10GB").
+	// When free space drops below it, the oldest sessions are purged automatically.
+	MinFree string `toml:"min_free"`
+
+	// BWLimit caps the rate of cross-device copies per second (e.g. "20MB")
+	BWLimit string `toml:"bwlimit"`
+
+	// CompressAfter is the age (e.g. "30d") after which prune packs the
+	// payloads of a session into a compressed archive
+	CompressAfter string `toml:"compress_after"`
+
+	// PurgeRate caps purges at deleting this many files and directories per
+	// second, so that emptying a huge trash does not saturate the disk
+	PurgeRate uint64 `toml:"purge_rate"`
+
+	// CopyRetries is how often a copy retries a read or write that failed
+	// with a transient error such as EIO (default 3)
+	CopyRetries *uint64 `toml:"copy_retries"`
+
+	// NetworkFS treats the trash directory as on a network filesystem such as
+	// NFS: "auto" (detect it), "always" or "never"
+	NetworkFS string `toml:"network_fs"`
+
+	// Notify controls desktop notifications for automatic purges (default true)
+	Notify *bool `toml:"notify"`
+
+	// SessionWindow appends trash operations to the current "hour" or "day"
+	// session instead of creating a session per operation
+	SessionWindow string `toml:"session_window"`
+
+	// Roots lists additional trash directories searched by --all-roots
+	Roots []string `toml:"roots"`
+
+	// WSLDriveTrash keeps items trashed from a Windows drive under WSL in a
+	// trash directory on that drive instead of copying them into the Linux home
+	WSLDriveTrash bool `toml:"wsl_drive_trash"`
+
+	// Snapshots sets TrashOptions.Snapshot by default
+	Snapshots bool `toml:"snapshots"`
+
+	// SignMetadata writes an HMAC next to every .restore file so that changes
+	// made outside trash are detected before restoring
+	SignMetadata bool `toml:"sign_metadata"`
+
+	// KeySource is where the signing key is kept: "file", "keyring", "age",
+	// "gpg" or "passphrase"
+	KeySource string `toml:"key_source"`
+
+	// AgeIdentity is the age identity file that protects the key with key_source age
+	AgeIdentity string `toml:"age_identity"`
+
+	// Viewer is the command used by open instead of the desktop's default
+	// application, e.g. "less" or "code --wait"
+	Viewer string `toml:"viewer"`
+
+	// Store keeps sessions in a remote location instead of this directory,
+	// e.g. "s3://bucket/prefix" or "sftp://user@host/~/trash"
+	Store string `toml:"store"`
+
+	// S3Endpoint is the URL of an S3-compatible service such as MinIO
+	S3Endpoint string `toml:"s3_endpoint"`
+
+	// S3Region is the region of the bucket of an s3:// store
+	S3Region string `toml:"s3_region"`
+
+	// DirMode is the octal permissions of the trash directory and its sessions
+	// (default "0700"); metadata files get the same without execute bits
+	DirMode string `toml:"dir_mode"`
+}
+
+// GetSettingsPath returns the path to the config.toml file
+func GetSettingsPath() (string, error) {
+	dir, err := settingsDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, SettingsFileName), nil
+}
+
+// settingsDir returns the directory of config.toml: a project's local trash
+// directory, a trash directory of an older version, which keeps its settings
+// with it, or $XDG_CONFIG_HOME/trash
+func settingsDir() (string, error) {
+	if storeDir != "" {
+		return storeDir, nil
+	}
+
+	store, err := homeStoreDir()
+	if err != nil {
+		return "", err
+	}
+	if legacy, err := LegacyStoreDir(); err == nil && store == legacy {
+		return store, nil
+	}
+	return homeSettingsDir()
+}
+
+// LoadSettings reads config.toml from the trash directory
+// A missing file is not an error and yields the default settings
+func LoadSettings() (*Settings, error) {
+	settings := &Settings{}
+
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return settings, err
+	}
+
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return settings, nil
+	}
+
+	if _, err := toml.DecodeFile(settingsPath, settings); err != nil {
+		return settings, fmt.Errorf("failed to parse %s: %w", settingsPath, err)
+	}
+
+	return settings, nil
+}
+
+// MinFreeBytes returns the min_free policy in bytes, or 0 when it is not set
+func (s *Settings) MinFreeBytes() (uint64, error) {
+	if s.MinFree == "" {
+		return 0, nil
+	}
+
+	size, err := ParseSize(s.MinFree)
+	if err != nil {
+		return 0, fmt.Errorf("invalid min_free: %w", err)
+	}
+
+	return size, nil
+}
+
+// BWLimitBytes returns the bwlimit setting in bytes per second, or 0 when it is not set
+func (s *Settings) BWLimitBytes() (uint64, error) {
+	if s.BWLimit == "" {
+		return 0, nil
+	}
+
+	size, err := ParseSize(s.BWLimit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bwlimit: %w", err)
+	}
+
+	return size, nil
+}
+
+// CompressAge returns the compress_after policy, or 0 when it is not set
+func (s *Settings) CompressAge() (time.Duration, error) {
+	if s.CompressAfter == "" {
+		return 0, nil
+	}
+
+	age, err := ParseDuration(s.CompressAfter)
+	if err != nil {
+		return 0, fmt.Errorf("invalid compress_after: %w", err)
+	}
+
+	return age, nil
+}
+
+// Window returns the configured session window
+func (s *Settings) Window() (SessionWindow, error) {
+	window, err := ParseSessionWindow(s.SessionWindow)
+	if err != nil {
+		return WindowNone, fmt.Errorf("invalid session_window: %w", err)
+	}
+	return window, nil
+}
+
+// Network values of network_fs
+const (
+	NetworkAuto   = "auto"
+	NetworkAlways = "always"
+	NetworkNever  = "never"
+)
+
+// Network returns the network_fs policy, "auto" when it is not set
+func (s *Settings) Network() (string, error) {
+	switch s.NetworkFS {
+	case "":
+		return NetworkAuto, nil
+	case NetworkAuto, NetworkAlways, NetworkNever:
+		return s.NetworkFS, nil
+	}
+	return NetworkAuto, fmt.Errorf("invalid network_fs: expected auto, always or never")
+}
+
+// Retries returns how often copies retry a read or write after a transient error
+func (s *Settings) Retries() uint64 {
+	if s.CopyRetries == nil {
+		return DefaultCopyRetries
+	}
+	return *s.CopyRetries
+}
+
+// NotifyEnabled reports whether automatic purges should send a desktop notification
+func (s *Settings) NotifyEnabled() bool {
+	return s.Notify == nil || *s.Notify
+}
+
+// SettingInfo describes a key that can be set in config.toml
+type SettingInfo struct {
+	Name        string
+	Description string
+	Default     string
+}
+
+// settingsInfo lists every supported key in config.toml
+var settingsInfo = []SettingInfo{
+	{Name: "age_identity", Description: "age identity file protecting the signing key when key_source is age", Default: ""},
+	{Name: "bwlimit", Description: "Limit cross-device copies to this many bytes per second (e.g. 20MB)", Default: ""},
+	{Name: "compress_after", Description: "Compress the payloads of sessions older than this when prune runs (e.g. 30d)", Default: ""},
+	{Name: "copy_retries", Description: "Retry a read or write of a copy this many times, with backoff, when it fails with a transient error such as EIO", Default: "3"},
+	{Name: "dir_mode", Description: "Permissions of the trash directory and its sessions; metadata files get the same without execute bits", Default: "0700"},
+	{Name: "key_source", Description: "Where the signing key is kept: file, keyring, age, gpg or passphrase", Default: "file"},
+	{Name: "min_free", Description: "Purge the oldest sessions when free space drops below this size (e.g. 10GB)", Default: ""},
+	{Name: "network_fs", Description: "Lock with lockfiles and sync copies, as on NFS: auto (when detected), always or never", Default: "auto"},
+	{Name: "notify", Description: "Show a desktop notification when items are purged automatically", Default: "true"},
+	{Name: "purge_rate", Description: "Delete at most this many files per second when purging (0 for no limit)", Default: "0"},
+	{Name: "roots", Description: "Additional trash directories searched by --all-roots (comma-separated)", Default: ""},
+	{Name: "s3_endpoint", Description: "URL of the S3-compatible service of an s3:// store (default AWS)", Default: ""},
+	{Name: "s3_region", Description: "Region of the bucket of an s3:// store", Default: "us-east-1"},
+	{Name: "session_window", Description: "Reuse one session per hour or day instead of one per trash operation (none, hour or day)", Default: "none"},
+	{Name: "sign_metadata", Description: "Sign session metadata and refuse to restore from sessions modified outside trash", Default: "false"},
+	{Name: "snapshots", Description: "Keep btrfs subvolumes, and directories and large files on ZFS datasets with trash:snapshots=on, in a snapshot instead of copying them to the trash on another filesystem", Default: "false"},
+	{Name: "store", Description: "Keep sessions in a remote store instead of the trash directory (e.g. s3://bucket/prefix or sftp://host/~/trash)", Default: ""},
+	{Name: "viewer", Description: "Command used by open instead of the default application (e.g. less)", Default: ""},
+	{Name: "wsl_drive_trash", Description: "Under WSL, trash files on Windows drives into a trash directory on that drive", Default: "false"},
+}
+
+// SettingsInfo returns the supported configuration keys
+func SettingsInfo() []SettingInfo {
+	return settingsInfo
+}
+
+// Value returns the configured value of a key and whether it is set explicitly
+func (s *Settings) Value(name string) (string, bool, error) {
+	switch name {
+	case "age_identity":
+		return s.AgeIdentity, s.AgeIdentity != "", nil
+	case "bwlimit":
+		return s.BWLimit, s.BWLimit != "", nil
+	case "compress_after":
+		return s.CompressAfter, s.CompressAfter != "", nil
+	case "copy_retries":
+		return strconv.FormatUint(s.Retries(), 10), s.CopyRetries != nil, nil
+	case "dir_mode":
+		if s.DirMode == "" {
+			return fmt.Sprintf("%04o", DefaultDirMode), false, nil
+		}
+		return s.DirMode, true, nil
+	case "key_source":
+		if s.KeySource == "" {
+			return KeySourceFile, false, nil
+		}
+		return s.KeySource, true, nil
+	case "min_free":
+		return s.MinFree, s.MinFree != "", nil
+	case "network_fs":
+		if s.NetworkFS == "" {
+			return NetworkAuto, false, nil
+		}
+		return s.NetworkFS, true, nil
+	case "notify":
+		return strconv.FormatBool(s.NotifyEnabled()), s.Notify != nil, nil
+	case "purge_rate":
+		return strconv.FormatUint(s.PurgeRate, 10), s.PurgeRate != 0, nil
+	case "roots":
+		return strings.Join(s.Roots, ","), len(s.Roots) > 0, nil
+	case "session_window":
+		if s.SessionWindow == "" {
+			return "none", false, nil
+		}
+		return s.SessionWindow, true, nil
+	case "sign_metadata":
+		return strconv.FormatBool(s.SignMetadata), s.SignMetadata, nil
+	case "snapshots":
+		return strconv.FormatBool(s.Snapshots), s.Snapshots, nil
+	case "store":
+		return s.Store, s.Store != "", nil
+	case "s3_endpoint":
+		return s.S3Endpoint, s.S3Endpoint != "", nil
+	case "s3_region":
+		if s.S3Region == "" {
+			return "us-east-1", false, nil
+		}
+		return s.S3Region, true, nil
+	case "viewer":
+		return s.Viewer, s.Viewer != "", nil
+	case "wsl_drive_trash":
+		return strconv.FormatBool(s.WSLDriveTrash), s.WSLDriveTrash, nil
+	}
+	return "", false, fmt.Errorf("unknown setting %q", name)
+}
+
+// parseSetting validates a value for a key and converts it to its TOML type
+func parseSetting(name, value string) (interface{}, error) {
+	switch name {
+	case "age_identity":
+		absPath, err := filepath.Abs(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age_identity: %w", err)
+		}
+		return absPath, nil
+	case "bwlimit":
+		if _, err := ParseSize(value); err != nil {
+			return nil, fmt.Errorf("invalid bwlimit: %w", err)
+		}
+		return value, nil
+	case "compress_after":
+		if _, err := ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid compress_after: %w", err)
+		}
+		return value, nil
+	case "copy_retries":
+		retries, err := strconv.ParseUint(value, 10, 63)
+		if err != nil {
+			return nil, fmt.Errorf("invalid copy_retries: expected a number of retries")
+		}
+		return int64(retries), nil
+	case "dir_mode":
+		if _, err := ParseDirMode(value); err != nil {
+			return nil, fmt.Errorf("invalid dir_mode: %w", err)
+		}
+		return value, nil
+	case "key_source":
+		switch value {
+		case KeySourceFile, KeySourceKeyring, KeySourceAge, KeySourceGPG, KeySourcePassphrase:
+			return value, nil
+		}
+		return nil, fmt.Errorf("invalid key_source: expected file, keyring, age, gpg or passphrase")
+	case "min_free":
+		if _, err := ParseSize(value); err != nil {
+			return nil, fmt.Errorf("invalid min_free: %w", err)
+		}
+		return value, nil
+	case "network_fs":
+		if _, err := (&Settings{NetworkFS: value}).Network(); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "notify":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notify: expected true or false")
+		}
+		return enabled, nil
+	case "purge_rate":
+		rate, err := strconv.ParseUint(value, 10, 63)
+		if err != nil {
+			return nil, fmt.Errorf("invalid purge_rate: expected a number of files per second")
+		}
+		return int64(rate), nil
+	case "roots":
+		var dirs []string
+		for _, dir := range strings.Split(value, ",") {
+			if dir = strings.TrimSpace(dir); dir == "" {
+				continue
+			}
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, fmt.Errorf("invalid roots: %w", err)
+			}
+			dirs = append(dirs, absDir)
+		}
+		return dirs, nil
+	case "session_window":
+		if _, err := ParseSessionWindow(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "sign_metadata":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sign_metadata: expected true or false")
+		}
+		return enabled, nil
+	case "snapshots":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshots: expected true or false")
+		}
+		return enabled, nil
+	case "store":
+		location, err := url.Parse(value)
+		if err != nil || location.Host == "" || (location.Scheme != "s3" && location.Scheme != "sftp" && location.Scheme != "ssh") {
+			return nil, fmt.Errorf("invalid store: expected s3://bucket/prefix or sftp://[user@]host/dir")
+		}
+		return value, nil
+	case "s3_endpoint":
+		if location, err := url.Parse(value); err != nil || location.Scheme == "" || location.Host == "" {
+			return nil, fmt.Errorf("invalid s3_endpoint: expected a URL such as http://localhost:9000")
+		}
+		return value, nil
+	case "s3_region":
+		return value, nil
+	case "viewer":
+		return value, nil
+	case "wsl_drive_trash":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wsl_drive_trash: expected true or false")
+		}
+		return enabled, nil
+	}
+	return nil, fmt.Errorf("unknown setting %q", name)
+}
+
+// SetSetting validates value and writes it to config.toml. Other keys in the
+// file are kept, but comments are not preserved.
+func SetSetting(name, value string) error {
+	parsed, err := parseSetting(name, value)
+	if err != nil {
+		return err
+	}
+
+	return updateSettingsFile(func(raw map[string]interface{}) {
+		raw[name] = parsed
+	})
+}
+
+// UnsetSetting removes a key from config.toml so its default applies again
+func UnsetSetting(name string) error {
+	if !isKnownSetting(name) {
+		return fmt.Errorf("unknown setting %q", name)
+	}
+
+	return updateSettingsFile(func(raw map[string]interface{}) {
+		delete(raw, name)
+	})
+}
+
+// isKnownSetting reports whether name is a supported key
+func isKnownSetting(name string) bool {
+	for _, info := range settingsInfo {
+		if info.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// updateSettingsFile applies update to the raw contents of config.toml and writes it back
+func updateSettingsFile(update func(map[string]interface{})) error {
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return err
+	}
+
+	raw := make(map[string]interface{})
+	if _, err := os.Stat(settingsPath); err == nil {
+		if _, err := toml.DecodeFile(settingsPath, &raw); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", settingsPath, err)
+		}
+	}
+
+	update(raw)
+
+	// The XDG base directory specification asks for 0700 on directories it creates
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(settingsPath), err)
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := os.WriteFile(settingsPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", settingsPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// SignatureFileName holds the HMAC of a session's .restore file
+const SignatureFileName = ".restore.sig"
+
+// SigningKeyFileName is the secret key for metadata signatures. It always lives
+// in the home trash directory, never in a local or shared store it protects,
+// unless the key_source setting keeps it elsewhere.
+const SigningKeyFileName = "signing.key"
+
+// SignatureStatus is the result of checking a session's metadata signature
+type SignatureStatus int
+
+const (
+	// SignatureMissing means the session has no signature, e.g. it was
+	// created before sign_metadata was enabled
+	SignatureMissing SignatureStatus = iota
+	// SignatureValid means the metadata is unchanged since trash last wrote it
+	SignatureValid
+	// SignatureInvalid means the metadata was modified outside trash
+	SignatureInvalid
+)
+
+// SigningEnabled reports whether the sign_metadata setting is on
+func SigningEnabled() bool {
+	settings, err := LoadSettings()
+	return err == nil && settings.SignMetadata
+}
+
+// signature returns the hex HMAC-SHA256 of data
+func signature(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRestoreMetadata writes the signature of the .restore contents data when
+// signing is enabled. Otherwise a previous signature is removed, since it no
+// longer matches.
+func signRestoreMetadata(trashDir string, data []byte) error {
+	sigPath := filepath.Join(trashDir, SignatureFileName)
+
+	if !SigningEnabled() {
+		if err := storeFS.Remove(sigPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove stale signature: %w", err)
+		}
+		return nil
+	}
+
+	key, err := signingKey(true)
+	if err != nil {
+		return err
+	}
+	_, fileMode := storeModes()
+	if err := WriteFile(storeFS, sigPath, []byte(signature(key, data)+"\n"), fileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SignatureFileName, err)
+	}
+	return nil
+}
+
+// CheckRestoreSignature checks the .restore file of a session against its signature
+func CheckRestoreSignature(trashDir string) (SignatureStatus, error) {
+	sig, err := ReadFile(storeFS, filepath.Join(trashDir, SignatureFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return SignatureMissing, nil
+	}
+	if err != nil {
+		return SignatureInvalid, fmt.Errorf("failed to read %s: %w", SignatureFileName, err)
+	}
+
+	data, err := ReadFile(storeFS, filepath.Join(trashDir, ".restore"))
+	if err != nil {
+		return SignatureInvalid, fmt.Errorf("failed to read .restore file: %w", err)
+	}
+
+	key, err := signingKey(false)
+	if err != nil {
+		return SignatureInvalid, err
+	}
+
+	expected := signature(key, data)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(string(sig)))) {
+		return SignatureInvalid, nil
+	}
+	return SignatureValid, nil
+}
+
+// VerifyRestoreMetadata returns an ErrTampered error when the metadata of a
+// session does not match its signature, or when signing is enabled and the
+// session has none
+func VerifyRestoreMetadata(trashDir string) error {
+	status, err := CheckRestoreSignature(trashDir)
+	session := filepath.Base(trashDir)
+
+	switch {
+	case err != nil:
+		return withKind(ErrTampered, fmt.Errorf("cannot verify metadata of session %s: %w", session, err))
+	case status == SignatureInvalid:
+		return withKind(ErrTampered, fmt.Errorf("metadata of session %s was modified outside trash", session))
+	case status == SignatureMissing && SigningEnabled():
+		return withKind(ErrTampered, fmt.Errorf("metadata of session %s is not signed", session))
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps size suffixes to their multiplier in bytes (binary units)
+var sizeUnits = map[string]uint64{
+	"":   1,
+	"B":  1,
+	"K":  1 << 10,
+	"KB": 1 << 10,
+	"M":  1 << 20,
+	"MB": 1 << 20,
+	"G":  1 << 30,
+	"GB": 1 << 30,
+	"T":  1 << 40,
+	"TB": 1 << 40,
+}
+
+// ParseSize parses a human readable size such as "10GB", "512M" or "1024"
+func ParseSize(value string) (uint64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	// Split numeric part from unit suffix
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	number, unit := s[:i], strings.TrimSpace(s[i:])
+	unit = strings.TrimSuffix(unit, "IB") // accept KiB, MiB, GiB...
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in %q", value)
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	return uint64(n * float64(multiplier)), nil
+}
+
+// FormatSize formats a byte count as a human readable string (e.g. "1.5 GB")
+func FormatSize(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// PayloadSize returns the size of the item's payload in the session trashDir,
+// as recorded at trash time or, for items without a recorded size, measured
+func (item RestoreItem) PayloadSize(trashDir string) uint64 {
+	if item.Size != nil {
+		return *item.Size
+	}
+	size, _ := treeSize(storeFS, item.PayloadPath(trashDir))
+	return size
+}
+
+// Size returns the size of the item's payload, see RestoreItem.PayloadSize
+func (e TrashedItem) Size() uint64 {
+	if e.Item.Size != nil {
+		return *e.Item.Size
+	}
+	payload, err := e.Path()
+	if err != nil {
+		return 0
+	}
+	size, _ := PathSize(payload)
+	return size
+}
+
+// sessionSize returns the size of a session: the sum of its items' recorded
+// sizes, or, when some are not recorded or it has no metadata, its measured size
+func sessionSize(trashDir string) uint64 {
+	if metadata, err := LoadRestoreMetadata(trashDir); err == nil {
+		var total uint64
+		recorded := true
+		for _, item := range metadata.Items {
+			if item.Size == nil {
+				recorded = false
+				break
+			}
+			total += *item.Size
+		}
+		if recorded {
+			return total
+		}
+	}
+	size, _ := treeSize(storeFS, trashDir)
+	return size
+}
+
+// PathSize returns the total size in bytes of a file or directory tree
+// Symlinks are counted by their own size and never followed
+func PathSize(path string) (uint64, error) {
+	var total uint64
+
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0 {
+			total += uint64(info.Size())
+		}
+		return nil
+	})
+
+	return total, err
+}
@@ -0,0 +1,18 @@
+//go:build darwin || freebsd
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// snapshotKind returns the kind of snapshot the filesystem of path can
+// keep trashed files in, "" for none
+func snapshotKind(path string) string {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return ""
+	}
+	if unix.ByteSliceToString(stat.Fstypename[:]) == "zfs" {
+		return SnapshotZFS
+	}
+	return ""
+}
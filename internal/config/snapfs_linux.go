@@ -0,0 +1,24 @@
+//go:build linux
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// zfsSuperMagic is the statfs type of ZFS, which x/sys does not name
+const zfsSuperMagic = 0x2fc12fc1
+
+// snapshotKind returns the kind of snapshot the filesystem of path can
+// keep trashed files in, "" for none
+func snapshotKind(path string) string {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return ""
+	}
+	switch uint32(stat.Type) {
+	case unix.BTRFS_SUPER_MAGIC:
+		return SnapshotBtrfs
+	case zfsSuperMagic:
+		return SnapshotZFS
+	}
+	return ""
+}
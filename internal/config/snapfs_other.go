@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd
+
+package config
+
+// snapshotKind returns "": snapshots are only taken on Linux, macOS and FreeBSD
+func snapshotKind(path string) string {
+	return ""
+}
@@ -0,0 +1,460 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Snapshot kinds
+const (
+	SnapshotBtrfs = "btrfs"
+	SnapshotZFS   = "zfs"
+)
+
+// SnapshotRefName is the file in the item directory of an item kept in a
+// snapshot that points at the snapshot, so that purging the item directory
+// deletes the snapshot too
+const SnapshotRefName = ".snapshot-ref"
+
+// SnapshotDirName is the directory next to a trashed btrfs subvolume that
+// holds the snapshot taken of it
+const SnapshotDirName = ".trash-snapshots"
+
+// zfsSnapshotPrefix starts the names of the ZFS snapshots taken by trash
+const zfsSnapshotPrefix = "trash-"
+
+// ZFSSnapshotProperty is the ZFS user property that allows trash to snapshot
+// a dataset. A snapshot keeps every block of the dataset that changes while
+// it exists, not just the trashed path, so it is only taken of datasets set
+// to "on", e.g. with 'zfs set trash:snapshots=on tank/scratch'.
+const ZFSSnapshotProperty = "trash:snapshots"
+
+// snapshotMinSize is the size from which regular files are snapshotted;
+// smaller ones are copied quicker than a snapshot is taken
+const snapshotMinSize = 64 * 1024 * 1024
+
+// SnapshotRef locates a payload that was left in a snapshot of the btrfs
+// subvolume or ZFS dataset it was trashed from instead of being copied into
+// the trash directory
+type SnapshotRef struct {
+	Kind string `json:"kind"`
+	// Name is the snapshot: the path of the btrfs subvolume, or dataset@name for ZFS
+	Name string `json:"name"`
+	// Path is the payload inside the snapshot
+	Path string `json:"path"`
+}
+
+// WarnSnapshot, when set, is called for every path that could not be
+// snapshotted and is copied into the trash directory instead
+var WarnSnapshot func(path string, err error)
+
+// PayloadPath returns the location of the item's payload: inside its
+// snapshot, or in the session trashDir
+func (item RestoreItem) PayloadPath(trashDir string) string {
+	if item.Snapshot != nil {
+		return item.Snapshot.Path
+	}
+	return filepath.Join(trashDir, item.Storage())
+}
+
+// Validate rejects a reference that does not name a snapshot trash takes, so
+// that one edited by hand never makes restore move, or purge delete, anything
+// else. A btrfs snapshot is <dir>/.trash-snapshots/<session>-<item-id> and is
+// the payload itself; a ZFS snapshot is <dataset>@trash-<session>-<item-id>
+// and the payload is below its directory <mountpoint>/.zfs/snapshot/<name>.
+func (ref *SnapshotRef) Validate() error {
+	if !filepath.IsAbs(ref.Path) || filepath.Clean(ref.Path) != ref.Path || strings.ContainsRune(ref.Path, 0) {
+		return fmt.Errorf("invalid snapshot path %q: not a clean absolute path", ref.Path)
+	}
+
+	switch ref.Kind {
+	case SnapshotBtrfs:
+		if !filepath.IsAbs(ref.Name) || filepath.Clean(ref.Name) != ref.Name ||
+			filepath.Base(filepath.Dir(ref.Name)) != SnapshotDirName || !validSnapshotLabel(filepath.Base(ref.Name)) {
+			return fmt.Errorf("invalid btrfs snapshot %q", ref.Name)
+		}
+		if ref.Path != ref.Name {
+			return fmt.Errorf("invalid snapshot path %q: not the snapshot %s", ref.Path, ref.Name)
+		}
+	case SnapshotZFS:
+		dataset, name, _ := strings.Cut(ref.Name, "@")
+		if dataset == "" || strings.ContainsAny(dataset, "@\x00") || !strings.HasPrefix(name, zfsSnapshotPrefix) ||
+			!validSnapshotLabel(strings.TrimPrefix(name, zfsSnapshotPrefix)) {
+			return fmt.Errorf("invalid ZFS snapshot %q", ref.Name)
+		}
+		dir := "/.zfs/snapshot/" + name
+		i := strings.Index(ref.Path, dir)
+		if i < 0 || !IsWithin(ref.Path, ref.Path[:i+len(dir)]) && ref.Path != ref.Path[:i+len(dir)] {
+			return fmt.Errorf("invalid snapshot path %q: not in the snapshot %s", ref.Path, ref.Name)
+		}
+	default:
+		return fmt.Errorf("unknown snapshot kind %q", ref.Kind)
+	}
+	return nil
+}
+
+// validSnapshotLabel reports whether label names a snapshot after the item
+// it keeps: <session>-<item-id>
+func validSnapshotLabel(label string) bool {
+	i := strings.LastIndexByte(label, '-')
+	if i < 0 || !IsItemID(label[i+1:]) {
+		return false
+	}
+	session := label[:i]
+	_, err := ParseSessionTime(session)
+	return err == nil && filepath.Base(session) == session && session != ".."
+}
+
+// snapshotToTrash trashes absPath into a new item directory of the session
+// trashDir by snapshotting it and removing it, which is much quicker than
+// copying a large tree to another filesystem. It applies to paths that cannot
+// be renamed into the trash directory and that a snapshot keeps alone: a btrfs
+// subvolume, or a directory or large file on a ZFS dataset that allows it (see
+// ZFSSnapshotProperty). Otherwise, or when the snapshot fails, ref is nil and
+// absPath is to be moved as usual. size is what the snapshot keeps of absPath.
+func snapshotToTrash(absPath, trashDir string) (storagePath string, ref *SnapshotRef, size uint64, err error) {
+	if !isLocal(storeFS) {
+		return "", nil, 0, nil
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil || !snapshottable(absPath, info) {
+		return "", nil, 0, nil // moved as usual, which reports a missing path
+	}
+	kind := snapshotKind(absPath)
+	if same, err := SameDevice(absPath, trashDir); err != nil || same {
+		return "", nil, 0, nil
+	}
+	// Subvolumes and datasets below it are not part of the snapshot
+	size, ok := treeOnDevice(absPath, info)
+	if !ok {
+		return "", nil, 0, nil
+	}
+
+	id, err := newItemDir(trashDir)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	itemDir := filepath.Join(trashDir, id)
+
+	resolved := resolvePath(absPath)
+	label := filepath.Base(trashDir) + "-" + id
+	if kind == SnapshotBtrfs {
+		ref, err = btrfsSnapshot(resolved, label)
+	} else {
+		ref, err = zfsSnapshot(resolved, label)
+	}
+	if err == nil {
+		if _, err = os.Lstat(ref.Path); err == nil {
+			err = writeSnapshotRef(itemDir, ref)
+		}
+		if err != nil {
+			destroySnapshot(ref)
+		}
+	}
+	if err != nil {
+		storeFS.RemoveAll(itemDir)
+		if WarnSnapshot != nil {
+			WarnSnapshot(absPath, err)
+		}
+		return "", nil, 0, nil
+	}
+
+	// Remove the original now that the snapshot keeps it. Without the
+	// privilege to delete subvolumes, an empty one is removed like a directory.
+	if info.IsDir() {
+		err = os.RemoveAll(absPath)
+		if err != nil && kind == SnapshotBtrfs {
+			if _, deleteErr := runSnapshotTool("btrfs", "subvolume", "delete", absPath); deleteErr == nil {
+				err = nil
+			}
+		}
+	} else {
+		err = os.Remove(absPath)
+	}
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("failed to remove original %s: %w", absPath, err)
+	}
+
+	return id + "/" + filepath.Base(absPath), ref, size, nil
+}
+
+// snapshottable reports whether a snapshot keeps path, described by info, and
+// nothing else of its filesystem: a btrfs subvolume, or a directory or large
+// file on a ZFS dataset that allows snapshots (see ZFSSnapshotProperty). A
+// snapshot of the subvolume holding a mere directory would keep all of it,
+// and every change made to it later, until the item is purged.
+func snapshottable(path string, info os.FileInfo) bool {
+	switch snapshotKind(path) {
+	case SnapshotBtrfs:
+		return isSubvolume(info)
+	case SnapshotZFS:
+		if !info.IsDir() && !(info.Mode().IsRegular() && info.Size() >= snapshotMinSize) {
+			return false
+		}
+		_, _, enabled, err := zfsDataset(resolvePath(path))
+		return err == nil && enabled
+	}
+	return false
+}
+
+// SnapshotKindOf returns the kind of snapshot path, described by info, is kept
+// in when snapshots are enabled and it cannot be renamed into the trash
+// directory, or "" when it is copied
+func SnapshotKindOf(path string, info os.FileInfo) string {
+	if !snapshottable(path, info) {
+		return ""
+	}
+	return snapshotKind(path)
+}
+
+// treeOnDevice returns the size of the tree at path, described by info, and
+// whether all of it is on the same device, i.e. in the same btrfs subvolume
+// or ZFS dataset
+func treeOnDevice(path string, info os.FileInfo) (uint64, bool) {
+	device, _, _, ok := FileID(info)
+	if !ok {
+		return 0, false
+	}
+
+	var total uint64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if dev, _, _, _ := FileID(info); dev != device {
+				return errOtherDevice
+			}
+		}
+		if info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0 {
+			total += uint64(info.Size())
+		}
+		return nil
+	})
+	return total, err == nil
+}
+
+// errOtherDevice stops treeOnDevice at a directory on another device
+var errOtherDevice = errors.New("crosses a device boundary")
+
+// btrfsRootInode is the inode of the root directory of every btrfs subvolume
+const btrfsRootInode = 256
+
+// isSubvolume reports whether info describes the root directory of a btrfs
+// subvolume
+func isSubvolume(info os.FileInfo) bool {
+	_, inode, _, ok := FileID(info)
+	return ok && info.IsDir() && inode == btrfsRootInode
+}
+
+// btrfsSnapshot snapshots the btrfs subvolume path into the SnapshotDirName
+// directory next to it. The snapshot is writable, so that its owner can
+// delete it without the privilege deleting subvolumes needs.
+func btrfsSnapshot(path, label string) (*SnapshotRef, error) {
+	dir := filepath.Join(filepath.Dir(path), SnapshotDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	name := filepath.Join(dir, label)
+	if _, err := runSnapshotTool("btrfs", "subvolume", "snapshot", path, name); err != nil {
+		os.Remove(dir) // unless it holds other snapshots
+		return nil, err
+	}
+	return &SnapshotRef{Kind: SnapshotBtrfs, Name: name, Path: name}, nil
+}
+
+// zfsDataset returns the ZFS dataset holding path, its mount point and
+// whether it allows snapshots (see ZFSSnapshotProperty)
+func zfsDataset(path string) (dataset, mountpoint string, enabled bool, err error) {
+	out, err := runSnapshotTool("zfs", "list", "-H", "-o", "name,mountpoint,"+ZFSSnapshotProperty, path)
+	if err != nil {
+		return "", "", false, err
+	}
+	fields := strings.Split(strings.TrimSpace(string(out)), "\t")
+	if len(fields) != 3 || !filepath.IsAbs(fields[1]) || path != fields[1] && !IsWithin(path, fields[1]) {
+		return "", "", false, fmt.Errorf("cannot find the ZFS dataset of %s", path)
+	}
+	return fields[0], fields[1], fields[2] == "on", nil
+}
+
+// zfsSnapshot snapshots the ZFS dataset holding path; the snapshot is
+// reached through the .zfs directory at the dataset's mount point
+func zfsSnapshot(path, label string) (*SnapshotRef, error) {
+	dataset, mountpoint, enabled, err := zfsDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, fmt.Errorf("%s is not set to on for %s", ZFSSnapshotProperty, dataset)
+	}
+	rel, _ := filepath.Rel(mountpoint, path)
+
+	snapshot := zfsSnapshotPrefix + label
+	name := dataset + "@" + snapshot
+	if _, err := runSnapshotTool("zfs", "snapshot", name); err != nil {
+		return nil, err
+	}
+	return &SnapshotRef{Kind: SnapshotZFS, Name: name, Path: filepath.Join(mountpoint, ".zfs", "snapshot", snapshot, rel)}, nil
+}
+
+// checkSnapshot makes sure the snapshot ref names exists where it says
+// before its payload is moved out of it: a btrfs subvolume, or a snapshot of
+// a ZFS dataset mounted where its path starts
+func checkSnapshot(ref *SnapshotRef) error {
+	switch ref.Kind {
+	case SnapshotBtrfs:
+		info, err := os.Lstat(ref.Name)
+		if err != nil {
+			return err
+		}
+		if !isSubvolume(info) {
+			return fmt.Errorf("%s is not a btrfs snapshot", ref.Name)
+		}
+	case SnapshotZFS:
+		dataset, name, _ := strings.Cut(ref.Name, "@")
+		out, err := runSnapshotTool("zfs", "list", "-H", "-o", "mountpoint", dataset)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(strings.TrimSpace(string(out)), ".zfs", "snapshot", name)
+		if ref.Path != dir && !IsWithin(ref.Path, dir) {
+			return fmt.Errorf("%s is not in the snapshot %s", ref.Path, ref.Name)
+		}
+	}
+	return nil
+}
+
+// destroySnapshot deletes the snapshot ref names; one that is gone already,
+// e.g. a btrfs snapshot renamed back by restore, is not an error
+func destroySnapshot(ref *SnapshotRef) error {
+	if err := ref.Validate(); err != nil {
+		return fmt.Errorf("refusing to delete %s: %w", ref.Name, err)
+	}
+
+	switch ref.Kind {
+	case SnapshotBtrfs:
+		defer os.Remove(filepath.Dir(ref.Name)) // unless it holds other snapshots
+		info, err := os.Lstat(ref.Name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Whatever is there now, it is only deleted if it is a subvolume
+		if !isSubvolume(info) {
+			return fmt.Errorf("refusing to delete %s: not a btrfs subvolume", ref.Name)
+		}
+		if _, err := runSnapshotTool("btrfs", "subvolume", "delete", ref.Name); err != nil {
+			// Without the privilege, empty the snapshot and remove it like a
+			// directory, which its owner may do
+			if removeErr := os.RemoveAll(ref.Name); removeErr != nil {
+				return err
+			}
+		}
+	case SnapshotZFS:
+		if _, err := runSnapshotTool("zfs", "destroy", ref.Name); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return err
+			}
+			if _, listErr := runSnapshotTool("zfs", "list", "-H", "-t", "snapshot", ref.Name); listErr != nil {
+				return nil // gone already
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// dropSnapshot deletes the snapshot keeping the payload of item of the
+// session trashDir, if it has one, and the file pointing at it
+func dropSnapshot(trashDir string, item RestoreItem) error {
+	if item.Snapshot == nil {
+		return nil
+	}
+	if err := destroySnapshot(item.Snapshot); err != nil {
+		return err
+	}
+	err := storeFS.Remove(filepath.Join(trashDir, item.Entry(), SnapshotRefName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// moveSnapshotRef moves the file pointing at the snapshot of item, of the
+// session fromDir, into a new item directory of the session trashDir and
+// returns the item's storage path there
+func moveSnapshotRef(fromDir string, item RestoreItem, trashDir string) (string, error) {
+	id, err := newItemDir(trashDir)
+	if err != nil {
+		return "", err
+	}
+	if err := writeSnapshotRef(filepath.Join(trashDir, id), item.Snapshot); err != nil {
+		storeFS.RemoveAll(filepath.Join(trashDir, id))
+		return "", err
+	}
+	os.Remove(filepath.Join(fromDir, item.Entry(), SnapshotRefName))
+	return id + "/" + filepath.Base(item.Storage()), nil
+}
+
+// writeSnapshotRef writes the file pointing at the snapshot ref into an item directory
+func writeSnapshotRef(itemDir string, ref *SnapshotRef) error {
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	_, fileMode := storeModes()
+	return WriteFile(storeFS, filepath.Join(itemDir, SnapshotRefName), append(data, '\n'), fileMode)
+}
+
+// readSnapshotRef reads a file written by writeSnapshotRef; it fails for
+// anything else
+func readSnapshotRef(path string) (*SnapshotRef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ref SnapshotRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return nil, err
+	}
+	if err := ref.Validate(); err != nil {
+		return nil, fmt.Errorf("%s does not point at a snapshot taken by trash: %w", path, err)
+	}
+	return &ref, nil
+}
+
+// destroyReferenced deletes the snapshot the file at path points at, when it
+// is one written by writeSnapshotRef; a payload of the same name is left
+// alone
+func destroyReferenced(path string) error {
+	ref, err := readSnapshotRef(path)
+	if err != nil {
+		return nil
+	}
+	return destroySnapshot(ref)
+}
+
+// runSnapshotTool runs btrfs or zfs, returning its output and its error
+// message on failure
+func runSnapshotTool(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s: %s", name, message)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
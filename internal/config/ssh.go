@@ -0,0 +1,409 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSHConfig describes the remote directory an SSHFS keeps the trash store in
+type SSHConfig struct {
+	Host string // [user@]host, as given to ssh
+	Port string // empty for ssh's default
+	Dir  string // absolute, or relative to the remote home directory
+}
+
+// Exit codes of the remote scripts for the errors the FS interface names
+const (
+	sshExitNotExist = 3
+	sshExitExist    = 4
+)
+
+// sshStat prints "<mode in hex> <size> <mtime> <name>" for its arguments with
+// GNU stat or, on BSD and macOS, stat -f
+const sshStat = `st() { stat -c '%f %s %Y %n' -- "$@" 2>/dev/null || stat -f '%Xp %z %m %N' -- "$@"; }; `
+
+// SSHFS keeps the trash store in a directory on another host, driving the
+// system's ssh client with POSIX shell commands so that keys, agents and
+// ~/.ssh/config apply as usual. Paths below root, the local trash directory,
+// map to paths below the remote directory. Outside Windows one master
+// connection is shared by all commands for a minute, so each operation does
+// not pay for a new login; its control socket is kept in a directory only
+// the current user can write to (see sshControlDir). Names containing
+// newlines are not supported.
+type SSHFS struct {
+	root       string
+	config     SSHConfig
+	controlDir string
+}
+
+// NewSSHFS returns an SSHFS serving the paths below root from the remote directory in cfg
+func NewSSHFS(root string, cfg SSHConfig) *SSHFS {
+	cfg.Dir = strings.TrimSuffix(cfg.Dir, "/")
+	if cfg.Dir == "" {
+		cfg.Dir = "."
+	}
+	return &SSHFS{root: filepath.Clean(root), config: cfg, controlDir: sshControlDir()}
+}
+
+// sshControlDir returns a directory for the control sockets of ssh master
+// connections that no other user can create entries in: $XDG_RUNTIME_DIR, or
+// ~/.ssh. In a shared directory such as /tmp another user could create the
+// socket first, and trash's commands would go through their connection.
+// Without such a directory it is "", and connections are not shared.
+func sshControlDir() string {
+	var dirs []string
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dir := filepath.Join(home, ".ssh")
+		os.Mkdir(dir, 0700)
+		dirs = append(dirs, dir)
+	}
+
+	for _, dir := range dirs {
+		info, err := os.Lstat(dir)
+		if err == nil && info.IsDir() && info.Mode().Perm()&0022 == 0 && !ownedByOther(info) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// remotePath returns the remote path of name
+func (s *SSHFS) remotePath(name string) (string, error) {
+	rel, err := filepath.Rel(s.root, filepath.Clean(name))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the trash store %s", name, s.root)
+	}
+	return path.Join(s.config.Dir, filepath.ToSlash(rel)), nil
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// command returns the ssh command running script with sh on the remote host.
+// The script gets the remote paths of names as $1, $2 and so on.
+func (s *SSHFS) command(script string, names ...string) (*exec.Cmd, error) {
+	remote := []string{"sh", "-c", shellQuote(script), "sh"}
+	for _, name := range names {
+		remotePath, err := s.remotePath(name)
+		if err != nil {
+			return nil, err
+		}
+		remote = append(remote, shellQuote(remotePath))
+	}
+
+	var args []string
+	if s.config.Port != "" {
+		args = append(args, "-p", s.config.Port)
+	}
+	if runtime.GOOS != "windows" && s.controlDir != "" {
+		args = append(args, "-o", "ControlMaster=auto",
+			"-o", "ControlPath="+filepath.Join(s.controlDir, "trash-ssh-%C"),
+			"-o", "ControlPersist=60")
+	}
+	args = append(args, "--", s.config.Host, strings.Join(remote, " "))
+
+	return exec.Command("ssh", args...), nil
+}
+
+// run runs script for names and returns its output; the error of a failure
+// carries the remote error message
+func (s *SSHFS) run(op string, stdin io.Reader, script string, names ...string) ([]byte, error) {
+	cmd, err := s.command(script, names...)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: names[0], Err: err}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, &fs.PathError{Op: op, Path: names[0], Err: sshError(err, stderr.String())}
+	}
+	return stdout.Bytes(), nil
+}
+
+// sshError maps the exit codes of the remote scripts to fs errors
+func sshError(err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.ExitCode() {
+		case sshExitNotExist:
+			return fs.ErrNotExist
+		case sshExitExist:
+			return fs.ErrExist
+		}
+	}
+	if message := strings.TrimSpace(stderr); message != "" {
+		return errors.New(message)
+	}
+	return err
+}
+
+// existsCheck makes a script exit with sshExitNotExist unless $1 exists
+const existsCheck = `[ -e "$1" ] || [ -L "$1" ] || exit 3; `
+
+// sshFileInfo describes a remote file from a line printed by sshStat
+type sshFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi *sshFileInfo) Name() string       { return fi.name }
+func (fi *sshFileInfo) Size() int64        { return fi.size }
+func (fi *sshFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *sshFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *sshFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *sshFileInfo) Sys() interface{}   { return nil }
+
+// parseSSHStat parses a line printed by sshStat
+func parseSSHStat(line string) (*sshFileInfo, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected stat output %q", line)
+	}
+	rawMode, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected stat output %q", line)
+	}
+	size, _ := strconv.ParseInt(fields[1], 10, 64)
+	mtime, _ := strconv.ParseInt(fields[2], 10, 64)
+
+	return &sshFileInfo{
+		name:    path.Base(fields[3]),
+		size:    size,
+		mode:    unixFileMode(uint32(rawMode)),
+		modTime: time.Unix(mtime, 0),
+	}, nil
+}
+
+// unixFileMode converts a st_mode value to an fs.FileMode
+func unixFileMode(raw uint32) fs.FileMode {
+	mode := fs.FileMode(raw & 0777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	case 0010000:
+		mode |= fs.ModeNamedPipe
+	case 0140000:
+		mode |= fs.ModeSocket
+	case 0020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0060000:
+		mode |= fs.ModeDevice
+	}
+	if raw&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if raw&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if raw&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// Lstat describes a remote file without following a final symlink
+func (s *SSHFS) Lstat(name string) (fs.FileInfo, error) {
+	out, err := s.run("lstat", nil, sshStat+existsCheck+`st "$1"`, name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseSSHStat(strings.TrimSuffix(string(out), "\n"))
+	if err != nil {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: err}
+	}
+	info.name = filepath.Base(name)
+	return info, nil
+}
+
+// sshDirEntry is an entry of a remote directory listing
+type sshDirEntry struct {
+	info *sshFileInfo
+}
+
+func (e *sshDirEntry) Name() string               { return e.info.name }
+func (e *sshDirEntry) IsDir() bool                { return e.info.IsDir() }
+func (e *sshDirEntry) Type() fs.FileMode          { return e.info.mode.Type() }
+func (e *sshDirEntry) Info() (fs.FileInfo, error) { return e.info, nil }
+
+// ReadDir lists a remote directory with a single command
+func (s *SSHFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	script := sshStat + `[ -d "$1" ] || exit 3; cd "$1" || exit 1; ` +
+		`for f in * .[!.]* ..?*; do if [ -e "$f" ] || [ -L "$f" ]; then st "$f"; fi; done`
+	out, err := s.run("readdir", nil, script, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []fs.DirEntry
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		info, err := parseSSHStat(line)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+		}
+		entries = append(entries, &sshDirEntry{info: info})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// sshReader streams the output of a remote cat
+type sshReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+	name   string
+}
+
+func (r *sshReader) Close() error {
+	r.ReadCloser.Close()
+	if err := r.cmd.Wait(); err != nil {
+		return &fs.PathError{Op: "read", Path: r.name, Err: sshError(err, r.stderr.String())}
+	}
+	return nil
+}
+
+// Open streams the contents of a remote file
+func (s *SSHFS) Open(name string) (io.ReadCloser, error) {
+	// A missing file is reported before streaming starts
+	if _, err := s.run("open", nil, existsCheck, name); err != nil {
+		return nil, err
+	}
+
+	cmd, err := s.command(`exec cat -- "$1"`, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &sshReader{ReadCloser: stdout, cmd: cmd, stderr: stderr, name: name}, nil
+}
+
+// sshWriter streams a new remote file into a remote cat
+type sshWriter struct {
+	io.WriteCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+	name   string
+}
+
+func (w *sshWriter) Close() error {
+	w.WriteCloser.Close()
+	if err := w.cmd.Wait(); err != nil {
+		return &fs.PathError{Op: "write", Path: w.name, Err: sshError(err, w.stderr.String())}
+	}
+	return nil
+}
+
+// Create streams a new remote file with the permission bits perm
+func (s *SSHFS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	script := fmt.Sprintf(`cat > "$1" && chmod %o "$1"`, perm.Perm())
+	cmd, err := s.command(script, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: err}
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: err}
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: err}
+	}
+	return &sshWriter{WriteCloser: stdin, cmd: cmd, stderr: stderr, name: name}, nil
+}
+
+// Mkdir creates a remote directory, failing when name exists
+func (s *SSHFS) Mkdir(name string, perm fs.FileMode) error {
+	script := fmt.Sprintf(`{ [ -e "$1" ] || [ -L "$1" ]; } && exit 4; mkdir -m %o -- "$1"`, perm.Perm())
+	_, err := s.run("mkdir", nil, script, name)
+	return err
+}
+
+// MkdirAll creates a remote directory and its missing parents
+func (s *SSHFS) MkdirAll(name string, perm fs.FileMode) error {
+	_, err := s.run("mkdir", nil, fmt.Sprintf(`mkdir -p -m %o -- "$1"`, perm.Perm()), name)
+	return err
+}
+
+// Rename moves a remote file or directory
+func (s *SSHFS) Rename(oldname, newname string) error {
+	_, err := s.run("rename", nil, existsCheck+`mv -- "$1" "$2"`, oldname, newname)
+	return err
+}
+
+// Remove deletes a remote file or empty directory
+func (s *SSHFS) Remove(name string) error {
+	script := existsCheck + `if [ -d "$1" ] && [ ! -L "$1" ]; then rmdir -- "$1"; else rm -f -- "$1"; fi`
+	_, err := s.run("remove", nil, script, name)
+	return err
+}
+
+// RemoveAll deletes a remote file or directory with everything inside it
+func (s *SSHFS) RemoveAll(name string) error {
+	_, err := s.run("removeall", nil, `rm -rf -- "$1"`, name)
+	return err
+}
+
+// Symlink creates a remote symlink to target
+func (s *SSHFS) Symlink(target, name string) error {
+	_, err := s.run("symlink", nil, `ln -s -- `+shellQuote(target)+` "$1"`, name)
+	return err
+}
+
+// Readlink returns the target of a remote symlink
+func (s *SSHFS) Readlink(name string) (string, error) {
+	out, err := s.run("readlink", nil, existsCheck+`readlink -- "$1"`, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Chmod changes the permission bits of a remote file
+func (s *SSHFS) Chmod(name string, mode fs.FileMode) error {
+	_, err := s.run("chmod", nil, fmt.Sprintf(`chmod %o -- "$1"`, mode.Perm()), name)
+	return err
+}
+
+// Chtimes sets the modification time of a remote file to the second; atime is
+// set to the same time
+func (s *SSHFS) Chtimes(name string, atime, mtime time.Time) error {
+	stamp := mtime.UTC().Format("200601021504.05")
+	_, err := s.run("chtimes", nil, `TZ=UTC0 touch -t `+stamp+` -- "$1"`, name)
+	return err
+}
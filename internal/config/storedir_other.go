@@ -0,0 +1,32 @@
+//go:build !windows
+
+package config
+
+import "os"
+
+// homeStoreDir returns the trash directory in the user's home directory,
+// $XDG_DATA_HOME/trash (~/.local/share/trash): trashed files are data, not
+// configuration. A trash directory left in ~/.config/trash by an older
+// version keeps being used until 'trash migrate --xdg' moves it.
+func homeStoreDir() (string, error) {
+	store, err := xdgStoreDir()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Lstat(store); os.IsNotExist(err) {
+		if legacy, err := LegacyStoreDir(); err == nil && legacy != store && isLegacyStore(legacy) {
+			return legacy, nil
+		}
+	}
+	return store, nil
+}
+
+// preserveMode applies the permission bits of a copied file to its copy.
+// Android shared storage has no permission bits, so failures there are ignored.
+func preserveMode(path string, mode os.FileMode) error {
+	if err := os.Chmod(path, mode); err != nil && !SharedStorage(path) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+//go:build windows
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// homeStoreDir returns the trash directory of the current user, %LOCALAPPDATA%\trash.
+// A trash directory left in %USERPROFILE%\.config\trash by an older version
+// keeps being used until 'trash migrate --xdg' moves it.
+func homeStoreDir() (string, error) {
+	store, err := xdgStoreDir()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Lstat(store); os.IsNotExist(err) {
+		if legacy, err := LegacyStoreDir(); err == nil && legacy != store && isLegacyStore(legacy) {
+			return legacy, nil
+		}
+	}
+	return store, nil
+}
+
+// homeSettingsDir returns the directory of config.toml, the trash directory
+// itself on Windows
+func homeSettingsDir() (string, error) {
+	return homeStoreDir()
+}
+
+// xdgStoreDir returns %LOCALAPPDATA%\trash, the trash directory of this version;
+// Windows has no XDG directories. The local (not roaming) profile keeps the
+// trash off roaming profile syncs.
+func xdgStoreDir() (string, error) {
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return filepath.Join(dir, "trash"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, "AppData", "Local", "trash"), nil
+}
+
+// LegacyStoreDir returns %USERPROFILE%\.config\trash, where older versions kept
+// the trash directory on Windows as everywhere else
+func LegacyStoreDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "trash"), nil
+}
+
+// isLegacyStore reports whether dir holds a trash directory of an older
+// version: anything besides a config.toml
+func isLegacyStore(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Name() != SettingsFileName {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateToXDG moves the trash directory of an older version from
+// %USERPROFILE%\.config\trash to %LOCALAPPDATA%\trash, settings included since
+// Windows keeps them in the trash directory. It returns where the trash
+// directory was and is now.
+func MigrateToXDG(dryRun bool) (from, to string, err error) {
+	if storeDir != "" {
+		return "", "", fmt.Errorf("a project's local trash directory stays where it is")
+	}
+	if from, err = LegacyStoreDir(); err != nil {
+		return "", "", err
+	}
+	if to, err = xdgStoreDir(); err != nil {
+		return "", "", err
+	}
+	if from == to || !isLegacyStore(from) {
+		return "", "", ErrNoLegacyStore
+	}
+
+	// An empty directory, e.g. created by a command run before migrating, may go
+	if entries, err := os.ReadDir(to); err == nil && len(entries) > 0 {
+		return "", "", fmt.Errorf("%s already holds a trash directory; move the sessions of %s into it by hand", to, from)
+	}
+	if dryRun {
+		return from, to, nil
+	}
+	if err := os.Remove(to); err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to replace %s: %w", to, err)
+	}
+
+	// Windows cannot rename a directory holding an open file, so the lock is
+	// only taken to wait for commands still using the trash directory; one
+	// starting after it is released makes the rename fail rather than be lost
+	unlock, err := lockFile(filepath.Join(from, LockFileName), "trash directory")
+	if err != nil {
+		return "", "", err
+	}
+	unlock()
+
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", filepath.Dir(to), err)
+	}
+	if err := os.Rename(from, to); errors.Is(err, windows.ERROR_NOT_SAME_DEVICE) {
+		if err := CopyDir(from, to); err != nil {
+			return "", "", fmt.Errorf("failed to copy %s to %s: %w", from, to, err)
+		}
+		if err := os.RemoveAll(from); err != nil {
+			return "", "", fmt.Errorf("copied to %s but failed to remove %s: %w", to, from, err)
+		}
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+	return from, to, nil
+}
+
+// preserveMode is a no-op on Windows, where a mode only carries the read-only
+// attribute; copying it would keep trashed copies from being purged
+func preserveMode(path string, mode os.FileMode) error {
+	return nil
+}
+
+// freedesktopTrashDirs returns nothing: Windows tools keep deleted files in
+// the Recycle Bin, which trash leaves alone
+func freedesktopTrashDirs() []string {
+	return nil
+}
+
+// shellStartupFiles returns nothing: the shells of Windows have no startup
+// files trash knows of
+func shellStartupFiles() []string {
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// termuxHome is the Termux home directory, used when HOME is not set
+const termuxHome = "/data/data/com.termux/files/home"
+
+// sharedStoragePrefixes are where Android mounts shared ("external") storage.
+// It is a separate filesystem without Unix permissions, so moves between it and
+// the Termux home are copies and chmod fails.
+var sharedStoragePrefixes = []string{"/storage", "/sdcard", "/mnt/sdcard"}
+
+// IsTermux reports whether this is running on Android, typically inside Termux
+func IsTermux() bool {
+	return runtime.GOOS == "android" ||
+		os.Getenv("TERMUX_VERSION") != "" ||
+		strings.Contains(os.Getenv("PREFIX"), "com.termux")
+}
+
+// SharedStorage reports whether path is on Android shared storage, following
+// symlinks such as ~/storage/shared set up by termux-setup-storage
+func SharedStorage(path string) bool {
+	if !IsTermux() {
+		return false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(absPath)); err == nil {
+		absPath = filepath.Join(dir, filepath.Base(absPath))
+	}
+
+	for _, prefix := range sharedStoragePrefixes {
+		if absPath == prefix || strings.HasPrefix(absPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
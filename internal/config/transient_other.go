@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package config
+
+import "syscall"
+
+// transientErrno reports whether errno may go away when retried; on these
+// systems only EIO is
+func transientErrno(errno syscall.Errno) bool {
+	return errno == syscall.EIO
+}
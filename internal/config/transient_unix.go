@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+
+package config
+
+import "syscall"
+
+// transientErrno reports whether errno may go away when retried: EIO from a
+// flaky device, and timeouts and dropped connections of network filesystems.
+// ESTALE is not: the file handle stays stale however often it is used.
+func transientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case syscall.EIO, syscall.EAGAIN, syscall.EINTR, syscall.EBUSY, syscall.ETIMEDOUT,
+		syscall.ECONNRESET, syscall.ECONNABORTED, syscall.ENETDOWN, syscall.ENETRESET,
+		syscall.ENETUNREACH, syscall.EHOSTDOWN, syscall.EHOSTUNREACH:
+		return true
+	}
+	return false
+}
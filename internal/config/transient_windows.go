@@ -0,0 +1,22 @@
+//go:build windows
+
+package config
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// transientErrno reports whether errno may go away when retried: device
+// errors of removable media and dropped connections of network shares
+func transientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case windows.ERROR_NOT_READY, windows.ERROR_CRC, windows.ERROR_LOCK_VIOLATION,
+		windows.ERROR_NETWORK_BUSY, windows.ERROR_UNEXP_NET_ERR, windows.ERROR_NETNAME_DELETED,
+		windows.ERROR_SEM_TIMEOUT, windows.ERROR_IO_DEVICE, windows.ERROR_NETWORK_UNREACHABLE,
+		windows.ERROR_CONNECTION_ABORTED, windows.WSAECONNRESET, windows.WSAETIMEDOUT:
+		return true
+	}
+	return false
+}
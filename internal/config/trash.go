@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TrashOptions controls how paths are trashed
+type TrashOptions struct {
+	// ExpiresAt is the RFC3339 expiry recorded for every item, empty for none
+	ExpiresAt string
+
+	// Retained makes ExpiresAt a retention override, see RestoreItem.Retained
+	Retained bool
+
+	// Window reuses the current hour's or day's session instead of creating one (see Trash)
+	Window SessionWindow
+
+	// AllowMounts trashes paths that are or contain mount points instead of refusing them
+	AllowMounts bool
+
+	// NoIgnore trashes paths protected by a .trashignore file instead of refusing them
+	NoIgnore bool
+
+	// SkipPreflight makes Trash start trashing without checking the paths
+	// with CheckTrash first; TrashInto itself never runs it
+	SkipPreflight bool
+
+	// Redo marks the operation as redoing an undone one, which keeps the rest
+	// of the redo stack (see PopRedo)
+	Redo bool
+
+	// Snapshot leaves btrfs subvolumes, and directories and large files on
+	// ZFS datasets that allow it, in a snapshot instead of copying them to the
+	// trash directory on another filesystem (see snapshottable)
+	Snapshot bool
+}
+
+// TrashResult reports the outcome of trashing a single path
+type TrashResult struct {
+	Path    string
+	Session string      // the session the path was trashed into
+	Item    RestoreItem // valid when Err is nil
+	Copied  bool        // the cross-device copy fallback was used instead of a rename
+	Bytes   uint64      // size of what was moved
+	Err     error
+}
+
+// TrashInto moves each path into the trash session directory trashDir and records
+// their restore metadata, keeping any items already recorded for the session.
+// progress, when not nil, is called after each path.
+// The returned error only reports a failure to save the metadata; per-path
+// failures are in the results.
+func TrashInto(trashDir string, paths []string, opts TrashOptions, progress func(TrashResult)) ([]TrashResult, error) {
+	metadata := &RestoreMetadata{
+		Items: []RestoreItem{},
+	}
+	owner := CurrentOwner()
+
+	var results []TrashResult
+	for _, path := range paths {
+		// Get absolute path for metadata
+		absPath, err := os.Getwd()
+		if err == nil {
+			absPath, _ = filepath.Abs(path)
+		} else {
+			absPath = path
+		}
+
+		// Detect the type and identity before the move, while the original is still in place
+		kind, mimeType := DetectFileType(absPath)
+		var device, inode, links uint64
+		if info, err := os.Lstat(absPath); err == nil {
+			device, inode, links, _ = FileID(info)
+			if info.IsDir() {
+				links = 0 // directories cannot be hard-linked; their count means something else
+			}
+		}
+
+		result := TrashResult{Path: path, Session: filepath.Base(trashDir)}
+		storagePath, err := "", checkOutsideStore(absPath, filepath.Dir(trashDir))
+		if err == nil && !opts.AllowMounts {
+			err = CheckMounts(absPath)
+		}
+		if err == nil && !opts.NoIgnore {
+			err = checkNotProtected(absPath)
+		}
+		var snapshot *SnapshotRef
+		if err == nil && opts.Snapshot {
+			storagePath, snapshot, result.Bytes, err = snapshotToTrash(absPath, trashDir)
+		}
+		if err == nil && snapshot == nil {
+			storagePath, result.Copied, err = moveToTrash(path, trashDir)
+			if err == nil {
+				result.Bytes, _ = treeSize(storeFS, filepath.Join(trashDir, storagePath))
+			}
+		}
+		if err != nil {
+			result.Err = err
+		} else {
+			size := result.Bytes
+			result.Item = RestoreItem{
+				Name:         filepath.Base(storagePath),
+				StoragePath:  storagePath,
+				OriginalPath: absPath,
+				TrashedAt:    time.Now().Format(time.RFC3339),
+				ExpiresAt:    opts.ExpiresAt,
+				Retained:     opts.Retained,
+				User:         owner.User,
+				UID:          owner.UID,
+				Hostname:     owner.Hostname,
+				Type:         kind,
+				MIMEType:     mimeType,
+				Size:         &size,
+				Device:       device,
+				Inode:        inode,
+				Links:        links,
+				Snapshot:     snapshot,
+			}
+			result.Item.ID = result.Item.ShortID()
+			metadata.Items = append(metadata.Items, result.Item)
+		}
+
+		results = append(results, result)
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	// A session that nothing made it into is removed again, unless it was
+	// reused and holds the metadata of earlier items
+	if len(metadata.Items) == 0 {
+		storeFS.Remove(trashDir)
+	}
+
+	// Save restore metadata, after the items of a reused session
+	if len(metadata.Items) > 0 {
+		if existing, err := LoadRestoreMetadata(trashDir); err == nil {
+			metadata.Items = append(existing.Items, metadata.Items...)
+			metadata.CreatedAt = existing.CreatedAt
+			metadata.Sequence = existing.Sequence
+		}
+		if err := SaveRestoreMetadata(trashDir, metadata); err != nil {
+			return results, err
+		}
+
+		session := filepath.Base(trashDir)
+		var entries []HistoryEntry
+		for _, result := range results {
+			if result.Err == nil {
+				entries = append(entries, historyEntry(OpTrash, session, result.Item))
+			}
+		}
+		recordHistory(entries...)
+		recordOperation(trashDir, results, opts.Redo)
+	}
+
+	return results, nil
+}
+
+// CheckTrashable reports why trashing path into the current trash directory
+// would be refused or fail: it overlaps the trash directory, is or contains a
+// mount point (unless allowMounts is set) or cannot be moved out of its
+// directory. It is nil when the move would be attempted.
+func CheckTrashable(path string, allowMounts bool) error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if err := checkOutsideStore(absPath, configDir); err != nil {
+		return err
+	}
+	if !allowMounts {
+		if err := CheckMounts(absPath); err != nil {
+			return err
+		}
+	}
+	return checkRemovable(absPath)
+}
+
+// checkOutsideStore refuses a path that is the trash directory, lies inside it
+// or contains it, any of which would move the trash into itself
+func checkOutsideStore(path, storeDir string) error {
+	resolved := resolvePath(path)
+	store := resolvePath(storeDir)
+
+	switch {
+	case resolved == store:
+		return withKind(ErrTrashStore, fmt.Errorf("refusing to trash %s: it is the trash directory", path))
+	case IsWithin(resolved, store):
+		return withKind(ErrTrashStore, fmt.Errorf("refusing to trash %s: it is inside the trash directory", path))
+	case IsWithin(store, resolved):
+		return withKind(ErrTrashStore, fmt.Errorf("refusing to trash %s: it contains the trash directory %s", path, storeDir))
+	}
+	return nil
+}
+
+// resolvePath returns path with symlinks in its parent directories resolved.
+// The last element is kept as is, so a symlink resolves to its own location.
+func resolvePath(path string) string {
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, filepath.Base(path))
+}
+
+// IsWithin reports whether path lies strictly below dir
+func IsWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UndoFileName holds the undo and redo stacks of trash operations, kept in the
+// trash directory
+const UndoFileName = "undo.json"
+
+// undoDepth is how many trash operations can be undone
+const undoDepth = 100
+
+// Operation is a trash operation on the undo or redo stack
+type Operation struct {
+	Time string `json:"time"` // RFC 3339
+
+	// Paths are the paths the operation trashed
+	Paths []string `json:"paths"`
+
+	// Session and Items locate what it trashed: the session and the storage
+	// paths of the items in it. An undone operation has neither.
+	Session string   `json:"session,omitempty"`
+	Items   []string `json:"items,omitempty"`
+}
+
+// undoStacks is the content of the undo file, newest operations last
+type undoStacks struct {
+	Undo []Operation `json:"undo"`
+	Redo []Operation `json:"redo"`
+}
+
+// ErrNothingToUndo is returned by Undo when no trash operation is left
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned by Redo when no undone operation is left
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// loadUndoStacks reads the undo file; a missing or unreadable file yields empty stacks
+func loadUndoStacks(configDir string) *undoStacks {
+	stacks := &undoStacks{}
+	if data, err := os.ReadFile(filepath.Join(configDir, UndoFileName)); err == nil {
+		json.Unmarshal(data, stacks)
+	}
+	return stacks
+}
+
+// save writes the stacks, dropping the oldest operations beyond undoDepth.
+// Write then rename so a concurrent reader never sees a partial file.
+func (s *undoStacks) save(configDir string) error {
+	if len(s.Undo) > undoDepth {
+		s.Undo = s.Undo[len(s.Undo)-undoDepth:]
+	}
+	if len(s.Redo) > undoDepth {
+		s.Redo = s.Redo[len(s.Redo)-undoDepth:]
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	undoPath := filepath.Join(configDir, UndoFileName)
+	tmpPath := undoPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, undoPath)
+}
+
+// recordOperation pushes what TrashInto moved into trashDir onto the undo
+// stack. Unless it is a redo, a new operation makes the undone ones impossible
+// to redo, like in an editor. Recording is best effort and never fails a trash
+// operation.
+func recordOperation(trashDir string, results []TrashResult, redo bool) {
+	op := Operation{Time: time.Now().Format(time.RFC3339), Session: filepath.Base(trashDir)}
+	for _, result := range results {
+		if result.Err == nil {
+			op.Paths = append(op.Paths, result.Item.OriginalPath)
+			op.Items = append(op.Items, result.Item.StoragePath)
+		}
+	}
+	if len(op.Items) == 0 {
+		return
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return
+	}
+	stacks := loadUndoStacks(configDir)
+	stacks.Undo = append(stacks.Undo, op)
+	if !redo {
+		stacks.Redo = nil
+	}
+	stacks.save(configDir)
+}
+
+// UndoResult reports what Undo restored
+type UndoResult struct {
+	Operation Operation
+	Restored  []string // the paths put back
+	Missing   []string // paths whose items are no longer in the trash
+	Failed    []error  // items that could not be restored
+}
+
+// Undo restores the items of the last trash operation to where they were
+// trashed from and moves the operation to the redo stack. Items restored or
+// purged since are skipped. When some items cannot be restored the operation
+// stays on the undo stack with only those, so that undo can be run again.
+func Undo(verify bool) (*UndoResult, error) {
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	stacks := loadUndoStacks(configDir)
+	if len(stacks.Undo) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	op := stacks.Undo[len(stacks.Undo)-1]
+	stacks.Undo = stacks.Undo[:len(stacks.Undo)-1]
+	result := &UndoResult{Operation: op}
+
+	trashDir := filepath.Join(configDir, op.Session)
+	if verify {
+		if err := VerifyRestoreMetadata(trashDir); err != nil {
+			return nil, err
+		}
+	}
+	metadata, _ := LoadRestoreMetadata(trashDir)
+
+	left := Operation{Time: op.Time, Session: op.Session}
+	for i, storagePath := range op.Items {
+		var item *RestoreItem
+		if metadata != nil {
+			for j := range metadata.Items {
+				if metadata.Items[j].StoragePath == storagePath {
+					item = &metadata.Items[j]
+				}
+			}
+		}
+		if item == nil {
+			result.Missing = append(result.Missing, op.Paths[i])
+			continue
+		}
+
+		if _, err := RestoreTrashedItem(trashDir, *item, item.OriginalPath, ConflictFail); err != nil {
+			result.Failed = append(result.Failed, err)
+			left.Paths = append(left.Paths, op.Paths[i])
+			left.Items = append(left.Items, storagePath)
+			continue
+		}
+		result.Restored = append(result.Restored, item.OriginalPath)
+	}
+
+	if len(left.Items) > 0 {
+		stacks.Undo = append(stacks.Undo, left)
+	}
+	if len(result.Restored) > 0 {
+		stacks.Redo = append(stacks.Redo, Operation{Time: time.Now().Format(time.RFC3339), Paths: result.Restored})
+	}
+	return result, stacks.save(configDir)
+}
+
+// PopRedo removes the last undone operation from the redo stack and returns
+// it. Trashing its paths again with TrashOptions.Redo set puts it back on the
+// undo stack while keeping the operations undone before it.
+func PopRedo() (*Operation, error) {
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	stacks := loadUndoStacks(configDir)
+	if len(stacks.Redo) == 0 {
+		return nil, ErrNothingToRedo
+	}
+	op := stacks.Redo[len(stacks.Redo)-1]
+	stacks.Redo = stacks.Redo[:len(stacks.Redo)-1]
+	return &op, stacks.save(configDir)
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// Usage summarizes the contents of the trash store
+type Usage struct {
+	Sessions      int
+	Items         int
+	TotalBytes    uint64
+	ExpiredItems  int
+	OldestSession string
+	NewestSession string
+	OldestItem    time.Time // when the oldest item was trashed, zero when empty
+}
+
+// GetUsage counts sessions, items and bytes in the trash store; items are
+// counted as expired relative to now
+func GetUsage(now time.Time) (*Usage, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ListTrashedItems()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &Usage{
+		Sessions: len(sessions),
+		Items:    len(items),
+	}
+
+	for _, entry := range items {
+		if entry.Item.IsExpired(now) {
+			usage.ExpiredItems++
+		}
+		if trashedAt, err := time.Parse(time.RFC3339, entry.Item.TrashedAt); err == nil {
+			if usage.OldestItem.IsZero() || trashedAt.Before(usage.OldestItem) {
+				usage.OldestItem = trashedAt
+			}
+		}
+	}
+
+	for _, session := range sessions {
+		usage.TotalBytes += sessionSize(filepath.Join(configDir, session))
+	}
+
+	if len(sessions) > 0 {
+		usage.OldestSession = sessions[0]
+		usage.NewestSession = sessions[len(sessions)-1]
+	}
+
+	return usage, nil
+}
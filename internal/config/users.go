@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os/user"
+	"path/filepath"
+)
+
+// UserAccount is a local user whose trash can be inspected, e.g. by root
+type UserAccount struct {
+	Name    string
+	UID     string
+	HomeDir string
+}
+
+// LookupUser returns the account with the given user name or uid
+func LookupUser(name string) (UserAccount, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		if u, err = user.LookupId(name); err != nil {
+			return UserAccount{}, fmt.Errorf("unknown user %s", name)
+		}
+	}
+	return UserAccount{Name: u.Username, UID: u.Uid, HomeDir: u.HomeDir}, nil
+}
+
+// UserRoots returns the trash directories of account: the store in their home
+// directory, where this or an older version keeps it, and their per-user trash
+// at the top of every mounted volume (e.g. /mnt/c/.trash-1000). Roots that do
+// not exist are left out. Their XDG variables are unknown, so the default
+// locations are assumed.
+func UserRoots(account UserAccount) []Root {
+	candidates := []Root{
+		{Name: account.Name, Dir: filepath.Join(account.HomeDir, ".local", "share", "trash")},
+		{Name: account.Name, Dir: filepath.Join(account.HomeDir, ".config", "trash")},
+	}
+
+	points, _ := mountPoints()
+	for _, point := range points {
+		candidates = append(candidates, Root{
+			Name: account.Name + ", " + point,
+			Dir:  filepath.Join(point, ".trash-"+account.UID),
+		})
+	}
+
+	return existingRoots(candidates)
+}
+
+// AllUserRoots returns the trash directories of every local user
+func AllUserRoots() ([]Root, error) {
+	accounts, err := listUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []Root
+	for _, account := range accounts {
+		roots = append(roots, UserRoots(account)...)
+	}
+	return existingRoots(roots), nil
+}
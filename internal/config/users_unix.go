@@ -0,0 +1,39 @@
+//go:build !windows
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// listUsers returns the accounts in /etc/passwd
+func listUsers() ([]UserAccount, error) {
+	file, err := os.Open("/etc/passwd")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer file.Close()
+
+	var accounts []UserAccount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// name:password:uid:gid:gecos:home:shell
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 || fields[5] == "" {
+			continue
+		}
+		accounts = append(accounts, UserAccount{Name: fields[0], UID: fields[2], HomeDir: fields[5]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return accounts, nil
+}
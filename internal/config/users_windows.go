@@ -0,0 +1,10 @@
+//go:build windows
+
+package config
+
+import "errors"
+
+// listUsers is not supported on Windows; use --user with a name instead
+func listUsers() ([]UserAccount, error) {
+	return nil, errors.New("listing every user is not supported on Windows")
+}
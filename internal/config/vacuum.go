@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// staleTempAge is how old a temporary file must be before vacuum removes it,
+// so that the files of a write still in progress are left alone
+const staleTempAge = time.Hour
+
+// VacuumReport describes what Vacuum did, or would do
+type VacuumReport struct {
+	Before uint64 // the size of the trash's own files: metadata, state and temporary files
+	After  uint64
+
+	Rewritten     int      // sessions whose metadata was rewritten in the current format
+	DroppedItems  []string // "session/name" of listed items whose payload is gone
+	EmptySessions []string // session directories holding nothing
+	TempFiles     []string // leftovers of interrupted writes
+}
+
+// Vacuum compacts the trash directory: it drops items from the metadata whose
+// payload is gone (see 'trash doctor') along with their empty item directories, rewrites metadata written by older
+// versions in the current format, removes empty session directories and
+// removes temporary files left by interrupted writes. Sessions without usable
+// metadata are left for adopt, those failing their signature check for the
+// user to inspect. Payloads are never touched. With dryRun nothing is changed.
+func Vacuum(dryRun bool) (*VacuumReport, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VacuumReport{Before: ownFilesSize(configDir, sessions)}
+	cutoff := time.Now().Add(-staleTempAge)
+
+	// Written then renamed over the state files of the trash directory
+	for _, name := range []string{UndoFileName, PurgeStatsFileName} {
+		tmpPath := filepath.Join(configDir, name+".tmp")
+		if info, err := os.Lstat(tmpPath); err == nil && info.ModTime().Before(cutoff) {
+			report.TempFiles = append(report.TempFiles, tmpPath)
+			if !dryRun {
+				os.Remove(tmpPath)
+			}
+		}
+	}
+	// The sequence counter is kept in the store with the sessions
+	tmpPath := filepath.Join(configDir, SequenceFileName+".tmp")
+	if info, err := storeFS.Lstat(tmpPath); err == nil && info.ModTime().Before(cutoff) {
+		report.TempFiles = append(report.TempFiles, tmpPath)
+		if !dryRun {
+			storeFS.Remove(tmpPath)
+		}
+	}
+
+	for _, session := range sessions {
+		trashDir := filepath.Join(configDir, session)
+
+		tmpPath := filepath.Join(trashDir, ArchiveFileName+".tmp")
+		if info, err := storeFS.Lstat(tmpPath); err == nil && info.ModTime().Before(cutoff) {
+			report.TempFiles = append(report.TempFiles, tmpPath)
+			if !dryRun {
+				storeFS.Remove(tmpPath)
+			}
+		}
+
+		data, err := ReadFile(storeFS, filepath.Join(trashDir, ".restore"))
+		if errors.Is(err, fs.ErrNotExist) {
+			if entries, err := storeFS.ReadDir(trashDir); err == nil && len(entries) == 0 {
+				report.EmptySessions = append(report.EmptySessions, session)
+				if !dryRun {
+					storeFS.Remove(trashDir)
+				}
+			}
+			continue
+		}
+		metadata, err := LoadRestoreMetadata(trashDir)
+		if err != nil || VerifyRestoreMetadata(trashDir) != nil {
+			continue
+		}
+
+		var kept []RestoreItem
+		for _, item := range metadata.Items {
+			_, err := storeFS.Lstat(item.PayloadPath(trashDir))
+			if errors.Is(err, fs.ErrNotExist) && !IsArchived(trashDir, item) {
+				report.DroppedItems = append(report.DroppedItems, session+"/"+item.Name)
+				if !dryRun && item.Snapshot != nil && dropSnapshot(trashDir, item) != nil {
+					continue // purging its item directory tries again
+				}
+				if !dryRun && item.Entry() != item.Storage() {
+					storeFS.Remove(filepath.Join(trashDir, item.Entry())) // the item directory, if empty
+				}
+				continue
+			}
+			kept = append(kept, item)
+		}
+		dropped := len(kept) < len(metadata.Items)
+		metadata.Items = kept
+
+		switch {
+		case len(kept) == 0:
+			report.EmptySessions = append(report.EmptySessions, session)
+			if !dryRun {
+				if err := storeFS.RemoveAll(trashDir); err != nil {
+					return report, fmt.Errorf("failed to remove empty session %s: %w", session, err)
+				}
+			}
+		case dropped || outdatedMetadata(data, metadata):
+			report.Rewritten++
+			if !dryRun {
+				if err := SaveRestoreMetadata(trashDir, metadata); err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+
+	report.After = report.Before
+	if !dryRun {
+		remaining, _ := ListTrashSessions()
+		report.After = ownFilesSize(configDir, remaining)
+	}
+	return report, nil
+}
+
+// outdatedMetadata reports whether the .restore file data would be written
+// differently now: by an older version, or with fields no longer known
+func outdatedMetadata(data []byte, metadata *RestoreMetadata) bool {
+	if metadata.Version != MetadataVersion || metadata.CreatedAt == "" {
+		return true
+	}
+	current, err := json.MarshalIndent(metadata, "", "  ")
+	return err == nil && !bytes.Equal(current, data)
+}
+
+// ownFilesSize returns the size of what the trash keeps besides payloads: the
+// metadata of sessions and the state files of the trash directory
+func ownFilesSize(configDir string, sessions []string) uint64 {
+	var total uint64
+	for _, session := range sessions {
+		for _, name := range []string{".restore", SignatureFileName, ArchiveFileName + ".tmp"} {
+			if info, err := storeFS.Lstat(filepath.Join(configDir, session, name)); err == nil {
+				total += uint64(info.Size())
+			}
+		}
+	}
+
+	entries, _ := os.ReadDir(configDir)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			total += uint64(info.Size())
+		}
+	}
+	return total
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Outcomes of verifying a payload
+const (
+	VerifyOK       = "ok"       // it matches its checksum
+	VerifyRecorded = "recorded" // it had no checksum yet; the one taken now is stored
+	VerifyCorrupt  = "corrupt"  // its content changed since its checksum was taken
+	VerifyMissing  = "missing"  // the payload is gone
+	VerifyFailed   = "error"    // it could not be read
+)
+
+// VerifyResult is the outcome of verifying the payload of one item
+type VerifyResult struct {
+	Item   TrashedItem
+	Status string
+	Err    error // why it is corrupt or could not be read
+}
+
+// VerifyPayloads checks the payloads of the trash directory against their
+// stored checksums, those never verified first, then the longest unverified,
+// so that runs checking up to limit items cycle through the whole trash over
+// time. A limit of 0 checks every item. Payloads without a checksum get one
+// recorded, against which later runs check them. Payloads of compressed
+// sessions are checked by reading their session's archive through, which
+// detects corruption with its own checksum. When each item was checked is
+// stored in its session's metadata; the error of storing it is returned with
+// the results.
+func VerifyPayloads(limit int) ([]VerifyResult, error) {
+	items, err := ListTrashedItems()
+	if err != nil {
+		return nil, err
+	}
+
+	// RFC 3339 times in UTC sort as strings; never verified sorts first
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Item.VerifiedAt < items[j].Item.VerifiedAt })
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	archives := make(map[string]error)                        // session, then the error of reading its archive
+	updates := make(map[string]map[string]func(*RestoreItem)) // session, then item entry
+	results := make([]VerifyResult, 0, len(items))
+	for _, entry := range items {
+		result := verifyItem(entry, archives)
+		results = append(results, result)
+		if result.Status == VerifyMissing {
+			continue
+		}
+
+		recorded := ""
+		if result.Status == VerifyRecorded {
+			recorded = result.Item.Item.Checksum
+		}
+		if updates[entry.Session] == nil {
+			updates[entry.Session] = make(map[string]func(*RestoreItem))
+		}
+		updates[entry.Session][entry.Item.Entry()] = func(item *RestoreItem) {
+			item.VerifiedAt = now
+			if recorded != "" {
+				item.Checksum = recorded
+			}
+		}
+	}
+
+	return results, updateItems(updates)
+}
+
+// verifyItem checks the payload of one item; archives caches the outcome of
+// reading the archives of compressed sessions
+func verifyItem(entry TrashedItem, archives map[string]error) VerifyResult {
+	result := VerifyResult{Item: entry, Status: VerifyOK}
+	trashDir, err := entry.SessionDir()
+	if err != nil {
+		result.Status, result.Err = VerifyFailed, err
+		return result
+	}
+
+	if IsArchived(trashDir, entry.Item) {
+		err, ok := archives[entry.Session]
+		if !ok {
+			err = checkArchive(trashDir)
+			archives[entry.Session] = err
+		}
+		if err != nil {
+			result.Status, result.Err = VerifyCorrupt, err
+		}
+		return result
+	}
+
+	checksum, err := payloadChecksum(storeFS, entry.Item.PayloadPath(trashDir))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		result.Status = VerifyMissing
+	case err != nil:
+		result.Status, result.Err = VerifyFailed, err
+	case entry.Item.Checksum == "":
+		result.Status = VerifyRecorded
+		result.Item.Item.Checksum = checksum
+	case checksum != entry.Item.Checksum:
+		result.Status = VerifyCorrupt
+		result.Err = fmt.Errorf("content changed since its checksum was taken: checksum %s, expected %s", checksum, entry.Item.Checksum)
+	}
+	return result
+}
+
+// checkArchive reads the archive of a compressed session through, which fails
+// when its content does not match the checksum gzip keeps
+func checkArchive(trashDir string) error {
+	file, err := storeFS.Open(filepath.Join(trashDir, ArchiveFileName))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("archive of session %s is damaged: %w", filepath.Base(trashDir), err)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		_, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			_, err = io.Copy(io.Discard, tr)
+		}
+		if err != nil {
+			return fmt.Errorf("archive of session %s is damaged: %w", filepath.Base(trashDir), err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// LockFileName is the lock file serializing changes to the trash directory
+const LockFileName = ".lock"
+
+// SessionWindow groups trash operations into one session per period of time
+type SessionWindow string
+
+const (
+	// WindowNone creates a new session for every trash operation
+	WindowNone SessionWindow = ""
+	// WindowHour appends to the session created in the current hour
+	WindowHour SessionWindow = "hour"
+	// WindowDay appends to the session created today
+	WindowDay SessionWindow = "day"
+)
+
+// ParseSessionWindow parses "hour", "day", or "none"/"" for a session per operation
+func ParseSessionWindow(value string) (SessionWindow, error) {
+	switch value {
+	case "", "none":
+		return WindowNone, nil
+	case "hour":
+		return WindowHour, nil
+	case "day":
+		return WindowDay, nil
+	}
+	return WindowNone, fmt.Errorf("invalid session window %q: expected none, hour or day", value)
+}
+
+// start returns the beginning of the window containing t
+func (w SessionWindow) start(t time.Time) time.Time {
+	switch w {
+	case WindowHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case WindowDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+// Trash moves paths into a trash session and records their restore metadata,
+// returning the session directory. Unless opts.SkipPreflight is set, the paths
+// are checked with CheckTrash first and nothing is moved when that finds
+// problems (see PreflightError). With a session window, the newest session of
+// the current window is reused unless one of the names is already taken in it;
+// the trash directory stays locked for the whole operation so concurrent
+// invocations merge their metadata safely.
+func Trash(paths []string, opts TrashOptions, progress func(TrashResult)) (string, []TrashResult, error) {
+	if !opts.SkipPreflight {
+		if problems := CheckTrash(paths, opts.Snapshot); len(problems) > 0 {
+			return "", nil, &PreflightError{Problems: problems}
+		}
+	}
+
+	if opts.Window == WindowNone {
+		trashDir, err := CreateTrashTimestampDir()
+		if err != nil {
+			return "", nil, err
+		}
+		results, err := TrashInto(trashDir, paths, opts, progress)
+		return trashDir, results, err
+	}
+
+	unlock, err := LockStore()
+	if err != nil {
+		return "", nil, err
+	}
+	defer unlock()
+
+	trashDir, err := currentWindowSession(opts.Window, paths, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+	if trashDir == "" {
+		if trashDir, err = CreateTrashTimestampDir(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	results, err := TrashInto(trashDir, paths, opts, progress)
+	return trashDir, results, err
+}
+
+// currentWindowSession returns the newest session created in the window
+// containing now, or "" when there is none or it already holds an item with the
+// name of one of paths
+func currentWindowSession(window SessionWindow, paths []string, now time.Time) (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	sessions, err := ListTrashSessions()
+	if err != nil {
+		return "", err
+	}
+
+	for i := len(sessions) - 1; i >= 0; i-- {
+		created, err := SessionTime(sessions[i])
+		if err != nil {
+			continue
+		}
+		if !window.start(created).Equal(window.start(now)) {
+			return "", nil
+		}
+
+		trashDir := filepath.Join(configDir, sessions[i])
+		for _, path := range paths {
+			if _, err := storeFS.Lstat(filepath.Join(trashDir, filepath.Base(path))); err == nil {
+				return "", nil
+			}
+		}
+		return trashDir, nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wslMountRoot is where WSL mounts the Windows drives
+const wslMountRoot = "/mnt"
+
+// WindowsDrive returns the mount point of the Windows drive containing path
+// (e.g. /mnt/c) when running under WSL. Renaming between such a drive and the
+// Linux filesystem is impossible, so trashing from it always copies.
+func WindowsDrive(path string) (string, bool) {
+	if !IsWSL() {
+		return "", false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+
+	rest, ok := strings.CutPrefix(absPath, wslMountRoot+"/")
+	if !ok {
+		return "", false
+	}
+	drive, _, _ := strings.Cut(rest, "/")
+	if len(drive) != 1 || drive[0] < 'a' || drive[0] > 'z' {
+		return "", false
+	}
+
+	return wslMountRoot + "/" + drive, true
+}
+
+// DriveStoreDir returns the per-user trash directory at the top of a Windows drive
+func DriveStoreDir(drive string) string {
+	return filepath.Join(drive, ".trash-"+strconv.Itoa(os.Getuid()))
+}
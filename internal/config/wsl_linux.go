@@ -0,0 +1,20 @@
+//go:build linux
+
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// IsWSL reports whether this is running under the Windows Subsystem for Linux
+func IsWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(data)), "microsoft")
+}
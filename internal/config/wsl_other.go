@@ -0,0 +1,8 @@
+//go:build !linux
+
+package config
+
+// IsWSL reports whether this is running under the Windows Subsystem for Linux
+func IsWSL() bool {
+	return false
+}
@@ -0,0 +1,198 @@
+//go:build !windows
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// xdgStoreDir returns $XDG_DATA_HOME/trash, the trash directory of this version
+func xdgStoreDir() (string, error) {
+	dataHome, err := xdgDir("XDG_DATA_HOME", ".local", "share")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, "trash"), nil
+}
+
+// homeSettingsDir returns the directory of config.toml, $XDG_CONFIG_HOME/trash
+// (~/.config/trash)
+func homeSettingsDir() (string, error) {
+	configHome, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, "trash"), nil
+}
+
+// LegacyStoreDir returns ~/.config/trash, where versions before the XDG base
+// directories were honored kept sessions and settings together
+func LegacyStoreDir() (string, error) {
+	homeDir, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "trash"), nil
+}
+
+// xdgDir returns the directory in the environment variable name or, when it
+// is unset or relative as the XDG base directory specification requires it
+// not to be, the default below the home directory
+func xdgDir(name string, defaults ...string) (string, error) {
+	if dir := os.Getenv(name); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	homeDir, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{homeDir}, defaults...)...), nil
+}
+
+// userHomeDir returns the home directory of the current user
+func userHomeDir() (string, error) {
+	// Without HOME, Go falls back to /sdcard on Android, where the store does not belong
+	if IsTermux() && os.Getenv("HOME") == "" {
+		return termuxHome, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return homeDir, nil
+}
+
+// isLegacyStore reports whether dir holds a trash directory of an older
+// version: anything besides the config.toml this version keeps there
+func isLegacyStore(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Name() != SettingsFileName {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateToXDG moves the trash directory of an older version from
+// ~/.config/trash to $XDG_DATA_HOME/trash, and its config.toml to
+// $XDG_CONFIG_HOME/trash. It returns where the trash directory was and is now.
+func MigrateToXDG(dryRun bool) (from, to string, err error) {
+	if storeDir != "" {
+		return "", "", fmt.Errorf("a project's local trash directory stays where it is")
+	}
+	if from, err = LegacyStoreDir(); err != nil {
+		return "", "", err
+	}
+	if to, err = xdgStoreDir(); err != nil {
+		return "", "", err
+	}
+	if from == to || !isLegacyStore(from) {
+		return "", "", ErrNoLegacyStore
+	}
+
+	// An empty directory, e.g. created by a command run before migrating, may go
+	if entries, err := os.ReadDir(to); err == nil && len(entries) > 0 {
+		return "", "", fmt.Errorf("%s already holds a trash directory; move the sessions of %s into it by hand", to, from)
+	}
+	if dryRun {
+		return from, to, nil
+	}
+	if err := os.Remove(to); err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to replace %s: %w", to, err)
+	}
+
+	unlock, err := lockFile(filepath.Join(from, LockFileName), "trash directory")
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
+
+	// The XDG base directory specification asks for 0700 on directories it creates
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", filepath.Dir(to), err)
+	}
+	if err := os.Rename(from, to); errors.Is(err, syscall.EXDEV) {
+		if err := CopyDir(from, to); err != nil {
+			return "", "", fmt.Errorf("failed to copy %s to %s: %w", from, to, err)
+		}
+		if err := os.RemoveAll(from); err != nil {
+			return "", "", fmt.Errorf("copied to %s but failed to remove %s: %w", to, from, err)
+		}
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+
+	// Settings are configuration and go back to the config directory
+	settingsHome, err := homeSettingsDir()
+	if err != nil {
+		return from, to, err
+	}
+	if _, err := os.Stat(filepath.Join(to, SettingsFileName)); err == nil {
+		if err := os.MkdirAll(settingsHome, 0700); err != nil {
+			return from, to, fmt.Errorf("failed to create %s: %w", settingsHome, err)
+		}
+		if err := moveFile(filepath.Join(to, SettingsFileName), filepath.Join(settingsHome, SettingsFileName)); err != nil {
+			return from, to, fmt.Errorf("failed to move %s: %w", SettingsFileName, err)
+		}
+	}
+	return from, to, nil
+}
+
+// moveFile renames a small file, copying it when it crosses filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// freedesktopTrashDirs returns the trash directories of the freedesktop.org
+// trash specification that may hold items of the current user: the home trash
+// $XDG_DATA_HOME/Trash, then $topdir/.Trash/$uid and $topdir/.Trash-$uid on
+// every mounted volume
+func freedesktopTrashDirs() []string {
+	var dirs []string
+	if dataHome, err := xdgDir("XDG_DATA_HOME", ".local", "share"); err == nil {
+		dirs = append(dirs, filepath.Join(dataHome, "Trash"))
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	points, _ := mountPoints()
+	for _, point := range points {
+		dirs = append(dirs,
+			filepath.Join(point, ".Trash", uid),
+			filepath.Join(point, ".Trash-"+uid))
+	}
+	return dirs
+}
+
+// shellStartupFiles returns the startup files of the shells whose aliases may
+// send rm to another trash tool
+func shellStartupFiles() []string {
+	homeDir, err := userHomeDir()
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, name := range []string{".bashrc", ".bash_profile", ".bash_aliases", ".profile", ".zshrc", ".config/fish/config.fish"} {
+		files = append(files, filepath.Join(homeDir, name))
+	}
+	return files
+}
@@ -0,0 +1,85 @@
+// Package git queries a git working tree for untracked and ignored files
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Selection chooses which untracked files to list
+type Selection int
+
+const (
+	// Untracked lists untracked files that are not ignored (like git clean -d)
+	Untracked Selection = iota
+	// Ignored lists only files matched by ignore rules (like git clean -dX)
+	Ignored
+	// All lists untracked and ignored files (like git clean -dx)
+	All
+)
+
+// ErrNotRepository is returned when the directory is not inside a git working tree
+var ErrNotRepository = errors.New("not a git repository")
+
+// ListUntracked returns the absolute paths of untracked files below dir.
+// Directories that are entirely untracked are returned as one path.
+func ListUntracked(dir string, selection Selection) ([]string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found in PATH")
+	}
+
+	if err := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree").Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, dir)
+	}
+
+	args := []string{"-C", dir, "ls-files", "-z", "--others", "--directory"}
+	switch selection {
+	case Untracked:
+		args = append(args, "--exclude-standard")
+	case Ignored:
+		args = append(args, "--ignored", "--exclude-standard")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var paths []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(name, "/"))))
+	}
+
+	return paths, nil
+}
+
+// IsIgnored reports whether path is matched by the ignore rules of the git
+// working tree containing it. Paths outside a working tree are never ignored.
+func IsIgnored(path string) (bool, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return false, fmt.Errorf("git not found in PATH")
+	}
+
+	cmd := exec.Command("git", "-C", filepath.Dir(path), "check-ignore", "-q", "--", filepath.Base(path))
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// 1 means not ignored, 128 means not inside a working tree
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,374 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// German translations, keyed by the English format strings
+func init() {
+	register(language.German, map[string]string{
+		"    Expires:  %s\n":                                  "    Ablauf:   %s\n",
+		"    Original: %s\n":                                  "    Herkunft: %s\n",
+		"    Owner:    %s\n":                                  "    Besitzer: %s\n",
+		"    Session:  %s\n":                                  "    Sitzung:  %s\n",
+		"    Trashed:  %s\n":                                  "    Gelöscht: %s\n",
+		"    Type:     %s (%s)\n":                             "    Typ:      %s (%s)\n",
+		"    Type:     %s\n":                                  "    Typ:      %s\n",
+		"   Name:     %s\n":                                   "   Name:     %s\n",
+		"   Original: %s\n":                                   "   Herkunft: %s\n",
+		"   Trashed:  %s\n\n":                                 "   Gelöscht: %s\n\n",
+		"  Expires:  %s\n":                                    "  Ablauf:   %s\n",
+		"  Location: %s\n":                                    "  Ort:      %s\n",
+		"  Original: %s\n":                                    "  Herkunft: %s\n",
+		"  Owner:    %s\n":                                    "  Besitzer: %s\n",
+		"  Session:  %s\n":                                    "  Sitzung:  %s\n",
+		"  Size:     %s\n":                                    "  Größe:    %s\n",
+		"  Trashed:  %s\n":                                    "  Gelöscht: %s\n",
+		"  Type:     %s (%s)\n":                               "  Typ:      %s (%s)\n",
+		"  Type:     %s\n":                                    "  Typ:      %s\n",
+		"  • %s (from %s) [%s]\n":                             "  • %s (aus %s) [%s]\n",
+		"  • %s (from %s)\n":                                  "  • %s (aus %s)\n",
+		"%s [y/N]: ":                                          "%s [j/N]: ",
+		"Aborted\n":                                           "Abgebrochen\n",
+		"Bundle from %s created %s\n":                         "Bündel von %s, erstellt %s\n",
+		"Bundled %d session(s) into %s\n":                     "%d Sitzung(en) in %s gebündelt\n",
+		"Created trash directory: %s\n":                       "Papierkorbverzeichnis angelegt: %s\n",
+		"Deleted: %s\n":                                       "Gelöscht: %s\n",
+		"Error creating trash directory: %v\n":                "Fehler beim Anlegen des Papierkorbverzeichnisses: %v\n",
+		"Error deleting %s: %v\n":                             "Fehler beim Löschen von %s: %v\n",
+		"Error emptying trash: %v\n":                          "Fehler beim Leeren des Papierkorbs: %v\n",
+		"Error getting config directory: %v\n":                "Fehler beim Ermitteln des Konfigurationsverzeichnisses: %v\n",
+		"Error reading trash directory: %v\n":                 "Fehler beim Lesen des Papierkorbverzeichnisses: %v\n",
+		"Error restoring %s: %v\n":                            "Fehler beim Wiederherstellen von %s: %v\n",
+		"Error: %v\n":                                         "Fehler: %v\n",
+		"Error: invalid --expire value: %v\n":                 "Fehler: ungültiger Wert für --expire: %v\n",
+		"Error: invalid regular expression: %v\n":             "Fehler: ungültiger regulärer Ausdruck: %v\n",
+		"Error: item '%s' not found in trash\n":               "Fehler: Element '%s' nicht im Papierkorb gefunden\n",
+		"Error: path does not exist: %s\n":                    "Fehler: Pfad existiert nicht: %s\n",
+		"Error: specify either an item name or --manifest\n":  "Fehler: entweder einen Elementnamen oder --manifest angeben\n",
+		"Example: trash restore %s --timestamp %s\n":          "Beispiel: trash restore %s --timestamp %s\n",
+		"Expired items:   %d (run 'trash empty --expired')\n": "Abgelaufen:      %d ('trash empty --expired' ausführen)\n",
+		"Failed to delete %d item(s)\n":                       "%d Element(e) konnten nicht gelöscht werden\n",
+		"Failed to restore %d item(s)\n":                      "%d Element(e) konnten nicht wiederhergestellt werden\n",
+		"Failed to trash %d item(s)\n":                        "%d Element(e) konnten nicht in den Papierkorb verschoben werden\n",
+		"Found %d instances of '%s'. Restoring the most recent one.\n":          "%d Vorkommen von '%s' gefunden. Das neueste wird wiederhergestellt.\n",
+		"Found %d instances of '%s':\n\n":                                       "%d Vorkommen von '%s' gefunden:\n\n",
+		"Free space below %s, purged %d old session(s)\n":                       "Freier Speicher unter %s, %d alte Sitzung(en) gelöscht\n",
+		"Imported %d item(s) in %d session(s)\n":                                "%d Element(e) in %d Sitzung(en) importiert\n",
+		"Items:           %d\n":                                                 "Elemente:        %d\n",
+		"Listening on %s\n":                                                     "Lausche auf %s\n",
+		"Moved to trash: %s\n":                                                  "In den Papierkorb verschoben: %s\n",
+		"Newest session:  %s\n":                                                 "Neueste Sitzung: %s\n",
+		"No items matching '%s' found in trash\n":                               "Keine Elemente zu '%s' im Papierkorb gefunden\n",
+		"Nothing was restored\n":                                                "Es wurde nichts wiederhergestellt\n",
+		"Oldest session:  %s\n":                                                 "Älteste Sitzung: %s\n",
+		"Overwrote existing file/directory: %s\n":                               "Vorhandene Datei bzw. vorhandenes Verzeichnis überschrieben: %s\n",
+		"Permanently delete %d item(s) (%s)? This cannot be undone.":            "%d Element(e) (%s) endgültig löschen? Dies kann nicht rückgängig gemacht werden.",
+		"Permanently delete %d item(s) matching '%s'?":                          "%d Element(e) zu '%s' endgültig löschen?",
+		"Permanently delete everything in the trash?":                           "Den gesamten Papierkorb endgültig löschen?",
+		"Permanently deleted %d item(s)\n":                                      "%d Element(e) endgültig gelöscht\n",
+		"Purged %d expired item(s)":                                             "%d abgelaufene(s) Element(e) gelöscht",
+		"Purged %d expired item(s)\n":                                           "%d abgelaufene(s) Element(e) gelöscht\n",
+		"Purged %d old session(s) to keep free space":                           "%d alte Sitzung(en) gelöscht, um Speicherplatz freizuhalten",
+		"Purged: %s (from %s)\n":                                                "Gelöscht: %s (aus %s)\n",
+		"Purged: %s\n":                                                          "Gelöscht: %s\n",
+		"Reclaimed %s":                                                          "%s freigegeben",
+		"Remapped %s -> %s\n":                                                   "Umgeschrieben: %s -> %s\n",
+		"Removed empty trash directory: %s\n":                                   "Leeres Papierkorbverzeichnis entfernt: %s\n",
+		"Restored (copied): %s -> %s\n":                                         "Wiederhergestellt (kopiert): %s -> %s\n",
+		"Restored: %s -> %s\n":                                                  "Wiederhergestellt: %s -> %s\n",
+		"Restored: %s\n":                                                        "Wiederhergestellt: %s\n",
+		"Session %s imported as %s\n":                                           "Sitzung %s als %s importiert\n",
+		"Sessions:        %d\n":                                                 "Sitzungen:       %d\n",
+		"Successfully moved %d item(s) to trash\n":                              "%d Element(e) in den Papierkorb verschoben\n",
+		"Successfully restored %d item(s)\n":                                    "%d Element(e) wiederhergestellt\n",
+		"Successfully restored: %s\n":                                           "Erfolgreich wiederhergestellt: %s\n",
+		"Total size:      %s\n":                                                 "Gesamtgröße:     %s\n",
+		"Trash directory: %s\n":                                                 "Verzeichnis:     %s\n",
+		"Trash is empty, nothing to bundle\n":                                   "Der Papierkorb ist leer, es gibt nichts zu bündeln\n",
+		"Trash is empty\n":                                                      "Der Papierkorb ist leer\n",
+		"Trash purged":                                                          "Papierkorb bereinigt",
+		"Usage: trash [file/directory paths...] to move items to trash\n":       "Verwendung: trash [Datei-/Verzeichnispfade...] verschiebt Elemente in den Papierkorb\n",
+		"Use --all to see all matches or --timestamp to specify which one.\n\n": "Mit --all alle Treffer anzeigen oder mit --timestamp einen auswählen.\n\n",
+		"Use --force to overwrite\n":                                            "Mit --force überschreiben\n",
+		"Use --timestamp flag to specify which one to restore\n":                "Mit --timestamp angeben, welches wiederhergestellt werden soll\n",
+		"Warning: %v\n":                                                         "Warnung: %v\n",
+		"Warning: auto-prune failed: %v\n":                                      "Warnung: automatische Bereinigung fehlgeschlagen: %v\n",
+		"Warning: failed to purge expired items: %v\n":                          "Warnung: abgelaufene Elemente konnten nicht gelöscht werden: %v\n",
+		"Warning: failed to save restore metadata: %v\n":                        "Warnung: Wiederherstellungsdaten konnten nicht gespeichert werden: %v\n",
+		"Welcome to Trash! Use --help to see available commands.\n":             "Willkommen bei Trash! Mit --help werden die verfügbaren Befehle angezeigt.\n",
+		"\nFound %d matching item(s)\n":                                         "\n%d passende(s) Element(e) gefunden\n",
+		"\nTotal: %d item(s) in trash\n":                                        "\nInsgesamt: %d Element(e) im Papierkorb\n",
+		"\n[%s] (no metadata)\n":                                                "\n[%s] (keine Metadaten)\n",
+		"\n[%s] Error parsing metadata: %v\n":                                   "\n[%s] Fehler beim Auswerten der Metadaten: %v\n",
+		"\n[%s] Error reading metadata: %v\n":                                   "\n[%s] Fehler beim Lesen der Metadaten: %v\n",
+		"y":                                                                     "j",
+		"yes":                                                                   "ja",
+		"in the future":                                                         "in der Zukunft",
+		"just now":                                                              "gerade eben",
+		"1 year ago":                                                            "vor 1 Jahr",
+		"%d years ago":                                                          "vor %d Jahren",
+		"1 month ago":                                                           "vor 1 Monat",
+		"%d months ago":                                                         "vor %d Monaten",
+		"1 week ago":                                                            "vor 1 Woche",
+		"%d weeks ago":                                                          "vor %d Wochen",
+		"1 day ago":                                                             "vor 1 Tag",
+		"%d days ago":                                                           "vor %d Tagen",
+		"1 hour ago":                                                            "vor 1 Stunde",
+		"%d hours ago":                                                          "vor %d Stunden",
+		"1 minute ago":                                                          "vor 1 Minute",
+		"%d minutes ago":                                                        "vor %d Minuten",
+		"%s on %s":                                                              "%s auf %s",
+		"unknown":                                                               "unbekannt",
+		"%d item(s)":                                                            "%d Element(e)",
+		" (default)":                                                            " (Standard)",
+		"%d item(s), %s in %s (%s/s): %d renamed, %d copied\n":                  "%d Element(e), %s in %s (%s/s): %d umbenannt, %d kopiert\n",
+		"    Expires:  %s (retained until then)\n":                              "    Ablauf:   %s (bis dahin aufbewahrt)\n",
+		"  Expires:  %s (retained until then)\n":                                "  Ablauf:   %s (bis dahin aufbewahrt)\n",
+		"Kept %d retained item(s); use --include-retained to delete them too\n": "%d aufbewahrte(s) Element(e) behalten; mit --include-retained auch diese löschen\n",
+		"Nothing to undo\n":                      "Nichts rückgängig zu machen\n",
+		"Nothing to redo\n":                      "Nichts zu wiederholen\n",
+		"No longer in the trash: %s\n":           "Nicht mehr im Papierkorb: %s\n",
+		"  • %s  %s (from %s)\n":                 "  • %s  %s (aus %s)\n",
+		"Permanently delete %d item(s)?":         "%d Element(e) endgültig löschen?",
+		"No problems found\n":                    "Keine Probleme gefunden\n",
+		"%d problem(s) found\n":                  "%d Problem(e) gefunden\n",
+		"Fixed the permissions of %d entry(s)\n": "Berechtigungen von %d Eintrag/Einträgen korrigiert\n",
+		"Would move %s to %s\n":                  "Würde %s nach %s verschieben\n",
+		"Moved %s to %s\n":                       "%s nach %s verschoben\n",
+		"Warning: config.toml sets %s.%s, but '%s' has no such flag\n": "Warnung: config.toml setzt %s.%s, aber '%s' hat keine solche Option\n",
+		"Warning: invalid default for --%s in config.toml: %v\n":       "Warnung: ungültige Vorgabe für --%s in config.toml: %v\n",
+		"Error: alias loop: %s\n":                                      "Fehler: Alias-Schleife: %s\n",
+		"Error: invalid alias %s = %q in config.toml\n":                "Fehler: ungültiger Alias %s = %q in config.toml\n",
+		"  Does not exist\n":                                           "  Existiert nicht\n",
+		"  Store:      %s (%s)\n":                                      "  Speicher:   %s (%s)\n",
+		"  Method:     %s\n":                                           "  Methode:    %s\n",
+		"  Protection: %s\n":                                           "  Schutz:     %s\n",
+		"  Policy:     %s\n":                                           "  Regel:      %s\n",
+		"trash on Windows drive %s":                                    "Papierkorb auf dem Windows-Laufwerk %s",
+		"refused: %v":                                                  "abgelehnt: %v",
+		"protected by %s (%s); trashed only with --no-ignore":          "geschützt durch %s (%s); nur mit --no-ignore verschoben",
+		"ignored by git; skipped with --respect-gitignore":             "von git ignoriert; mit --respect-gitignore übersprungen",
+		"remote store %s":                                              "entfernter Speicher %s",
+		"local trash of the project at %s":                             "lokaler Papierkorb des Projekts in %s",
+		"home trash, still where an older version kept it; see 'trash migrate --xdg'": "Home-Papierkorb, noch am Ort einer älteren Version; siehe 'trash migrate --xdg'",
+		"home trash": "Home-Papierkorb",
+		"renamed into the trash, nothing is copied":         "in den Papierkorb umbenannt, nichts wird kopiert",
+		"copied to the remote store (%s), then deleted":     "in den entfernten Speicher kopiert (%s), dann gelöscht",
+		"copied from another filesystem (%s), then deleted": "von einem anderen Dateisystem kopiert (%s), dann gelöscht",
+		", at most %s/s": ", höchstens %s/s",
+		"; the link is trashed, not its target (-L trashes the target)":                                "; der Link wird verschoben, nicht sein Ziel (-L verschiebt das Ziel)",
+		"joins the session of the current hour (session_window)":                                       "kommt in die Sitzung der aktuellen Stunde (session_window)",
+		"joins today's session (session_window)":                                                       "kommt in die heutige Sitzung (session_window)",
+		"gets a session of its own":                                                                    "bekommt eine eigene Sitzung",
+		"the oldest sessions are purged when less than %s is free (min_free)":                          "die ältesten Sitzungen werden gelöscht, wenn weniger als %s frei ist (min_free)",
+		"its metadata is signed (sign_metadata)":                                                       "seine Metadaten werden signiert (sign_metadata)",
+		"sessions are created %04o, metadata %04o (dir_mode)":                                          "Sitzungen werden mit %04o angelegt, Metadaten mit %04o (dir_mode)",
+		"defaults from [root] in config.toml: %s":                                                      "Vorgaben aus [root] in config.toml: %s",
+		"Other trash:    %d item(s) in %s (run 'trash import' to move them here)\n":                    "Anderer Papierkorb: %d Element(e) in %s ('trash import' holt sie hierher)\n",
+		"Error: %s is not a trash directory of trash-cli, gio or a file manager\n":                     "Fehler: %s ist kein Papierkorb von trash-cli, gio oder einem Dateimanager\n",
+		"Would import: %s (from %s)\n":                                                                 "Würde importieren: %s (aus %s)\n",
+		"Imported: %s (from %s) [%s]\n":                                                                "Importiert: %s (aus %s) [%s]\n",
+		"Nothing to import\n":                                                                          "Nichts zu importieren\n",
+		"Would import %d item(s)\n":                                                                    "Würde %d Element(e) importieren\n",
+		"Imported %d item(s)\n":                                                                        "%d Element(e) importiert\n",
+		"Warning: checksums could not be stored: %v\n":                                                 "Warnung: Prüfsummen konnten nicht gespeichert werden: %v\n",
+		"No duplicates: every payload in the trash is different\n":                                     "Keine Duplikate: jeder Inhalt im Papierkorb ist verschieden\n",
+		"\n%d copies of %s, %s each (%s to save)\n":                                                    "\n%d Kopien von %s, je %s (%s einzusparen)\n",
+		"  • %s  %s [%s] (from %s)\n":                                                                  "  • %s  %s [%s] (aus %s)\n",
+		"\nTotal: %d group(s) of duplicates, %s could be saved\n":                                      "\nGesamt: %d Gruppe(n) von Duplikaten, %s könnten eingespart werden\n",
+		"Would link %s to %s\n":                                                                        "Würde %s mit %s verlinken\n",
+		"Linked %s to %s\n":                                                                            "%s mit %s verlinkt\n",
+		"No duplicate files to link\n":                                                                 "Keine doppelten Dateien zu verlinken\n",
+		"Would replace %d duplicate file(s) with hard links, reclaiming %s\n":                          "Würde %d doppelte Datei(en) durch harte Links ersetzen und %s freigeben\n",
+		"Replaced %d duplicate file(s) with hard links, reclaimed %s\n":                                "%d doppelte Datei(en) durch harte Links ersetzt, %s freigegeben\n",
+		"Error: %s is compressed into %s of its session; use 'trash open' or 'trash restore --keep'\n": "Fehler: %s ist in %s seiner Sitzung komprimiert; verwenden Sie 'trash open' oder 'trash restore --keep'\n",
+		"  Location: %s (compressed into %s)\n":                                                        "  Ort:      %s (komprimiert in %s)\n",
+		"Warning: failed to compress old sessions: %v\n":                                               "Warnung: Alte Sitzungen konnten nicht komprimiert werden: %v\n",
+		"Warning: failed to compress %s: %v\n":                                                         "Warnung: %s konnte nicht komprimiert werden: %v\n",
+		"Compressed: %s (%s to %s)\n":                                                                  "Komprimiert: %s (%s auf %s)\n",
+		"Compressed %d session(s) older than %s, %s to %s\n":                                           "%d Sitzung(en) älter als %s komprimiert, %s auf %s\n",
+		"Dropped from the metadata: %s\n":                                                              "Aus den Metadaten entfernt: %s\n",
+		"Empty session: %s\n":                                                                          "Leere Sitzung: %s\n",
+		"Temporary file: %s\n":                                                                         "Temporäre Datei: %s\n",
+		"Would drop %d missing item(s), rewrite %d session(s), remove %d empty session(s) and %d temporary file(s)\n": "Würde %d fehlende(s) Element(e) entfernen, %d Sitzung(en) neu schreiben, %d leere Sitzung(en) und %d temporäre Datei(en) löschen\n",
+		"Dropped %d missing item(s), rewrote %d session(s), removed %d empty session(s) and %d temporary file(s)\n":   "%d fehlende(s) Element(e) entfernt, %d Sitzung(en) neu geschrieben, %d leere Sitzung(en) und %d temporäre Datei(en) gelöscht\n",
+		"Metadata and state: %s before, %s after\n": "Metadaten und Zustand: %s vorher, %s nachher\n",
+		"Corrupt: %s [%s]: %v\n":                    "Beschädigt: %s [%s]: %v\n",
+		"Missing: %s [%s]\n":                        "Fehlt: %s [%s]\n",
+		"Unreadable: %s [%s]: %v\n":                 "Nicht lesbar: %s [%s]: %v\n",
+		"Recorded: %s [%s]\n":                       "Erfasst: %s [%s]\n",
+		"OK: %s [%s]\n":                             "OK: %s [%s]\n",
+		"Verified %d item(s): %d intact, %d checksum(s) recorded, %d problem(s)\n":                "%d Element(e) geprüft: %d intakt, %d Prüfsumme(n) erfasst, %d Problem(e)\n",
+		"Error: invalid --verify-every %q\n":                                                      "Fehler: ungültiges --verify-every %q\n",
+		"Warning: verify: %v\n":                                                                   "Warnung: Prüfung: %v\n",
+		"Warning: verify: %s [%s] is %s: %v\n":                                                    "Warnung: Prüfung: %s [%s] ist %s: %v\n",
+		"Warning: verify: %s [%s] is missing\n":                                                   "Warnung: Prüfung: %s [%s] fehlt\n",
+		"Error: --resume cannot be combined with item IDs, --expired, --regex or --interactive\n": "Fehler: --resume kann nicht mit Element-IDs, --expired, --regex oder --interactive kombiniert werden\n",
+		"Finished %d interrupted purge(s)\n":                                                      "%d unterbrochene Löschung(en) abgeschlossen\n",
+		"No interrupted purge to finish\n":                                                        "Keine unterbrochene Löschung abzuschließen\n",
+		"Error: --stage cannot be combined with --here\n":                                         "Fehler: --stage kann nicht mit --here kombiniert werden\n",
+		"Error: no trash session matches '%s'\n":                                                  "Fehler: keine Papierkorb-Sitzung passt zu '%s'\n",
+		"Error: mount point %s is not a directory\n":                                              "Fehler: Einhängepunkt %s ist kein Verzeichnis\n",
+		"Error: cannot mount the trash: %v\n":                                                     "Fehler: Papierkorb kann nicht eingehängt werden: %v\n",
+		"Trash mounted at %s; press Ctrl+C to unmount\n":                                          "Papierkorb unter %s eingehängt; Strg+C hängt ihn aus\n",
+		"Error: cannot unmount %s: %v\n":                                                          "Fehler: %s kann nicht ausgehängt werden: %v\n",
+		"Close the programs using it and try again\n":                                             "Schließen Sie die Programme, die ihn verwenden, und versuchen Sie es erneut\n",
+		"Unmounted %s\n": "%s ausgehängt\n",
+		"the trash directory is on a network filesystem (%s): it is locked with lockfiles and copies are synced before originals are deleted (network_fs)":       "das Papierkorbverzeichnis liegt auf einem Netzwerkdateisystem (%s): es wird mit Sperrdateien gesperrt und Kopien werden synchronisiert, bevor die Originale gelöscht werden (network_fs)",
+		"the trash directory is treated as on a network filesystem: it is locked with lockfiles and copies are synced before originals are deleted (network_fs)": "das Papierkorbverzeichnis wird wie auf einem Netzwerkdateisystem behandelt: es wird mit Sperrdateien gesperrt und Kopien werden synchronisiert, bevor die Originale gelöscht werden (network_fs)",
+		"Warning: copying %s failed: %v; retrying in %s\n":                              "Warnung: Kopieren von %s fehlgeschlagen: %v; neuer Versuch in %s\n",
+		"Warning: cannot snapshot %s, copying it instead: %v\n":                         "Warnung: Snapshot von %s nicht möglich, es wird stattdessen kopiert: %v\n",
+		"%d item(s), %s in %s (%s/s): %d renamed, %d copied, %d snapshotted\n":          "%d Element(e), %s in %s (%s/s): %d umbenannt, %d kopiert, %d in Snapshots\n",
+		"  Location: %s (in the %s snapshot %s)\n":                                      "  Ort: %s (im %s-Snapshot %s)\n",
+		"kept in a %s snapshot of its filesystem (%s), then deleted; nothing is copied": "in einem %s-Snapshot seines Dateisystems behalten (%s), dann gelöscht; nichts wird kopiert",
+		"Clients must send the token in %s\n":                                           "Clients müssen das Token aus %s senden\n",
+		"Error reading crontab, leaving it unchanged: %v\n":                             "Fehler beim Lesen der Crontab, sie bleibt unverändert: %v\n",
+		"%s is neither a trash session nor a path in the trash":                         "%s ist weder eine Papierkorb-Sitzung noch ein Pfad im Papierkorb",
+		"%s is not in the trash directory %s":                                           "%s liegt nicht im Papierkorb-Verzeichnis %s",
+		"%s is inside a trashed item; adopt the item %s instead":                        "%s liegt in einem gelöschten Element; übernehmen Sie stattdessen das Element %s",
+		"Error: specify sessions or paths to adopt, or --all\n":                         "Fehler: Sitzungen oder Pfade zum Übernehmen angeben, oder --all\n",
+		"Adopted: %s [%s]\n": "Übernommen: %s [%s]\n",
+		"Skipping %s: its metadata is unusable; fix or remove the .restore file first\n":     "%s wird übersprungen: seine Metadaten sind unbrauchbar; zuerst die .restore-Datei reparieren oder entfernen\n",
+		"Adopted %d item(s); their original location is unknown, restore them with --here\n": "%d Element(e) übernommen; ihr ursprünglicher Ort ist unbekannt, mit --here wiederherstellen\n",
+		"Nothing to adopt\n":                                                            "Nichts zu übernehmen\n",
+		"Error applying bundle: %v\n":                                                   "Fehler beim Anwenden des Bundles: %v\n",
+		"Error creating bundle: %v\n":                                                   "Fehler beim Erstellen des Bundles: %v\n",
+		"Error writing bundle: %v\n":                                                    "Fehler beim Schreiben des Bundles: %v\n",
+		"Error getting home directory: %v\n":                                            "Fehler beim Ermitteln des Home-Verzeichnisses: %v\n",
+		"Error opening bundle: %v\n":                                                    "Fehler beim Öffnen des Bundles: %v\n",
+		"Error: schedule must have 5 fields, got %q\n":                                  "Fehler: der Zeitplan muss 5 Felder haben, nicht %q\n",
+		"Error locating trash executable: %v\n":                                         "Fehler beim Ermitteln der trash-Programmdatei: %v\n",
+		"Error installing crontab: %v\n":                                                "Fehler beim Installieren der crontab: %v\n",
+		"Error: cannot dereference %s: %v\n":                                            "Fehler: %s kann nicht aufgelöst werden: %v\n",
+		"Dereferenced %s -> %s\n":                                                       "Aufgelöst: %s -> %s\n",
+		"Changed %s from %04o to %04o\n":                                                "%s von %04o auf %04o geändert\n",
+		"Would trash %d item(s): %d file(s), %d dir(s), %s\n":                           "Würde %d Element(e) löschen: %d Datei(en), %d Verzeichnis(se), %s\n",
+		"Everything is on the trash filesystem and would be moved without copying\n":    "Alles liegt auf dem Dateisystem des Papierkorbs und würde ohne Kopieren verschoben\n",
+		"Cross-device copy needed for %d item(s) (%s)\n":                                "Kopie über Dateisystemgrenzen nötig für %d Element(e) (%s)\n",
+		"Warning: only %s free on the trash filesystem\n":                               "Warnung: nur %s frei auf dem Dateisystem des Papierkorbs\n",
+		"Error: item IDs cannot be combined with --expired, --regex or --interactive\n": "Fehler: Element-IDs können nicht mit --expired, --regex oder --interactive kombiniert werden\n",
+		"Error: --interactive cannot be combined with --expired, --regex or --force\n":  "Fehler: --interactive kann nicht mit --expired, --regex oder --force kombiniert werden\n",
+		"Purge this session? [y]es, [n]o or [q]uit: ":                                   "Diese Sitzung löschen? [j]a, [n]ein oder [q] beenden: ",
+		"Skipped\n": "Übersprungen\n",
+		"Error: invalid --older-than value: %v\n":                          "Fehler: ungültiger Wert für --older-than: %v\n",
+		"Error: invalid --larger-than value: %v\n":                         "Fehler: ungültiger Wert für --larger-than: %v\n",
+		"Skipped (%s): %s\n":                                               "Übersprungen (%s): %s\n",
+		"modified %s":                                                      "geändert %s",
+		"Error: invalid --type %q: expected file or dir\n":                 "Fehler: ungültiger --type %q: file oder dir erwartet\n",
+		"No matches found\n":                                               "Keine Treffer gefunden\n",
+		"Error: --all-users requires root\n":                               "Fehler: --all-users erfordert root\n",
+		"Error: --user %s requires root\n":                                 "Fehler: --user %s erfordert root\n",
+		"Error writing output: %v\n":                                       "Fehler beim Schreiben der Ausgabe: %v\n",
+		"Error: --ignored and --all are mutually exclusive\n":              "Fehler: --ignored und --all schließen sich gegenseitig aus\n",
+		"Nothing to clean\n":                                               "Nichts aufzuräumen\n",
+		"%s  restored  %s\n":                                               "%s  zurückgeholt  %s\n",
+		"%s  purged    %s\n":                                               "%s  gelöscht      %s\n",
+		"    Original:   %s\n":                                             "    Herkunft:   %s\n",
+		"    Session:    %s/%s\n":                                          "    Sitzung:    %s/%s\n",
+		"    Invocation: %s (%s)\n":                                        "    Aufruf:     %s (%s)\n",
+		"Error: invalid --op value %q: expected trash, restore or purge\n": "Fehler: ungültiger Wert für --op %q: trash, restore oder purge erwartet\n",
+		"No history\n":                                                     "Kein Verlauf\n",
+		"%s  trashed   %s\n":                                               "%s  entsorgt      %s\n",
+		"Warning: failed to read %s: %v\n":                                 "Warnung: %s konnte nicht gelesen werden: %v\n",
+		"Skipping %s: protected by %s (%s)\n":                              "%s wird übersprungen: geschützt durch %s (%s)\n",
+		"Skipping %s: ignored by git\n":                                    "%s wird übersprungen: von git ignoriert\n",
+		"  Links:    %d when trashed (inode %s on device %s); other links may still exist\n": "  Links:    %d beim Löschen (Inode %s auf Gerät %s); weitere Links können noch existieren\n",
+		"Error: could not open file manager: %v\n":                                           "Fehler: Dateimanager konnte nicht geöffnet werden: %v\n",
+		"  ID:       %s\n": "  ID:       %s\n",
+		"Skipping %s: it is inside trash session %s; restore the item first\n": "%s wird übersprungen: es liegt in der Papierkorb-Sitzung %s; zuerst das Element wiederherstellen\n",
+		"%s is already in the trash (session %s, from %s)\n":                   "%s ist bereits im Papierkorb (Sitzung %s, aus %s)\n",
+		"Skipping %s: run interactively to purge or re-file it\n":              "%s wird übersprungen: interaktiv ausführen, um es zu löschen oder neu einzuordnen\n",
+		"Re-filed: %s\n": "Neu eingeordnet: %s\n",
+		"[p]urge it, [r]e-file it into this trash, or [s]kip? ":                   "[p] endgültig löschen, [r] in diesen Papierkorb einordnen oder [s] überspringen? ",
+		"[p]urge it or [s]kip? ":                                                  "[p] endgültig löschen oder [s] überspringen? ",
+		"  • %s (from %s) %s, retained until %s\n":                                "  • %s (aus %s) %s, aufbewahrt bis %s\n",
+		"  • %s (from %s) %s\n":                                                   "  • %s (aus %s) %s\n",
+		"  (no items recorded)\n":                                                 "  (keine Elemente verzeichnet)\n",
+		"  Total: %d item(s), %s\n":                                               "  Gesamt: %d Element(e), %s\n",
+		"Error: --restore and --empty cannot be used together\n":                  "Fehler: --restore und --empty können nicht zusammen verwendet werden\n",
+		"Nothing to restore\n":                                                    "Nichts wiederherzustellen\n",
+		"Permanently delete the %d item(s) of session %s?":                        "Die %d Element(e) der Sitzung %s endgültig löschen?",
+		"\n[%s] Invalid metadata: %v\n":                                           "\n[%s] Ungültige Metadaten: %v\n",
+		"Error: invalid --%s value: %v\n":                                         "Fehler: ungültiger Wert für --%s: %v\n",
+		"Error: --limit must not be negative\n":                                   "Fehler: --limit darf nicht negativ sein\n",
+		"    ID:       %s\n":                                                      "    ID:       %s\n",
+		"No orphans: everything in the trash is listed\n":                         "Keine verwaisten Elemente: alles im Papierkorb wird aufgelistet\n",
+		"[%s] whole session, %s: %s\n":                                            "[%s] ganze Sitzung, %s: %s\n",
+		"\nTotal: %d orphan(s), %s not shown by list\n":                           "\nGesamt: %d verwaiste(s) Element(e), %s von list nicht angezeigt\n",
+		"Skipping %s: %v\n":                                                       "%s wird übersprungen: %v\n",
+		"%s: would move %d item(s) into item directories and record %d size(s)\n": "%s: würde %d Element(e) in Elementverzeichnisse verschieben und %d Größe(n) erfassen\n",
+		"%s: %d item(s) moved into item directories, %d size(s) recorded\n":       "%s: %d Element(e) in Elementverzeichnisse verschoben, %d Größe(n) erfasst\n",
+		"Would move %d item(s) into item directories and record %d size(s)\n":     "Würde %d Element(e) in Elementverzeichnisse verschieben und %d Größe(n) erfassen\n",
+		"Moved %d item(s) into item directories and recorded %d size(s)\n":        "%d Element(e) in Elementverzeichnisse verschoben und %d Größe(n) erfasst\n",
+		"Previous metadata saved to %s\n":                                         "Bisherige Metadaten gespeichert in %s\n",
+		"The trash directory is up to date\n":                                     "Das Papierkorb-Verzeichnis ist auf dem neuesten Stand\n",
+		"Error: could not copy %s: %v\n":                                          "Fehler: %s konnte nicht kopiert werden: %v\n",
+		"Opening %s\n":                                                            "%s wird geöffnet\n",
+		"Error: could not open %s: %v\n":                                          "Fehler: %s konnte nicht geöffnet werden: %v\n",
+		"Passphrase for the signing key: ":                                        "Passphrase für den Signaturschlüssel: ",
+		"Error: cannot run plugin %s: %v\n":                                       "Fehler: Plugin %s kann nicht ausgeführt werden: %v\n",
+		"Error reading manifest: %v\n":                                            "Fehler beim Lesen des Manifests: %v\n",
+		"Error: %s: %v\n":                                                         "Fehler: %s: %v\n",
+		"Error: --interactive needs a terminal\n":                                 "Fehler: --interactive benötigt ein Terminal\n",
+		"Use --force to overwrite, or --on-conflict=rename to keep both\n":        "Mit --force überschreiben oder mit --on-conflict=rename beide behalten\n",
+		"Use --here to restore it into the current directory\n":                   "Mit --here in das aktuelle Verzeichnis wiederherstellen\n",
+		"Warning: skipping %s: %v\n":                                              "Warnung: %s wird übersprungen: %v\n",
+		"Nothing was restored; fix the problems above or use --no-preflight\n":    "Nichts wurde wiederhergestellt; die obigen Probleme beheben oder --no-preflight verwenden\n",
+		"Choose an item by number (empty to finish): ":                            "Element per Nummer wählen (leer zum Beenden): ",
+		"Invalid choice: %s\n":                                                    "Ungültige Auswahl: %s\n",
+		"Item %d was already handled\n":                                           "Element %d wurde bereits bearbeitet\n",
+		"%d. %s: [o]riginal location, [h]ere, [p]urge or [s]kip? ":                "%d. %s: [o] ursprünglicher Ort, [h] hier, [p] endgültig löschen oder [s] überspringen? ",
+		"Error getting current directory: %v\n":                                   "Fehler beim Ermitteln des aktuellen Verzeichnisses: %v\n",
+		"Permanently delete %s?":                                                  "%s endgültig löschen?",
+		"Skipped: %s\n":                                                           "Übersprungen: %s\n",
+		"%s is no longer retained\n":                                              "%s wird nicht mehr aufbewahrt\n",
+		"Keeping %s until %s\n":                                                   "%s wird bis %s aufbewahrt\n",
+		"Nothing to trash\n":                                                      "Nichts zu löschen\n",
+		"Would trash: %s\n":                                                       "Würde löschen: %s\n",
+		"Error: --print and --print0 cannot be used together\n":                   "Fehler: --print und --print0 können nicht zusammen verwendet werden\n",
+		"Use 'trash empty' to permanently delete what is in the trash\n":          "Mit 'trash empty' wird der Inhalt des Papierkorbs endgültig gelöscht\n",
+		"Unmount it first, or use --allow-mounts to trash it anyway\n":            "Zuerst aushängen oder mit --allow-mounts trotzdem löschen\n",
+		"Nothing was trashed; fix the problems above or use --no-preflight\n":     "Nichts wurde gelöscht; die obigen Probleme beheben oder --no-preflight verwenden\n",
+		"Error: invalid bandwidth limit: %v\n":                                    "Fehler: ungültige Bandbreitenbegrenzung: %v\n",
+		"Error: --expire and --keep-for cannot be used together\n":                "Fehler: --expire und --keep-for können nicht zusammen verwendet werden\n",
+		"Error: invalid --keep-for value: %v\n":                                   "Fehler: ungültiger Wert für --keep-for: %v\n",
+		"    Store:    %s\n":                                                      "    Speicher: %s\n",
+		"  • %s (from %s) [%s]%s\n":                                               "  • %s (aus %s) [%s]%s\n",
+		"Error: D-Bus: %v\n":                                                      "Fehler: D-Bus: %v\n",
+		"Retained:       %d item(s) kept past the retention policies\n":           "Aufbewahrt:     %d Element(e) über die Aufbewahrungsregeln hinaus behalten\n",
+		"Min free:       not set\n":                                               "Min. frei:      nicht gesetzt\n",
+		"Min free:       %s (invalid: %v)\n":                                      "Min. frei:      %s (ungültig: %v)\n",
+		"Min free:       %s (free space unknown)\n":                               "Min. frei:      %s (freier Speicher unbekannt)\n",
+		"Min free:       %s, only %s free: the next trash operation purges the oldest sessions\n": "Min. frei:      %s, nur %s frei: der nächste Löschvorgang entfernt die ältesten Sitzungen\n",
+		"Min free:       %s, %s free (%s to spare)\n":                                             "Min. frei:      %s, %s frei (%s Reserve)\n",
+		"Session window: %s\n":                              "Zeitfenster:    %s\n",
+		"Problems:       none\n":                            "Probleme:       keine\n",
+		"Problems:       %d\n":                              "Probleme:       %d\n",
+		"    Fix: %s\n":                                     "    Abhilfe: %s\n",
+		"Store:          %s (%s)\n":                         "Speicher:       %s (%s)\n",
+		"Contents:       %d item(s) in %d session(s), %s\n": "Inhalt:         %d Element(e) in %d Sitzung(en), %s\n",
+		"Oldest item:    %s\n":                              "Ältestes:       %s\n",
+		"Expired:        %d item(s) waiting to be purged (run 'trash empty --expired')\n":                                                                     "Abgelaufen:     %d Element(e) warten auf das Löschen ('trash empty --expired' ausführen)\n",
+		"Check the session's .restore file, or use --no-verify to restore anyway\n":                                                                           "Die .restore-Datei der Sitzung prüfen oder mit --no-verify trotzdem wiederherstellen\n",
+		"Move the files now in the way and run 'trash undo' again\n":                                                                                          "Die jetzt im Weg liegenden Dateien verschieben und 'trash undo' erneut ausführen\n",
+		"Warning: failed to save the undo stack: %v\n":                                                                                                        "Warnung: der Undo-Stapel konnte nicht gespeichert werden: %v\n",
+		"Warning: paths span several drives, using the default trash\n":                                                                                       "Warnung: die Pfade liegen auf mehreren Laufwerken, der Standard-Papierkorb wird verwendet\n",
+		"Warning: %s is a Windows drive; trashing from it copies everything into the Linux trash (set wsl_drive_trash = true to keep a trash on the drive)\n": "Warnung: %s ist ein Windows-Laufwerk; beim Löschen von dort wird alles in den Linux-Papierkorb kopiert (wsl_drive_trash = true setzen, um einen Papierkorb auf dem Laufwerk zu führen)\n",
+		"Note: %s is on shared storage and will be copied into the trash\n":                                                                                   "Hinweis: %s liegt auf gemeinsamem Speicher und wird in den Papierkorb kopiert\n",
+		"%d items match '%s':\n": "%d Elemente passen auf '%s':\n",
+		"Error: restoring more than one match needs --yes when stdin is not a terminal\n": "Fehler: mehr als einen Treffer wiederherzustellen erfordert --yes, wenn die Standardeingabe kein Terminal ist\n",
+		"Restore %d item(s)?":                                          "%d Element(e) wiederherstellen?",
+		"Created config directory: %s\n":                               "Verzeichnis angelegt: %s\n",
+		"Installed crontab entry:\n%s\n":                               "Crontab-Eintrag installiert:\n%s\n",
+		"Error parsing --format template: %v\n":                        "Fehler beim Parsen der --format-Vorlage: %v\n",
+		"\nError executing --format template: %v\n":                    "\nFehler beim Ausführen der --format-Vorlage: %v\n",
+		"Error: unsupported shell '%s' (expected bash, zsh or fish)\n": "Fehler: nicht unterstützte Shell '%s' (erwartet bash, zsh oder fish)\n",
+		"Trash v%s\n":      "Trash v%s\n",
+		"Build Date: %s\n": "Build-Datum:   %s\n",
+		"Git Commit: %s\n": "Git-Commit:    %s\n",
+		"Go Version: %s\n": "Go-Version:    %s\n",
+		"Platform:   %s\n": "Plattform:     %s\n",
+		"Backend:    %s\n": "Backend:       %s\n",
+		"Trash Dir:  %s\n": "Papierkorb:    %s\n",
+		"Config:     %s\n": "Konfiguration: %s\n",
+	})
+}
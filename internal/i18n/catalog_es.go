@@ -0,0 +1,374 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// Spanish translations, keyed by the English format strings
+func init() {
+	register(language.Spanish, map[string]string{
+		"    Expires:  %s\n":                                  "    Caduca:      %s\n",
+		"    Original: %s\n":                                  "    Origen:      %s\n",
+		"    Owner:    %s\n":                                  "    Propietario: %s\n",
+		"    Session:  %s\n":                                  "    Sesión:      %s\n",
+		"    Trashed:  %s\n":                                  "    Eliminado:   %s\n",
+		"    Type:     %s (%s)\n":                             "    Tipo:        %s (%s)\n",
+		"    Type:     %s\n":                                  "    Tipo:        %s\n",
+		"   Name:     %s\n":                                   "   Nombre:      %s\n",
+		"   Original: %s\n":                                   "   Origen:      %s\n",
+		"   Trashed:  %s\n\n":                                 "   Eliminado:   %s\n\n",
+		"  Expires:  %s\n":                                    "  Caduca:      %s\n",
+		"  Location: %s\n":                                    "  Ubicación:   %s\n",
+		"  Original: %s\n":                                    "  Origen:      %s\n",
+		"  Owner:    %s\n":                                    "  Propietario: %s\n",
+		"  Session:  %s\n":                                    "  Sesión:      %s\n",
+		"  Size:     %s\n":                                    "  Tamaño:      %s\n",
+		"  Trashed:  %s\n":                                    "  Eliminado:   %s\n",
+		"  Type:     %s (%s)\n":                               "  Tipo:        %s (%s)\n",
+		"  Type:     %s\n":                                    "  Tipo:        %s\n",
+		"  • %s (from %s) [%s]\n":                             "  • %s (de %s) [%s]\n",
+		"  • %s (from %s)\n":                                  "  • %s (de %s)\n",
+		"%s [y/N]: ":                                          "%s [s/N]: ",
+		"Aborted\n":                                           "Cancelado\n",
+		"Bundle from %s created %s\n":                         "Paquete de %s, creado %s\n",
+		"Bundled %d session(s) into %s\n":                     "%d sesión(es) empaquetada(s) en %s\n",
+		"Created trash directory: %s\n":                       "Directorio de papelera creado: %s\n",
+		"Deleted: %s\n":                                       "Eliminado: %s\n",
+		"Error creating trash directory: %v\n":                "Error al crear el directorio de la papelera: %v\n",
+		"Error deleting %s: %v\n":                             "Error al eliminar %s: %v\n",
+		"Error emptying trash: %v\n":                          "Error al vaciar la papelera: %v\n",
+		"Error getting config directory: %v\n":                "Error al obtener el directorio de configuración: %v\n",
+		"Error reading trash directory: %v\n":                 "Error al leer el directorio de la papelera: %v\n",
+		"Error restoring %s: %v\n":                            "Error al restaurar %s: %v\n",
+		"Error: %v\n":                                         "Error: %v\n",
+		"Error: invalid --expire value: %v\n":                 "Error: valor de --expire no válido: %v\n",
+		"Error: invalid regular expression: %v\n":             "Error: expresión regular no válida: %v\n",
+		"Error: item '%s' not found in trash\n":               "Error: el elemento '%s' no está en la papelera\n",
+		"Error: path does not exist: %s\n":                    "Error: la ruta no existe: %s\n",
+		"Error: specify either an item name or --manifest\n":  "Error: indique un nombre de elemento o --manifest\n",
+		"Example: trash restore %s --timestamp %s\n":          "Ejemplo: trash restore %s --timestamp %s\n",
+		"Expired items:   %d (run 'trash empty --expired')\n": "Caducados:           %d (ejecute 'trash empty --expired')\n",
+		"Failed to delete %d item(s)\n":                       "No se pudieron eliminar %d elemento(s)\n",
+		"Failed to restore %d item(s)\n":                      "No se pudieron restaurar %d elemento(s)\n",
+		"Failed to trash %d item(s)\n":                        "No se pudieron mover %d elemento(s) a la papelera\n",
+		"Found %d instances of '%s'. Restoring the most recent one.\n":          "Se encontraron %d instancias de '%s'. Se restaurará la más reciente.\n",
+		"Found %d instances of '%s':\n\n":                                       "Se encontraron %d instancias de '%s':\n\n",
+		"Free space below %s, purged %d old session(s)\n":                       "Espacio libre por debajo de %s, se purgaron %d sesión(es) antigua(s)\n",
+		"Imported %d item(s) in %d session(s)\n":                                "%d elemento(s) importado(s) en %d sesión(es)\n",
+		"Items:           %d\n":                                                 "Elementos:           %d\n",
+		"Listening on %s\n":                                                     "Escuchando en %s\n",
+		"Moved to trash: %s\n":                                                  "Movido a la papelera: %s\n",
+		"Newest session:  %s\n":                                                 "Sesión más reciente: %s\n",
+		"No items matching '%s' found in trash\n":                               "No se encontraron elementos que coincidan con '%s' en la papelera\n",
+		"Nothing was restored\n":                                                "No se restauró nada\n",
+		"Oldest session:  %s\n":                                                 "Sesión más antigua:  %s\n",
+		"Overwrote existing file/directory: %s\n":                               "Se sobrescribió el archivo o directorio existente: %s\n",
+		"Permanently delete %d item(s) (%s)? This cannot be undone.":            "¿Eliminar definitivamente %d elemento(s) (%s)? No se puede deshacer.",
+		"Permanently delete %d item(s) matching '%s'?":                          "¿Eliminar definitivamente %d elemento(s) que coinciden con '%s'?",
+		"Permanently delete everything in the trash?":                           "¿Eliminar definitivamente todo el contenido de la papelera?",
+		"Permanently deleted %d item(s)\n":                                      "%d elemento(s) eliminado(s) definitivamente\n",
+		"Purged %d expired item(s)":                                             "Se purgaron %d elemento(s) caducado(s)",
+		"Purged %d expired item(s)\n":                                           "Se purgaron %d elemento(s) caducado(s)\n",
+		"Purged %d old session(s) to keep free space":                           "Se purgaron %d sesión(es) antigua(s) para mantener espacio libre",
+		"Purged: %s (from %s)\n":                                                "Purgado: %s (de %s)\n",
+		"Purged: %s\n":                                                          "Purgado: %s\n",
+		"Reclaimed %s":                                                          "Se liberaron %s",
+		"Remapped %s -> %s\n":                                                   "Reasignado: %s -> %s\n",
+		"Removed empty trash directory: %s\n":                                   "Se eliminó el directorio de papelera vacío: %s\n",
+		"Restored (copied): %s -> %s\n":                                         "Restaurado (copiado): %s -> %s\n",
+		"Restored: %s -> %s\n":                                                  "Restaurado: %s -> %s\n",
+		"Restored: %s\n":                                                        "Restaurado: %s\n",
+		"Session %s imported as %s\n":                                           "Sesión %s importada como %s\n",
+		"Sessions:        %d\n":                                                 "Sesiones:            %d\n",
+		"Successfully moved %d item(s) to trash\n":                              "%d elemento(s) movido(s) a la papelera\n",
+		"Successfully restored %d item(s)\n":                                    "%d elemento(s) restaurado(s)\n",
+		"Successfully restored: %s\n":                                           "Restaurado correctamente: %s\n",
+		"Total size:      %s\n":                                                 "Tamaño total:        %s\n",
+		"Trash directory: %s\n":                                                 "Directorio:          %s\n",
+		"Trash is empty, nothing to bundle\n":                                   "La papelera está vacía, no hay nada que empaquetar\n",
+		"Trash is empty\n":                                                      "La papelera está vacía\n",
+		"Trash purged":                                                          "Papelera purgada",
+		"Usage: trash [file/directory paths...] to move items to trash\n":       "Uso: trash [rutas de archivos/directorios...] para mover elementos a la papelera\n",
+		"Use --all to see all matches or --timestamp to specify which one.\n\n": "Use --all para ver todas las coincidencias o --timestamp para elegir una.\n\n",
+		"Use --force to overwrite\n":                                            "Use --force para sobrescribir\n",
+		"Use --timestamp flag to specify which one to restore\n":                "Use --timestamp para indicar cuál restaurar\n",
+		"Warning: %v\n":                                                         "Advertencia: %v\n",
+		"Warning: auto-prune failed: %v\n":                                      "Advertencia: falló la purga automática: %v\n",
+		"Warning: failed to purge expired items: %v\n":                          "Advertencia: no se pudieron purgar los elementos caducados: %v\n",
+		"Warning: failed to save restore metadata: %v\n":                        "Advertencia: no se pudieron guardar los metadatos de restauración: %v\n",
+		"Welcome to Trash! Use --help to see available commands.\n":             "¡Bienvenido a Trash! Use --help para ver los comandos disponibles.\n",
+		"\nFound %d matching item(s)\n":                                         "\nSe encontraron %d elemento(s) coincidente(s)\n",
+		"\nTotal: %d item(s) in trash\n":                                        "\nTotal: %d elemento(s) en la papelera\n",
+		"\n[%s] (no metadata)\n":                                                "\n[%s] (sin metadatos)\n",
+		"\n[%s] Error parsing metadata: %v\n":                                   "\n[%s] Error al analizar los metadatos: %v\n",
+		"\n[%s] Error reading metadata: %v\n":                                   "\n[%s] Error al leer los metadatos: %v\n",
+		"y":                                                                     "s",
+		"yes":                                                                   "sí",
+		"in the future":                                                         "en el futuro",
+		"just now":                                                              "ahora mismo",
+		"1 year ago":                                                            "hace 1 año",
+		"%d years ago":                                                          "hace %d años",
+		"1 month ago":                                                           "hace 1 mes",
+		"%d months ago":                                                         "hace %d meses",
+		"1 week ago":                                                            "hace 1 semana",
+		"%d weeks ago":                                                          "hace %d semanas",
+		"1 day ago":                                                             "hace 1 día",
+		"%d days ago":                                                           "hace %d días",
+		"1 hour ago":                                                            "hace 1 hora",
+		"%d hours ago":                                                          "hace %d horas",
+		"1 minute ago":                                                          "hace 1 minuto",
+		"%d minutes ago":                                                        "hace %d minutos",
+		"%s on %s":                                                              "%s en %s",
+		"unknown":                                                               "desconocido",
+		"%d item(s)":                                                            "%d elemento(s)",
+		" (default)":                                                            " (predeterminado)",
+		"%d item(s), %s in %s (%s/s): %d renamed, %d copied\n":                  "%d elemento(s), %s en %s (%s/s): %d renombrado(s), %d copiado(s)\n",
+		"    Expires:  %s (retained until then)\n":                              "    Caduca:      %s (se conserva hasta entonces)\n",
+		"  Expires:  %s (retained until then)\n":                                "  Caduca:      %s (se conserva hasta entonces)\n",
+		"Kept %d retained item(s); use --include-retained to delete them too\n": "Se conservan %d elemento(s) retenido(s); use --include-retained para eliminarlos también\n",
+		"Nothing to undo\n":                      "Nada que deshacer\n",
+		"Nothing to redo\n":                      "Nada que rehacer\n",
+		"No longer in the trash: %s\n":           "Ya no está en la papelera: %s\n",
+		"  • %s  %s (from %s)\n":                 "  • %s  %s (de %s)\n",
+		"Permanently delete %d item(s)?":         "¿Eliminar definitivamente %d elemento(s)?",
+		"No problems found\n":                    "No se encontraron problemas\n",
+		"%d problem(s) found\n":                  "%d problema(s) encontrado(s)\n",
+		"Fixed the permissions of %d entry(s)\n": "Permisos corregidos en %d entrada(s)\n",
+		"Would move %s to %s\n":                  "Se movería %s a %s\n",
+		"Moved %s to %s\n":                       "Movido %s a %s\n",
+		"Warning: config.toml sets %s.%s, but '%s' has no such flag\n": "Aviso: config.toml define %s.%s, pero '%s' no tiene esa opción\n",
+		"Warning: invalid default for --%s in config.toml: %v\n":       "Aviso: valor predeterminado no válido para --%s en config.toml: %v\n",
+		"Error: alias loop: %s\n":                                      "Error: bucle de alias: %s\n",
+		"Error: invalid alias %s = %q in config.toml\n":                "Error: alias no válido %s = %q en config.toml\n",
+		"  Does not exist\n":                                           "  No existe\n",
+		"  Store:      %s (%s)\n":                                      "  Almacén:    %s (%s)\n",
+		"  Method:     %s\n":                                           "  Método:     %s\n",
+		"  Protection: %s\n":                                           "  Protección: %s\n",
+		"  Policy:     %s\n":                                           "  Política:   %s\n",
+		"trash on Windows drive %s":                                    "papelera en la unidad de Windows %s",
+		"refused: %v":                                                  "rechazado: %v",
+		"protected by %s (%s); trashed only with --no-ignore":          "protegido por %s (%s); solo se mueve con --no-ignore",
+		"ignored by git; skipped with --respect-gitignore":             "ignorado por git; se omite con --respect-gitignore",
+		"remote store %s":                                              "almacén remoto %s",
+		"local trash of the project at %s":                             "papelera local del proyecto en %s",
+		"home trash, still where an older version kept it; see 'trash migrate --xdg'": "papelera personal, aún donde la guardaba una versión anterior; véase 'trash migrate --xdg'",
+		"home trash": "papelera personal",
+		"renamed into the trash, nothing is copied":         "se renombra a la papelera, no se copia nada",
+		"copied to the remote store (%s), then deleted":     "se copia al almacén remoto (%s) y luego se elimina",
+		"copied from another filesystem (%s), then deleted": "se copia desde otro sistema de archivos (%s) y luego se elimina",
+		", at most %s/s": ", como máximo %s/s",
+		"; the link is trashed, not its target (-L trashes the target)":                                "; se mueve el enlace, no su destino (-L mueve el destino)",
+		"joins the session of the current hour (session_window)":                                       "se une a la sesión de la hora actual (session_window)",
+		"joins today's session (session_window)":                                                       "se une a la sesión de hoy (session_window)",
+		"gets a session of its own":                                                                    "obtiene una sesión propia",
+		"the oldest sessions are purged when less than %s is free (min_free)":                          "las sesiones más antiguas se purgan cuando quedan menos de %s libres (min_free)",
+		"its metadata is signed (sign_metadata)":                                                       "sus metadatos se firman (sign_metadata)",
+		"sessions are created %04o, metadata %04o (dir_mode)":                                          "las sesiones se crean con %04o, los metadatos con %04o (dir_mode)",
+		"defaults from [root] in config.toml: %s":                                                      "valores predeterminados de [root] en config.toml: %s",
+		"Other trash:    %d item(s) in %s (run 'trash import' to move them here)\n":                    "Otra papelera:  %d elemento(s) en %s (ejecute 'trash import' para traerlos aquí)\n",
+		"Error: %s is not a trash directory of trash-cli, gio or a file manager\n":                     "Error: %s no es una papelera de trash-cli, gio ni de un gestor de archivos\n",
+		"Would import: %s (from %s)\n":                                                                 "Se importaría: %s (de %s)\n",
+		"Imported: %s (from %s) [%s]\n":                                                                "Importado: %s (de %s) [%s]\n",
+		"Nothing to import\n":                                                                          "Nada que importar\n",
+		"Would import %d item(s)\n":                                                                    "Se importarían %d elemento(s)\n",
+		"Imported %d item(s)\n":                                                                        "Importado(s) %d elemento(s)\n",
+		"Warning: checksums could not be stored: %v\n":                                                 "Aviso: no se pudieron guardar las sumas de comprobación: %v\n",
+		"No duplicates: every payload in the trash is different\n":                                     "Sin duplicados: cada contenido de la papelera es distinto\n",
+		"\n%d copies of %s, %s each (%s to save)\n":                                                    "\n%d copias de %s, %s cada una (%s por ahorrar)\n",
+		"  • %s  %s [%s] (from %s)\n":                                                                  "  • %s  %s [%s] (de %s)\n",
+		"\nTotal: %d group(s) of duplicates, %s could be saved\n":                                      "\nTotal: %d grupo(s) de duplicados, se podrían ahorrar %s\n",
+		"Would link %s to %s\n":                                                                        "Se enlazaría %s a %s\n",
+		"Linked %s to %s\n":                                                                            "Enlazado %s a %s\n",
+		"No duplicate files to link\n":                                                                 "No hay archivos duplicados que enlazar\n",
+		"Would replace %d duplicate file(s) with hard links, reclaiming %s\n":                          "Se reemplazarían %d archivo(s) duplicado(s) por enlaces duros, liberando %s\n",
+		"Replaced %d duplicate file(s) with hard links, reclaimed %s\n":                                "Reemplazado(s) %d archivo(s) duplicado(s) por enlaces duros, liberados %s\n",
+		"Error: %s is compressed into %s of its session; use 'trash open' or 'trash restore --keep'\n": "Error: %s está comprimido en %s de su sesión; use 'trash open' o 'trash restore --keep'\n",
+		"  Location: %s (compressed into %s)\n":                                                        "  Ubicación:   %s (comprimido en %s)\n",
+		"Warning: failed to compress old sessions: %v\n":                                               "Advertencia: no se pudieron comprimir las sesiones antiguas: %v\n",
+		"Warning: failed to compress %s: %v\n":                                                         "Advertencia: no se pudo comprimir %s: %v\n",
+		"Compressed: %s (%s to %s)\n":                                                                  "Comprimida: %s (%s a %s)\n",
+		"Compressed %d session(s) older than %s, %s to %s\n":                                           "%d sesión(es) con más de %s comprimida(s), %s a %s\n",
+		"Dropped from the metadata: %s\n":                                                              "Eliminado de los metadatos: %s\n",
+		"Empty session: %s\n":                                                                          "Sesión vacía: %s\n",
+		"Temporary file: %s\n":                                                                         "Archivo temporal: %s\n",
+		"Would drop %d missing item(s), rewrite %d session(s), remove %d empty session(s) and %d temporary file(s)\n": "Se eliminarían %d elemento(s) ausente(s), se reescribirían %d sesión(es) y se borrarían %d sesión(es) vacía(s) y %d archivo(s) temporal(es)\n",
+		"Dropped %d missing item(s), rewrote %d session(s), removed %d empty session(s) and %d temporary file(s)\n":   "Eliminados %d elemento(s) ausente(s), reescritas %d sesión(es), borradas %d sesión(es) vacía(s) y %d archivo(s) temporal(es)\n",
+		"Metadata and state: %s before, %s after\n": "Metadatos y estado: %s antes, %s después\n",
+		"Corrupt: %s [%s]: %v\n":                    "Dañado: %s [%s]: %v\n",
+		"Missing: %s [%s]\n":                        "Ausente: %s [%s]\n",
+		"Unreadable: %s [%s]: %v\n":                 "Ilegible: %s [%s]: %v\n",
+		"Recorded: %s [%s]\n":                       "Registrado: %s [%s]\n",
+		"OK: %s [%s]\n":                             "OK: %s [%s]\n",
+		"Verified %d item(s): %d intact, %d checksum(s) recorded, %d problem(s)\n":                "Verificados %d elemento(s): %d intacto(s), %d suma(s) de comprobación registrada(s), %d problema(s)\n",
+		"Error: invalid --verify-every %q\n":                                                      "Error: --verify-every no válido %q\n",
+		"Warning: verify: %v\n":                                                                   "Advertencia: verificación: %v\n",
+		"Warning: verify: %s [%s] is %s: %v\n":                                                    "Advertencia: verificación: %s [%s] está %s: %v\n",
+		"Warning: verify: %s [%s] is missing\n":                                                   "Advertencia: verificación: falta %s [%s]\n",
+		"Error: --resume cannot be combined with item IDs, --expired, --regex or --interactive\n": "Error: --resume no se puede combinar con IDs de elementos, --expired, --regex o --interactive\n",
+		"Finished %d interrupted purge(s)\n":                                                      "Completada(s) %d purga(s) interrumpida(s)\n",
+		"No interrupted purge to finish\n":                                                        "No hay ninguna purga interrumpida que completar\n",
+		"Error: --stage cannot be combined with --here\n":                                         "Error: --stage no se puede combinar con --here\n",
+		"Error: no trash session matches '%s'\n":                                                  "Error: ninguna sesión de la papelera coincide con '%s'\n",
+		"Error: mount point %s is not a directory\n":                                              "Error: el punto de montaje %s no es un directorio\n",
+		"Error: cannot mount the trash: %v\n":                                                     "Error: no se puede montar la papelera: %v\n",
+		"Trash mounted at %s; press Ctrl+C to unmount\n":                                          "Papelera montada en %s; pulse Ctrl+C para desmontarla\n",
+		"Error: cannot unmount %s: %v\n":                                                          "Error: no se puede desmontar %s: %v\n",
+		"Close the programs using it and try again\n":                                             "Cierre los programas que la usan e inténtelo de nuevo\n",
+		"Unmounted %s\n": "%s desmontado\n",
+		"the trash directory is on a network filesystem (%s): it is locked with lockfiles and copies are synced before originals are deleted (network_fs)":       "el directorio de la papelera está en un sistema de archivos de red (%s): se bloquea con archivos de bloqueo y las copias se sincronizan antes de borrar los originales (network_fs)",
+		"the trash directory is treated as on a network filesystem: it is locked with lockfiles and copies are synced before originals are deleted (network_fs)": "el directorio de la papelera se trata como si estuviera en un sistema de archivos de red: se bloquea con archivos de bloqueo y las copias se sincronizan antes de borrar los originales (network_fs)",
+		"Warning: copying %s failed: %v; retrying in %s\n":                              "Aviso: falló la copia de %s: %v; se reintenta en %s\n",
+		"Warning: cannot snapshot %s, copying it instead: %v\n":                         "Aviso: no se puede crear una instantánea de %s, se copia en su lugar: %v\n",
+		"%d item(s), %s in %s (%s/s): %d renamed, %d copied, %d snapshotted\n":          "%d elemento(s), %s en %s (%s/s): %d renombrados, %d copiados, %d en instantáneas\n",
+		"  Location: %s (in the %s snapshot %s)\n":                                      "  Ubicación: %s (en la instantánea %s %s)\n",
+		"kept in a %s snapshot of its filesystem (%s), then deleted; nothing is copied": "se conserva en una instantánea %s de su sistema de archivos (%s) y luego se elimina; no se copia nada",
+		"Clients must send the token in %s\n":                                           "Los clientes deben enviar el token de %s\n",
+		"Error reading crontab, leaving it unchanged: %v\n":                             "Error al leer el crontab, se deja sin cambios: %v\n",
+		"%s is neither a trash session nor a path in the trash":                         "%s no es una sesión de la papelera ni una ruta dentro de ella",
+		"%s is not in the trash directory %s":                                           "%s no está en el directorio de la papelera %s",
+		"%s is inside a trashed item; adopt the item %s instead":                        "%s está dentro de un elemento eliminado; adopte en su lugar el elemento %s",
+		"Error: specify sessions or paths to adopt, or --all\n":                         "Error: indique sesiones o rutas que adoptar, o --all\n",
+		"Adopted: %s [%s]\n": "Adoptado: %s [%s]\n",
+		"Skipping %s: its metadata is unusable; fix or remove the .restore file first\n":     "Se omite %s: sus metadatos no se pueden usar; corrija o elimine primero el archivo .restore\n",
+		"Adopted %d item(s); their original location is unknown, restore them with --here\n": "Adoptado(s) %d elemento(s); se desconoce su ubicación original, restáurelos con --here\n",
+		"Nothing to adopt\n":                                                            "Nada que adoptar\n",
+		"Error applying bundle: %v\n":                                                   "Error al aplicar el paquete: %v\n",
+		"Error creating bundle: %v\n":                                                   "Error al crear el paquete: %v\n",
+		"Error writing bundle: %v\n":                                                    "Error al escribir el paquete: %v\n",
+		"Error getting home directory: %v\n":                                            "Error al obtener el directorio personal: %v\n",
+		"Error opening bundle: %v\n":                                                    "Error al abrir el paquete: %v\n",
+		"Error: schedule must have 5 fields, got %q\n":                                  "Error: la programación debe tener 5 campos, no %q\n",
+		"Error locating trash executable: %v\n":                                         "Error al localizar el ejecutable de trash: %v\n",
+		"Error installing crontab: %v\n":                                                "Error al instalar el crontab: %v\n",
+		"Error: cannot dereference %s: %v\n":                                            "Error: no se puede resolver %s: %v\n",
+		"Dereferenced %s -> %s\n":                                                       "Resuelto: %s -> %s\n",
+		"Changed %s from %04o to %04o\n":                                                "%s cambiado de %04o a %04o\n",
+		"Would trash %d item(s): %d file(s), %d dir(s), %s\n":                           "Se moverían a la papelera %d elemento(s): %d archivo(s), %d directorio(s), %s\n",
+		"Everything is on the trash filesystem and would be moved without copying\n":    "Todo está en el sistema de archivos de la papelera y se movería sin copiar\n",
+		"Cross-device copy needed for %d item(s) (%s)\n":                                "Hace falta copiar entre dispositivos %d elemento(s) (%s)\n",
+		"Warning: only %s free on the trash filesystem\n":                               "Advertencia: solo quedan %s libres en el sistema de archivos de la papelera\n",
+		"Error: item IDs cannot be combined with --expired, --regex or --interactive\n": "Error: los ID de elemento no se pueden combinar con --expired, --regex ni --interactive\n",
+		"Error: --interactive cannot be combined with --expired, --regex or --force\n":  "Error: --interactive no se puede combinar con --expired, --regex ni --force\n",
+		"Purge this session? [y]es, [n]o or [q]uit: ":                                   "¿Purgar esta sesión? [s]í, [n]o o [q] salir: ",
+		"Skipped\n": "Omitido\n",
+		"Error: invalid --older-than value: %v\n":                          "Error: valor de --older-than no válido: %v\n",
+		"Error: invalid --larger-than value: %v\n":                         "Error: valor de --larger-than no válido: %v\n",
+		"Skipped (%s): %s\n":                                               "Omitido (%s): %s\n",
+		"modified %s":                                                      "modificado %s",
+		"Error: invalid --type %q: expected file or dir\n":                 "Error: --type %q no válido: se esperaba file o dir\n",
+		"No matches found\n":                                               "No se encontraron coincidencias\n",
+		"Error: --all-users requires root\n":                               "Error: --all-users requiere root\n",
+		"Error: --user %s requires root\n":                                 "Error: --user %s requiere root\n",
+		"Error writing output: %v\n":                                       "Error al escribir la salida: %v\n",
+		"Error: --ignored and --all are mutually exclusive\n":              "Error: --ignored y --all son mutuamente excluyentes\n",
+		"Nothing to clean\n":                                               "Nada que limpiar\n",
+		"%s  restored  %s\n":                                               "%s  restaurado  %s\n",
+		"%s  purged    %s\n":                                               "%s  purgado     %s\n",
+		"    Original:   %s\n":                                             "    Origen:     %s\n",
+		"    Session:    %s/%s\n":                                          "    Sesión:     %s/%s\n",
+		"    Invocation: %s (%s)\n":                                        "    Invocación: %s (%s)\n",
+		"Error: invalid --op value %q: expected trash, restore or purge\n": "Error: valor de --op %q no válido: se esperaba trash, restore o purge\n",
+		"No history\n":                                                     "Sin historial\n",
+		"%s  trashed   %s\n":                                               "%s  eliminado   %s\n",
+		"Warning: failed to read %s: %v\n":                                 "Advertencia: no se pudo leer %s: %v\n",
+		"Skipping %s: protected by %s (%s)\n":                              "Se omite %s: protegido por %s (%s)\n",
+		"Skipping %s: ignored by git\n":                                    "Se omite %s: ignorado por git\n",
+		"  Links:    %d when trashed (inode %s on device %s); other links may still exist\n": "  Enlaces:     %d al eliminarlo (inodo %s en el dispositivo %s); puede que aún existan otros enlaces\n",
+		"Error: could not open file manager: %v\n":                                           "Error: no se pudo abrir el gestor de archivos: %v\n",
+		"  ID:       %s\n": "  ID:          %s\n",
+		"Skipping %s: it is inside trash session %s; restore the item first\n": "Se omite %s: está dentro de la sesión de la papelera %s; restaure primero el elemento\n",
+		"%s is already in the trash (session %s, from %s)\n":                   "%s ya está en la papelera (sesión %s, de %s)\n",
+		"Skipping %s: run interactively to purge or re-file it\n":              "Se omite %s: ejecute de forma interactiva para purgarlo o volver a archivarlo\n",
+		"Re-filed: %s\n": "Archivado de nuevo: %s\n",
+		"[p]urge it, [r]e-file it into this trash, or [s]kip? ":                   "¿[p] purgarlo, [r] archivarlo en esta papelera o [s] omitirlo? ",
+		"[p]urge it or [s]kip? ":                                                  "¿[p] purgarlo o [s] omitirlo? ",
+		"  • %s (from %s) %s, retained until %s\n":                                "  • %s (de %s) %s, retenido hasta %s\n",
+		"  • %s (from %s) %s\n":                                                   "  • %s (de %s) %s\n",
+		"  (no items recorded)\n":                                                 "  (no hay elementos registrados)\n",
+		"  Total: %d item(s), %s\n":                                               "  Total: %d elemento(s), %s\n",
+		"Error: --restore and --empty cannot be used together\n":                  "Error: --restore y --empty no se pueden usar juntos\n",
+		"Nothing to restore\n":                                                    "Nada que restaurar\n",
+		"Permanently delete the %d item(s) of session %s?":                        "¿Eliminar definitivamente los %d elemento(s) de la sesión %s?",
+		"\n[%s] Invalid metadata: %v\n":                                           "\n[%s] Metadatos no válidos: %v\n",
+		"Error: invalid --%s value: %v\n":                                         "Error: valor de --%s no válido: %v\n",
+		"Error: --limit must not be negative\n":                                   "Error: --limit no puede ser negativo\n",
+		"    ID:       %s\n":                                                      "    ID:          %s\n",
+		"No orphans: everything in the trash is listed\n":                         "No hay huérfanos: todo lo que hay en la papelera aparece en la lista\n",
+		"[%s] whole session, %s: %s\n":                                            "[%s] sesión completa, %s: %s\n",
+		"\nTotal: %d orphan(s), %s not shown by list\n":                           "\nTotal: %d huérfano(s), %s que list no muestra\n",
+		"Skipping %s: %v\n":                                                       "Se omite %s: %v\n",
+		"%s: would move %d item(s) into item directories and record %d size(s)\n": "%s: se moverían %d elemento(s) a directorios de elemento y se registrarían %d tamaño(s)\n",
+		"%s: %d item(s) moved into item directories, %d size(s) recorded\n":       "%s: %d elemento(s) movido(s) a directorios de elemento, %d tamaño(s) registrado(s)\n",
+		"Would move %d item(s) into item directories and record %d size(s)\n":     "Se moverían %d elemento(s) a directorios de elemento y se registrarían %d tamaño(s)\n",
+		"Moved %d item(s) into item directories and recorded %d size(s)\n":        "Movido(s) %d elemento(s) a directorios de elemento y registrado(s) %d tamaño(s)\n",
+		"Previous metadata saved to %s\n":                                         "Metadatos anteriores guardados en %s\n",
+		"The trash directory is up to date\n":                                     "El directorio de la papelera está al día\n",
+		"Error: could not copy %s: %v\n":                                          "Error: no se pudo copiar %s: %v\n",
+		"Opening %s\n":                                                            "Abriendo %s\n",
+		"Error: could not open %s: %v\n":                                          "Error: no se pudo abrir %s: %v\n",
+		"Passphrase for the signing key: ":                                        "Frase de contraseña de la clave de firma: ",
+		"Error: cannot run plugin %s: %v\n":                                       "Error: no se puede ejecutar el plugin %s: %v\n",
+		"Error reading manifest: %v\n":                                            "Error al leer el manifiesto: %v\n",
+		"Error: %s: %v\n":                                                         "Error: %s: %v\n",
+		"Error: --interactive needs a terminal\n":                                 "Error: --interactive necesita un terminal\n",
+		"Use --force to overwrite, or --on-conflict=rename to keep both\n":        "Use --force para sobrescribir, o --on-conflict=rename para conservar ambos\n",
+		"Use --here to restore it into the current directory\n":                   "Use --here para restaurarlo en el directorio actual\n",
+		"Warning: skipping %s: %v\n":                                              "Advertencia: se omite %s: %v\n",
+		"Nothing was restored; fix the problems above or use --no-preflight\n":    "No se restauró nada; corrija los problemas anteriores o use --no-preflight\n",
+		"Choose an item by number (empty to finish): ":                            "Elija un elemento por su número (vacío para terminar): ",
+		"Invalid choice: %s\n":                                                    "Opción no válida: %s\n",
+		"Item %d was already handled\n":                                           "El elemento %d ya se ha tratado\n",
+		"%d. %s: [o]riginal location, [h]ere, [p]urge or [s]kip? ":                "%d. %s: ¿[o] ubicación original, [h] aquí, [p] purgar u [s] omitir? ",
+		"Error getting current directory: %v\n":                                   "Error al obtener el directorio actual: %v\n",
+		"Permanently delete %s?":                                                  "¿Eliminar definitivamente %s?",
+		"Skipped: %s\n":                                                           "Omitido: %s\n",
+		"%s is no longer retained\n":                                              "%s ya no se retiene\n",
+		"Keeping %s until %s\n":                                                   "Se retiene %s hasta %s\n",
+		"Nothing to trash\n":                                                      "Nada que mover a la papelera\n",
+		"Would trash: %s\n":                                                       "Se movería a la papelera: %s\n",
+		"Error: --print and --print0 cannot be used together\n":                   "Error: --print y --print0 no se pueden usar juntos\n",
+		"Use 'trash empty' to permanently delete what is in the trash\n":          "Use 'trash empty' para eliminar definitivamente lo que hay en la papelera\n",
+		"Unmount it first, or use --allow-mounts to trash it anyway\n":            "Desmóntelo primero, o use --allow-mounts para moverlo a la papelera de todos modos\n",
+		"Nothing was trashed; fix the problems above or use --no-preflight\n":     "No se movió nada a la papelera; corrija los problemas anteriores o use --no-preflight\n",
+		"Error: invalid bandwidth limit: %v\n":                                    "Error: límite de ancho de banda no válido: %v\n",
+		"Error: --expire and --keep-for cannot be used together\n":                "Error: --expire y --keep-for no se pueden usar juntos\n",
+		"Error: invalid --keep-for value: %v\n":                                   "Error: valor de --keep-for no válido: %v\n",
+		"    Store:    %s\n":                                                      "    Almacén:     %s\n",
+		"  • %s (from %s) [%s]%s\n":                                               "  • %s (de %s) [%s]%s\n",
+		"Error: D-Bus: %v\n":                                                      "Error: D-Bus: %v\n",
+		"Retained:       %d item(s) kept past the retention policies\n":           "Retenidos:      %d elemento(s) conservados más allá de las políticas de retención\n",
+		"Min free:       not set\n":                                               "Mín. libre:     no definido\n",
+		"Min free:       %s (invalid: %v)\n":                                      "Mín. libre:     %s (no válido: %v)\n",
+		"Min free:       %s (free space unknown)\n":                               "Mín. libre:     %s (espacio libre desconocido)\n",
+		"Min free:       %s, only %s free: the next trash operation purges the oldest sessions\n": "Mín. libre:     %s, solo %s libres: la próxima operación purgará las sesiones más antiguas\n",
+		"Min free:       %s, %s free (%s to spare)\n":                                             "Mín. libre:     %s, %s libres (%s de margen)\n",
+		"Session window: %s\n":                              "Ventana sesión: %s\n",
+		"Problems:       none\n":                            "Problemas:      ninguno\n",
+		"Problems:       %d\n":                              "Problemas:      %d\n",
+		"    Fix: %s\n":                                     "    Solución: %s\n",
+		"Store:          %s (%s)\n":                         "Almacén:        %s (%s)\n",
+		"Contents:       %d item(s) in %d session(s), %s\n": "Contenido:      %d elemento(s) en %d sesión(es), %s\n",
+		"Oldest item:    %s\n":                              "Más antiguo:    %s\n",
+		"Expired:        %d item(s) waiting to be purged (run 'trash empty --expired')\n":                                                                     "Caducados:      %d elemento(s) pendientes de purgar (ejecute 'trash empty --expired')\n",
+		"Check the session's .restore file, or use --no-verify to restore anyway\n":                                                                           "Revise el archivo .restore de la sesión, o use --no-verify para restaurar de todos modos\n",
+		"Move the files now in the way and run 'trash undo' again\n":                                                                                          "Mueva los archivos que ahora estorban y vuelva a ejecutar 'trash undo'\n",
+		"Warning: failed to save the undo stack: %v\n":                                                                                                        "Advertencia: no se pudo guardar la pila de deshacer: %v\n",
+		"Warning: paths span several drives, using the default trash\n":                                                                                       "Advertencia: las rutas abarcan varias unidades, se usa la papelera predeterminada\n",
+		"Warning: %s is a Windows drive; trashing from it copies everything into the Linux trash (set wsl_drive_trash = true to keep a trash on the drive)\n": "Advertencia: %s es una unidad de Windows; mover a la papelera desde ella copia todo a la papelera de Linux (ponga wsl_drive_trash = true para tener una papelera en la unidad)\n",
+		"Note: %s is on shared storage and will be copied into the trash\n":                                                                                   "Nota: %s está en almacenamiento compartido y se copiará a la papelera\n",
+		"%d items match '%s':\n": "%d elementos coinciden con '%s':\n",
+		"Error: restoring more than one match needs --yes when stdin is not a terminal\n": "Error: restaurar más de una coincidencia requiere --yes cuando la entrada estándar no es un terminal\n",
+		"Restore %d item(s)?":                                          "¿Restaurar %d elemento(s)?",
+		"Created config directory: %s\n":                               "Directorio creado: %s\n",
+		"Installed crontab entry:\n%s\n":                               "Entrada de crontab instalada:\n%s\n",
+		"Error parsing --format template: %v\n":                        "Error al analizar la plantilla de --format: %v\n",
+		"\nError executing --format template: %v\n":                    "\nError al ejecutar la plantilla de --format: %v\n",
+		"Error: unsupported shell '%s' (expected bash, zsh or fish)\n": "Error: shell '%s' no compatible (se esperaba bash, zsh o fish)\n",
+		"Trash v%s\n":      "Trash v%s\n",
+		"Build Date: %s\n": "Compilación:   %s\n",
+		"Git Commit: %s\n": "Commit Git:    %s\n",
+		"Go Version: %s\n": "Versión Go:    %s\n",
+		"Platform:   %s\n": "Plataforma:    %s\n",
+		"Backend:    %s\n": "Backend:       %s\n",
+		"Trash Dir:  %s\n": "Papelera:      %s\n",
+		"Config:     %s\n": "Configuración: %s\n",
+	})
+}
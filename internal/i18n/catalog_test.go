@@ -0,0 +1,217 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// moduleRoot is the root of the module, relative to this package
+const moduleRoot = "../.."
+
+// inspectCalls calls fn with every package-qualified call, such as fmt.Printf,
+// in the non-test sources of the module, with the file it is in
+func inspectCalls(t *testing.T, fn func(fset *token.FileSet, path, pkg, name string, call *ast.CallExpr)) {
+	t.Helper()
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(moduleRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != moduleRoot && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := selector.X.(*ast.Ident); ok {
+				fn(fset, path, pkg.Name, selector.Sel.Name, call)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// messageKeys returns the format strings passed as literals to Printf, Fprintf
+// and Sprintf of this package anywhere in the module, with where each is used
+func messageKeys(t *testing.T) map[string]string {
+	t.Helper()
+	keys := make(map[string]string)
+
+	inspectCalls(t, func(fset *token.FileSet, path, pkg, name string, call *ast.CallExpr) {
+		if pkg != "i18n" {
+			return
+		}
+		format := 0
+		switch name {
+		case "Printf", "Sprintf":
+		case "Fprintf":
+			format = 1
+		default:
+			return
+		}
+		if len(call.Args) <= format {
+			return
+		}
+		if key, ok := stringConstant(call.Args[format]); ok && translatable(key) {
+			keys[key] = fset.Position(call.Pos()).String()
+		}
+	})
+	return keys
+}
+
+// stringConstant returns the value of a string literal, or of a
+// concatenation of them
+func stringConstant(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(e.Value)
+		return value, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok1 := stringConstant(e.X)
+		y, ok2 := stringConstant(e.Y)
+		return x + y, ok1 && ok2
+	case *ast.ParenExpr:
+		return stringConstant(e.X)
+	}
+	return "", false
+}
+
+// translatable reports whether format has any text besides its verbs, so
+// that "%s\n" and the like need no translation
+func translatable(format string) bool {
+	text := format
+	for _, verb := range strings.Fields(verbs(format)) {
+		text = strings.Replace(text, verb, "", 1)
+	}
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
+
+func TestCatalogsTranslateEveryMessage(t *testing.T) {
+	keys := messageKeys(t)
+	if len(keys) == 0 {
+		t.Fatal("found no messages; is moduleRoot right?")
+	}
+
+	for _, tag := range supported[1:] {
+		catalog := catalogs[tag]
+		var missing []string
+		for key, pos := range keys {
+			if _, ok := catalog[key]; !ok {
+				missing = append(missing, pos+": "+strconv.Quote(key))
+			}
+		}
+		sort.Strings(missing)
+		if len(missing) > 0 {
+			t.Errorf("%d of %d messages have no %s translation:\n%s", len(missing), len(keys), tag, strings.Join(missing, "\n"))
+		}
+	}
+}
+
+// TestCommandsPrintThroughCatalog catches messages that commands print to the
+// terminal with fmt, bypassing the catalogs. Output to other writers, such as
+// the Prometheus exposition format, is not a message and is left alone.
+func TestCommandsPrintThroughCatalog(t *testing.T) {
+	commands := filepath.Join(moduleRoot, "cmd") + string(filepath.Separator)
+	var bare []string
+
+	inspectCalls(t, func(fset *token.FileSet, path, pkg, name string, call *ast.CallExpr) {
+		if pkg != "fmt" || !strings.HasPrefix(path, commands) {
+			return
+		}
+		args := call.Args
+		switch name {
+		case "Print", "Printf", "Println":
+		case "Fprint", "Fprintf", "Fprintln":
+			if len(args) == 0 || !terminal(args[0]) {
+				return
+			}
+			args = args[1:]
+		default:
+			return
+		}
+		for _, arg := range args {
+			if text, ok := stringConstant(arg); ok && translatable(text) {
+				bare = append(bare, fset.Position(call.Pos()).String()+": "+strconv.Quote(text))
+			}
+		}
+	})
+	sort.Strings(bare)
+	if len(bare) > 0 {
+		t.Errorf("%d messages are printed with fmt instead of i18n:\n%s", len(bare), strings.Join(bare, "\n"))
+	}
+}
+
+// terminal reports whether expr is os.Stdout or os.Stderr
+func terminal(expr ast.Expr) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && pkg.Name == "os" && (selector.Sel.Name == "Stdout" || selector.Sel.Name == "Stderr")
+}
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for _, tag := range supported[1:] {
+		for key, translation := range catalogs[tag] {
+			if verbs(key) != verbs(translation) {
+				t.Errorf("%s translation of %q has verbs %q, want %q", tag, key, verbs(translation), verbs(key))
+			}
+		}
+	}
+}
+
+// verbs returns the formatting verbs of format, in order
+func verbs(format string) string {
+	var out []string
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.[]*", format[j]) >= 0 {
+			j++
+		}
+		if j < len(format) {
+			out = append(out, format[i:j+1])
+		}
+		i = j
+	}
+	return strings.Join(out, " ")
+}
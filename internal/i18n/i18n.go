@@ -0,0 +1,99 @@
+// Package i18n translates user-facing messages. The English format strings used
+// throughout the commands are the message keys; catalogs register translations
+// for them and untranslated messages fall back to English.
+package i18n
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// supported lists the languages with a catalog, English first as the fallback
+var supported = []language.Tag{language.English, language.German, language.Spanish}
+
+var (
+	printer     *message.Printer
+	printerOnce sync.Once
+)
+
+// Locale returns the language selected by LC_ALL, LC_MESSAGES or LANG, in that order
+func Locale() language.Tag {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		return parseLocale(value)
+	}
+	return language.English
+}
+
+// parseLocale converts a POSIX locale such as de_DE.UTF-8 to the closest supported language
+func parseLocale(value string) language.Tag {
+	// Strip encoding and modifier: de_DE.UTF-8@euro -> de_DE
+	value, _, _ = strings.Cut(value, ".")
+	value, _, _ = strings.Cut(value, "@")
+	if value == "C" || value == "POSIX" {
+		return language.English
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(value, "_", "-"))
+	if err != nil {
+		return language.English
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(tag)
+	if confidence == language.No {
+		return language.English
+	}
+	return supported[index]
+}
+
+// Printer returns the message printer for the user's locale
+func Printer() *message.Printer {
+	printerOnce.Do(func() {
+		printer = message.NewPrinter(Locale())
+	})
+	return printer
+}
+
+// Printf prints a translated message to stdout
+func Printf(format string, args ...interface{}) {
+	Printer().Printf(format, args...)
+}
+
+// Fprintf prints a translated message to w
+func Fprintf(w io.Writer, format string, args ...interface{}) {
+	Printer().Fprintf(w, format, args...)
+}
+
+// Sprintf returns a translated message
+func Sprintf(format string, args ...interface{}) string {
+	return Printer().Sprintf(format, args...)
+}
+
+// IsYes reports whether answer is an affirmative reply, in English or the user's language
+func IsYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	switch answer {
+	case "y", "yes", Sprintf("y"), Sprintf("yes"):
+		return true
+	}
+	return false
+}
+
+// catalogs holds the registered translations by language
+var catalogs = make(map[language.Tag]map[string]string)
+
+// register adds a catalog of translations for tag
+func register(tag language.Tag, messages map[string]string) {
+	catalogs[tag] = messages
+	for key, msg := range messages {
+		message.SetString(tag, key, msg)
+	}
+}
@@ -0,0 +1,34 @@
+// Package mount exposes the trash as a read-only FUSE filesystem, served by
+// `trash mount`
+package mount
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// ErrRemoteStore is returned when the trash store is not on the local disk,
+// whose payloads the filesystem reads directly
+var ErrRemoteStore = errors.New("only a trash directory on the local disk can be mounted")
+
+// itemPath returns the components of the path an item is shown at below its
+// session directory: its original path, or its name when that is unknown
+func itemPath(item config.RestoreItem) []string {
+	if item.OriginalPath == "" {
+		return []string{item.Name}
+	}
+
+	var components []string
+	for _, component := range strings.Split(filepath.ToSlash(item.OriginalPath), "/") {
+		if component != "" && component != "." && component != ".." {
+			components = append(components, component)
+		}
+	}
+	if len(components) == 0 {
+		return []string{item.Name}
+	}
+	return components
+}
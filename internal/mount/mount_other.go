@@ -0,0 +1,24 @@
+//go:build !linux && !darwin && !freebsd
+
+package mount
+
+import "errors"
+
+// errUnsupported is returned where FUSE is not available
+var errUnsupported = errors.New("mounting the trash is only supported on Linux, macOS and FreeBSD")
+
+// Mount is a mounted view of the trash
+type Mount struct{}
+
+// New is not supported without FUSE
+func New(mountpoint string, debug bool) (*Mount, error) {
+	return nil, errUnsupported
+}
+
+// Unmount is not supported without FUSE
+func (m *Mount) Unmount() error {
+	return errUnsupported
+}
+
+// Wait returns immediately without FUSE
+func (m *Mount) Wait() {}
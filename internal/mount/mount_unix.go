@@ -0,0 +1,330 @@
+//go:build linux || darwin || freebsd
+
+package mount
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// Mount is a mounted view of the trash
+type Mount struct {
+	server *fuse.Server
+	cache  string // where items of compressed sessions are extracted to
+}
+
+// New mounts the trash at mountpoint, read-only: a directory per session
+// holding its items at their original paths. The view is taken when it is
+// mounted. Items of compressed sessions are extracted to a temporary
+// directory the first time they are opened.
+func New(mountpoint string, debug bool) (*Mount, error) {
+	if _, ok := config.StoreFS().(config.LocalFS); !ok {
+		return nil, ErrRemoteStore
+	}
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := os.MkdirTemp("", "trash-mount-")
+	if err != nil {
+		return nil, err
+	}
+
+	root := &trashRoot{dirNode: dirNode{mtime: time.Now()}, items: items, cache: cache}
+	server, err := fs.Mount(mountpoint, root, &fs.Options{
+		MountOptions: fuse.MountOptions{
+			Options: []string{"ro"},
+			FsName:  "trash",
+			Name:    "trash",
+			Debug:   debug,
+		},
+		UID: uint32(os.Getuid()),
+		GID: uint32(os.Getgid()),
+	})
+	if err != nil {
+		os.RemoveAll(cache)
+		return nil, err
+	}
+	return &Mount{server: server, cache: cache}, nil
+}
+
+// Unmount detaches the filesystem; it fails while programs still use it
+func (m *Mount) Unmount() error {
+	return m.server.Unmount()
+}
+
+// Wait blocks until the filesystem is unmounted, then removes the items
+// extracted from compressed sessions
+func (m *Mount) Wait() {
+	m.server.Wait()
+	os.RemoveAll(m.cache)
+}
+
+// dirNode is a directory of the view that does not exist in the trash: the
+// root, a session or a parent directory of an original path
+type dirNode struct {
+	fs.Inode
+	mtime time.Time
+}
+
+var _ = (fs.NodeGetattrer)((*dirNode)(nil))
+
+func (n *dirNode) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	out.Mode = fuse.S_IFDIR | 0555
+	out.SetTimes(nil, &n.mtime, &n.mtime)
+	return fs.OK
+}
+
+// trashRoot is the root of the view; it builds the whole tree when mounted
+type trashRoot struct {
+	dirNode
+	items []config.TrashedItem
+	cache string
+}
+
+var _ = (fs.NodeOnAdder)((*trashRoot)(nil))
+
+func (r *trashRoot) OnAdd(ctx context.Context) {
+	for _, entry := range r.items {
+		trashDir, err := entry.SessionDir()
+		if err != nil {
+			continue
+		}
+		node, mode, ok := r.itemNode(trashDir, entry)
+		if !ok {
+			continue // its payload is gone; see 'trash doctor'
+		}
+
+		trashedAt := entry.TrashedTime()
+		parent := subdir(ctx, &r.Inode, entry.Session, trashedAt)
+		components := itemPath(entry.Item)
+		for _, component := range components[:len(components)-1] {
+			parent = subdir(ctx, parent, component, trashedAt)
+		}
+
+		name := uniqueName(parent, components[len(components)-1])
+		parent.AddChild(name, parent.NewPersistentInode(ctx, node, fs.StableAttr{Mode: mode}), false)
+	}
+}
+
+// subdir returns the directory name of parent, creating it if needed
+func subdir(ctx context.Context, parent *fs.Inode, name string, mtime time.Time) *fs.Inode {
+	if child := parent.GetChild(name); child != nil {
+		if _, ok := child.Operations().(*dirNode); ok {
+			return child
+		}
+		name = uniqueName(parent, name) // an item already has this name
+	}
+	child := parent.NewPersistentInode(ctx, &dirNode{mtime: mtime}, fs.StableAttr{Mode: fuse.S_IFDIR})
+	parent.AddChild(name, child, false)
+	return child
+}
+
+// uniqueName returns name, or "name (2)", "name (3)" and so on if parent
+// already has a child of that name
+func uniqueName(parent *fs.Inode, name string) string {
+	unique := name
+	for i := 2; parent.GetChild(unique) != nil; i++ {
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return unique
+}
+
+// itemNode returns the node showing the payload of an item of the session
+// trashDir, and its file type; ok is false when the payload is missing
+func (r *trashRoot) itemNode(trashDir string, entry config.TrashedItem) (node fs.InodeEmbedder, mode uint32, ok bool) {
+	if config.IsArchived(trashDir, entry.Item) {
+		n := &archivedNode{trashDir: trashDir, entry: entry}
+		n.RootData = &fs.LoopbackRoot{Path: filepath.Join(r.cache, entry.Session, entry.Item.Storage()), RootNode: n}
+		return n, archivedMode(entry.Item), true
+	}
+
+	path := entry.Item.PayloadPath(trashDir)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	var dev uint64
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		dev = uint64(st.Dev)
+	}
+	if info.IsDir() {
+		n := &fs.LoopbackNode{}
+		n.RootData = &fs.LoopbackRoot{Path: path, Dev: dev, RootNode: n}
+		return n, fuse.S_IFDIR, true
+	}
+	n := &fileNode{}
+	n.RootData = &fs.LoopbackRoot{Path: path, Dev: dev, RootNode: n}
+	return n, fileMode(info.Mode()), true
+}
+
+// fileNode shows an item that is not a directory. The loopback opens files
+// relative to its root directory, which such an item does not have.
+type fileNode struct {
+	fs.LoopbackNode
+}
+
+var _ = (fs.NodeOpener)((*fileNode)(nil))
+
+func (n *fileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	return openFile(n.RootData.Path)
+}
+
+// openFile opens a payload for reading; the filesystem is mounted read-only,
+// so nothing asks for more
+func openFile(path string) (fs.FileHandle, uint32, syscall.Errno) {
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, 0, fs.ToErrno(err)
+	}
+	return fs.NewLoopbackFile(fd), 0, fs.OK
+}
+
+// fileMode returns the file type bits of mode as FUSE expects them
+func fileMode(mode os.FileMode) uint32 {
+	switch {
+	case mode.IsDir():
+		return fuse.S_IFDIR
+	case mode&os.ModeSymlink != 0:
+		return fuse.S_IFLNK
+	default:
+		return fuse.S_IFREG
+	}
+}
+
+// archivedMode returns the file type of an item of a compressed session
+func archivedMode(item config.RestoreItem) uint32 {
+	switch item.Type {
+	case config.TypeDirectory:
+		return fuse.S_IFDIR
+	case config.TypeSymlink:
+		return fuse.S_IFLNK
+	default:
+		return fuse.S_IFREG
+	}
+}
+
+// archivedNode shows an item of a compressed session. Until it is first
+// opened its attributes come from the metadata; then it is extracted from
+// the session's archive and shown like any other item.
+type archivedNode struct {
+	fs.LoopbackNode
+	trashDir string
+	entry    config.TrashedItem
+
+	mu        sync.Mutex
+	extracted bool
+	err       error
+}
+
+var (
+	_ = (fs.NodeGetattrer)((*archivedNode)(nil))
+	_ = (fs.NodeGetxattrer)((*archivedNode)(nil))
+	_ = (fs.NodeListxattrer)((*archivedNode)(nil))
+	_ = (fs.NodeLookuper)((*archivedNode)(nil))
+	_ = (fs.NodeOpener)((*archivedNode)(nil))
+	_ = (fs.NodeOpendirHandler)((*archivedNode)(nil))
+	_ = (fs.NodeReaddirer)((*archivedNode)(nil))
+	_ = (fs.NodeReadlinker)((*archivedNode)(nil))
+)
+
+// extract unpacks the item from its session's archive once
+func (n *archivedNode) extract() syscall.Errno {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.extracted && n.err == nil {
+		path := n.RootData.Path
+		n.err = os.MkdirAll(filepath.Dir(path), 0700)
+		if n.err == nil {
+			n.err = config.ExtractArchived(n.trashDir, n.entry.Item, path)
+		}
+		n.extracted = n.err == nil
+	}
+	if n.err != nil {
+		return syscall.EIO
+	}
+	return fs.OK
+}
+
+// isExtracted reports whether the item has been unpacked
+func (n *archivedNode) isExtracted() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.extracted
+}
+
+func (n *archivedNode) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	if n.isExtracted() {
+		return n.LoopbackNode.Getattr(ctx, f, out)
+	}
+	mtime := n.entry.TrashedTime()
+	out.Mode = archivedMode(n.entry.Item) | 0444
+	if out.Mode&fuse.S_IFDIR != 0 {
+		out.Mode |= 0111
+	}
+	if n.entry.Item.Size != nil {
+		out.Size = *n.entry.Item.Size
+	}
+	out.SetTimes(nil, &mtime, &mtime)
+	return fs.OK
+}
+
+// Getxattr finds no extended attributes until the item is extracted
+func (n *archivedNode) Getxattr(ctx context.Context, attr string, dest []byte) (uint32, syscall.Errno) {
+	if n.isExtracted() {
+		return n.LoopbackNode.Getxattr(ctx, attr, dest)
+	}
+	return 0, fs.ENOATTR
+}
+
+func (n *archivedNode) Listxattr(ctx context.Context, dest []byte) (uint32, syscall.Errno) {
+	if n.isExtracted() {
+		return n.LoopbackNode.Listxattr(ctx, dest)
+	}
+	return 0, fs.OK
+}
+
+func (n *archivedNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	if errno := n.extract(); errno != fs.OK {
+		return nil, errno
+	}
+	return n.LoopbackNode.Lookup(ctx, name, out)
+}
+
+func (n *archivedNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if errno := n.extract(); errno != fs.OK {
+		return nil, 0, errno
+	}
+	return openFile(n.RootData.Path)
+}
+
+func (n *archivedNode) OpendirHandle(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if errno := n.extract(); errno != fs.OK {
+		return nil, 0, errno
+	}
+	return n.LoopbackNode.OpendirHandle(ctx, flags)
+}
+
+func (n *archivedNode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	if errno := n.extract(); errno != fs.OK {
+		return nil, errno
+	}
+	return n.LoopbackNode.Readdir(ctx)
+}
+
+func (n *archivedNode) Readlink(ctx context.Context) ([]byte, syscall.Errno) {
+	if errno := n.extract(); errno != fs.OK {
+		return nil, errno
+	}
+	return n.LoopbackNode.Readlink(ctx)
+}
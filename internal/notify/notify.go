@@ -0,0 +1,28 @@
+// Package notify sends desktop notifications
+package notify
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrUnavailable is returned when neither a notification service nor a terminal is available
+var ErrUnavailable = errors.New("no desktop notification service available")
+
+// appName identifies the sender in the notification
+const appName = "trash"
+
+// Send shows a desktop notification. Without a notification service it rings
+// the terminal bell on stderr instead, when stderr is a terminal.
+func Send(summary, body string) error {
+	if err := send(summary, body); err == nil {
+		return nil
+	}
+
+	if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		_, err := os.Stderr.WriteString("\a")
+		return err
+	}
+
+	return ErrUnavailable
+}
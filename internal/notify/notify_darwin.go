@@ -0,0 +1,14 @@
+//go:build darwin
+
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// send uses osascript to post a notification
+func send(summary, body string) error {
+	script := fmt.Sprintf("display notification %q with title %q", body, summary)
+	return exec.Command("osascript", "-e", script).Run()
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package notify
+
+import (
+	"os/exec"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/artemisfowl/trash/internal/bus"
+)
+
+// send uses the freedesktop notification service, falling back to notify-send
+func send(summary, body string) error {
+	if bus.Available() {
+		if err := sendDBus(summary, body); err == nil {
+			return nil
+		}
+	}
+
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return ErrUnavailable
+	}
+	return exec.Command(path, "--app-name", appName, "--icon", "user-trash-full", summary, body).Run()
+}
+
+// sendDBus calls org.freedesktop.Notifications.Notify on the session bus
+func sendDBus(summary, body string) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	return obj.Call("org.freedesktop.Notifications.Notify", 0,
+		appName, uint32(0), "user-trash-full", summary, body,
+		[]string{}, map[string]dbus.Variant{}, int32(-1)).Err
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package notify
+
+// send has no notification service to use on this platform
+func send(summary, body string) error {
+	return ErrUnavailable
+}
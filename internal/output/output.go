@@ -0,0 +1,139 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies how command results are rendered
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+	CSV  Format = "csv"
+)
+
+// ParseFormat validates an --output flag value
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(strings.ToLower(value)); f {
+	case Text, JSON, YAML, CSV:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unknown output format %q (expected text, json, yaml or csv)", value)
+}
+
+// Structured reports whether the format is machine readable rather than text
+func (f Format) Structured() bool {
+	return f != Text
+}
+
+// Write renders v in the given structured format
+// For CSV, v must be a struct or a slice of structs; columns follow the json tags
+func Write(w io.Writer, format Format, v interface{}) error {
+	switch format {
+	case JSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case YAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case CSV:
+		return writeCSV(w, v)
+	}
+
+	return fmt.Errorf("format %q is not structured", format)
+}
+
+// writeCSV writes a header row from the json tags of the element type followed by one row per element
+func writeCSV(w io.Writer, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	// A single struct is written as a one-row table
+	var rows []reflect.Value
+	var elemType reflect.Type
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elemType = rv.Type().Elem()
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, rv.Index(i))
+		}
+	case reflect.Struct:
+		elemType = rv.Type()
+		rows = append(rows, rv)
+	default:
+		return fmt.Errorf("cannot write %s as CSV", rv.Kind())
+	}
+
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot write %s as CSV", elemType.Kind())
+	}
+
+	var header []string
+	var fields []int
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if row.Kind() == reflect.Ptr {
+			row = row.Elem()
+		}
+		record := make([]string, len(fields))
+		for j, i := range fields {
+			value := row.Field(i)
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					continue // optional values that are not set are left empty
+				}
+				value = value.Elem()
+			}
+			record[j] = fmt.Sprint(value.Interface())
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
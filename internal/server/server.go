@@ -0,0 +1,298 @@
+// Package server implements the gRPC control interface served by `trash serve`
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	trashv1 "github.com/artemisfowl/trash/api/trash/v1"
+	"github.com/artemisfowl/trash/internal/bus"
+	"github.com/artemisfowl/trash/internal/config"
+)
+
+// Server implements trashv1.TrashServiceServer on top of the local trash store
+type Server struct {
+	trashv1.UnimplementedTrashServiceServer
+}
+
+// New returns a gRPC server with the trash service registered. With a token,
+// every call must carry it as "authorization: Bearer <token>" metadata and is
+// refused otherwise.
+func New(token string) *grpc.Server {
+	var opts []grpc.ServerOption
+	if token != "" {
+		opts = append(opts,
+			grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				if err := authorize(ctx, token); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := authorize(stream.Context(), token); err != nil {
+					return err
+				}
+				return handler(srv, stream)
+			}),
+		)
+	}
+	s := grpc.NewServer(opts...)
+	trashv1.RegisterTrashServiceServer(s, &Server{})
+	return s
+}
+
+// authorize checks that the call in ctx carries token
+func authorize(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(value), []byte("Bearer "+token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or wrong token")
+}
+
+// List returns every trashed item, oldest session first
+func (s *Server) List(ctx context.Context, req *trashv1.ListRequest) (*trashv1.ListResponse, error) {
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading trash directory: %v", err)
+	}
+
+	resp := &trashv1.ListResponse{}
+	for _, entry := range items {
+		resp.Items = append(resp.Items, newItem(entry))
+	}
+	return resp, nil
+}
+
+// Trash moves the requested paths into a new session, streaming progress per
+// path. Like the command line, it refuses paths protected by a .trashignore
+// file and checks all of them before moving any.
+func (s *Server) Trash(req *trashv1.TrashRequest, stream trashv1.TrashService_TrashServer) error {
+	if len(req.Paths) == 0 {
+		return status.Error(codes.InvalidArgument, "no paths given")
+	}
+
+	expiresAt := ""
+	if req.Expire != "" {
+		expireAfter, err := config.ParseDuration(req.Expire)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid expire value: %v", err)
+		}
+		expiresAt = time.Now().Add(expireAfter).Format(time.RFC3339)
+	}
+
+	done := 0
+	var sendErr error
+	trashDir, _, err := config.Trash(req.Paths, config.TrashOptions{ExpiresAt: expiresAt}, func(result config.TrashResult) {
+		done++
+		progress := &trashv1.TrashProgress{
+			Path:  result.Path,
+			Done:  int32(done),
+			Total: int32(len(req.Paths)),
+		}
+		if result.Err != nil {
+			progress.Error = result.Err.Error()
+		} else {
+			progress.Item = newItem(config.TrashedItem{Session: result.Session, Item: result.Item})
+		}
+		if sendErr == nil {
+			sendErr = stream.Send(progress)
+		}
+	})
+	var preflight *config.PreflightError
+	switch {
+	case errors.As(err, &preflight):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case trashDir == "":
+		return status.Errorf(codes.Internal, "creating trash directory: %v", err)
+	case err != nil:
+		return status.Errorf(codes.Internal, "saving restore metadata: %v", err)
+	}
+	bus.EmitChanged(bus.ReasonTrashed)
+	return sendErr
+}
+
+// Restore restores matching items, streaming progress per item
+func (s *Server) Restore(req *trashv1.RestoreRequest, stream trashv1.TrashService_RestoreServer) error {
+	if req.Name == "" {
+		return status.Error(codes.InvalidArgument, "no item name given")
+	}
+
+	matcher := config.MatchOptions{Normalize: true}
+	matchItem := func(item config.RestoreItem) bool {
+		return matcher.Equal(item.Name, req.Name)
+	}
+	if req.Regex {
+		re, err := matcher.Regexp(req.Name)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid regular expression: %v", err)
+		}
+		matchItem = func(item config.RestoreItem) bool {
+			return matcher.MatchRegexp(re, item.Name)
+		}
+	}
+
+	items, err := config.ListTrashedItems()
+	if err != nil {
+		return status.Errorf(codes.Internal, "reading trash directory: %v", err)
+	}
+
+	// Newest first; with a regex restore the latest instance of each original path,
+	// otherwise only the most recent match
+	seen := make(map[string]bool)
+	var matches []config.TrashedItem
+	for i := len(items) - 1; i >= 0; i-- {
+		entry := items[i]
+		if req.Session != "" && !strings.HasPrefix(entry.Session, req.Session) {
+			continue
+		}
+		if !matchItem(entry.Item) || seen[entry.Item.OriginalPath] {
+			continue
+		}
+		seen[entry.Item.OriginalPath] = true
+		matches = append(matches, entry)
+		if !req.Regex {
+			break
+		}
+	}
+
+	if len(matches) == 0 {
+		return status.Errorf(codes.NotFound, "item '%s' not found in trash", req.Name)
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return status.Errorf(codes.Internal, "getting config directory: %v", err)
+	}
+
+	onConflict := config.ConflictFail
+	if req.Force {
+		onConflict = config.ConflictOverwrite
+	}
+
+	defer bus.EmitChanged(bus.ReasonRestored)
+	for _, entry := range matches {
+		dest := entry.Item.OriginalPath
+		if req.TargetDir != "" {
+			dest = filepath.Join(req.TargetDir, entry.Item.Name)
+		}
+
+		progress := &trashv1.RestoreProgress{Item: newItem(entry)}
+		trashDir := filepath.Join(configDir, entry.Session)
+		var result *config.RestoreResult
+		err := config.VerifyRestoreMetadata(trashDir)
+		if err == nil {
+			result, err = config.RestoreTrashedItem(trashDir, entry.Item, dest, onConflict)
+		}
+		if err != nil {
+			progress.Error = err.Error()
+			if !req.Regex {
+				return status.Error(statusCode(err), err.Error())
+			}
+		} else {
+			progress.RestoredTo = result.Destination
+			progress.Overwrote = result.Overwrote
+		}
+
+		if err := stream.Send(progress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Empty permanently deletes all, expired or matching items
+func (s *Server) Empty(ctx context.Context, req *trashv1.EmptyRequest) (*trashv1.EmptyResponse, error) {
+	var purged []config.PurgedItem
+	var err error
+
+	switch {
+	case req.ExpiredOnly:
+		purged, err = config.PurgeExpired(time.Now())
+	case req.Regex != "":
+		matcher := config.MatchOptions{Normalize: true}
+		re, reErr := matcher.Regexp(req.Regex)
+		if reErr != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid regular expression: %v", reErr)
+		}
+		now := time.Now()
+		purged, err = config.PurgeMatching(func(entry config.TrashedItem) bool {
+			return matcher.MatchRegexp(re, entry.Item.Name) && !entry.Item.IsRetained(now)
+		})
+	default:
+		purged, err = config.EmptyTrash(false)
+	}
+
+	if len(purged) > 0 {
+		bus.EmitChanged(bus.ReasonPurged)
+	}
+
+	resp := &trashv1.EmptyResponse{}
+	for _, entry := range purged {
+		resp.Purged = append(resp.Purged, newItem(entry))
+	}
+	if err != nil {
+		return resp, status.Errorf(codes.Internal, "emptying trash: %v", err)
+	}
+	return resp, nil
+}
+
+// Stats reports usage of the trash store
+func (s *Server) Stats(ctx context.Context, req *trashv1.StatsRequest) (*trashv1.StatsResponse, error) {
+	usage, err := config.GetUsage(time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading trash directory: %v", err)
+	}
+
+	return &trashv1.StatsResponse{
+		Sessions:      int32(usage.Sessions),
+		Items:         int32(usage.Items),
+		TotalBytes:    usage.TotalBytes,
+		ExpiredItems:  int32(usage.ExpiredItems),
+		OldestSession: usage.OldestSession,
+		NewestSession: usage.NewestSession,
+	}, nil
+}
+
+// statusCode maps a library error to the matching gRPC status code
+func statusCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, config.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, config.ErrDestinationExists):
+		return codes.AlreadyExists
+	case errors.Is(err, config.ErrQuotaExceeded):
+		return codes.ResourceExhausted
+	case errors.Is(err, config.ErrTampered):
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// newItem converts a trashed item to its wire representation
+func newItem(entry config.TrashedItem) *trashv1.Item {
+	return &trashv1.Item{
+		Session:      entry.Session,
+		Name:         entry.Item.Name,
+		OriginalPath: entry.Item.OriginalPath,
+		TrashedAt:    entry.Item.TrashedAt,
+		ExpiresAt:    entry.Item.ExpiresAt,
+		User:         entry.Item.User,
+		Uid:          entry.Item.UID,
+		Hostname:     entry.Item.Hostname,
+		Type:         entry.Item.Type,
+		MimeType:     entry.Item.MIMEType,
+	}
+}